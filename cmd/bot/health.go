@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/gofiber/fiber/v2"
+)
+
+// healthCheckTimeout - таймаут каждой проверки зависимостей в /health
+const healthCheckTimeout = 3 * time.Second
+
+// Статусы ответа /health
+const (
+	healthOK        = "ok"        // Все зависимости доступны
+	healthDegraded  = "degraded"  // Telegram недоступен, но бот может обрабатывать уже полученные обновления
+	healthUnhealthy = "unhealthy" // База данных недоступна, бот не может работать
+)
+
+// healthChecks описывает проверки зависимостей для /health
+type healthChecks struct {
+	database  func(ctx context.Context) error
+	telegram  func(ctx context.Context) error
+	username  string
+	startTime time.Time
+}
+
+// telegramCheck проверяет доступность Telegram Bot API запросом getMe.
+// Клиент tgbotapi не принимает контекст, поэтому таймаут соблюдается ожиданием результата
+func telegramCheck(botAPI *tgbotapi.BotAPI) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		result := make(chan error, 1)
+		go func() {
+			_, err := botAPI.GetMe()
+			result <- err
+		}()
+
+		select {
+		case err := <-result:
+			return err
+		case <-ctx.Done():
+			return errors.New("превышено время ожидания ответа Telegram")
+		}
+	}
+}
+
+// healthHandler проверяет базу данных и Telegram. Если база недоступна, отвечает 503,
+// чтобы оркестратор перестал направлять трафик на экземпляр
+func healthHandler(checks healthChecks) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.Context(), healthCheckTimeout)
+		defer cancel()
+
+		status := healthOK
+		results := fiber.Map{
+			"database": healthOK,
+			"telegram": healthOK,
+		}
+
+		if err := checks.database(ctx); err != nil {
+			slog.Warn("Проверка здоровья: база данных недоступна", "error", err)
+			results["database"] = err.Error()
+			status = healthUnhealthy
+		}
+
+		if err := checks.telegram(ctx); err != nil {
+			slog.Warn("Проверка здоровья: Telegram недоступен", "error", err)
+			results["telegram"] = err.Error()
+			if status == healthOK {
+				status = healthDegraded
+			}
+		}
+
+		code := fiber.StatusOK
+		if status == healthUnhealthy {
+			code = fiber.StatusServiceUnavailable
+		}
+
+		uptime := time.Since(checks.startTime)
+		return c.Status(code).JSON(fiber.Map{
+			"status":         status,
+			"bot":            checks.username,
+			"uptime":         uptime.Truncate(time.Second).String(),
+			"uptime_seconds": int64(uptime.Seconds()),
+			"checks":         results,
+		})
+	}
+}
+
+// liveHandler сообщает только о том, что процесс запущен и отвечает на запросы
+func liveHandler(startTime time.Time) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":         healthOK,
+			"uptime_seconds": int64(time.Since(startTime).Seconds()),
+		})
+	}
+}
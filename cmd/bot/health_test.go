@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// healthResponse - тело ответа /health
+type healthResponse struct {
+	Status        string            `json:"status"`
+	Bot           string            `json:"bot"`
+	Uptime        string            `json:"uptime"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Checks        map[string]string `json:"checks"`
+}
+
+func TestHealthHandler(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name       string
+		database   func(ctx context.Context) error
+		telegram   func(ctx context.Context) error
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{"все доступно", ok, ok, fiber.StatusOK, healthOK,
+			map[string]string{"database": healthOK, "telegram": healthOK}},
+		{"база недоступна", down, ok, fiber.StatusServiceUnavailable, healthUnhealthy,
+			map[string]string{"database": "connection refused", "telegram": healthOK}},
+		{"Telegram недоступен", ok, down, fiber.StatusOK, healthDegraded,
+			map[string]string{"database": healthOK, "telegram": "connection refused"}},
+		{"все недоступно", down, down, fiber.StatusServiceUnavailable, healthUnhealthy,
+			map[string]string{"database": "connection refused", "telegram": "connection refused"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/health", healthHandler(healthChecks{
+				database:  tt.database,
+				telegram:  tt.telegram,
+				username:  "english_test_bot",
+				startTime: time.Now().Add(-90 * time.Second),
+			}))
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/health", nil))
+			if err != nil {
+				t.Fatalf("ошибка запроса /health: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var body healthResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("не удалось разобрать ответ: %v", err)
+			}
+
+			if resp.StatusCode != tt.wantCode || body.Status != tt.wantStatus {
+				t.Errorf("ответ %d со статусом %q, ожидался %d с %q", resp.StatusCode, body.Status, tt.wantCode, tt.wantStatus)
+			}
+			for name, want := range tt.wantChecks {
+				if body.Checks[name] != want {
+					t.Errorf("проверка %s = %q, ожидалось %q", name, body.Checks[name], want)
+				}
+			}
+			if body.Bot != "english_test_bot" || body.Uptime != "1m30s" || body.UptimeSeconds != 90 {
+				t.Errorf("бот %q, время работы %q (%d с)", body.Bot, body.Uptime, body.UptimeSeconds)
+			}
+		})
+	}
+}
+
+func TestLiveHandlerDoesNotCheckDependencies(t *testing.T) {
+	app := fiber.New()
+	app.Get("/live", liveHandler(time.Now().Add(-time.Minute)))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/live", nil))
+	if err != nil {
+		t.Fatalf("ошибка запроса /live: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body healthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("не удалось разобрать ответ: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK || body.Status != healthOK || body.UptimeSeconds != 60 {
+		t.Errorf("ответ %d: %+v", resp.StatusCode, body)
+	}
+	if body.Checks != nil {
+		t.Errorf("/live проверяет зависимости: %v", body.Checks)
+	}
+}
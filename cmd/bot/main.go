@@ -3,98 +3,145 @@ package main
 import (
 	"context"
 	"english-bot/internal/bot"
+	"english-bot/internal/config"
 	"english-bot/internal/database"
+	"english-bot/internal/logging"
 	"english-bot/internal/services"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/gofiber/fiber/v2"
-	"github.com/joho/godotenv"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 )
 
-// Конфигурация бота
-type Config struct {
-	TelegramToken string
-	OpenAIToken   string
-	DBConnString  string
-	Debug         bool
-}
+// Таймауты корректной остановки
+const (
+	shutdownTimeout = 5 * time.Second  // Ожидание завершения HTTP-запросов
+	drainTimeout    = 30 * time.Second // Ожидание обработки полученных обновлений
+)
+
+// newLogger создает логгер с указанным форматом и уровнем.
+// Записи с контекстом обновления получают его request_id
+func newLogger(format string, level slog.Level) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
 
-// Загрузка конфигурации из .env файла
-func loadConfig() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
-		return nil, fmt.Errorf("ошибка загрузки .env файла: %w", err)
+	var handler slog.Handler
+	if format == config.LogFormatText {
+		handler = slog.NewTextHandler(os.Stdout, options)
+	} else {
+		handler = slog.NewJSONHandler(os.Stdout, options)
 	}
 
-	return &Config{
-		TelegramToken: os.Getenv("TELEGRAM_TOKEN"),
-		OpenAIToken:   os.Getenv("OPENAI_TOKEN"),
-		DBConnString:  os.Getenv("DATABASE_URL"),
-		Debug:         os.Getenv("DEBUG") == "true",
-	}, nil
+	return slog.New(logging.NewContextHandler(handler))
 }
 
 // Основная функция запуска бота
 func main() {
-	// Настройка логгера
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
-	slog.SetDefault(logger)
+	startTime := time.Now()
+
+	// До загрузки конфигурации пишем журнал в JSON с уровнем info
+	slog.SetDefault(newLogger(config.LogFormatJSON, slog.LevelInfo))
 
 	// Загрузка конфигурации
-	config, err := loadConfig()
+	cfg, err := config.Load()
 	if err != nil {
 		slog.Error("Ошибка загрузки конфигурации", "error", err)
 		os.Exit(1)
 	}
 
+	slog.SetDefault(newLogger(cfg.LogFormat, cfg.LogLevel))
+
 	// Инициализация Telegram бота
-	botAPI, err := tgbotapi.NewBotAPI(config.TelegramToken)
+	botAPI, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
 	if err != nil {
 		slog.Error("Ошибка инициализации Telegram API", "error", err)
 		os.Exit(1)
 	}
 
-	botAPI.Debug = config.Debug
+	botAPI.Debug = cfg.Debug
 	slog.Info("Бот успешно авторизован", "username", botAPI.Self.UserName)
 
 	// Подключение к базе данных
-	db, err := database.NewPostgresDB(config.DBConnString)
+	db, err := database.NewPostgresDB(cfg.DBConnString, cfg.DBConnectAttempts, cfg.DBConnectInterval)
 	if err != nil {
 		slog.Error("Ошибка подключения к базе данных", "error", err)
 		os.Exit(1)
 	}
-	defer db.Close()
+
+	// Применение миграций схемы базы данных
+	if err := db.Migrate(context.Background()); err != nil {
+		slog.Error("Ошибка применения миграций", "error", err)
+		os.Exit(1)
+	}
 
 	// Инициализация сервисов
-	openAIService := services.NewOpenAIService(config.OpenAIToken)
+	openAIService := services.NewOpenAIService(cfg.OpenAIToken, cfg.OpenAIBaseURL)
+	openAIService.SetModels(cfg.OpenAIModel, cfg.GrammarModel, cfg.ExerciseModel)
+	openAIService.SetCircuitBreaker(services.NewCircuitBreaker(cfg.OpenAIBreakerErrors, cfg.OpenAIBreakerPause))
+	openAIService.SetContextTokens(cfg.OpenAIContextTokens)
+	openAIService.SetTimeout(cfg.OpenAITimeout)
 	exerciseService := services.NewExerciseService(openAIService)
-	languageToolService := services.NewLanguageToolService()
+	languageToolService := services.NewLanguageToolService(cfg.LanguageToolURL, cfg.LanguageToolTimeout)
+	languageToolService.EnableCache(cfg.LanguageToolCache, cfg.LanguageToolTTL)
 	progressService := services.NewProgressService(db)
 
+	// Контекст работы бота отменяется при остановке и прерывает фоновые запросы
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Инициализация обработчиков
 	handler := bot.NewHandler(botAPI, db, openAIService)
 	// Установка дополнительных сервисов
 	handler.SetExerciseService(exerciseService)
 	handler.SetLanguageToolService(languageToolService)
 	handler.SetProgressService(progressService)
+	handler.SetModeration(cfg.Moderation)
+	handler.SetFeedbackRecipients(cfg.AdminIDs)
+	handler.SetExercisePool(ctx, cfg.ExercisePoolSize)
 
-	middleware := bot.NewMiddleware(*handler)
+	// Хранилище сессий: по умолчанию база данных, для нескольких экземпляров бота - Redis
+	var redisClient *redis.Client
+	if cfg.SessionStore == config.SessionStoreRedis {
+		redisClient, err = newRedisClient(cfg.RedisURL)
+		if err != nil {
+			slog.Error("Ошибка подключения к Redis", "error", err)
+			os.Exit(1)
+		}
+		handler.SetSessionStore(bot.NewRedisSessionStore(redisClient, db, cfg.SessionCacheTTL))
+		slog.Info("Сессии хранятся в Redis")
+	}
 
-	// Настройка обработки обновлений
-	updateConfig := tgbotapi.NewUpdate(0)
-	updateConfig.Timeout = 60
+	if len(cfg.AdminIDs) == 0 {
+		slog.Warn("Не заданы администраторы ADMIN_IDS, служебные команды недоступны")
+	}
 
-	updates := botAPI.GetUpdatesChan(updateConfig)
+	// Регистрация меню команд в Telegram
+	if err := handler.RegisterCommands(); err != nil {
+		slog.Warn("Не удалось зарегистрировать команды бота", "error", err)
+	}
 
-	// Создаем канал для сигналов завершения
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Цепочка обработки: восстановление после паники -> отбрасывание повторных обновлений ->
+	// отбрасывание устаревших сообщений -> метрики -> логирование и таймаут ->
+	// ограничение частоты запросов -> проверка прав администратора -> обработчик
+	adminMiddleware := bot.NewAdminMiddleware(handler, botAPI, cfg.AdminIDs)
+	var rateLimiter *bot.RateLimiter
+	if cfg.RateLimitStore == config.RateLimitStorePostgres {
+		rateLimiter = bot.NewPostgresRateLimiter(adminMiddleware, botAPI, db, cfg.RateLimitWindow, cfg.CommandRateLimits)
+	} else {
+		rateLimiter = bot.NewRateLimiter(adminMiddleware, botAPI, cfg.RateLimitWindow, cfg.CommandRateLimits)
+	}
+	var middleware bot.UpdateHandler = bot.NewMiddleware(rateLimiter, config.UpdateTimeout)
+	middleware = bot.NewMetricsMiddleware(middleware)
+	middleware = bot.NewStaleUpdateFilter(middleware, startTime, cfg.StaleThreshold)
+	middleware = bot.NewDuplicateUpdateFilter(middleware)
+	middleware = bot.NewRecoveryMiddleware(middleware, botAPI)
 
 	// Обработка сигналов остановки
 	go func() {
@@ -108,11 +155,37 @@ func main() {
 	// Запуск API сервера на Fiber (опционально)
 	app := fiber.New()
 
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"status": "ok",
-		})
-	})
+	// Проверки состояния: /live - процесс запущен, /health - доступны база данных и Telegram
+	app.Get("/live", liveHandler(startTime))
+	app.Get("/health", healthHandler(healthChecks{
+		database:  db.Ping,
+		telegram:  telegramCheck(botAPI),
+		username:  botAPI.Self.UserName,
+		startTime: startTime,
+	}))
+
+	// Метрики Prometheus
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+
+	// Настройка получения обновлений: вебхук или long polling
+	var updates tgbotapi.UpdatesChannel
+	var webhookUpdates chan tgbotapi.Update
+	if cfg.BotMode == config.BotModeWebhook {
+		webhookUpdates, err = setupWebhook(botAPI, app, cfg.WebhookURL, cfg.WebhookSecret)
+		updates = webhookUpdates
+		if err != nil {
+			slog.Error("Ошибка настройки вебхука", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Бот работает в режиме вебхука")
+	} else {
+		updates, err = setupPolling(botAPI)
+		if err != nil {
+			slog.Error("Ошибка настройки long polling", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Бот работает в режиме long polling")
+	}
 
 	go func() {
 		if err := app.Listen(":8080"); err != nil {
@@ -121,25 +194,73 @@ func main() {
 	}()
 
 	// Обработка сообщений через middleware и handler
-	go processUpdates(ctx, middleware, updates)
+	workers := bot.NewWorkerPool(middleware, cfg.UpdateWorkers)
+	processorDone := make(chan struct{})
+	go func() {
+		processUpdates(workers, updates)
+		close(processorDone)
+	}()
+
+	// Запуск фоновых задач
+	scheduler := bot.NewScheduler()
+	scheduler.Add("daily_word", 10*time.Minute, handler.SendDailyWords)
+	scheduler.Add("study_reminders", time.Hour, handler.SendStudyReminders)
+	scheduler.Add("weekly_report", time.Hour, handler.SendWeeklyReports)
+	scheduler.Add("exercise_timeouts", 5*time.Minute, handler.ExpireStaleExercises)
+	scheduler.Start(ctx)
+	rateLimiter.StartCleanup(ctx)
 
 	// Ожидание завершения контекста
 	<-ctx.Done()
+
+	// Останавливаем прием новых обновлений: tgbotapi закрывает канал long polling сам,
+	// а канал вебхука закрываем после остановки Fiber, когда в него больше никто не пишет
+	if cfg.BotMode != config.BotModeWebhook {
+		botAPI.StopReceivingUpdates()
+	}
+	if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		slog.Error("Ошибка остановки Fiber сервера", "error", err)
+	}
+	if webhookUpdates != nil {
+		close(webhookUpdates)
+	}
+
+	// Дожидаемся, пока все уже полученные обновления попадут в пул, и завершения их обработки
+	<-processorDone
+	if !workers.Shutdown(drainTimeout) {
+		slog.Warn("Не все обновления успели обработаться до остановки")
+	}
+
+	if redisClient != nil {
+		redisClient.Close()
+	}
+	db.Close()
 	slog.Info("Бот остановлен")
 }
 
-// processUpdates обрабатывает обновления от Telegram API
-func processUpdates(ctx context.Context, middleware *bot.Middleware, updates tgbotapi.UpdatesChannel) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case update := <-updates:
-			// Создаем новый контекст для каждого обновления
-			updateCtx := context.Background()
-
-			// Обрабатываем обновление асинхронно
-			go middleware.HandleUpdate(updateCtx, update)
-		}
+// newRedisClient подключается к Redis по адресу вида redis://host:port/db и проверяет соединение
+func newRedisClient(url string) (*redis.Client, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("ошибка проверки соединения с Redis: %w", err)
+	}
+
+	return client, nil
+}
+
+// processUpdates передает обновления от Telegram API в пул обработчиков, пока канал не закрыт.
+// Отмена контекста не прерывает цикл: обновления, уже попавшие в буфер канала,
+// подтверждены Telegram и должны быть обработаны
+func processUpdates(workers *bot.WorkerPool, updates tgbotapi.UpdatesChannel) {
+	for update := range updates {
+		workers.Submit(update)
 	}
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"english-bot/internal/bot"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// countingHandler считает обработанные обновления
+type countingHandler struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (h *countingHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	time.Sleep(10 * time.Millisecond)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.count++
+}
+
+func TestProcessUpdatesDrainsOnShutdown(t *testing.T) {
+	handler := &countingHandler{}
+	workers := bot.NewWorkerPool(handler, 2)
+
+	updates := make(chan tgbotapi.Update, 10)
+	for i := range 10 {
+		updates <- tgbotapi.Update{UpdateID: i, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: int64(i)}}}
+	}
+
+	// Как в main: по сигналу остановки прием обновлений прекращается и канал закрывается,
+	// хотя в буфере еще лежат подтвержденные Telegram обновления
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ctx.Done()
+		close(updates)
+	}()
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		processUpdates(workers, updates)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("processUpdates не завершился после закрытия канала обновлений")
+	}
+
+	if !workers.Shutdown(time.Second) {
+		t.Fatal("обработчики не завершились за отведенное время")
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if handler.count != 10 {
+		t.Errorf("обработано %d обновлений из 10 полученных до остановки", handler.count)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/gofiber/fiber/v2"
+)
+
+// webhookBufferSize - размер буфера канала обновлений, полученных через вебхук
+const webhookBufferSize = 100
+
+// setupPolling настраивает получение обновлений через long polling
+func setupPolling(botAPI *tgbotapi.BotAPI) (tgbotapi.UpdatesChannel, error) {
+	// Long polling не работает, пока у бота зарегистрирован вебхук
+	if _, err := botAPI.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return nil, fmt.Errorf("ошибка удаления вебхука: %w", err)
+	}
+
+	updateConfig := tgbotapi.NewUpdate(0)
+	updateConfig.Timeout = 60
+
+	return botAPI.GetUpdatesChan(updateConfig), nil
+}
+
+// setupWebhook регистрирует вебхук в Telegram и добавляет в Fiber маршрут для приема обновлений.
+// Возвращенный канал закрывает вызывающий код после остановки Fiber
+func setupWebhook(botAPI *tgbotapi.BotAPI, app *fiber.App, webhookURL, secret string) (chan tgbotapi.Update, error) {
+	if webhookURL == "" || secret == "" {
+		return nil, fmt.Errorf("для режима вебхука необходимо задать WEBHOOK_URL и WEBHOOK_SECRET")
+	}
+
+	updates := make(chan tgbotapi.Update, webhookBufferSize)
+	app.Post("/webhook/:secret", webhookHandler(secret, updates))
+
+	// tgbotapi не поддерживает secret_token, поэтому регистрируем вебхук напрямую
+	params := tgbotapi.Params{
+		"url":          strings.TrimRight(webhookURL, "/") + "/webhook/" + secret,
+		"secret_token": secret,
+	}
+	if _, err := botAPI.MakeRequest("setWebhook", params); err != nil {
+		return nil, fmt.Errorf("ошибка регистрации вебхука: %w", err)
+	}
+
+	return updates, nil
+}
+
+// webhookHandler принимает обновления от Telegram, проверяет секрет и передает их в канал обработки
+func webhookHandler(secret string, updates chan<- tgbotapi.Update) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !secretEqual(c.Params("secret"), secret) ||
+			!secretEqual(c.Get("X-Telegram-Bot-Api-Secret-Token"), secret) {
+			slog.Warn("Запрос к вебхуку с неверным секретом", "ip", c.IP())
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		var update tgbotapi.Update
+		if err := json.Unmarshal(c.Body(), &update); err != nil {
+			slog.Error("Ошибка разбора обновления из вебхука", "error", err)
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+
+		updates <- update
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+// secretEqual сравнивает секреты за постоянное время
+func secretEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	const secret = "s3cret"
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		body       string
+		wantStatus int
+		wantUpdate bool
+	}{
+		{"верный секрет", "/webhook/" + secret, secret, `{"update_id": 42, "message": {"message_id": 1, "text": "hi"}}`, http.StatusOK, true},
+		{"неверный секрет в пути", "/webhook/wrong", secret, `{"update_id": 42}`, http.StatusUnauthorized, false},
+		{"неверный заголовок", "/webhook/" + secret, "wrong", `{"update_id": 42}`, http.StatusUnauthorized, false},
+		{"нет заголовка", "/webhook/" + secret, "", `{"update_id": 42}`, http.StatusUnauthorized, false},
+		{"некорректный JSON", "/webhook/" + secret, secret, `{"update_id":`, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := make(chan tgbotapi.Update, 1)
+			app := fiber.New()
+			app.Post("/webhook/:secret", webhookHandler(secret, updates))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("X-Telegram-Bot-Api-Secret-Token", tt.header)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("ошибка запроса: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("статус %d, ожидался %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			select {
+			case update := <-updates:
+				if !tt.wantUpdate {
+					t.Errorf("обновление %d передано в обработку", update.UpdateID)
+				} else if update.UpdateID != 42 || update.Message == nil || update.Message.Text != "hi" {
+					t.Errorf("получено обновление %+v", update)
+				}
+			default:
+				if tt.wantUpdate {
+					t.Error("обновление не передано в обработку")
+				}
+			}
+		})
+	}
+}
+
+func TestSecretEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"secret", "secret", true},
+		{"secret", "Secret", false},
+		{"secret", "secret2", false},
+		{"", "", true},
+		{"", "secret", false},
+	}
+
+	for _, tt := range tests {
+		if got := secretEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("secretEqual(%q, %q) = %v, ожидалось %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleAchievements показывает полученные достижения и те, что еще можно открыть
+func (h *Handler) handleAchievements(ctx context.Context, chatID int64, user *database.User) {
+	achievements, err := h.db.GetUserAchievements(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения достижений", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatAchievements(languageFrom(ctx), achievements, user.EnglishLevel))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// formatAchievements форматирует список полученных и закрытых достижений на языке lang
+func formatAchievements(lang string, achievements []database.UserAchievement, level string) string {
+	var result strings.Builder
+	result.WriteString(i18n.T(lang, "achievements.title") + "\n\n")
+
+	unlocked := make(map[string]bool, len(achievements))
+	if len(achievements) == 0 {
+		result.WriteString(i18n.T(lang, "achievements.none") + "\n")
+	}
+	for _, achievement := range achievements {
+		unlocked[achievement.AchievementType] = true
+
+		// Название берем из каталога, чтобы старые записи выглядели так же, как новые
+		title := achievement.Title
+		if definition, ok := database.FindAchievement(achievement.AchievementType); ok {
+			title = definition.Title
+		}
+		result.WriteString(fmt.Sprintf("✅ %s — %s\n", escapeMarkdown(title), achievement.UnlockedAt.Format("02 Jan 2006")))
+	}
+
+	levelIndex := slices.Index(database.EnglishLevels, level)
+
+	var locked []string
+	for _, definition := range database.AchievementCatalog {
+		if unlocked[definition.Type] {
+			continue
+		}
+		// Уровни, которые пользователь уже прошел, открыть больше нельзя
+		if definition.Level != "" && slices.Index(database.EnglishLevels, definition.Level) <= levelIndex {
+			continue
+		}
+		locked = append(locked, fmt.Sprintf("🔒 %s — %s", definition.Title, definition.Description))
+	}
+
+	if len(locked) > 0 {
+		result.WriteString("\n" + i18n.T(lang, "achievements.locked") + "\n")
+		result.WriteString(strings.Join(locked, "\n"))
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
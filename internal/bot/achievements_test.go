@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatAchievements(t *testing.T) {
+	achievements := []database.UserAchievement{
+		{AchievementType: database.StreakAchievementType(7), Title: "old title", UnlockedAt: time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{AchievementType: database.LevelUpAchievementType("A2"), UnlockedAt: time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	message := formatAchievements("en", achievements, "B1")
+
+	streak, _ := database.FindAchievement(database.StreakAchievementType(7))
+	if !strings.Contains(message, "✅ "+escapeMarkdown(streak.Title)+" — 05 Mar 2024") {
+		t.Errorf("полученное достижение показано не по каталогу или без даты:\n%s", message)
+	}
+	if strings.Contains(message, "old title") {
+		t.Error("показано устаревшее название достижения")
+	}
+
+	_, locked, _ := strings.Cut(message, i18n.T("en", "achievements.locked"))
+	for _, achievementType := range []string{database.StreakAchievementType(7), database.LevelUpAchievementType("A2"), database.LevelUpAchievementType("B1")} {
+		definition, _ := database.FindAchievement(achievementType)
+		if strings.Contains(locked, definition.Title) {
+			t.Errorf("достижение %q показано среди закрытых", achievementType)
+		}
+	}
+	for _, achievementType := range []string{database.StreakAchievementType(30), database.ExerciseAchievementType(10), database.LevelUpAchievementType("B2")} {
+		definition, _ := database.FindAchievement(achievementType)
+		if !strings.Contains(locked, "🔒 "+definition.Title) {
+			t.Errorf("достижения %q нет среди закрытых", achievementType)
+		}
+	}
+}
+
+func TestFormatAchievementsWithoutAchievements(t *testing.T) {
+	message := formatAchievements("en", nil, "A1")
+
+	if !strings.Contains(message, i18n.T("en", "achievements.none")) {
+		t.Errorf("нет сообщения об отсутствии достижений:\n%s", message)
+	}
+	if got := strings.Count(message, "🔒"); got != len(database.AchievementCatalog) {
+		t.Errorf("закрытых достижений %d, ожидалось %d", got, len(database.AchievementCatalog))
+	}
+}
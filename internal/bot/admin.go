@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/services"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleStats показывает администратору общую статистику использования бота
+func (h *Handler) handleStats(ctx context.Context, chatID int64) {
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	stats, err := h.db.GetBotStats(ctx, dayStart)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения статистики бота", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	text := tr(ctx, "admin.stats",
+		stats.TotalUsers,
+		stats.ActiveToday,
+		stats.ActiveWeek,
+		stats.TotalExercises,
+		stats.TotalMessages,
+	)
+
+	// Разбивка ответов по типам упражнений дополняет статистику, но не обязательна
+	typeStats, err := h.db.GetExerciseTypeStats(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения статистики по типам упражнений", "error", err)
+	} else if len(typeStats) > 0 {
+		successByType := make(map[string]float64, len(typeStats))
+		for _, stat := range typeStats {
+			successByType[stat.ExerciseType] = stat.SuccessRate()
+		}
+		text += "\n" + services.FormatSuccessByType(languageFrom(ctx), successByType)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// handleReload заново регистрирует меню команд в Telegram, например после обновления списка команд
+func (h *Handler) handleReload(ctx context.Context, chatID int64) {
+	if err := h.RegisterCommands(); err != nil {
+		slog.ErrorContext(ctx, "Ошибка повторной регистрации команд", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "Команды бота зарегистрированы повторно", "chat_id", chatID)
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "admin.reloaded"))
+	h.bot.Send(msg)
+}
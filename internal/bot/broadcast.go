@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// broadcastInterval - пауза между сообщениями рассылки.
+// Telegram допускает около 30 сообщений в секунду, мы отправляем не больше 25
+const broadcastInterval = time.Second / 25
+
+// messageSender отправляет сообщения в Telegram. Его реализует *tgbotapi.BotAPI
+type messageSender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+// broadcastResult - итог рассылки
+type broadcastResult struct {
+	Sent    int // Доставленные сообщения
+	Blocked int // Пользователи, заблокировавшие бота
+	Failed  int // Остальные ошибки отправки
+}
+
+// handleBroadcast отправляет сообщение администратора всем пользователям.
+// Рассылка идет в фоне, а по ее завершении администратор получает отчет
+func (h *Handler) handleBroadcast(ctx context.Context, chatID int64, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "broadcast.usage"))
+		h.bot.Send(msg)
+		return
+	}
+
+	chatIDs, err := h.db.GetAllUserChatIDs(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения списка пользователей для рассылки", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "broadcast.started", len(chatIDs)))
+	h.bot.Send(msg)
+
+	// Рассылка может занять больше времени, чем таймаут обработки обновления
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		slog.InfoContext(ctx, "Начата рассылка", "admin_chat_id", chatID, "recipients", len(chatIDs))
+
+		result := broadcast(ctx, h.bot, chatIDs, text, broadcastInterval)
+
+		slog.InfoContext(ctx, "Рассылка завершена",
+			"sent", result.Sent,
+			"blocked", result.Blocked,
+			"failed", result.Failed,
+		)
+
+		report := tgbotapi.NewMessage(chatID, tr(ctx, "broadcast.finished",
+			result.Sent, result.Blocked, result.Failed,
+		))
+		h.bot.Send(report)
+	}()
+}
+
+// broadcast отправляет текст во все чаты не чаще одного сообщения за interval.
+// Пользователи, заблокировавшие бота, пропускаются. Рассылка прерывается при отмене контекста
+func broadcast(ctx context.Context, sender messageSender, chatIDs []int64, text string, interval time.Duration) broadcastResult {
+	var result broadcastResult
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for i, chatID := range chatIDs {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return result
+			case <-ticker.C:
+			}
+		}
+
+		_, err := sender.Send(tgbotapi.NewMessage(chatID, text))
+		switch {
+		case err == nil:
+			result.Sent++
+		case isBlockedByUser(err):
+			result.Blocked++
+		default:
+			result.Failed++
+			slog.WarnContext(ctx, "Ошибка отправки сообщения рассылки", "error", err, "chat_id", chatID)
+		}
+	}
+
+	return result
+}
+
+// isBlockedByUser проверяет, что Telegram отказал в отправке, потому что
+// пользователь заблокировал бота или удалил аккаунт
+func isBlockedByUser(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
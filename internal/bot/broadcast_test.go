@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/i18n"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeSender запоминает получателей сообщений и возвращает заданные ошибки
+type fakeSender struct {
+	mu      sync.Mutex
+	errors  map[int64]error
+	chatIDs []int64
+}
+
+func (s *fakeSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chatID := c.(tgbotapi.MessageConfig).ChatID
+	s.chatIDs = append(s.chatIDs, chatID)
+	return tgbotapi.Message{}, s.errors[chatID]
+}
+
+func TestBroadcast(t *testing.T) {
+	sender := &fakeSender{errors: map[int64]error{
+		2: &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"},
+		4: errors.New("соединение разорвано"),
+	}}
+	chatIDs := []int64{1, 2, 3, 4, 5}
+	interval := 10 * time.Millisecond
+
+	start := time.Now()
+	result := broadcast(context.Background(), sender, chatIDs, "New feature!", interval)
+	elapsed := time.Since(start)
+
+	if want := (broadcastResult{Sent: 3, Blocked: 1, Failed: 1}); result != want {
+		t.Errorf("итог рассылки %+v, ожидался %+v", result, want)
+	}
+	if !slices.Equal(sender.chatIDs, chatIDs) {
+		t.Errorf("сообщения отправлены в чаты %v, ожидалось %v", sender.chatIDs, chatIDs)
+	}
+	if minimum := time.Duration(len(chatIDs)-1) * interval; elapsed < minimum {
+		t.Errorf("рассылка заняла %v, при паузе %v ожидалось не меньше %v", elapsed, interval, minimum)
+	}
+}
+
+func TestBroadcastStopsOnCancel(t *testing.T) {
+	sender := &fakeSender{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := broadcast(ctx, sender, []int64{1, 2, 3}, "New feature!", time.Hour)
+
+	if result.Sent != 1 || len(sender.chatIDs) != 1 {
+		t.Errorf("после отмены отправлено %d сообщений, ожидалось только первое", len(sender.chatIDs))
+	}
+}
+
+func TestIsBlockedByUser(t *testing.T) {
+	if !isBlockedByUser(&tgbotapi.Error{Code: http.StatusForbidden}) {
+		t.Error("ошибка 403 не распознана как блокировка бота")
+	}
+	if isBlockedByUser(&tgbotapi.Error{Code: http.StatusBadRequest}) || isBlockedByUser(errors.New("timeout")) {
+		t.Error("другие ошибки распознаны как блокировка бота")
+	}
+}
+
+func TestHandleBroadcastWithoutText(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	h.handleBroadcast(context.Background(), 1, "   ")
+
+	sent := stub.sent()
+	if len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "broadcast.usage") {
+		t.Errorf("отправлено %q, ожидалась подсказка по использованию", sent)
+	}
+}
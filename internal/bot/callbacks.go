@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Префиксы данных inline-кнопок. Данные кнопки имеют вид "<префикс>:<параметры>"
+const (
+	callbackAnswer       = "answer"   // answer:<exerciseID>:<индекс варианта>
+	callbackSettings     = "settings" // settings:<настройка>[:<значение>]
+	callbackLevel        = "level"    // level:<уровень>
+	callbackForget       = "forget"   // forget:confirm или forget:cancel
+	callbackReset        = "reset"    // reset:keep, reset:all или reset:cancel
+	callbackExercise     = "exercise" // exercise:<тип упражнения>
+	callbackPractice     = "practice" // practice:<количество>:<тип упражнения>
+	callbackChat         = "chat"     // chat:<тема диалога>
+	callbackExplain      = "explain"  // explain:<exerciseID>
+	callbackHistory      = "history"  // history:<смещение>
+	callbackGrammarTopic = "grammar"  // grammar:<тема грамматики> или grammar:any
+)
+
+// handleCallback обрабатывает нажатия на inline-кнопки
+func (h *Handler) handleCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) {
+	slog.InfoContext(ctx, "Получен callback",
+		"from", callback.From.UserName,
+		"data", callback.Data,
+	)
+
+	// Отвечаем на языке интерфейса пользователя, не создавая его запись:
+	// после удаления данных кнопки старых сообщений не должны заводить пользователя заново
+	user, err := h.db.GetUserByTelegramID(ctx, callback.From.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения пользователя", "error", err)
+	} else if user != nil {
+		ctx = withLanguage(ctx, h.userLanguage(ctx, user))
+	}
+
+	prefix, payload, _ := strings.Cut(callback.Data, ":")
+
+	switch prefix {
+	case callbackAnswer:
+		h.handleAnswerCallback(ctx, callback, payload)
+
+	case callbackSettings:
+		h.handleSettingsCallback(ctx, callback, payload)
+
+	case callbackLevel:
+		h.handleLevelCallback(ctx, callback, payload)
+
+	case callbackForget:
+		h.handleForgetCallback(ctx, callback, payload)
+
+	case callbackReset:
+		h.handleResetCallback(ctx, callback, payload)
+
+	case callbackExercise:
+		h.handleExerciseTypeCallback(ctx, callback, payload)
+
+	case callbackGrammarTopic:
+		h.handleGrammarTopicCallback(ctx, callback, payload)
+
+	case callbackPractice:
+		h.handlePracticeCallback(ctx, callback, payload)
+
+	case callbackChat:
+		h.handleChatTopicCallback(ctx, callback, payload)
+
+	case callbackExplain:
+		h.handleExplainCallback(ctx, callback, payload)
+
+	case callbackHistory:
+		h.handleHistoryCallback(ctx, callback, payload)
+
+	default:
+		h.answerCallback(callback.ID, "")
+	}
+}
+
+// answerCallback подтверждает получение callback, чтобы у кнопки пропал индикатор загрузки
+func (h *Handler) answerCallback(callbackID string, text string) {
+	h.bot.Request(tgbotapi.NewCallback(callbackID, text))
+}
+
+// callbackUser возвращает пользователя, нажавшего кнопку. Пользователь не создается заново:
+// кнопки могли остаться в чате после удаления данных через /forgetme. Если пользователя нет
+// или его не удалось получить, на нажатие уже дан ответ и вернется false
+func (h *Handler) callbackUser(ctx context.Context, callback *tgbotapi.CallbackQuery, chatID int64) (*database.User, bool) {
+	user, err := h.db.GetUserByTelegramID(ctx, callback.From.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения пользователя", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return nil, false
+	}
+	if user == nil {
+		h.answerCallback(callback.ID, tr(ctx, "callback.inactive"))
+		return nil, false
+	}
+	return user, true
+}
+
+// handleAnswerCallback проверяет вариант ответа, выбранный на inline-клавиатуре упражнения
+func (h *Handler) handleAnswerCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, payload string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	exerciseIDStr, optionIndexStr, _ := strings.Cut(payload, ":")
+	exerciseID, err := strconv.ParseInt(exerciseIDStr, 10, 64)
+	if err != nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	optionIndex, err := strconv.Atoi(optionIndexStr)
+	if err != nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	session, err := h.sessions.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения сессии", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	// Принимаем ответ только на текущее упражнение
+	var contextData map[string]string
+	json.Unmarshal(session.ContextData, &contextData)
+	if session.State != StateExerciseReply || contextData["exerciseID"] != exerciseIDStr {
+		h.answerCallback(callback.ID, tr(ctx, "exercise.inactive"))
+		return
+	}
+
+	exercise, err := h.db.GetExerciseByID(ctx, exerciseID)
+	if err != nil || exercise == nil || optionIndex < 0 || optionIndex >= len(exercise.Options) {
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения упражнения", "error", err)
+		}
+		h.answerCallback(callback.ID, tr(ctx, "exercise.unavailable"))
+		return
+	}
+
+	answer := strings.TrimSpace(exercise.Options[optionIndex])
+	h.answerCallback(callback.ID, "")
+
+	isCorrect := h.completeExercise(ctx, chatID, user, session, exercise, answer)
+
+	// Убираем клавиатуру и отмечаем выбранный вариант
+	mark := "❌"
+	if isCorrect {
+		mark = "✅"
+	}
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		tr(ctx, "exercise.your_answer", callback.Message.Text, answer, mark))
+	h.bot.Send(edit)
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// chatTopicFree - тема свободного диалога без заданного направления
+const chatTopicFree = "general"
+
+// chatTopic - тема диалога, которую можно выбрать после /chat
+type chatTopic struct {
+	ID       string   // Тема, сохраняемая в диалоге
+	Aliases  []string // Другие названия темы для /chat <тема>
+	LabelKey string   // Ключ названия кнопки в каталоге сообщений
+	Focus    string   // Описание темы для системного промпта; пустое для свободного диалога
+}
+
+// chatTopics - темы диалога на клавиатуре выбора в порядке показа
+var chatTopics = []chatTopic{
+	{ID: "travel", LabelKey: "chat.topic.travel", Focus: "travel: trips, transport, hotels, sightseeing and travel stories"},
+	{ID: "food", LabelKey: "chat.topic.food", Focus: "food: cooking, restaurants, favourite dishes and ordering food"},
+	{ID: "business", LabelKey: "chat.topic.business", Focus: "business: work, meetings, emails, negotiations and careers. Use a professional register and business vocabulary"},
+	{ID: "smalltalk", Aliases: []string{"small_talk", "small-talk"}, LabelKey: "chat.topic.smalltalk", Focus: "small talk: weather, hobbies, weekends, plans and other everyday topics"},
+	{ID: chatTopicFree, Aliases: []string{"free"}, LabelKey: "chat.topic.free"},
+}
+
+// findChatTopic ищет тему диалога по ее названию или другому названию без учета регистра
+func findChatTopic(name string) (chatTopic, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, topic := range chatTopics {
+		if topic.ID == name {
+			return topic, true
+		}
+		for _, alias := range topic.Aliases {
+			if alias == name {
+				return topic, true
+			}
+		}
+	}
+	return chatTopic{}, false
+}
+
+// chatSystemPrompt формирует системный промпт собеседника для уровня пользователя и темы диалога.
+// Неизвестная тема, например из старых диалогов, считается свободной
+func chatSystemPrompt(level, topicID string) string {
+	prompt := fmt.Sprintf("You are an English tutor speaking with a student at %s level. Be encouraging, correct major mistakes, and adapt your language to their level. Keep responses concise and natural. Respond in English only.", level)
+
+	if topic, ok := findChatTopic(topicID); ok && topic.Focus != "" {
+		prompt += fmt.Sprintf(" The conversation topic is %s. Keep the conversation on this topic and gently steer back to it if the student drifts away.", topic.Focus)
+	}
+
+	return prompt
+}
+
+// handleChat начинает диалог на теме из аргументов команды,
+// а без аргументов предлагает выбрать тему на клавиатуре
+func (h *Handler) handleChat(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, args string) {
+	if strings.TrimSpace(args) != "" {
+		if topic, ok := findChatTopic(args); ok {
+			h.startChat(ctx, chatID, user, session, topic)
+			return
+		}
+	}
+
+	text := tr(ctx, "chat.choose_topic")
+	if strings.TrimSpace(args) != "" {
+		text = tr(ctx, "chat.unknown_topic")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = chatTopicKeyboard(languageFrom(ctx))
+	h.bot.Send(msg)
+}
+
+// chatTopicKeyboard создает клавиатуру выбора темы диалога на языке lang, по две в ряд
+func chatTopicKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+
+	for _, topic := range chatTopics {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, topic.LabelKey), callbackChat+":"+topic.ID))
+
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleChatTopicCallback начинает диалог на теме, выбранной на клавиатуре
+func (h *Handler) handleChatTopicCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	topic, ok := findChatTopic(topicID)
+	if !ok {
+		h.answerCallback(callback.ID, tr(ctx, "chat.unknown_topic"))
+		return
+	}
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	session, err := h.sessions.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения сессии", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	h.answerCallback(callback.ID, "")
+
+	// Убираем клавиатуру, чтобы нельзя было начать диалог повторно
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		tr(ctx, "chat.topic_chosen", tr(ctx, topic.LabelKey)))
+	h.bot.Send(edit)
+
+	h.startChat(ctx, chatID, user, session, topic)
+}
+
+// startChat начинает новый диалог на выбранной теме и переводит пользователя в режим чата
+func (h *Handler) startChat(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, topic chatTopic) {
+	conversation, err := h.db.StartConversation(ctx, user.ID, topic.ID, user.EnglishLevel)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка создания диалога", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	// Устанавливаем состояние чата и сохраняем ID диалога в сессии
+	session.State = StateChat
+	session.ConversationID = fmt.Sprintf("%d", conversation.ID)
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	text := tr(ctx, "chat.started")
+	if topic.Focus != "" {
+		text = tr(ctx, "chat.started_topic", tr(ctx, topic.LabelKey))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
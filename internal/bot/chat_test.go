@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestFindChatTopic(t *testing.T) {
+	tests := []struct {
+		name   string
+		wantID string
+		ok     bool
+	}{
+		{"business", "business", true},
+		{" Business ", "business", true},
+		{"small_talk", "smalltalk", true},
+		{"small-talk", "smalltalk", true},
+		{"free", chatTopicFree, true},
+		{"general", chatTopicFree, true},
+		{"sports", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		topic, ok := findChatTopic(tt.name)
+		if ok != tt.ok || topic.ID != tt.wantID {
+			t.Errorf("findChatTopic(%q) = %q, %v; ожидалось %q, %v", tt.name, topic.ID, ok, tt.wantID, tt.ok)
+		}
+	}
+}
+
+func TestChatSystemPrompt(t *testing.T) {
+	tests := []struct {
+		topicID   string
+		wantFocus string
+	}{
+		{"business", "professional register"},
+		{"travel", "trips, transport"},
+		{chatTopicFree, ""},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		prompt := chatSystemPrompt("B1", tt.topicID)
+
+		if !strings.Contains(prompt, "B1 level") {
+			t.Errorf("промпт для темы %q без уровня ученика:\n%s", tt.topicID, prompt)
+		}
+		hasTopic := strings.Contains(prompt, "The conversation topic is")
+		if hasTopic != (tt.wantFocus != "") || !strings.Contains(prompt, tt.wantFocus) {
+			t.Errorf("промпт для темы %q:\n%s", tt.topicID, prompt)
+		}
+	}
+}
+
+func TestChatTopicKeyboard(t *testing.T) {
+	keyboard := chatTopicKeyboard(i18n.Russian)
+
+	var data []string
+	for _, row := range keyboard.InlineKeyboard {
+		if len(row) > 2 {
+			t.Errorf("в ряду %d кнопок, ожидалось не больше двух", len(row))
+		}
+		for _, button := range row {
+			data = append(data, *button.CallbackData)
+		}
+	}
+
+	if len(data) != len(chatTopics) {
+		t.Fatalf("на клавиатуре %d тем, ожидалось %d", len(data), len(chatTopics))
+	}
+	for i, topic := range chatTopics {
+		if data[i] != callbackChat+":"+topic.ID {
+			t.Errorf("кнопка %d: данные %q, ожидались %q", i, data[i], callbackChat+":"+topic.ID)
+		}
+	}
+}
+
+func TestHandleChatAsksForTopic(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		wantKey string
+	}{
+		{"без темы", "", "chat.choose_topic"},
+		{"неизвестная тема", "sports", "chat.unknown_topic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			// Без базы данных: диалог не должен начинаться
+			h := &Handler{bot: botAPI}
+
+			h.handleChat(context.Background(), 100, nil, nil, tt.args)
+
+			calls := stub.calls("sendMessage")
+			if len(calls) != 1 || calls[0].Get("text") != i18n.T(i18n.DefaultLanguage, tt.wantKey) {
+				t.Fatalf("отправлено %v, ожидался текст %q", calls, tt.wantKey)
+			}
+			if calls[0].Get("reply_markup") == "" {
+				t.Error("сообщение без клавиатуры выбора темы")
+			}
+		})
+	}
+}
+
+func TestHandleChatTopicCallbackBusiness(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	var request services.OpenAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Let's talk about your last meeting."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, services.NewOpenAIService("test-key", server.URL))
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: user.TelegramID, FirstName: user.FirstName},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+	h.handleChatTopicCallback(ctx, callback, "business")
+
+	conversation, err := db.GetActiveConversation(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetActiveConversation: %v", err)
+	}
+	if conversation == nil || conversation.Topic != "business" {
+		t.Fatalf("активный диалог %+v, ожидался диалог на тему business", conversation)
+	}
+
+	session, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	if session.State != StateChat || session.ConversationID != strconv.FormatInt(conversation.ID, 10) {
+		t.Errorf("сессия %s с диалогом %q, ожидался режим чата с диалогом %d", session.State, session.ConversationID, conversation.ID)
+	}
+
+	// Первая реплика ученика уходит в OpenAI с промптом деловой темы
+	h.handleMessageByState(ctx, textUpdate(1, user.TelegramID, "Hello, I have a question about my job."), user, session)
+
+	if len(request.Messages) == 0 || request.Messages[0].Role != "system" || request.Messages[0].Content != chatSystemPrompt(user.EnglishLevel, "business") {
+		t.Errorf("сообщения запроса к OpenAI %+v, ожидался системный промпт деловой темы", request.Messages)
+	}
+	if sent := stub.sent(); len(sent) == 0 || sent[0] != i18n.T(i18n.DefaultLanguage, "chat.started_topic", i18n.T(i18n.DefaultLanguage, "chat.topic.business")) {
+		t.Errorf("отправлено %q, ожидалось начало диалога на деловую тему", sent)
+	}
+}
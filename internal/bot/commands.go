@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"english-bot/internal/i18n"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Command описывает команду бота для меню Telegram и справки /help
+type Command struct {
+	Name        string // Имя команды без косой черты
+	Args        string // Описание аргументов для справки, например "<text>"
+	Emoji       string
+	Description string // Описание на английском; переводы хранятся в каталоге под ключом "command.<имя>"
+	Admin       bool   // Команда доступна только администраторам и не показывается в меню и справке
+}
+
+// commands - единый список команд бота.
+// Из него строятся меню команд в Telegram, справка /help, допустимые значения метрик
+// и список служебных команд администратора
+var commands = []Command{
+	{Name: "start", Emoji: "👋", Description: "Start the bot"},
+	{Name: "help", Emoji: "❓", Description: "Show available commands"},
+	{Name: "chat", Args: "[topic]", Emoji: "📝", Description: "Start a conversation in English"},
+	{Name: "topics", Emoji: "🗂️", Description: "Find topics to talk about and practice"},
+	{Name: "endchat", Emoji: "🏁", Description: "Finish the conversation and review your mistakes"},
+	{Name: "check", Emoji: "✅", Description: "Check grammar of your sentence"},
+	{Name: "write", Emoji: "✍️", Description: "Get your writing graded"},
+	{Name: "exercise", Emoji: "📚", Description: "Get a new exercise"},
+	{Name: "practice", Args: "[n]", Emoji: "🏋️", Description: "Do a set of exercises in a row"},
+	{Name: "quiz", Emoji: "🎯", Description: "Take a quick quiz with mixed questions"},
+	{Name: "hint", Emoji: "💡", Description: "Get a hint for the current exercise"},
+	{Name: "skip", Emoji: "⏭️", Description: "Skip the current exercise"},
+	{Name: "history", Emoji: "🗂️", Description: "Review your past exercises"},
+	{Name: "pronounce", Args: "<text>", Emoji: "🔊", Description: "Hear how a text is pronounced"},
+	{Name: "vocab", Emoji: "📖", Description: "Manage your vocabulary"},
+	{Name: "review", Emoji: "🔁", Description: "Review words that are due"},
+	{Name: "daily", Emoji: "🌟", Description: "Subscribe to the word of the day"},
+	{Name: "progress", Emoji: "📊", Description: "Show your learning progress"},
+	{Name: "leaderboard", Args: "[streak|exercises|xp]", Emoji: "🏆", Description: "See the top learners"},
+	{Name: "achievements", Emoji: "🏅", Description: "See your achievements"},
+	{Name: "level", Emoji: "🎯", Description: "View or change your English level"},
+	{Name: "me", Emoji: "🪪", Description: "Show your profile"},
+	{Name: "settings", Emoji: "⚙️", Description: "Change your preferences"},
+	{Name: "timezone", Args: "[zone]", Emoji: "🕒", Description: "View or set your time zone"},
+	{Name: "feedback", Args: "<text>", Emoji: "💌", Description: "Report a problem or share an idea"},
+	{Name: "cancel", Emoji: "↩️", Description: "Leave the current mode"},
+	{Name: "reset", Emoji: "♻️", Description: "Start learning from scratch"},
+	{Name: "export", Emoji: "📦", Description: "Download all your data"},
+	{Name: "forgetme", Emoji: "🗑️", Description: "Delete all your data"},
+	{Name: "broadcast", Args: "<text>", Emoji: "📣", Description: "Send a message to all users", Admin: true},
+	{Name: "stats", Emoji: "📈", Description: "Show bot usage statistics", Admin: true},
+	{Name: "reload", Emoji: "🔄", Description: "Re-register bot commands with Telegram", Admin: true},
+}
+
+// isKnownCommand проверяет, есть ли команда в списке команд бота
+func isKnownCommand(name string) bool {
+	for _, command := range commands {
+		if command.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdminCommand проверяет, что команда доступна только администраторам
+func isAdminCommand(name string) bool {
+	for _, command := range commands {
+		if command.Name == name {
+			return command.Admin
+		}
+	}
+	return false
+}
+
+// commandDescription возвращает описание команды на языке lang,
+// а если перевода нет - описание на английском
+func commandDescription(lang string, command Command) string {
+	if description, ok := i18n.Lookup(lang, "command."+command.Name); ok {
+		return description
+	}
+	return command.Description
+}
+
+// setMyCommandsConfig формирует запрос регистрации меню команд в Telegram для языка lang.
+// Меню на языке по умолчанию показывается пользователям, для языка которых меню не задано.
+// Команды администратора в меню не попадают
+func setMyCommandsConfig(lang string) tgbotapi.SetMyCommandsConfig {
+	botCommands := make([]tgbotapi.BotCommand, 0, len(commands))
+	for _, command := range commands {
+		if command.Admin {
+			continue
+		}
+		botCommands = append(botCommands, tgbotapi.BotCommand{
+			Command:     command.Name,
+			Description: commandDescription(lang, command),
+		})
+	}
+
+	config := tgbotapi.NewSetMyCommands(botCommands...)
+	if lang != i18n.DefaultLanguage {
+		config.LanguageCode = lang
+	}
+	return config
+}
+
+// RegisterCommands регистрирует список команд на всех языках интерфейса,
+// чтобы Telegram показывал их в меню "/"
+func (h *Handler) RegisterCommands() error {
+	for _, lang := range []string{i18n.English, i18n.Russian} {
+		if _, err := h.bot.Request(setMyCommandsConfig(lang)); err != nil {
+			return fmt.Errorf("ошибка регистрации команд для языка %s: %w", lang, err)
+		}
+	}
+	return nil
+}
+
+// helpText формирует справку по командам на языке lang.
+// Команды /start, /help и команды администратора в справке не показываются
+func helpText(lang string) string {
+	var result strings.Builder
+	result.WriteString(i18n.T(lang, "help.title") + "\n\n")
+
+	lines := make([]string, 0, len(commands))
+	for _, command := range commands {
+		if command.Name == "start" || command.Name == "help" || command.Admin {
+			continue
+		}
+
+		usage := "/" + command.Name
+		if command.Args != "" {
+			usage += " " + command.Args
+		}
+		lines = append(lines, fmt.Sprintf("%s *%s* - %s", command.Emoji, usage, commandDescription(lang, command)))
+	}
+	result.WriteString(strings.Join(lines, "\n"))
+
+	return result.String()
+}
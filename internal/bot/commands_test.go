@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"english-bot/internal/i18n"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// botCommandName - допустимое имя команды в меню Telegram
+var botCommandName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+func TestSetMyCommandsConfig(t *testing.T) {
+	for _, lang := range []string{i18n.English, i18n.Russian} {
+		t.Run(lang, func(t *testing.T) {
+			config := setMyCommandsConfig(lang)
+
+			wantLanguage := lang
+			if lang == i18n.DefaultLanguage {
+				wantLanguage = ""
+			}
+			if config.LanguageCode != wantLanguage {
+				t.Errorf("язык меню %q, ожидался %q", config.LanguageCode, wantLanguage)
+			}
+
+			registered := make(map[string]string, len(config.Commands))
+			for _, command := range config.Commands {
+				if !botCommandName.MatchString(command.Command) {
+					t.Errorf("недопустимое имя команды %q", command.Command)
+				}
+				if command.Description == "" || len([]rune(command.Description)) > 256 {
+					t.Errorf("недопустимое описание команды %q: %q", command.Command, command.Description)
+				}
+				registered[command.Command] = command.Description
+			}
+
+			for _, command := range commands {
+				description, ok := registered[command.Name]
+				if ok == command.Admin {
+					t.Errorf("команда %q в меню: %v, команда администратора: %v", command.Name, ok, command.Admin)
+				}
+				if ok {
+					if want := commandDescription(lang, command); description != want {
+						t.Errorf("описание %q = %q, ожидалось %q", command.Name, description, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCommandDescriptionsTranslated(t *testing.T) {
+	for _, command := range commands {
+		if command.Admin {
+			continue
+		}
+		if _, ok := i18n.Lookup(i18n.Russian, "command."+command.Name); !ok {
+			t.Errorf("нет русского описания команды %q", command.Name)
+		}
+	}
+}
+
+func TestHelpText(t *testing.T) {
+	help := helpText(i18n.English)
+
+	for _, want := range []string{"/chat [topic]", "/exercise", "/leaderboard [streak|exercises|xp]", "/forgetme"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("в справке нет команды %q", want)
+		}
+	}
+	for _, hidden := range []string{"/start", "/help", "/broadcast", "/stats", "/reload"} {
+		if strings.Contains(help, hidden+" ") || strings.Contains(help, hidden+"*") {
+			t.Errorf("в справке показана команда %q", hidden)
+		}
+	}
+}
+
+func TestIsKnownCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		known bool
+		admin bool
+	}{
+		{"start", true, false},
+		{"broadcast", true, true},
+		{"stats", true, true},
+		{"unknown", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := isKnownCommand(tt.name); got != tt.known {
+			t.Errorf("isKnownCommand(%q) = %v, ожидалось %v", tt.name, got, tt.known)
+		}
+		if got := isAdminCommand(tt.name); got != tt.admin {
+			t.Errorf("isAdminCommand(%q) = %v, ожидалось %v", tt.name, got, tt.admin)
+		}
+	}
+}
+
+func TestRegisterCommands(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	if err := h.RegisterCommands(); err != nil {
+		t.Fatalf("RegisterCommands: %v", err)
+	}
+
+	calls := stub.calls("setMyCommands")
+	if len(calls) != 2 {
+		t.Fatalf("меню зарегистрировано %d раз, ожидалось для двух языков", len(calls))
+	}
+	if calls[0].Get("language_code") != "" || calls[1].Get("language_code") != i18n.Russian {
+		t.Errorf("языки меню %q и %q", calls[0].Get("language_code"), calls[1].Get("language_code"))
+	}
+}
@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleDaily включает или выключает подписку на слово дня
+func (h *Handler) handleDaily(ctx context.Context, chatID int64, user *database.User) {
+	settings, err := h.db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения настроек", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	settings.DailyWord = !settings.DailyWord
+	if err := h.db.UpdateUserSettings(ctx, *settings); err != nil {
+		slog.ErrorContext(ctx, "Ошибка обновления настроек", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	var text string
+	if settings.DailyWord {
+		text = tr(ctx, "daily.subscribed", settings.NotificationHour)
+	} else {
+		text = tr(ctx, "daily.unsubscribed")
+	}
+
+	h.bot.Send(tgbotapi.NewMessage(chatID, text))
+}
+
+// SendDailyWords рассылает слово дня подписчикам, у которых наступило время уведомлений.
+// Предназначен для периодического запуска планировщиком
+func (h *Handler) SendDailyWords(ctx context.Context) {
+	now := time.Now()
+
+	// Сутки пользователей в других часовых поясах могут начаться позже,
+	// поэтому отбираем с запасом, а точно проверяем в shouldSendDailyWord
+	recipients, err := h.db.GetUsersForDailyWord(ctx, startOfDay(now).AddDate(0, 0, 1))
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения подписчиков слова дня", "error", err)
+		return
+	}
+
+	for _, recipient := range recipients {
+		if !shouldSendDailyWord(now.In(recipient.Location()), recipient.NotificationHour, recipient.LastSentAt) {
+			continue
+		}
+
+		if err := h.sendDailyWord(ctx, recipient); err != nil {
+			slog.ErrorContext(ctx, "Ошибка отправки слова дня", "user_id", recipient.UserID, "error", err)
+			continue
+		}
+
+		if err := h.db.MarkDailyWordSent(ctx, recipient.UserID, now); err != nil {
+			slog.ErrorContext(ctx, "Ошибка отметки отправки слова дня", "user_id", recipient.UserID, "error", err)
+		}
+	}
+}
+
+// sendDailyWord генерирует слово дня, сохраняет его в словарь пользователя и отправляет
+func (h *Handler) sendDailyWord(ctx context.Context, recipient database.NotificationRecipient) error {
+	word, err := h.openAI.GenerateWordOfTheDay(ctx, recipient.EnglishLevel)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.db.AddVocabulary(ctx, database.UserVocabulary{
+		UserID:      recipient.UserID,
+		Word:        word.Word,
+		Translation: word.Translation,
+		Examples:    word.Example,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(recipient.TelegramID, i18n.T(recipient.UILanguage, "daily.word",
+		escapeMarkdown(word.Word),
+		escapeMarkdown(word.Translation),
+		escapeMarkdown(word.Definition),
+		escapeMarkdown(word.Example),
+	))
+	msg.ParseMode = "Markdown"
+	_, err = h.bot.Send(msg)
+
+	return err
+}
+
+// shouldSendDailyWord решает, пора ли отправлять слово дня:
+// наступил час уведомлений пользователя и сегодня слово еще не отправлялось.
+// now передается в часовом поясе пользователя
+func shouldSendDailyWord(now time.Time, notificationHour int, lastSentAt time.Time) bool {
+	if now.Hour() < notificationHour {
+		return false
+	}
+
+	return lastSentAt.Before(startOfDay(now))
+}
+
+// startOfDay возвращает начало суток для указанного времени
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShouldSendDailyWord(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2024, 5, 20, 9, 30, 0, 0, moscow)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		hour       int
+		lastSentAt time.Time
+		want       bool
+	}{
+		{"наступил час, слово не отправлялось", now, 9, time.Time{}, true},
+		{"час еще не наступил", now, 12, time.Time{}, false},
+		{"час уже прошел", now, 8, time.Time{}, true},
+		{"отправлено вчера", now, 9, now.AddDate(0, 0, -1), true},
+		{"уже отправлено сегодня", now, 9, time.Date(2024, 5, 20, 9, 0, 0, 0, moscow), false},
+		{"отправлено ровно в полночь", now, 9, time.Date(2024, 5, 20, 0, 0, 0, 0, moscow), false},
+		{"отправлено до полуночи по поясу пользователя", now, 9, time.Date(2024, 5, 19, 22, 0, 0, 0, time.UTC), false},
+		{"отправлено за секунду до полуночи", now, 9, time.Date(2024, 5, 19, 23, 59, 59, 0, moscow), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldSendDailyWord(tt.now, tt.hour, tt.lastSentAt); got != tt.want {
+				t.Errorf("shouldSendDailyWord = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOfDay(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	got := startOfDay(time.Date(2024, 5, 20, 23, 45, 10, 5, moscow))
+
+	want := time.Date(2024, 5, 20, 0, 0, 0, 0, moscow)
+	if !got.Equal(want) || got.Location() != moscow {
+		t.Errorf("startOfDay = %v, ожидалось %v", got, want)
+	}
+}
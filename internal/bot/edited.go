@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"log/slog"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Ключи контекста сессии с сообщением, которым пользователь ответил на текущий вопрос
+const (
+	answerMessageKey = "answerMessageID" // ID сообщения с ответом
+	answerStateKey   = "answerState"     // Состояние, в котором ответ был получен
+)
+
+// resubmitStates - состояния, в которых бот ждет от пользователя ответ или текст.
+// Исправленный ответ в них обрабатывается как повторная отправка
+var resubmitStates = map[string]bool{
+	StateGrammarCheck:  true,
+	StateExerciseReply: true,
+	StateVocabReview:   true,
+	StateWriting:       true,
+}
+
+// rememberAnswerMessage запоминает в контексте сессии сообщение, которым пользователь отвечает
+// на текущий вопрос. Переход к следующему вопросу заменяет контекст, и старый ответ забывается
+func (h *Handler) rememberAnswerMessage(ctx context.Context, session *database.UserSession, messageID int) {
+	var contextData map[string]string
+	if err := json.Unmarshal(session.ContextData, &contextData); err != nil || contextData == nil {
+		contextData = map[string]string{}
+	}
+
+	contextData[answerMessageKey] = strconv.Itoa(messageID)
+	contextData[answerStateKey] = session.State
+
+	contextJSON, _ := json.Marshal(contextData)
+	session.ContextData = contextJSON
+	if err := h.sessions.UpdateUserSession(ctx, *session); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения сообщения с ответом", "error", err)
+	}
+}
+
+// resubmitState возвращает состояние, в котором нужно заново обработать исправленное сообщение
+// messageID, и false, если это сообщение не отвечает на вопрос, которого бот ждет сейчас.
+// Проверка грамматики после ответа возвращает в главное меню, но ее можно повторить:
+// она зависит только от текста
+func resubmitState(session *database.UserSession, messageID int) (string, bool) {
+	var contextData map[string]string
+	if err := json.Unmarshal(session.ContextData, &contextData); err != nil {
+		return "", false
+	}
+
+	if contextData[answerMessageKey] != strconv.Itoa(messageID) {
+		return "", false
+	}
+
+	state := contextData[answerStateKey]
+	switch {
+	case state == session.State && resubmitStates[state]:
+		return state, true
+	case state == StateGrammarCheck && session.State == StateIdle:
+		return state, true
+	default:
+		return "", false
+	}
+}
+
+// handleEditedMessage обрабатывает исправленное пользователем сообщение.
+// Если это ответ на вопрос, которого бот еще ждет, или текст последней проверки грамматики,
+// исправленный текст проверяется заново, иначе бот сообщает, что заметил исправление,
+// и просит отправить текст новым сообщением
+func (h *Handler) handleEditedMessage(ctx context.Context, update tgbotapi.Update) {
+	edited := update.EditedMessage
+	chatID := edited.Chat.ID
+
+	user, err := h.getOrCreateUser(ctx, edited.From)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения пользователя", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	ctx = withLanguage(ctx, h.userLanguage(ctx, user))
+
+	session, err := h.sessions.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения сессии", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	setUpdateState(ctx, session.State)
+
+	state, ok := resubmitState(session, edited.MessageID)
+	if edited.Text == "" || edited.IsCommand() || !ok {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "edit.ignored"))
+		msg.ReplyToMessageID = edited.MessageID
+		h.bot.Send(msg)
+		return
+	}
+
+	slog.InfoContext(ctx, "Исправленное сообщение обработано как новый ответ",
+		"state", state,
+		"message_id", edited.MessageID,
+	)
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "edit.resubmitted"))
+	msg.ReplyToMessageID = edited.MessageID
+	h.bot.Send(msg)
+
+	session.State = state
+	update.Message = edited
+	update.EditedMessage = nil
+	h.handleMessageByState(ctx, update, user, session)
+}
@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestResubmitState(t *testing.T) {
+	// answered - контекст сессии после ответа сообщением 5 в состоянии state
+	answered := func(state string) []byte {
+		contextData, _ := json.Marshal(map[string]string{answerMessageKey: "5", answerStateKey: state, "exerciseID": "7"})
+		return contextData
+	}
+
+	tests := []struct {
+		name      string
+		session   database.UserSession
+		messageID int
+		want      string
+		ok        bool
+	}{
+		{"ответ на текущее упражнение", database.UserSession{State: StateExerciseReply, ContextData: answered(StateExerciseReply)}, 5, StateExerciseReply, true},
+		{"текст последней проверки грамматики", database.UserSession{State: StateIdle, ContextData: answered(StateGrammarCheck)}, 5, StateGrammarCheck, true},
+		{"проверка грамматики еще ждет текст", database.UserSession{State: StateGrammarCheck, ContextData: answered(StateGrammarCheck)}, 5, StateGrammarCheck, true},
+		{"другое сообщение", database.UserSession{State: StateExerciseReply, ContextData: answered(StateExerciseReply)}, 4, "", false},
+		{"упражнение уже проверено", database.UserSession{State: StateIdle, ContextData: answered(StateExerciseReply)}, 5, "", false},
+		{"режим сменился", database.UserSession{State: StateWriting, ContextData: answered(StateExerciseReply)}, 5, "", false},
+		{"ответ не запомнен", database.UserSession{State: StateExerciseReply, ContextData: []byte(`{"exerciseID": "7"}`)}, 5, "", false},
+		{"испорченный контекст", database.UserSession{State: StateExerciseReply, ContextData: []byte("{")}, 5, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, ok := resubmitState(&tt.session, tt.messageID)
+			if state != tt.want || ok != tt.ok {
+				t.Errorf("resubmitState = %q, %v; ожидалось %q, %v", state, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRememberAnswerMessage(t *testing.T) {
+	store := newMemorySessionStore()
+	h := &Handler{sessions: store}
+	ctx := context.Background()
+
+	session, _ := store.GetOrCreateUserSession(ctx, 1)
+	session.State = StateExerciseReply
+	session.ContextData = []byte(`{"exerciseID": "7"}`)
+	h.rememberAnswerMessage(ctx, session, 42)
+
+	saved := store.session(1)
+	var contextData map[string]string
+	if err := json.Unmarshal(saved.ContextData, &contextData); err != nil {
+		t.Fatalf("контекст сессии %s не разбирается: %v", saved.ContextData, err)
+	}
+	if contextData["exerciseID"] != "7" {
+		t.Errorf("контекст %v потерял упражнение", contextData)
+	}
+	if state, ok := resubmitState(&saved, 42); !ok || state != StateExerciseReply {
+		t.Errorf("resubmitState после rememberAnswerMessage = %q, %v; контекст %v", state, ok, contextData)
+	}
+}
+
+func TestHandleEditedMessageRechecksGrammar(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, string(body))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Looks good."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	// checked возвращает тела запросов к OpenAI, полученных на проверку
+	checked := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(requests)
+	}
+
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := NewHandler(botAPI, db, services.NewOpenAIService("test-key", server.URL))
+	h.SetSessionStore(store)
+
+	session, _ := store.GetOrCreateUserSession(ctx, user.ID)
+	session.State = StateGrammarCheck
+	store.UpdateUserSession(ctx, *session)
+
+	message := textUpdate(5, user.TelegramID, "She go to school.")
+	message.Message.From.FirstName = user.FirstName
+	h.HandleUpdate(ctx, message)
+
+	// edit присылает исправленный текст сообщения messageID
+	edit := func(updateID, messageID int, text string) {
+		update := textUpdate(messageID, user.TelegramID, text)
+		update.UpdateID = updateID
+		update.Message.From.FirstName = user.FirstName
+		h.HandleUpdate(ctx, tgbotapi.Update{UpdateID: updateID, EditedMessage: update.Message})
+	}
+
+	// Настройки пользователя без языка клиента создаются на английском
+	lang := i18n.English
+
+	edit(6, 5, "She goes to school.")
+	if requests := checked(); len(requests) != 2 || !strings.Contains(requests[1], "She goes to school.") {
+		t.Fatalf("запросы к OpenAI %q, ожидалась повторная проверка исправленного текста", requests)
+	}
+	if sent := stub.sent(); !slices.Contains(sent, i18n.T(lang, "edit.resubmitted")) {
+		t.Errorf("отправлено %q, ожидалось сообщение о повторной проверке", sent)
+	}
+	if state := store.session(user.ID).State; state != StateIdle {
+		t.Errorf("после повторной проверки состояние %q, ожидалось %q", state, StateIdle)
+	}
+
+	// Исправление сообщения, которое не было ответом, только подтверждается
+	edit(7, 4, "Hello again")
+	if requests := checked(); len(requests) != 2 {
+		t.Errorf("исправление постороннего сообщения отправлено на проверку: %q", requests)
+	}
+	if sent := stub.sent(); sent[len(sent)-1] != i18n.T(lang, "edit.ignored") {
+		t.Errorf("последнее сообщение %q, ожидалось %q", sent[len(sent)-1], i18n.T(lang, "edit.ignored"))
+	}
+}
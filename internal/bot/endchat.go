@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/services"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// recapMessageLimit - сколько последних реплик диалога учитывается в разборе ошибок
+	recapMessageLimit = 50
+	// recapMistakeLimit - сколько самых частых ошибок показывается в разборе
+	recapMistakeLimit = 3
+)
+
+// handleEndChat завершает диалог, показывает разбор частых ошибок пользователя
+// и возвращает его в главное меню
+func (h *Handler) handleEndChat(ctx context.Context, chatID int64, user *database.User, session *database.UserSession) {
+	if session.State != StateChat {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "endchat.no_chat")))
+		return
+	}
+
+	conversation, err := h.db.GetActiveConversation(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения диалога", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	var texts []string
+	if conversation != nil {
+		messages, err := h.db.GetConversationMessages(ctx, conversation.ID, recapMessageLimit)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения истории диалога", "error", err)
+			h.sendErrorMessage(ctx, chatID, err)
+			return
+		}
+		for _, message := range messages {
+			if message.Role == "user" {
+				texts = append(texts, message.Content)
+			}
+		}
+	}
+
+	// Сбрасываем состояние
+	session.State = StateIdle
+	session.ConversationID = ""
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	if len(texts) == 0 {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "endchat.empty")))
+		return
+	}
+
+	var summary string
+	var mistakes int
+	h.withProgress(ctx, chatID, tr(ctx, "endchat.analyzing"), func() {
+		summary, mistakes, err = h.conversationRecap(ctx, user, texts)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка разбора ошибок диалога", "error", err, "user_id", user.ID)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	if err := h.db.EndConversation(ctx, conversation.ID, summary, mistakes); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения итогов диалога", "error", err, "conversation_id", conversation.ID)
+	}
+	if mistakes > 0 {
+		if err := h.db.IncrementGrammarCorrections(ctx, user.ID, mistakes); err != nil {
+			slog.ErrorContext(ctx, "Ошибка обновления счетчика исправлений", "error", err, "user_id", user.ID)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "endchat.title", len(texts))+summary+tr(ctx, "endchat.next"))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// conversationRecap составляет разбор частых ошибок в сообщениях пользователя в формате Markdown
+// и возвращает его вместе с количеством найденных ошибок. Ошибки ищет LanguageTool,
+// а если он недоступен - ChatGPT, который не сообщает их количество
+func (h *Handler) conversationRecap(ctx context.Context, user *database.User, texts []string) (string, int, error) {
+	if h.languageTool != nil {
+		mistakes, total, err := h.languageTool.SummarizeMistakes(texts, grammarCheckOptions(user), recapMistakeLimit)
+		if err == nil {
+			return formatRecurringMistakes(ctx, mistakes), total, nil
+		}
+		slog.WarnContext(ctx, "LanguageTool недоступен, разбираем ошибки диалога через OpenAI", "error", err)
+	}
+
+	summary, err := h.openAI.SummarizeMistakes(ctx, texts, recapMistakeLimit)
+	if err != nil {
+		return "", 0, err
+	}
+	return escapeMarkdown(summary), 0, nil
+}
+
+// formatRecurringMistakes форматирует самые частые ошибки для разбора диалога
+func formatRecurringMistakes(ctx context.Context, mistakes []services.RecurringMistake) string {
+	if len(mistakes) == 0 {
+		return tr(ctx, "endchat.no_mistakes")
+	}
+
+	var b strings.Builder
+	b.WriteString(tr(ctx, "endchat.work_on"))
+	for i, mistake := range mistakes {
+		b.WriteString(fmt.Sprintf("\n%d. %s", i+1, escapeMarkdown(mistake.Message)))
+		if mistake.Count > 1 {
+			b.WriteString(fmt.Sprintf(" (×%d)", mistake.Count))
+		}
+		if mistake.Correction != "" {
+			b.WriteString(fmt.Sprintf("\n   %s → %s", escapeMarkdown(mistake.Example), escapeMarkdown(mistake.Correction)))
+		} else if mistake.Example != "" {
+			b.WriteString(fmt.Sprintf("\n   %s", escapeMarkdown(mistake.Example)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newRecapLanguageTool запускает тестовый LanguageTool, который для каждого проверяемого
+// сообщения отвечает заданными ошибками в формате JSON
+func newRecapLanguageTool(t *testing.T, replies map[string]string) *services.LanguageToolService {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		matches, ok := replies[r.Form.Get("text")]
+		if !ok {
+			matches = "[]"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"matches": ` + matches + `}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return services.NewLanguageToolService(server.URL+"/", 0)
+}
+
+func TestFormatRecurringMistakes(t *testing.T) {
+	ctx := withLanguage(context.Background(), i18n.Russian)
+
+	tests := []struct {
+		name     string
+		mistakes []services.RecurringMistake
+		want     string
+	}{
+		{"без ошибок", nil, i18n.T(i18n.Russian, "endchat.no_mistakes")},
+		{
+			"ошибка с исправлением",
+			[]services.RecurringMistake{{Message: "Use 'goes'.", Example: "go", Correction: "goes", Count: 2}},
+			i18n.T(i18n.Russian, "endchat.work_on") + "\n1. Use 'goes'. (×2)\n   go → goes",
+		},
+		{
+			"ошибка без исправления",
+			[]services.RecurringMistake{{Message: "Possible typo.", Example: "teh", Count: 1}},
+			i18n.T(i18n.Russian, "endchat.work_on") + "\n1. Possible typo.\n   teh",
+		},
+		{
+			"разметка экранируется",
+			[]services.RecurringMistake{
+				{Message: "Check *this*.", Example: "my_var", Correction: "my_variable", Count: 1},
+				{Message: "Second.", Count: 3},
+			},
+			i18n.T(i18n.Russian, "endchat.work_on") + "\n1. Check \\*this\\*.\n   my\\_var → my\\_variable\n2. Second. (×3)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatRecurringMistakes(ctx, tt.mistakes); got != tt.want {
+				t.Errorf("formatRecurringMistakes =\n%s\nожидалось:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleEndChatOutsideChat(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	h.handleEndChat(context.Background(), 100, &database.User{ID: 1}, &database.UserSession{UserID: 1, State: StateIdle})
+
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "endchat.no_chat") {
+		t.Errorf("отправлено %q, ожидалось сообщение об отсутствии диалога", sent)
+	}
+}
+
+func TestConversationRecapFallsBackToOpenAI(t *testing.T) {
+	languageTool := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(languageTool.Close)
+
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Use *goes* after she."}}]}`))
+	}))
+	t.Cleanup(openAI.Close)
+
+	h := &Handler{openAI: services.NewOpenAIService("test-key", openAI.URL)}
+	h.SetLanguageToolService(services.NewLanguageToolService(languageTool.URL+"/", 0))
+
+	summary, mistakes, err := h.conversationRecap(context.Background(), &database.User{EnglishLevel: "B1"}, []string{"She go home."})
+	if err != nil {
+		t.Fatalf("conversationRecap: %v", err)
+	}
+	if summary != `Use \*goes\* after she.` {
+		t.Errorf("разбор %q, ожидался экранированный ответ OpenAI", summary)
+	}
+	if mistakes != 0 {
+		t.Errorf("OpenAI не сообщает количество ошибок, получено %d", mistakes)
+	}
+}
+
+func TestHandleEndChatRecap(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+	h.SetLanguageToolService(newRecapLanguageTool(t, map[string]string{
+		"She go home.":   `[{"message": "Use 'goes'.", "offset": 4, "length": 2, "rule": {"id": "AGREEMENT"}, "replacements": [{"value": "goes"}]}]`,
+		"He go to work.": `[{"message": "Use 'goes'.", "offset": 3, "length": 2, "rule": {"id": "AGREEMENT"}, "replacements": [{"value": "goes"}]}]`,
+	}))
+
+	session, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	topic, _ := findChatTopic("travel")
+	h.startChat(ctx, 100, user, session, topic)
+
+	conversation, err := db.GetActiveConversation(ctx, user.ID)
+	if err != nil || conversation == nil {
+		t.Fatalf("диалог не начат: %v", err)
+	}
+	// Реплики бота в разбор не попадают
+	for _, message := range []database.ConversationMessage{
+		{Role: "user", Content: "She go home."},
+		{Role: "bot", Content: "Why did she go home so early?"},
+		{Role: "user", Content: "He go to work."},
+	} {
+		message.ConversationID = conversation.ID
+		if _, err := db.AddConversationMessage(ctx, message); err != nil {
+			t.Fatalf("AddConversationMessage: %v", err)
+		}
+	}
+
+	h.handleEndChat(ctx, 100, user, session)
+
+	summary := formatRecurringMistakes(ctx, []services.RecurringMistake{{Message: "Use 'goes'.", Example: "go", Correction: "goes", Count: 2}})
+	want := tr(ctx, "endchat.title", 2) + summary + tr(ctx, "endchat.next")
+	calls := stub.calls("sendMessage")
+	if last := calls[len(calls)-1]; last.Get("text") != want || last.Get("parse_mode") != "Markdown" {
+		t.Errorf("разбор диалога %q, ожидалось:\n%s", last.Get("text"), want)
+	}
+
+	saved, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	if saved.State != StateIdle || saved.ConversationID != "" {
+		t.Errorf("после разбора сессия %s с диалогом %q, ожидался сброс", saved.State, saved.ConversationID)
+	}
+
+	progress, err := db.GetUserProgress(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserProgress: %v", err)
+	}
+	if progress.GrammarCorrections != 2 {
+		t.Errorf("счетчик исправлений %d, ожидалось 2", progress.GrammarCorrections)
+	}
+
+	export, err := db.ExportUserData(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ExportUserData: %v", err)
+	}
+	if len(export.Conversations) != 1 || export.Conversations[0].Summary != summary {
+		t.Errorf("итоги диалога не сохранены: %+v", export.Conversations)
+	}
+}
@@ -0,0 +1,633 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// passingScore - минимальная оценка, при которой ответ засчитывается как правильный
+const passingScore = 80
+
+// evaluateAnswer оценивает ответ пользователя на упражнение.
+// Если правильный ответ известен, используется локальная проверка, иначе ответ оценивает ChatGPT
+func (h *Handler) evaluateAnswer(ctx context.Context, exercise *database.Exercise, userAnswer string) (int, string, error) {
+	if exercise.Answer != "" {
+		score, comment := h.exerciseService.CheckAnswer(languageFrom(ctx), toServiceExercise(exercise), userAnswer)
+		if score < passingScore {
+			comment = tr(ctx, "exercise.correct_answer", comment, exercise.Answer)
+		}
+		return score, comment, nil
+	}
+
+	return h.exerciseService.GradeAnswer(ctx, exercise.Content, userAnswer)
+}
+
+// toServiceExercise преобразует упражнение из БД в модель сервиса упражнений
+func toServiceExercise(exercise *database.Exercise) *services.Exercise {
+	return &services.Exercise{
+		Type:    services.ExerciseType(exercise.Type),
+		Level:   services.EnglishLevel(exercise.Level),
+		Content: exercise.Content,
+		Answer:  exercise.Answer,
+	}
+}
+
+// exerciseTypeChoice - тип упражнения, который можно выбрать после /exercise
+type exerciseTypeChoice struct {
+	Type     services.ExerciseType
+	LabelKey string // Ключ названия кнопки в каталоге сообщений
+}
+
+// exerciseTypeChoices - типы упражнений на клавиатуре выбора в порядке показа
+var exerciseTypeChoices = []exerciseTypeChoice{
+	{Type: services.ExerciseTypeGrammar, LabelKey: "exercise.type.grammar"},
+	{Type: services.ExerciseTypeVocabulary, LabelKey: "exercise.type.vocabulary"},
+	{Type: services.ExerciseTypeTranslation, LabelKey: "exercise.type.translation"},
+	{Type: services.ExerciseTypeReading, LabelKey: "exercise.type.reading"},
+	{Type: services.ExerciseTypeSpeaking, LabelKey: "exercise.type.speaking"},
+}
+
+// grammarTopicAnyID - данные кнопки грамматического упражнения без выбранной темы
+const grammarTopicAnyID = "any"
+
+// grammarTopicChoice - тема грамматического упражнения, которую можно выбрать после типа
+type grammarTopicChoice struct {
+	Topic    services.GrammarTopic
+	LabelKey string // Ключ названия кнопки в каталоге сообщений
+}
+
+// grammarTopicChoices - темы грамматических упражнений на клавиатуре выбора в порядке показа
+var grammarTopicChoices = []grammarTopicChoice{
+	{Topic: services.GrammarTopicTenses, LabelKey: "exercise.topic.tenses"},
+	{Topic: services.GrammarTopicArticles, LabelKey: "exercise.topic.articles"},
+	{Topic: services.GrammarTopicPrepositions, LabelKey: "exercise.topic.prepositions"},
+	{Topic: services.GrammarTopicAny, LabelKey: "exercise.topic.any"},
+}
+
+// findGrammarTopicChoice ищет тему по данным кнопки
+func findGrammarTopicChoice(id string) (grammarTopicChoice, bool) {
+	for _, choice := range grammarTopicChoices {
+		if grammarTopicID(choice.Topic) == id {
+			return choice, true
+		}
+	}
+	return grammarTopicChoice{}, false
+}
+
+// grammarTopicID возвращает данные кнопки для темы грамматического упражнения
+func grammarTopicID(topic services.GrammarTopic) string {
+	if topic == services.GrammarTopicAny {
+		return grammarTopicAnyID
+	}
+	return string(topic)
+}
+
+// grammarTopicKeyboard создает клавиатуру выбора темы грамматического упражнения, по две в ряд
+func grammarTopicKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+
+	for _, choice := range grammarTopicChoices {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, choice.LabelKey),
+			callbackGrammarTopic+":"+grammarTopicID(choice.Topic)))
+
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// findExerciseTypeChoice ищет тип упражнения среди доступных для выбора
+func findExerciseTypeChoice(exerciseType string) (exerciseTypeChoice, bool) {
+	for _, choice := range exerciseTypeChoices {
+		if string(choice.Type) == exerciseType {
+			return choice, true
+		}
+	}
+	return exerciseTypeChoice{}, false
+}
+
+// handleExercise предлагает выбрать тип упражнения перед генерацией
+func (h *Handler) handleExercise(ctx context.Context, chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.choose_type"))
+	msg.ReplyMarkup = exerciseTypeKeyboard(languageFrom(ctx), callbackExercise)
+	h.bot.Send(msg)
+}
+
+// exerciseTypeKeyboard создает клавиатуру выбора типа упражнения на языке lang, по два в ряд.
+// Данные кнопки имеют вид "<dataPrefix>:<тип упражнения>"
+func exerciseTypeKeyboard(lang, dataPrefix string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+
+	for _, choice := range exerciseTypeChoices {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, choice.LabelKey), dataPrefix+":"+string(choice.Type)))
+
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleExerciseTypeCallback генерирует упражнение типа, выбранного на клавиатуре
+func (h *Handler) handleExerciseTypeCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, exerciseType string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	choice, ok := findExerciseTypeChoice(exerciseType)
+	if !ok {
+		h.answerCallback(callback.ID, tr(ctx, "exercise.unknown_type"))
+		return
+	}
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	session, err := h.sessions.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения сессии", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	h.answerCallback(callback.ID, "")
+
+	// Для грамматики сначала предлагаем выбрать тему
+	if choice.Type == services.ExerciseTypeGrammar {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, callback.Message.MessageID,
+			tr(ctx, "exercise.choose_topic"), grammarTopicKeyboard(languageFrom(ctx)))
+		h.bot.Send(edit)
+		return
+	}
+
+	// Убираем клавиатуру, чтобы нельзя было запустить генерацию повторно
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		tr(ctx, "exercise.type_chosen", tr(ctx, choice.LabelKey)))
+	h.bot.Send(edit)
+
+	h.startExercise(ctx, chatID, session, string(choice.Type), user.EnglishLevel, services.GrammarTopicAny)
+}
+
+// handleGrammarTopicCallback генерирует грамматическое упражнение на тему, выбранную на клавиатуре
+func (h *Handler) handleGrammarTopicCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, payload string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	choice, ok := findGrammarTopicChoice(payload)
+	if !ok {
+		h.answerCallback(callback.ID, tr(ctx, "exercise.unknown_type"))
+		return
+	}
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	session, err := h.sessions.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения сессии", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	h.answerCallback(callback.ID, "")
+
+	// Убираем клавиатуру, чтобы нельзя было запустить генерацию повторно
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		tr(ctx, "exercise.topic_chosen", tr(ctx, choice.LabelKey)))
+	h.bot.Send(edit)
+
+	h.startExercise(ctx, chatID, session, string(services.ExerciseTypeGrammar), user.EnglishLevel, choice.Topic)
+}
+
+// startExercise генерирует новое упражнение заданного типа, уровня и темы грамматики,
+// отправляет его пользователю и переводит сессию в ожидание ответа
+func (h *Handler) startExercise(ctx context.Context, chatID int64, session *database.UserSession, exerciseType, level string, topic services.GrammarTopic) {
+	// Сохраняем в контексте тип упражнения и тему
+	contextData := map[string]string{
+		"exerciseType": exerciseType,
+	}
+	if topic != services.GrammarTopicAny {
+		contextData["grammarTopic"] = string(topic)
+	}
+
+	h.startExerciseWithContext(ctx, chatID, session, contextData, level)
+}
+
+// startExerciseWithContext генерирует упражнение типа contextData["exerciseType"],
+// сохраняя в сессии остальные данные контекста, например состояние серии упражнений
+func (h *Handler) startExerciseWithContext(ctx context.Context, chatID int64, session *database.UserSession, contextData map[string]string, level string) {
+	exerciseType := contextData["exerciseType"]
+	userLevel := level
+	level = h.effectiveLevel(ctx, session.UserID, exerciseType, level)
+
+	// Устанавливаем состояние упражнения
+	session.State = StateExercise
+
+	contextJSON, _ := json.Marshal(contextData)
+	session.ContextData = contextJSON
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	// Генерируем упражнение через OpenAI, показывая сообщение о генерации
+	waitText := tr(ctx, "exercise.generating")
+	if level != userLevel {
+		waitText = tr(ctx, "exercise.generating_level", level)
+	}
+	var exercise *database.Exercise
+	var err error
+	h.withProgress(ctx, chatID, waitText, func() {
+		exercise, err = h.nextExercise(ctx, session.UserID, exerciseType, level, services.GrammarTopic(contextData["grammarTopic"]))
+	})
+	if err != nil {
+		// Если OpenAI недоступен, по возможности выдаем упражнение из локального генератора
+		exercise = h.generateOfflineExercise(exerciseType, level)
+		if exercise == nil {
+			slog.ErrorContext(ctx, "Ошибка генерации упражнения", "error", err)
+			h.sendErrorMessage(ctx, chatID, err)
+			return
+		}
+
+		slog.WarnContext(ctx, "Ошибка генерации упражнения, используется локальный генератор",
+			"type", exerciseType,
+			"error", err,
+		)
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "exercise.offline")))
+	}
+
+	// Сохраняем упражнение в БД
+	savedExercise, err := h.db.SaveExercise(ctx, *exercise)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения упражнения", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	// Обновляем контекст сессии, включая ID упражнения
+	contextData["exerciseID"] = fmt.Sprintf("%d", savedExercise.ID)
+	contextJSON, _ = json.Marshal(contextData)
+	session.ContextData = contextJSON
+	session.State = StateExerciseReply
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	// Отправляем упражнение
+	h.sendExercise(ctx, chatID, savedExercise)
+}
+
+// effectiveLevel возвращает уровень сложности упражнения с учетом недавних результатов
+// пользователя по этому типу упражнений. При ошибке используется уровень пользователя
+func (h *Handler) effectiveLevel(ctx context.Context, userID int64, exerciseType, level string) string {
+	recent, err := h.db.GetRecentExerciseResults(ctx, userID, exerciseType, services.AdaptiveWindow)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения последних результатов упражнений", "error", err)
+		return level
+	}
+
+	adapted := string(h.exerciseService.AdaptiveLevel(services.EnglishLevel(level), recent))
+	if adapted != level {
+		slog.InfoContext(ctx, "Сложность упражнения изменена по результатам",
+			"user_id", userID,
+			"type", exerciseType,
+			"level", level,
+			"effective_level", adapted,
+		)
+	}
+
+	return adapted
+}
+
+// nextExercise выдает упражнение из пула, а если пул выключен или пуст, генерирует его сразу.
+// Упражнения на выбранную тему грамматики всегда генерируются заново
+func (h *Handler) nextExercise(ctx context.Context, userID int64, exerciseType, level string, topic services.GrammarTopic) (*database.Exercise, error) {
+	if h.exercisePool != nil && topic == services.GrammarTopicAny {
+		if exercise := h.exercisePool.take(userID, exerciseType, level); exercise != nil {
+			return exercise, nil
+		}
+	}
+
+	return h.generateExercise(ctx, exerciseType, level, topic)
+}
+
+// generateExercise генерирует упражнение заданного типа и уровня через OpenAI.
+// topic учитывается только для грамматических упражнений
+func (h *Handler) generateExercise(ctx context.Context, exerciseType, level string, topic services.GrammarTopic) (*database.Exercise, error) {
+	switch services.ExerciseType(exerciseType) {
+	case services.ExerciseTypeReading:
+		return h.generateReadingExercise(ctx, level)
+	case services.ExerciseTypeVocabulary:
+		return h.generateVocabularyExercise(ctx, level)
+	case services.ExerciseTypeGrammar:
+		return h.generateGrammarExercise(ctx, level, topic)
+	case services.ExerciseTypeSpeaking:
+		return h.generateSpeakingExercise(ctx, level)
+	}
+
+	exerciseText, err := h.openAI.GenerateExercise(ctx, exerciseType, level)
+	if err != nil {
+		return nil, err
+	}
+
+	return &database.Exercise{
+		Type:    exerciseType,
+		Level:   level,
+		Content: exerciseText,
+		// Ответ будет заполнен позже для упражнений с ожидаемым ответом
+	}, nil
+}
+
+// generateOfflineExercise генерирует упражнение без OpenAI.
+// Возвращает nil, если для типа упражнения нет локального генератора
+func (h *Handler) generateOfflineExercise(exerciseType, level string) *database.Exercise {
+	if !services.SupportsSimpleExercise(services.ExerciseType(exerciseType)) {
+		return nil
+	}
+
+	exercise, err := h.exerciseService.GenerateSimpleExercise(services.ExerciseType(exerciseType), services.EnglishLevel(level))
+	if err != nil || exercise.Content == "" {
+		return nil
+	}
+
+	return &database.Exercise{
+		Type:    string(exercise.Type),
+		Level:   level,
+		Content: exercise.Instruction + "\n\n" + exercise.Content,
+		Answer:  exercise.Answer,
+		Options: exercise.Options,
+		Offline: true,
+		Data: database.ExerciseData{
+			Instruction: exercise.Instruction,
+			Prompt:      exercise.Content,
+		},
+	}
+}
+
+// generateGrammarExercise генерирует грамматическое упражнение, при необходимости на заданную тему
+func (h *Handler) generateGrammarExercise(ctx context.Context, level string, topic services.GrammarTopic) (*database.Exercise, error) {
+	exercise, err := h.exerciseService.GenerateExercise(ctx, services.ExerciseTypeGrammar, services.EnglishLevel(level), topic)
+	if err != nil {
+		return nil, err
+	}
+
+	return &database.Exercise{
+		Type:    string(exercise.Type),
+		Level:   level,
+		Content: exercise.Content,
+		Topic:   string(exercise.Topic),
+	}, nil
+}
+
+// generateVocabularyExercise генерирует словарное упражнение с вариантами ответа
+func (h *Handler) generateVocabularyExercise(ctx context.Context, level string) (*database.Exercise, error) {
+	exercise, err := h.exerciseService.GenerateVocabularyExercise(ctx, services.EnglishLevel(level))
+	if err != nil {
+		return nil, err
+	}
+
+	return &database.Exercise{
+		Type:    string(exercise.Type),
+		Level:   level,
+		Content: exercise.Content,
+		Answer:  exercise.Answer,
+		Options: exercise.Options,
+		Data: database.ExerciseData{
+			Instruction: exercise.Instruction,
+			Prompt:      exercise.Content,
+		},
+	}, nil
+}
+
+// currentExercise возвращает упражнение, на которое пользователь сейчас отвечает,
+// или nil, если активного упражнения нет
+func (h *Handler) currentExercise(ctx context.Context, session *database.UserSession) (*database.Exercise, error) {
+	if session.State != StateExerciseReply {
+		return nil, nil
+	}
+
+	var contextData map[string]string
+	if err := json.Unmarshal(session.ContextData, &contextData); err != nil {
+		return nil, fmt.Errorf("ошибка разбора контекста сессии: %w", err)
+	}
+
+	exerciseID, err := strconv.ParseInt(contextData["exerciseID"], 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	return h.db.GetExerciseByID(ctx, exerciseID)
+}
+
+// handleSkip пропускает текущее упражнение: показывает правильный ответ
+// и сразу выдает новое упражнение того же типа и уровня или следующее упражнение серии
+func (h *Handler) handleSkip(ctx context.Context, chatID int64, user *database.User, session *database.UserSession) {
+	exercise, err := h.currentExercise(ctx, session)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения упражнения", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	if exercise == nil {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.none_active"))
+		h.bot.Send(msg)
+		return
+	}
+
+	if err := h.db.SaveSkippedExercise(ctx, session.UserID, exercise.ID); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения пропуска упражнения", "error", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.skipped", h.revealAnswer(ctx, chatID, exercise)))
+	h.bot.Send(msg)
+
+	if set, ok := practiceFromSession(ctx, session); ok {
+		set.Results = append(set.Results, practiceResult{ExerciseID: exercise.ID, Skipped: true})
+		h.advancePractice(ctx, chatID, user, session, set)
+		return
+	}
+
+	// Уровень пропущенного упражнения уже подобран по результатам, поэтому подбор
+	// начинается заново с уровня пользователя, иначе понижение накапливалось бы с каждым пропуском
+	h.startExercise(ctx, chatID, session, exercise.Type, user.EnglishLevel, services.GrammarTopic(exercise.Topic))
+}
+
+// revealAnswer возвращает правильный ответ на упражнение: сохраненный или полученный от OpenAI.
+// Если ответ получить не удалось, возвращает сообщение об этом
+func (h *Handler) revealAnswer(ctx context.Context, chatID int64, exercise *database.Exercise) string {
+	if isReadingExercise(exercise) {
+		answer, err := readingAnswerKey(exercise)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения ответов к тексту", "error", err)
+			return tr(ctx, "reading.answers_failed")
+		}
+		return answer
+	}
+
+	if exercise.Answer != "" {
+		return exercise.Answer
+	}
+
+	var answer string
+	var err error
+	h.withProgress(ctx, chatID, "", func() {
+		answer, err = h.exerciseService.RevealAnswer(ctx, exercise.Content)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения ответа на упражнение", "error", err)
+		return tr(ctx, "exercise.answer_failed")
+	}
+	return answer
+}
+
+// sendExercise отправляет упражнение пользователю.
+// Если у упражнения есть варианты ответа, к сообщению прикрепляется inline-клавиатура
+func (h *Handler) sendExercise(ctx context.Context, chatID int64, exercise *database.Exercise) {
+	if isReadingExercise(exercise) {
+		h.sendReadingExercise(ctx, chatID, exercise)
+		return
+	}
+	if isSpeakingExercise(exercise) {
+		h.sendSpeakingExercise(ctx, chatID, exercise)
+		return
+	}
+
+	if len(exercise.Options) == 0 {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.prompt_type", escapeMarkdown(exercise.Content)))
+		msg.ParseMode = "Markdown"
+		h.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.prompt_choose", escapeMarkdown(exercise.Content)))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = exerciseKeyboard(exercise)
+	h.bot.Send(msg)
+}
+
+// exerciseKeyboard создает клавиатуру с вариантами ответа, по одной кнопке на вариант
+func exerciseKeyboard(exercise *database.Exercise) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(exercise.Options))
+	for i, option := range exercise.Options {
+		data := fmt.Sprintf("%s:%d:%d", callbackAnswer, exercise.ID, i)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(strings.TrimSpace(option), data),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// completeExercise проверяет ответ, сохраняет результат и возвращает пользователя в главное меню.
+// Возвращает true, если ответ засчитан как правильный
+func (h *Handler) completeExercise(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, exercise *database.Exercise, answer string) bool {
+	var score int
+	var explanation string
+	var err error
+	h.withProgress(ctx, chatID, tr(ctx, "exercise.checking"), func() {
+		score, explanation, err = h.evaluateAnswer(ctx, exercise, answer)
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка проверки ответа", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return false
+	}
+
+	// Подсказки снижают оценку
+	if hints := hintsUsed(session); hints > 0 {
+		score = applyHintPenalty(score, hints)
+		explanation = tr(ctx, "exercise.hints_used", explanation, hints, hints*hintPenalty)
+	}
+
+	return h.finishExercise(ctx, chatID, user, session, exercise, answer, score, explanation)
+}
+
+// finishExercise сохраняет итоговую оценку упражнения, сообщает ее пользователю
+// и возвращает его в главное меню. Возвращает true, если упражнение засчитано как правильное
+func (h *Handler) finishExercise(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, exercise *database.Exercise, answer string, score int, explanation string) bool {
+	isCorrect := score >= passingScore
+
+	// В серии упражнений результаты сохраняются все вместе в конце серии
+	if set, ok := practiceFromSession(ctx, session); ok {
+		h.sendExerciseFeedback(ctx, chatID, exercise, score, explanation)
+		set.Results = append(set.Results, practiceResult{ExerciseID: exercise.ID, Answer: answer, Score: score})
+		h.advancePractice(ctx, chatID, user, session, set)
+		return isCorrect
+	}
+
+	// Сохраняем ответ пользователя
+	userExercise := database.UserExercise{
+		UserID:     user.ID,
+		ExerciseID: exercise.ID,
+		UserAnswer: answer,
+		IsCorrect:  isCorrect,
+	}
+	h.db.SaveUserExercise(ctx, userExercise)
+
+	// Отправляем результат
+	h.sendExerciseFeedback(ctx, chatID, exercise, score, explanation)
+
+	// Предлагаем следующее упражнение
+	nextMsg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.next"))
+	h.bot.Send(nextMsg)
+
+	// Сбрасываем состояние
+	session.State = StateIdle
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	// Обновляем статистику пользователя
+	h.db.UpdateUserStreak(ctx, user.ID)
+	h.checkLevelUp(ctx, chatID, user)
+
+	return isCorrect
+}
+
+// sendExerciseFeedback сообщает пользователю оценку за упражнение с пояснением.
+// К оценке прикрепляется кнопка, по которой можно получить объяснение проверяемого правила
+func (h *Handler) sendExerciseFeedback(ctx context.Context, chatID int64, exercise *database.Exercise, score int, explanation string) {
+	var feedbackMsg string
+	if score >= passingScore {
+		feedbackMsg = tr(ctx, "exercise.correct", score, escapeMarkdown(explanation))
+	} else {
+		feedbackMsg = tr(ctx, "exercise.incorrect", score, escapeMarkdown(explanation))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, feedbackMsg)
+	msg.ParseMode = "Markdown"
+	// Правило объясняется только для упражнений, которые его проверяют
+	if !isReadingExercise(exercise) && !isSpeakingExercise(exercise) {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(ctx, "exercise.explain_button"), fmt.Sprintf("%s:%d", callbackExplain, exercise.ID)),
+		))
+	}
+	h.bot.Send(msg)
+}
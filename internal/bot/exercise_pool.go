@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+)
+
+// exercisePoolKey - тип и уровень упражнений одного пула
+type exercisePoolKey struct {
+	exerciseType string
+	level        string
+}
+
+// exercisePool хранит заранее сгенерированные упражнения для каждой пары тип/уровень,
+// чтобы /exercise не ждал OpenAI. Пул пары создается при первом запросе к ней
+// и пополняется в фоне, когда в нем остается половина упражнений или меньше
+type exercisePool struct {
+	ctx      context.Context // Отмена контекста прерывает пополнение
+	size     int             // Сколько упражнений держать в пуле каждой пары
+	generate func(ctx context.Context, exerciseType, level string) (*database.Exercise, error)
+
+	mu         sync.Mutex
+	ready      map[exercisePoolKey][]*database.Exercise
+	refilling  map[exercisePoolKey]bool
+	lastServed map[int64]uint64 // Хэш содержания последнего выданного пользователю упражнения
+}
+
+// newExercisePool создает пул упражнений размером size для каждой пары тип/уровень.
+// Пополнение идет в фоне с контекстом ctx
+func newExercisePool(ctx context.Context, size int, generate func(ctx context.Context, exerciseType, level string) (*database.Exercise, error)) *exercisePool {
+	return &exercisePool{
+		ctx:        ctx,
+		size:       size,
+		generate:   generate,
+		ready:      make(map[exercisePoolKey][]*database.Exercise),
+		refilling:  make(map[exercisePoolKey]bool),
+		lastServed: make(map[int64]uint64),
+	}
+}
+
+// take выдает пользователю готовое упражнение из пула и при необходимости запускает пополнение.
+// Упражнение с тем же содержанием, что и предыдущее у этого пользователя, не выдается.
+// Возвращает nil, если подходящего упражнения нет: тогда его нужно сгенерировать сразу
+func (p *exercisePool) take(userID int64, exerciseType, level string) *database.Exercise {
+	key := exercisePoolKey{exerciseType: exerciseType, level: level}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var exercise *database.Exercise
+	items := p.ready[key]
+	for i, item := range items {
+		if contentHash(item.Content) == p.lastServed[userID] {
+			continue
+		}
+
+		exercise = item
+		p.ready[key] = append(items[:i:i], items[i+1:]...)
+		p.lastServed[userID] = contentHash(item.Content)
+		break
+	}
+
+	if len(p.ready[key]) <= p.size/2 {
+		p.startRefill(key)
+	}
+
+	return exercise
+}
+
+// startRefill запускает фоновое пополнение пула, если оно еще не идет.
+// Вызывается под блокировкой
+func (p *exercisePool) startRefill(key exercisePoolKey) {
+	if p.refilling[key] {
+		return
+	}
+	p.refilling[key] = true
+
+	go p.refill(key)
+}
+
+// refill генерирует упражнения, пока пул не заполнится.
+// При ошибке генерации пополнение прекращается до следующего запроса.
+// Число попыток ограничено, чтобы повторяющиеся упражнения не зациклили генерацию
+func (p *exercisePool) refill(key exercisePoolKey) {
+	defer func() {
+		p.mu.Lock()
+		p.refilling[key] = false
+		p.mu.Unlock()
+	}()
+
+	for attempt := 0; attempt < 2*p.size; attempt++ {
+		p.mu.Lock()
+		full := len(p.ready[key]) >= p.size
+		p.mu.Unlock()
+		if full {
+			return
+		}
+
+		if p.ctx.Err() != nil {
+			return
+		}
+
+		exercise, err := p.generate(p.ctx, key.exerciseType, key.level)
+		if err != nil {
+			slog.WarnContext(p.ctx, "Ошибка пополнения пула упражнений",
+				"type", key.exerciseType,
+				"level", key.level,
+				"error", err,
+			)
+			return
+		}
+
+		p.mu.Lock()
+		if !p.contains(key, exercise.Content) {
+			p.ready[key] = append(p.ready[key], exercise)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// contains проверяет, есть ли в пуле упражнение с таким содержанием.
+// Вызывается под блокировкой
+func (p *exercisePool) contains(key exercisePoolKey, content string) bool {
+	for _, item := range p.ready[key] {
+		if item.Content == content {
+			return true
+		}
+	}
+	return false
+}
+
+// contentHash возвращает хэш содержания упражнения для сравнения без хранения текста
+func contentHash(content string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(content))
+	return h.Sum64()
+}
@@ -0,0 +1,245 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingGenerator генерирует упражнения с разным содержанием и считает вызовы
+type countingGenerator struct {
+	calls atomic.Int64
+}
+
+func (g *countingGenerator) generate(ctx context.Context, exerciseType, level string) (*database.Exercise, error) {
+	n := g.calls.Add(1)
+	return &database.Exercise{Type: exerciseType, Level: level, Content: fmt.Sprintf("exercise %d", n)}, nil
+}
+
+// waitForPool ждет, пока пополнение пары тип/уровень закончится, и возвращает число готовых упражнений
+func waitForPool(t *testing.T, p *exercisePool, key exercisePoolKey) int {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		p.mu.Lock()
+		refilling, ready := p.refilling[key], len(p.ready[key])
+		p.mu.Unlock()
+
+		if !refilling {
+			return ready
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatal("пополнение пула не закончилось за 2 секунды")
+	return 0
+}
+
+func TestExercisePoolFillsOnFirstRequest(t *testing.T) {
+	var generator countingGenerator
+	p := newExercisePool(context.Background(), 4, generator.generate)
+	key := exercisePoolKey{exerciseType: "grammar", level: "A1"}
+
+	if exercise := p.take(1, "grammar", "A1"); exercise != nil {
+		t.Fatalf("из пустого пула выдано упражнение %+v", exercise)
+	}
+	if ready := waitForPool(t, p, key); ready != 4 {
+		t.Fatalf("в пуле %d упражнений, ожидалось 4", ready)
+	}
+
+	exercise := p.take(1, "grammar", "A1")
+	if exercise == nil || exercise.Type != "grammar" || exercise.Level != "A1" {
+		t.Errorf("выдано упражнение %+v, ожидалось grammar уровня A1", exercise)
+	}
+}
+
+func TestExercisePoolRefillsWhenLow(t *testing.T) {
+	var generator countingGenerator
+	p := newExercisePool(context.Background(), 4, generator.generate)
+	key := exercisePoolKey{exerciseType: "vocabulary", level: "B1"}
+
+	p.take(1, "vocabulary", "B1")
+	waitForPool(t, p, key)
+	filled := generator.calls.Load()
+
+	// Пока в пуле больше половины упражнений, пополнение не запускается
+	p.take(1, "vocabulary", "B1")
+	if ready := waitForPool(t, p, key); ready != 3 || generator.calls.Load() != filled {
+		t.Errorf("после первого упражнения в пуле %d, генераций %d; ожидалось 3 без новых генераций",
+			ready, generator.calls.Load()-filled)
+	}
+
+	// Вторая выдача оставляет половину, и пул пополняется до полного размера
+	p.take(1, "vocabulary", "B1")
+	if ready := waitForPool(t, p, key); ready != 4 {
+		t.Errorf("после пополнения в пуле %d упражнений, ожидалось 4", ready)
+	}
+}
+
+func TestExercisePoolSkipsLastServedExercise(t *testing.T) {
+	generate := func(ctx context.Context, exerciseType, level string) (*database.Exercise, error) {
+		return nil, errors.New("генерация выключена")
+	}
+	p := newExercisePool(context.Background(), 10, generate)
+	key := exercisePoolKey{exerciseType: "grammar", level: "A1"}
+
+	p.ready[key] = []*database.Exercise{{Content: "same"}, {Content: "same"}, {Content: "other"}}
+
+	first := p.take(1, "grammar", "A1")
+	second := p.take(1, "grammar", "A1")
+	if first == nil || second == nil || first.Content != "same" || second.Content != "other" {
+		t.Fatalf("выданы %+v и %+v, ожидалось same, затем other", first, second)
+	}
+
+	// Осталось упражнение, совпадающее с предыдущим у второго пользователя,
+	// поэтому ему оно не выдается, а первому - выдается
+	p.mu.Lock()
+	p.lastServed[2] = contentHash("same")
+	p.mu.Unlock()
+	if exercise := p.take(2, "grammar", "A1"); exercise != nil {
+		t.Errorf("второму пользователю подряд выдано то же упражнение %q", exercise.Content)
+	}
+	if exercise := p.take(1, "grammar", "A1"); exercise == nil || exercise.Content != "same" {
+		t.Errorf("первому пользователю выдано %+v, ожидалось same", exercise)
+	}
+	waitForPool(t, p, key)
+}
+
+func TestExercisePoolStopsOnGenerateError(t *testing.T) {
+	var calls atomic.Int64
+	generate := func(ctx context.Context, exerciseType, level string) (*database.Exercise, error) {
+		calls.Add(1)
+		return nil, errors.New("OpenAI недоступен")
+	}
+	p := newExercisePool(context.Background(), 4, generate)
+	key := exercisePoolKey{exerciseType: "grammar", level: "A1"}
+
+	p.take(1, "grammar", "A1")
+	if ready := waitForPool(t, p, key); ready != 0 || calls.Load() != 1 {
+		t.Errorf("в пуле %d упражнений после %d генераций, ожидалось пусто после одной", ready, calls.Load())
+	}
+}
+
+func TestExercisePoolSkipsDuplicateGenerations(t *testing.T) {
+	var calls atomic.Int64
+	generate := func(ctx context.Context, exerciseType, level string) (*database.Exercise, error) {
+		calls.Add(1)
+		return &database.Exercise{Content: "always the same"}, nil
+	}
+	p := newExercisePool(context.Background(), 3, generate)
+	key := exercisePoolKey{exerciseType: "grammar", level: "A1"}
+
+	p.take(1, "grammar", "A1")
+	if ready := waitForPool(t, p, key); ready != 1 {
+		t.Errorf("в пуле %d упражнений, ожидалось одно без повторов", ready)
+	}
+	if calls.Load() != 6 {
+		t.Errorf("генераций %d, ожидалось 6: число попыток ограничено двойным размером пула", calls.Load())
+	}
+}
+
+func TestExercisePoolDoesNotRefillAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var generator countingGenerator
+	p := newExercisePool(ctx, 4, generator.generate)
+	key := exercisePoolKey{exerciseType: "grammar", level: "A1"}
+
+	p.take(1, "grammar", "A1")
+	if ready := waitForPool(t, p, key); ready != 0 || generator.calls.Load() != 0 {
+		t.Errorf("после отмены сгенерировано %d упражнений", generator.calls.Load())
+	}
+}
+
+func TestExercisePoolConcurrentTake(t *testing.T) {
+	var generator countingGenerator
+	p := newExercisePool(context.Background(), 5, generator.generate)
+
+	var mu sync.Mutex
+	served := make(map[*database.Exercise]bool)
+
+	var wg sync.WaitGroup
+	for user := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				exercise := p.take(int64(user), "grammar", "A1")
+				if exercise == nil {
+					continue
+				}
+
+				mu.Lock()
+				if served[exercise] {
+					t.Errorf("упражнение %q выдано дважды", exercise.Content)
+				}
+				served[exercise] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	waitForPool(t, p, exercisePoolKey{exerciseType: "grammar", level: "A1"})
+}
+
+func TestNextExerciseUsesPoolOnlyWithoutTopic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "generated"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	tests := []struct {
+		name  string
+		topic services.GrammarTopic
+		want  string
+	}{
+		{"любая тема из пула", services.GrammarTopicAny, "pooled"},
+		{"выбранная тема генерируется сразу", services.GrammarTopicArticles, "generated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{openAI: openAI, exerciseService: services.NewExerciseService(openAI)}
+			h.exercisePool = newExercisePool(context.Background(), 1, func(ctx context.Context, exerciseType, level string) (*database.Exercise, error) {
+				return nil, errors.New("генерация выключена")
+			})
+			h.exercisePool.ready[exercisePoolKey{exerciseType: "grammar", level: "A1"}] = []*database.Exercise{{Type: "grammar", Content: "pooled"}}
+
+			exercise, err := h.nextExercise(context.Background(), 1, "grammar", "A1", tt.topic)
+			if err != nil {
+				t.Fatalf("nextExercise: %v", err)
+			}
+			if exercise.Content != tt.want {
+				t.Errorf("выдано упражнение %q, ожидалось %q", exercise.Content, tt.want)
+			}
+			waitForPool(t, h.exercisePool, exercisePoolKey{exerciseType: "grammar", level: "A1"})
+		})
+	}
+}
+
+func TestSetExercisePool(t *testing.T) {
+	h := &Handler{}
+
+	h.SetExercisePool(context.Background(), 3)
+	if h.exercisePool == nil || h.exercisePool.size != 3 {
+		t.Fatalf("пул %+v, ожидался пул размером 3", h.exercisePool)
+	}
+
+	h.SetExercisePool(context.Background(), 0)
+	if h.exercisePool != nil {
+		t.Error("пул не выключен при размере 0")
+	}
+}
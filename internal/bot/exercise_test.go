@@ -0,0 +1,546 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestExerciseKeyboard(t *testing.T) {
+	exercise := &database.Exercise{
+		ID:      42,
+		Options: []string{"go", " am going ", "goes"},
+	}
+
+	keyboard := exerciseKeyboard(exercise)
+
+	if len(keyboard.InlineKeyboard) != len(exercise.Options) {
+		t.Fatalf("клавиатура из %d рядов, ожидалось %d", len(keyboard.InlineKeyboard), len(exercise.Options))
+	}
+
+	wantText := []string{"go", "am going", "goes"}
+	wantData := []string{"answer:42:0", "answer:42:1", "answer:42:2"}
+	for i, row := range keyboard.InlineKeyboard {
+		if len(row) != 1 {
+			t.Fatalf("ряд %d содержит %d кнопок, ожидалась одна", i, len(row))
+		}
+		button := row[0]
+		if button.Text != wantText[i] {
+			t.Errorf("кнопка %d: текст %q, ожидался %q", i, button.Text, wantText[i])
+		}
+		if button.CallbackData == nil || *button.CallbackData != wantData[i] {
+			t.Errorf("кнопка %d: данные %v, ожидались %q", i, button.CallbackData, wantData[i])
+		}
+	}
+}
+
+func TestExerciseKeyboardCallbackData(t *testing.T) {
+	exercise := &database.Exercise{ID: 7, Options: []string{"a", "b"}}
+
+	for i, row := range exerciseKeyboard(exercise).InlineKeyboard {
+		prefix, payload, _ := strings.Cut(*row[0].CallbackData, ":")
+		exerciseID, optionIndex, _ := strings.Cut(payload, ":")
+		if prefix != callbackAnswer || exerciseID != "7" || optionIndex != strconv.Itoa(i) {
+			t.Errorf("данные кнопки %q не разбираются обработчиком ответа", *row[0].CallbackData)
+		}
+	}
+}
+
+func TestExerciseKeyboardWithoutOptions(t *testing.T) {
+	keyboard := exerciseKeyboard(&database.Exercise{ID: 1})
+	if len(keyboard.InlineKeyboard) != 0 {
+		t.Errorf("упражнение без вариантов получило клавиатуру %+v", keyboard.InlineKeyboard)
+	}
+}
+
+func TestSendExerciseEscapesContent(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	h.sendExercise(context.Background(), 100, &database.Exercise{Type: "grammar", Content: "She ___ *always* late."})
+
+	calls := stub.calls("sendMessage")
+	if len(calls) != 1 {
+		t.Fatalf("отправлено %d сообщений, ожидалось одно", len(calls))
+	}
+	want := i18n.T(i18n.DefaultLanguage, "exercise.prompt_type", `She \_\_\_ \*always\* late.`)
+	if text := calls[0].Get("text"); text != want {
+		t.Errorf("текст упражнения %q, ожидалось %q", text, want)
+	}
+	if mode := calls[0].Get("parse_mode"); mode != "Markdown" {
+		t.Errorf("режим разметки %q", mode)
+	}
+}
+
+func TestExerciseTypeKeyboard(t *testing.T) {
+	keyboard := exerciseTypeKeyboard(i18n.Russian, callbackExercise)
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	for i, row := range keyboard.InlineKeyboard {
+		if len(row) > 2 || (len(row) < 2 && i != len(keyboard.InlineKeyboard)-1) {
+			t.Errorf("ряд %d содержит %d кнопок, ожидалось по две", i, len(row))
+		}
+		buttons = append(buttons, row...)
+	}
+
+	if len(buttons) != len(exerciseTypeChoices) {
+		t.Fatalf("на клавиатуре %d кнопок, ожидалось %d", len(buttons), len(exerciseTypeChoices))
+	}
+	for i, choice := range exerciseTypeChoices {
+		wantText := i18n.T(i18n.Russian, choice.LabelKey)
+		wantData := callbackExercise + ":" + string(choice.Type)
+		if buttons[i].Text != wantText {
+			t.Errorf("кнопка %d: текст %q, ожидался %q", i, buttons[i].Text, wantText)
+		}
+		if buttons[i].CallbackData == nil || *buttons[i].CallbackData != wantData {
+			t.Errorf("кнопка %d: данные %v, ожидались %q", i, buttons[i].CallbackData, wantData)
+		}
+	}
+}
+
+func TestFindExerciseTypeChoice(t *testing.T) {
+	tests := []struct {
+		exerciseType string
+		ok           bool
+	}{
+		{"grammar", true},
+		{"vocabulary", true},
+		{"translation", true},
+		{"reading", true},
+		{"speaking", true},
+		{"conversation", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		choice, ok := findExerciseTypeChoice(tt.exerciseType)
+		if ok != tt.ok {
+			t.Errorf("findExerciseTypeChoice(%q) = %v, ожидалось %v", tt.exerciseType, ok, tt.ok)
+		}
+		if ok && string(choice.Type) != tt.exerciseType {
+			t.Errorf("findExerciseTypeChoice(%q) вернул тип %q", tt.exerciseType, choice.Type)
+		}
+	}
+}
+
+func TestHandleExerciseSendsTypeKeyboard(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	h.handleExercise(withLanguage(context.Background(), i18n.Russian), 100)
+
+	calls := stub.calls("sendMessage")
+	if len(calls) != 1 {
+		t.Fatalf("отправлено %d сообщений, ожидалось одно", len(calls))
+	}
+	if text := calls[0].Get("text"); text != i18n.T(i18n.Russian, "exercise.choose_type") {
+		t.Errorf("текст %q, ожидалось предложение выбрать тип", text)
+	}
+
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(calls[0].Get("reply_markup")), &markup); err != nil {
+		t.Fatalf("не удалось разобрать клавиатуру: %v", err)
+	}
+	want := exerciseTypeKeyboard(i18n.Russian, callbackExercise)
+	if len(markup.InlineKeyboard) != len(want.InlineKeyboard) {
+		t.Errorf("клавиатура из %d рядов, ожидалось %d", len(markup.InlineKeyboard), len(want.InlineKeyboard))
+	}
+}
+
+func TestHandleExerciseTypeCallbackUnknownType(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: 1},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+	h.handleExerciseTypeCallback(context.Background(), callback, "conversation")
+
+	answers := stub.calls("answerCallbackQuery")
+	if len(answers) != 1 || answers[0].Get("text") != i18n.T(i18n.DefaultLanguage, "exercise.unknown_type") {
+		t.Errorf("ответы на нажатие %v, ожидалось сообщение о неизвестном типе", answers)
+	}
+	if edits := stub.calls("editMessageText"); len(edits) != 0 {
+		t.Errorf("сообщение изменено при неизвестном типе: %v", edits)
+	}
+}
+
+func TestHandleExerciseTypeCallbackVocabulary(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	// Один ответ подходит и для упражнения, и для отвлекающих вариантов: их ошибка не мешает генерации
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "{\"sentence\": \"I eat an _____ every day.\", \"answer\": \"apple\"}"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := NewHandler(botAPI, db, openAI)
+	h.SetExerciseService(services.NewExerciseService(openAI))
+	h.SetSessionStore(store)
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: user.TelegramID, FirstName: user.FirstName},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+	h.handleExerciseTypeCallback(ctx, callback, "vocabulary")
+
+	wantEdit := i18n.T(i18n.DefaultLanguage, "exercise.type_chosen", i18n.T(i18n.DefaultLanguage, "exercise.type.vocabulary"))
+	if edits := stub.calls("editMessageText"); len(edits) != 1 || edits[0].Get("text") != wantEdit {
+		t.Errorf("изменения сообщения %v, ожидалось %q", edits, wantEdit)
+	}
+
+	saved := store.session(user.ID)
+	var savedContext map[string]string
+	json.Unmarshal(saved.ContextData, &savedContext)
+	if saved.State != StateExerciseReply || savedContext["exerciseType"] != "vocabulary" {
+		t.Fatalf("сессия %s с контекстом %v, ожидалось словарное упражнение", saved.State, savedContext)
+	}
+
+	exerciseID, _ := strconv.ParseInt(savedContext["exerciseID"], 10, 64)
+	exercise, err := db.GetExerciseByID(ctx, exerciseID)
+	if err != nil {
+		t.Fatalf("GetExerciseByID: %v", err)
+	}
+	if exercise == nil || exercise.Type != "vocabulary" || !slices.Contains(exercise.Options, "apple") {
+		t.Errorf("сохранено упражнение %+v, ожидалось словарное с ответом apple среди вариантов", exercise)
+	}
+}
+
+func TestGrammarTopicKeyboard(t *testing.T) {
+	keyboard := grammarTopicKeyboard(i18n.Russian)
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	for _, row := range keyboard.InlineKeyboard {
+		if len(row) > 2 {
+			t.Errorf("ряд содержит %d кнопок, ожидалось не больше двух", len(row))
+		}
+		buttons = append(buttons, row...)
+	}
+
+	if len(buttons) != len(grammarTopicChoices) {
+		t.Fatalf("на клавиатуре %d кнопок, ожидалось %d", len(buttons), len(grammarTopicChoices))
+	}
+	for i, choice := range grammarTopicChoices {
+		wantText := i18n.T(i18n.Russian, choice.LabelKey)
+		wantData := callbackGrammarTopic + ":" + grammarTopicID(choice.Topic)
+		if buttons[i].Text != wantText {
+			t.Errorf("кнопка %d: текст %q, ожидался %q", i, buttons[i].Text, wantText)
+		}
+		if buttons[i].CallbackData == nil || *buttons[i].CallbackData != wantData {
+			t.Errorf("кнопка %d: данные %v, ожидались %q", i, buttons[i].CallbackData, wantData)
+		}
+	}
+}
+
+func TestFindGrammarTopicChoice(t *testing.T) {
+	tests := []struct {
+		id    string
+		topic services.GrammarTopic
+		ok    bool
+	}{
+		{"tenses", services.GrammarTopicTenses, true},
+		{"articles", services.GrammarTopicArticles, true},
+		{"prepositions", services.GrammarTopicPrepositions, true},
+		{grammarTopicAnyID, services.GrammarTopicAny, true},
+		{"", services.GrammarTopicAny, false},
+		{"conditionals", services.GrammarTopicAny, false},
+	}
+
+	for _, tt := range tests {
+		choice, ok := findGrammarTopicChoice(tt.id)
+		if ok != tt.ok || choice.Topic != tt.topic {
+			t.Errorf("findGrammarTopicChoice(%q) = %q, %v; ожидалось %q, %v", tt.id, choice.Topic, ok, tt.topic, tt.ok)
+		}
+	}
+}
+
+func TestHandleGrammarTopicCallbackUnknownTopic(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: 1},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+	h.handleGrammarTopicCallback(context.Background(), callback, "conditionals")
+
+	answers := stub.calls("answerCallbackQuery")
+	if len(answers) != 1 || answers[0].Get("text") != i18n.T(i18n.DefaultLanguage, "exercise.unknown_type") {
+		t.Errorf("ответы на нажатие %v, ожидалось сообщение о неизвестной теме", answers)
+	}
+	if edits := stub.calls("editMessageText"); len(edits) != 0 {
+		t.Errorf("сообщение изменено при неизвестной теме: %v", edits)
+	}
+}
+
+func TestHandleExerciseTypeCallbackGrammarAsksForTopic(t *testing.T) {
+	db := newTestDatabase(t)
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+	h.SetSessionStore(newMemorySessionStore())
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: user.TelegramID, FirstName: user.FirstName},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+	h.handleExerciseTypeCallback(context.Background(), callback, "grammar")
+
+	edits := stub.calls("editMessageText")
+	if len(edits) != 1 || edits[0].Get("text") != i18n.T(i18n.DefaultLanguage, "exercise.choose_topic") {
+		t.Fatalf("изменения сообщения %v, ожидалось предложение выбрать тему", edits)
+	}
+	if !strings.Contains(edits[0].Get("reply_markup"), callbackGrammarTopic+":"+string(services.GrammarTopicPrepositions)) {
+		t.Errorf("на клавиатуре нет темы предлогов: %s", edits[0].Get("reply_markup"))
+	}
+}
+
+func TestHandleGrammarTopicCallbackPrepositions(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request services.OpenAIRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		if len(request.Messages) > 0 {
+			prompt = request.Messages[0].Content
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "The book is ___ the table."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := NewHandler(botAPI, db, openAI)
+	h.SetExerciseService(services.NewExerciseService(openAI))
+	h.SetSessionStore(store)
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: user.TelegramID, FirstName: user.FirstName},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+	h.handleGrammarTopicCallback(ctx, callback, "prepositions")
+
+	wantEdit := i18n.T(i18n.DefaultLanguage, "exercise.topic_chosen", i18n.T(i18n.DefaultLanguage, "exercise.topic.prepositions"))
+	if edits := stub.calls("editMessageText"); len(edits) != 1 || edits[0].Get("text") != wantEdit {
+		t.Errorf("изменения сообщения %v, ожидалось %q", edits, wantEdit)
+	}
+	if !strings.Contains(prompt, "prepositions") {
+		t.Errorf("промпт генерации не упоминает предлоги:\n%s", prompt)
+	}
+
+	saved := store.session(user.ID)
+	var savedContext map[string]string
+	json.Unmarshal(saved.ContextData, &savedContext)
+	if savedContext["grammarTopic"] != "prepositions" {
+		t.Errorf("контекст сессии %v, ожидалась тема prepositions", savedContext)
+	}
+
+	exerciseID, _ := strconv.ParseInt(savedContext["exerciseID"], 10, 64)
+	exercise, err := db.GetExerciseByID(ctx, exerciseID)
+	if err != nil {
+		t.Fatalf("GetExerciseByID: %v", err)
+	}
+	if exercise == nil || exercise.Type != "grammar" || exercise.Topic != "prepositions" {
+		t.Errorf("сохранено упражнение %+v, ожидалось грамматическое на тему prepositions", exercise)
+	}
+}
+
+func TestGenerateOfflineExercise(t *testing.T) {
+	h := &Handler{exerciseService: services.NewExerciseService(nil)}
+
+	tests := []struct {
+		exerciseType string
+		ok           bool
+	}{
+		{"grammar", true},
+		{"vocabulary", true},
+		{"translation", true},
+		{"reading", false},
+		{"speaking", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exerciseType, func(t *testing.T) {
+			exercise := h.generateOfflineExercise(tt.exerciseType, "A2")
+			if (exercise != nil) != tt.ok {
+				t.Fatalf("generateOfflineExercise(%q) = %+v, ожидалось упражнение: %v", tt.exerciseType, exercise, tt.ok)
+			}
+			if exercise == nil {
+				return
+			}
+			if !exercise.Offline || exercise.Type != tt.exerciseType || exercise.Level != "A2" {
+				t.Errorf("упражнение %+v, ожидалось офлайн-упражнение %s уровня A2", exercise, tt.exerciseType)
+			}
+			if exercise.Content == "" || exercise.Answer == "" {
+				t.Errorf("неполное упражнение %+v", exercise)
+			}
+			if exercise.Data.Instruction == "" || exercise.Content != exercise.Data.Instruction+"\n\n"+exercise.Data.Prompt {
+				t.Errorf("структурированное содержание %+v не складывается в текст %q", exercise.Data, exercise.Content)
+			}
+		})
+	}
+}
+
+func TestStartExerciseFallsBackToOfflineExercise(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"message": "overloaded"}}`, http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	tests := []struct {
+		exerciseType string
+		wantOffline  bool
+	}{
+		{"grammar", true},
+		{"translation", true},
+		{"reading", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exerciseType, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			store := newMemorySessionStore()
+			h := NewHandler(botAPI, db, openAI)
+			h.SetExerciseService(services.NewExerciseService(openAI))
+			h.SetSessionStore(store)
+
+			session, err := store.GetOrCreateUserSession(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetOrCreateUserSession: %v", err)
+			}
+			h.startExercise(ctx, 100, session, tt.exerciseType, "A1", services.GrammarTopicAny)
+
+			offlineNotice := slices.Contains(stub.sent(), i18n.T(i18n.DefaultLanguage, "exercise.offline"))
+			if offlineNotice != tt.wantOffline {
+				t.Errorf("сообщения %q, ожидалось предупреждение об офлайн-упражнении: %v", stub.sent(), tt.wantOffline)
+			}
+
+			saved := store.session(user.ID)
+			if !tt.wantOffline {
+				if saved.State == StateExerciseReply {
+					t.Errorf("сессия ждет ответа, хотя упражнение не выдано")
+				}
+				return
+			}
+
+			var savedContext map[string]string
+			json.Unmarshal(saved.ContextData, &savedContext)
+			if saved.State != StateExerciseReply {
+				t.Fatalf("сессия %s, ожидалось ожидание ответа", saved.State)
+			}
+
+			exerciseID, _ := strconv.ParseInt(savedContext["exerciseID"], 10, 64)
+			exercise, err := db.GetExerciseByID(ctx, exerciseID)
+			if err != nil {
+				t.Fatalf("GetExerciseByID: %v", err)
+			}
+			if exercise == nil || !exercise.Offline || exercise.Type != tt.exerciseType || exercise.Answer == "" {
+				t.Errorf("сохранено упражнение %+v, ожидалось офлайн-упражнение %s с ответом", exercise, tt.exerciseType)
+			}
+		})
+	}
+}
+
+func TestCurrentExerciseWithoutExercise(t *testing.T) {
+	h := &Handler{}
+
+	tests := []struct {
+		name    string
+		session database.UserSession
+		wantErr bool
+	}{
+		{"не ответ на упражнение", database.UserSession{State: StateIdle, ContextData: []byte(`{"exerciseID": "7"}`)}, false},
+		{"нет упражнения в контексте", database.UserSession{State: StateExerciseReply, ContextData: []byte(`{}`)}, false},
+		{"испорченный контекст", database.UserSession{State: StateExerciseReply, ContextData: []byte(`not json`)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exercise, err := h.currentExercise(context.Background(), &tt.session)
+			if exercise != nil || (err != nil) != tt.wantErr {
+				t.Errorf("currentExercise = %+v, %v; ожидалось без упражнения, ошибка: %v", exercise, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleExerciseReplyReloadsExercise(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := withLanguage(context.Background(), i18n.Russian)
+	user := newTestDatabaseUser(t, db)
+
+	exercise, err := db.SaveExercise(ctx, database.Exercise{Type: "grammar", Level: "A1", Content: "She ___ home.", Answer: "goes"})
+	if err != nil {
+		t.Fatalf("SaveExercise: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		exerciseID int64
+		wantFound  bool
+	}{
+		{"упражнение найдено", exercise.ID, true},
+		{"упражнение удалено", -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			store := newMemorySessionStore()
+			h := NewHandler(botAPI, db, nil)
+			h.SetExerciseService(services.NewExerciseService(nil))
+			h.SetSessionStore(store)
+
+			session, _ := store.GetOrCreateUserSession(ctx, user.ID)
+			session.State = StateExerciseReply
+			session.ContextData = []byte(`{"exerciseID": "` + strconv.FormatInt(tt.exerciseID, 10) + `"}`)
+
+			h.handleMessageByState(ctx, textUpdate(1, user.TelegramID, "goes"), user, session)
+
+			// Найденное упражнение проверяется, иначе пользователь узнает, что его больше нет
+			want := i18n.T(i18n.Russian, "exercise.not_found")
+			if tt.wantFound {
+				want = i18n.T(i18n.Russian, "exercise.next")
+			}
+			if sent := stub.sent(); !slices.Contains(sent, want) {
+				t.Errorf("отправлено %q, ожидалось %q", sent, want)
+			}
+			if state := store.session(user.ID).State; state != StateIdle {
+				t.Errorf("состояние %q, ожидалось %q", state, StateIdle)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// exerciseAnswerTimeout - сколько ждать ответа на упражнение,
+// прежде чем показать правильный ответ и вернуть сессию в начальное состояние
+const exerciseAnswerTimeout = 30 * time.Minute
+
+// ExpireStaleExercises завершает упражнения, на которые пользователи давно не отвечают:
+// показывает правильный ответ, отмечает упражнение пропущенным и сбрасывает сессию.
+// Сессии, застрявшие на выборе упражнения, сбрасываются без сообщения
+func (h *Handler) ExpireStaleExercises(ctx context.Context) {
+	before := time.Now().Add(-exerciseAnswerTimeout)
+
+	sessions, err := h.db.GetStaleSessions(ctx, []string{StateExercise, StateExerciseReply}, before)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения неактивных сессий", "error", err)
+		return
+	}
+
+	for _, stale := range sessions {
+		h.expireExercise(withLanguage(ctx, i18n.Resolve(stale.UILanguage, stale.LanguageCode)), stale, before)
+	}
+}
+
+// expireExercise завершает упражнение одной неактивной сессии.
+// Сообщение отправляется, только если пользователь не ответил, пока шла проверка
+func (h *Handler) expireExercise(ctx context.Context, stale database.StaleSession, before time.Time) {
+	exercise, err := h.currentExercise(ctx, &stale.UserSession)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения упражнения", "user_id", stale.UserID, "error", err)
+	}
+
+	reset, err := h.db.ResetStaleSession(ctx, stale.ID, stale.State, StateIdle, before)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка сброса неактивной сессии", "user_id", stale.UserID, "error", err)
+		return
+	}
+	if !reset {
+		return
+	}
+	if err := h.sessions.InvalidateUserSession(ctx, stale.UserID); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сброса сохраненной сессии", "user_id", stale.UserID, "error", err)
+	}
+	if exercise == nil {
+		return
+	}
+
+	if err := h.db.SaveSkippedExercise(ctx, stale.UserID, exercise.ID); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения пропуска упражнения", "user_id", stale.UserID, "error", err)
+	}
+
+	msg := tgbotapi.NewMessage(stale.TelegramID, tr(ctx, "exercise.timed_out", h.revealAnswer(ctx, stale.TelegramID, exercise)))
+	if _, err := h.bot.Send(msg); err != nil {
+		slog.ErrorContext(ctx, "Ошибка отправки ответа на упражнение", "user_id", stale.UserID, "error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestExerciseSession переводит сессию пользователя в состояние state с упражнением exerciseID
+// и отодвигает последнюю активность в lastActivity
+func newTestExerciseSession(t *testing.T, db *database.PostgresDB, userID int64, state string, exerciseID int64, lastActivity time.Time) *database.UserSession {
+	t.Helper()
+	ctx := context.Background()
+
+	session, err := db.GetOrCreateUserSession(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+
+	session.State = state
+	session.ContextData, _ = json.Marshal(map[string]string{"exerciseID": strconv.FormatInt(exerciseID, 10)})
+	if err := db.UpdateUserSession(ctx, *session); err != nil {
+		t.Fatalf("UpdateUserSession: %v", err)
+	}
+	if err := db.TouchUserSession(ctx, session.ID, lastActivity); err != nil {
+		t.Fatalf("TouchUserSession: %v", err)
+	}
+
+	return session
+}
+
+func TestRevealAnswer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"message": "internal error"}}`, http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	botAPI, _ := newTestBotAPI(t)
+	h := &Handler{bot: botAPI, exerciseService: services.NewExerciseService(openAI)}
+	ctx := withLanguage(context.Background(), i18n.Russian)
+
+	tests := []struct {
+		name     string
+		exercise database.Exercise
+		want     string
+	}{
+		{"сохраненный ответ", database.Exercise{Type: "grammar", Content: "She ___ home.", Answer: "goes"}, "goes"},
+		{"ответ от OpenAI недоступен", database.Exercise{Type: "translation", Content: "Переведите: кошка"}, i18n.T(i18n.Russian, "exercise.answer_failed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.revealAnswer(ctx, 100, &tt.exercise); got != tt.want {
+				t.Errorf("revealAnswer = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpireStaleExercises(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	exercise, err := db.SaveExercise(ctx, database.Exercise{Type: "grammar", Level: "A1", Content: "She ___ home.", Answer: "goes"})
+	if err != nil {
+		t.Fatalf("SaveExercise: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		state        string
+		lastActivity time.Time
+		wantState    string
+		wantMessage  bool
+	}{
+		{"давний ответ на упражнение", StateExerciseReply, now.Add(-time.Hour), StateIdle, true},
+		{"давний выбор упражнения", StateExercise, now.Add(-time.Hour), StateIdle, false},
+		{"недавний ответ на упражнение", StateExerciseReply, now.Add(-time.Minute), StateExerciseReply, false},
+		{"давняя сессия без упражнения", StateGrammarCheck, now.Add(-time.Hour), StateGrammarCheck, false},
+	}
+
+	users := make([]*database.User, len(tests))
+	for i, tt := range tests {
+		users[i] = newTestDatabaseUser(t, db)
+		newTestExerciseSession(t, db, users[i].ID, tt.state, exercise.ID, tt.lastActivity)
+	}
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+	h.ExpireStaleExercises(ctx)
+
+	// Сообщения могут уходить и пользователям других тестов, считаем только созданных здесь
+	messages := make(map[string][]string)
+	for _, params := range stub.calls("sendMessage") {
+		messages[params.Get("chat_id")] = append(messages[params.Get("chat_id")], params.Get("text"))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := users[i]
+			lang := i18n.Resolve("", user.LanguageCode)
+
+			sent := messages[strconv.FormatInt(user.TelegramID, 10)]
+			if tt.wantMessage {
+				if want := i18n.T(lang, "exercise.timed_out", "goes"); len(sent) != 1 || sent[0] != want {
+					t.Errorf("отправлено %q, ожидалось %q", sent, want)
+				}
+			} else if len(sent) != 0 {
+				t.Errorf("отправлено %q, ожидалось без сообщений", sent)
+			}
+
+			saved, err := db.GetOrCreateUserSession(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetOrCreateUserSession: %v", err)
+			}
+			if saved.State != tt.wantState {
+				t.Errorf("состояние сессии %q, ожидалось %q", saved.State, tt.wantState)
+			}
+
+			attempts, err := db.GetUserExercises(ctx, user.ID, 10, 0)
+			if err != nil {
+				t.Fatalf("GetUserExercises: %v", err)
+			}
+			if skipped := len(attempts) == 1 && attempts[0].Skipped; skipped != tt.wantMessage {
+				t.Errorf("попытки %+v, пропуск ожидался: %v", attempts, tt.wantMessage)
+			}
+		})
+	}
+}
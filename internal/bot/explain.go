@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"log/slog"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleExplainCallback отправляет объяснение правила, которое проверяло упражнение.
+// Объяснение запрашивается у OpenAI один раз и сохраняется вместе с упражнением
+func (h *Handler) handleExplainCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, payload string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	exerciseID, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		h.answerCallback(callback.ID, tr(ctx, "exercise.unavailable"))
+		return
+	}
+
+	exercise, err := h.db.GetExerciseByID(ctx, exerciseID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения упражнения", "error", err, "exercise_id", exerciseID)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	if exercise == nil {
+		h.answerCallback(callback.ID, tr(ctx, "exercise.unavailable"))
+		return
+	}
+
+	h.answerCallback(callback.ID, "")
+
+	explanation, err := h.exerciseExplanation(ctx, chatID, exercise)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения объяснения правила", "error", err, "exercise_id", exerciseID)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.explanation", escapeMarkdown(explanation)))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// exerciseExplanation возвращает сохраненное объяснение правила упражнения,
+// а если его еще нет - получает его от OpenAI и сохраняет
+func (h *Handler) exerciseExplanation(ctx context.Context, chatID int64, exercise *database.Exercise) (string, error) {
+	if exercise.Explanation != "" {
+		return exercise.Explanation, nil
+	}
+
+	var explanation string
+	var err error
+	h.withProgress(ctx, chatID, "", func() {
+		explanation, err = h.exerciseService.ExplainRule(ctx, exercise.Content)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := h.db.SetExerciseExplanation(ctx, exercise.ID, explanation); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения объяснения правила", "error", err, "exercise_id", exercise.ID)
+	}
+
+	return explanation, nil
+}
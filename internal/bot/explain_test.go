@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// explainCallback создает нажатие кнопки объяснения правила упражнения
+func explainCallback(userID int64) *tgbotapi.CallbackQuery {
+	return &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: userID},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+}
+
+func TestSendExerciseFeedbackExplainButton(t *testing.T) {
+	tests := []struct {
+		exerciseType string
+		wantButton   bool
+	}{
+		{"grammar", true},
+		{"vocabulary", true},
+		{"translation", true},
+		{"reading", false},
+		{"speaking", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exerciseType, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			h := &Handler{bot: botAPI}
+
+			h.sendExerciseFeedback(context.Background(), 100, &database.Exercise{ID: 42, Type: tt.exerciseType}, 100, "Correct.")
+
+			calls := stub.calls("sendMessage")
+			if len(calls) != 1 {
+				t.Fatalf("отправлено %d сообщений, ожидалось одно", len(calls))
+			}
+			markup := calls[0].Get("reply_markup")
+			if !tt.wantButton {
+				if markup != "" {
+					t.Errorf("к оценке прикреплена клавиатура %s", markup)
+				}
+				return
+			}
+
+			var keyboard tgbotapi.InlineKeyboardMarkup
+			if err := json.Unmarshal([]byte(markup), &keyboard); err != nil {
+				t.Fatalf("не удалось разобрать клавиатуру %q: %v", markup, err)
+			}
+			if len(keyboard.InlineKeyboard) != 1 || len(keyboard.InlineKeyboard[0]) != 1 {
+				t.Fatalf("клавиатура %+v, ожидалась одна кнопка", keyboard.InlineKeyboard)
+			}
+			button := keyboard.InlineKeyboard[0][0]
+			if button.Text != i18n.T(i18n.DefaultLanguage, "exercise.explain_button") || *button.CallbackData != callbackExplain+":42" {
+				t.Errorf("кнопка %q с данными %q", button.Text, *button.CallbackData)
+			}
+		})
+	}
+}
+
+func TestHandleExplainCallbackInvalidExercise(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	h.handleExplainCallback(context.Background(), explainCallback(1), "not-a-number")
+
+	answers := stub.calls("answerCallbackQuery")
+	if len(answers) != 1 || answers[0].Get("text") != i18n.T(i18n.DefaultLanguage, "exercise.unavailable") {
+		t.Errorf("ответы на нажатие %v, ожидалось сообщение о недоступном упражнении", answers)
+	}
+	if sent := stub.sent(); len(sent) != 0 {
+		t.Errorf("отправлены сообщения %q", sent)
+	}
+}
+
+func TestHandleExplainCallback(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Use *goes* with he, she and it."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, openAI)
+	h.SetExerciseService(services.NewExerciseService(openAI))
+
+	tests := []struct {
+		name         string
+		stored       string
+		want         string
+		wantRequests int32
+	}{
+		{"сохраненное объяснение", "The Present Simple is used for habits.", "The Present Simple is used for habits.", 0},
+		{"объяснение от OpenAI", "", "Use *goes* with he, she and it.", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			exercise, err := db.SaveExercise(ctx, database.Exercise{Type: "grammar", Level: user.EnglishLevel, Content: "She ___ to school.", Answer: "goes"})
+			if err != nil {
+				t.Fatalf("SaveExercise: %v", err)
+			}
+			if tt.stored != "" {
+				if err := db.SetExerciseExplanation(ctx, exercise.ID, tt.stored); err != nil {
+					t.Fatalf("SetExerciseExplanation: %v", err)
+				}
+			}
+
+			// Второе нажатие берет объяснение из базы, не обращаясь к OpenAI
+			for range 2 {
+				before := len(stub.sent())
+				h.handleExplainCallback(ctx, explainCallback(user.TelegramID), strconv.FormatInt(exercise.ID, 10))
+
+				sent := stub.sent()[before:]
+				if want := i18n.T(i18n.DefaultLanguage, "exercise.explanation", escapeMarkdown(tt.want)); len(sent) != 1 || sent[0] != want {
+					t.Errorf("отправлено %q, ожидалось %q", sent, want)
+				}
+			}
+			if requests.Load() != tt.wantRequests {
+				t.Errorf("запросов к OpenAI %d, ожидалось %d", requests.Load(), tt.wantRequests)
+			}
+
+			saved, err := db.GetExerciseByID(ctx, exercise.ID)
+			if err != nil || saved == nil {
+				t.Fatalf("GetExerciseByID: %v", err)
+			}
+			if saved.Explanation != tt.want {
+				t.Errorf("сохранено объяснение %q, ожидалось %q", saved.Explanation, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// exportFileName - имя файла с выгрузкой данных пользователя
+const exportFileName = "english-bot-data.json"
+
+// handleExport выгружает все данные пользователя и отправляет их JSON-файлом
+func (h *Handler) handleExport(ctx context.Context, chatID int64, user *database.User) {
+	h.bot.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatUploadDocument))
+
+	export, err := h.db.ExportUserData(ctx, user.ID)
+	if err != nil || export == nil {
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка выгрузки данных пользователя", "error", err)
+		}
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка сериализации выгрузки данных", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	document := tgbotapi.NewDocument(chatID, tgbotapi.FileReader{
+		Name:   exportFileName,
+		Reader: bytes.NewReader(data),
+	})
+	document.Caption = tr(ctx, "export.caption")
+	if _, err := h.bot.Send(document); err != nil {
+		slog.ErrorContext(ctx, "Ошибка отправки выгрузки данных", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+	}
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"testing"
+)
+
+func TestHandleExportSendsDocument(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := withLanguage(context.Background(), i18n.Russian)
+	user := newTestDatabaseUser(t, db)
+
+	if _, err := db.AddVocabulary(ctx, database.UserVocabulary{UserID: user.ID, Word: "journey", Translation: "путешествие"}); err != nil {
+		t.Fatalf("AddVocabulary: %v", err)
+	}
+	if err := db.SaveFeedback(ctx, user.ID, "Great bot!"); err != nil {
+		t.Fatalf("SaveFeedback: %v", err)
+	}
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+	h.SetSessionStore(newMemorySessionStore())
+
+	h.handleCommand(ctx, textUpdate(1, user.TelegramID, "/export"), user, &database.UserSession{UserID: user.ID, State: StateIdle})
+
+	documents := stub.calls("sendDocument")
+	files := stub.uploads("sendDocument", "document")
+	if len(documents) != 1 || len(files) != 1 {
+		t.Fatalf("отправлено документов %d, файлов %d, ожидался один", len(documents), len(files))
+	}
+	if caption := documents[0].Get("caption"); caption != i18n.T(i18n.Russian, "export.caption") {
+		t.Errorf("подпись документа %q", caption)
+	}
+
+	var export database.UserDataExport
+	if err := json.Unmarshal(files[0], &export); err != nil {
+		t.Fatalf("документ не является JSON выгрузки: %v\n%s", err, files[0])
+	}
+	if export.User.TelegramID != user.TelegramID {
+		t.Errorf("выгружен пользователь %d, ожидался %d", export.User.TelegramID, user.TelegramID)
+	}
+	if len(export.Vocabulary) != 1 || export.Vocabulary[0].Word != "journey" {
+		t.Errorf("словарь в выгрузке %+v", export.Vocabulary)
+	}
+	if len(export.Feedback) != 1 || export.Feedback[0].Text != "Great bot!" {
+		t.Errorf("отзывы в выгрузке %+v", export.Feedback)
+	}
+	if sent := stub.sent(); len(sent) != 0 {
+		t.Errorf("вместе с выгрузкой отправлены сообщения %q", sent)
+	}
+}
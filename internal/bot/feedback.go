@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleFeedback сохраняет отзыв пользователя и пересылает его администраторам
+func (h *Handler) handleFeedback(ctx context.Context, chatID int64, user *database.User, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "feedback.usage")))
+		return
+	}
+
+	if err := h.db.SaveFeedback(ctx, user.ID, text); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения отзыва", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "Получен отзыв пользователя", "user_id", user.ID)
+	h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "feedback.thanks")))
+
+	h.notifyFeedback(ctx, user, text)
+}
+
+// notifyFeedback пересылает отзыв администраторам. Ошибки отправки только логируются:
+// отзыв уже сохранен в базе данных
+func (h *Handler) notifyFeedback(ctx context.Context, user *database.User, text string) {
+	if len(h.feedbackChatIDs) == 0 {
+		return
+	}
+
+	author := user.FirstName
+	if user.Username != "" {
+		author = "@" + user.Username
+	}
+	notice := i18n.T(i18n.DefaultLanguage, "feedback.admin", author, user.TelegramID, text)
+
+	for _, adminChatID := range h.feedbackChatIDs {
+		if _, err := h.bot.Send(tgbotapi.NewMessage(adminChatID, notice)); err != nil {
+			slog.ErrorContext(ctx, "Ошибка пересылки отзыва администратору", "admin_chat_id", adminChatID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"strconv"
+	"testing"
+)
+
+func TestHandleFeedbackWithoutText(t *testing.T) {
+	for _, text := range []string{"", "   ", "\n\t"} {
+		botAPI, stub := newTestBotAPI(t)
+		// Без базы данных: пустой отзыв не сохраняется
+		h := &Handler{bot: botAPI, feedbackChatIDs: []int64{900}}
+
+		h.handleFeedback(context.Background(), 100, &database.User{ID: 1}, text)
+
+		calls := stub.calls("sendMessage")
+		if len(calls) != 1 || calls[0].Get("chat_id") != "100" || calls[0].Get("text") != i18n.T(i18n.DefaultLanguage, "feedback.usage") {
+			t.Errorf("%q: отправлено %v, ожидалась подсказка пользователю", text, calls)
+		}
+	}
+}
+
+func TestNotifyFeedback(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       database.User
+		recipients []int64
+		wantAuthor string
+	}{
+		{"без администраторов", database.User{TelegramID: 10, FirstName: "Anna"}, nil, ""},
+		{"пользователь с ником", database.User{TelegramID: 10, FirstName: "Anna", Username: "anna_k"}, []int64{900}, "@anna_k"},
+		{"пользователь без ника", database.User{TelegramID: 10, FirstName: "Anna"}, []int64{900, 901}, "Anna"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			h := &Handler{bot: botAPI, feedbackChatIDs: tt.recipients}
+
+			h.notifyFeedback(context.Background(), &tt.user, "The check was wrong.")
+
+			calls := stub.calls("sendMessage")
+			if len(calls) != len(tt.recipients) {
+				t.Fatalf("отправлено %d сообщений, ожидалось %d", len(calls), len(tt.recipients))
+			}
+			// Администраторам отзыв пересылается на языке по умолчанию
+			want := i18n.T(i18n.DefaultLanguage, "feedback.admin", tt.wantAuthor, tt.user.TelegramID, "The check was wrong.")
+			for i, call := range calls {
+				if call.Get("chat_id") != strconv.FormatInt(tt.recipients[i], 10) || call.Get("text") != want {
+					t.Errorf("сообщение %d в чат %s: %q, ожидалось %q", i, call.Get("chat_id"), call.Get("text"), want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleFeedbackPersists(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := withLanguage(context.Background(), i18n.Russian)
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+	h.SetSessionStore(newMemorySessionStore())
+	h.SetFeedbackRecipients([]int64{900})
+
+	session := &database.UserSession{UserID: user.ID, State: StateIdle}
+	h.handleCommand(ctx, textUpdate(1, user.TelegramID, "/feedback  The grammar check marked a correct sentence as wrong. "), user, session)
+
+	export, err := db.ExportUserData(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ExportUserData: %v", err)
+	}
+	if len(export.Feedback) != 1 || export.Feedback[0].Text != "The grammar check marked a correct sentence as wrong." {
+		t.Errorf("сохранены отзывы %+v", export.Feedback)
+	}
+
+	calls := stub.calls("sendMessage")
+	if len(calls) != 2 {
+		t.Fatalf("отправлено %d сообщений, ожидались благодарность и пересылка администратору", len(calls))
+	}
+	if calls[0].Get("text") != i18n.T(i18n.Russian, "feedback.thanks") {
+		t.Errorf("пользователю отправлено %q, ожидалась благодарность", calls[0].Get("text"))
+	}
+	if calls[1].Get("chat_id") != "900" {
+		t.Errorf("отзыв переслан в чат %s, ожидался чат администратора", calls[1].Get("chat_id"))
+	}
+}
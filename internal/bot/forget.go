@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Действия на клавиатуре подтверждения удаления данных
+const (
+	forgetConfirm = "confirm"
+	forgetCancel  = "cancel"
+)
+
+// handleForgetMe просит пользователя подтвердить удаление всех его данных
+func (h *Handler) handleForgetMe(ctx context.Context, chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "forget.prompt"))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(ctx, "forget.confirm_button"), fmt.Sprintf("%s:%s", callbackForget, forgetConfirm)),
+			tgbotapi.NewInlineKeyboardButtonData(tr(ctx, "forget.cancel_button"), fmt.Sprintf("%s:%s", callbackForget, forgetCancel)),
+		),
+	)
+	h.bot.Send(msg)
+}
+
+// handleForgetCallback удаляет данные пользователя после подтверждения
+func (h *Handler) handleForgetCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, action string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	if action != forgetConfirm {
+		h.answerCallback(callback.ID, "")
+		h.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, tr(ctx, "forget.cancelled")))
+		return
+	}
+
+	// Ищем пользователя без создания, чтобы не завести запись заново
+	user, err := h.db.GetUserByTelegramID(ctx, callback.From.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения пользователя", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	if user != nil {
+		if err := h.db.DeleteUser(ctx, user.ID); err != nil {
+			slog.ErrorContext(ctx, "Ошибка удаления данных пользователя", "error", err, "user_id", user.ID)
+			h.answerCallback(callback.ID, "")
+			h.sendErrorMessage(ctx, chatID, err)
+			return
+		}
+		if err := h.sessions.InvalidateUserSession(ctx, user.ID); err != nil {
+			slog.ErrorContext(ctx, "Ошибка сброса сохраненной сессии", "error", err, "user_id", user.ID)
+		}
+		slog.InfoContext(ctx, "Данные пользователя удалены", "user_id", user.ID)
+	}
+
+	h.answerCallback(callback.ID, tr(ctx, "forget.deleted_toast"))
+	h.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, tr(ctx, "forget.deleted")))
+}
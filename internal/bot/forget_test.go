@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/i18n"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestHandleForgetMeAsksForConfirmation(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	h.handleForgetMe(withLanguage(context.Background(), i18n.Russian), 100)
+
+	calls := stub.calls("sendMessage")
+	if len(calls) != 1 {
+		t.Fatalf("отправлено %d сообщений, ожидалось одно", len(calls))
+	}
+	if text := calls[0].Get("text"); text != i18n.T(i18n.Russian, "forget.prompt") {
+		t.Errorf("текст запроса подтверждения %q", text)
+	}
+
+	var keyboard tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(calls[0].Get("reply_markup")), &keyboard); err != nil {
+		t.Fatalf("не удалось разобрать клавиатуру: %v", err)
+	}
+	if len(keyboard.InlineKeyboard) != 1 || len(keyboard.InlineKeyboard[0]) != 2 {
+		t.Fatalf("клавиатура %+v, ожидались две кнопки в одном ряду", keyboard.InlineKeyboard)
+	}
+	for i, want := range []string{"forget:confirm", "forget:cancel"} {
+		if data := keyboard.InlineKeyboard[0][i].CallbackData; data == nil || *data != want {
+			t.Errorf("кнопка %d: данные %v, ожидались %q", i, data, want)
+		}
+	}
+}
+
+func TestHandleForgetCallbackCancel(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	// Без базы данных: отмена не должна ничего удалять
+	h := &Handler{bot: botAPI}
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:      "callback-1",
+		From:    &tgbotapi.User{ID: 100},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+	h.handleForgetCallback(context.Background(), callback, forgetCancel)
+
+	edits := stub.calls("editMessageText")
+	if len(edits) != 1 || edits[0].Get("text") != i18n.T(i18n.DefaultLanguage, "forget.cancelled") {
+		t.Errorf("изменения сообщения %v, ожидалось сообщение об отмене", edits)
+	}
+	if answers := stub.calls("answerCallbackQuery"); len(answers) != 1 {
+		t.Errorf("на нажатие ответили %d раз, ожидался один ответ", len(answers))
+	}
+}
+
+func TestCallbacksDoNotRecreateDeletedUser(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	// Кнопки остались в чате после /forgetme, пользователя в базе уже нет
+	telegramID := -time.Now().UnixNano()
+	t.Cleanup(func() {
+		if user, _ := db.GetUserByTelegramID(context.Background(), telegramID); user != nil {
+			db.DeleteUser(context.Background(), user.ID)
+		}
+	})
+
+	for i, data := range []string{
+		"answer:1:0",
+		"settings:reminders",
+		"level:B1",
+		"reset:all",
+		"exercise:vocabulary",
+		"grammar:articles",
+		"practice:5:grammar",
+		"chat:travel",
+		"history:0",
+	} {
+		t.Run(data, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			h := NewHandler(botAPI, db, nil)
+
+			h.HandleUpdate(ctx, callbackUpdate(i+1, telegramID, data))
+
+			user, err := db.GetUserByTelegramID(ctx, telegramID)
+			if err != nil {
+				t.Fatalf("GetUserByTelegramID: %v", err)
+			}
+			if user != nil {
+				t.Fatalf("нажатие %q заново создало удаленного пользователя", data)
+			}
+
+			answers := stub.calls("answerCallbackQuery")
+			if len(answers) != 1 || answers[0].Get("text") != i18n.T(i18n.DefaultLanguage, "callback.inactive") {
+				t.Errorf("ответы на нажатие %v, ожидался один ответ о неактивной кнопке", answers)
+			}
+			if sent := stub.sent(); len(sent) != 0 {
+				t.Errorf("отправлены сообщения %q", sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/services"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleGrammarCheck проверяет текст пользователя и отправляет результат.
+// По умолчанию текст сначала проверяется через LanguageTool (быстро и детерминированно),
+// а объяснения от ChatGPT запрашиваются, только если найдены ошибки.
+// В настройках можно оставить только один из способов
+func (h *Handler) handleGrammarCheck(ctx context.Context, chatID int64, user *database.User, text string) {
+	if h.refuseFlagged(ctx, chatID, text) {
+		return
+	}
+
+	var result string
+	var err error
+	h.withProgress(ctx, chatID, tr(ctx, "check.progress"), func() {
+		result, err = h.checkGrammar(ctx, user, text)
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка проверки грамматики", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	// Отправляем результат проверки
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "check.result", result))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// checkGrammar возвращает отформатированный в Markdown результат проверки грамматики
+// способом, выбранным пользователем в настройках, и учитывает найденные ошибки в прогрессе
+func (h *Handler) checkGrammar(ctx context.Context, user *database.User, text string) (string, error) {
+	engine := h.grammarEngine(ctx, user.ID)
+
+	// Без LanguageTool проверяем грамматику только через OpenAI
+	if h.languageTool == nil || engine == database.GrammarEngineAI {
+		return h.checkGrammarWithOpenAI(ctx, text)
+	}
+
+	response, err := h.languageTool.CheckText(text, grammarCheckOptions(user))
+	if err != nil {
+		slog.WarnContext(ctx, "LanguageTool недоступен, проверяем грамматику через OpenAI", "error", err)
+		return h.checkGrammarWithOpenAI(ctx, text)
+	}
+
+	corrections := h.languageTool.FormatCorrections(languageFrom(ctx), text, response)
+	if len(response.Matches) == 0 {
+		return corrections, nil
+	}
+
+	if err := h.db.IncrementGrammarCorrections(ctx, user.ID, len(response.Matches)); err != nil {
+		slog.ErrorContext(ctx, "Ошибка обновления счетчика исправлений", "error", err, "user_id", user.ID)
+	}
+
+	if engine == database.GrammarEngineLanguageTool {
+		return corrections, nil
+	}
+
+	// Объяснения от ChatGPT дополняют результат, но не обязательны
+	explanation, err := h.openAI.CheckGrammar(ctx, text)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения объяснений от OpenAI", "error", err)
+		return corrections, nil
+	}
+
+	return tr(ctx, "check.explanation", corrections, escapeMarkdown(explanation)), nil
+}
+
+// grammarEngine возвращает способ проверки грамматики из настроек пользователя.
+// При ошибке чтения настроек используется проверка обоими способами
+func (h *Handler) grammarEngine(ctx context.Context, userID int64) string {
+	settings, err := h.db.GetUserSettings(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения настроек", "error", err)
+		return database.GrammarEngineBoth
+	}
+	return settings.GrammarEngine
+}
+
+// checkGrammarWithOpenAI проверяет грамматику только через OpenAI
+func (h *Handler) checkGrammarWithOpenAI(ctx context.Context, text string) (string, error) {
+	result, err := h.openAI.CheckGrammar(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return escapeMarkdown(result), nil
+}
+
+// grammarCheckOptions подбирает параметры LanguageTool для пользователя:
+// учитывает его родной язык и строже проверяет продвинутые уровни
+func grammarCheckOptions(user *database.User) services.CheckOptions {
+	opts := services.CheckOptions{
+		MotherTongue: user.LanguageCode,
+	}
+	if user.EnglishLevel == "C1" || user.EnglishLevel == "C2" {
+		opts.Level = "picky"
+	}
+	return opts
+}
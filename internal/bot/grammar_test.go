@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGrammarCheckOptions(t *testing.T) {
+	tests := []struct {
+		user database.User
+		want services.CheckOptions
+	}{
+		{database.User{LanguageCode: "ru", EnglishLevel: "A2"}, services.CheckOptions{MotherTongue: "ru"}},
+		{database.User{LanguageCode: "de", EnglishLevel: "B2"}, services.CheckOptions{MotherTongue: "de"}},
+		{database.User{LanguageCode: "ru", EnglishLevel: "C1"}, services.CheckOptions{MotherTongue: "ru", Level: "picky"}},
+		{database.User{EnglishLevel: "C2"}, services.CheckOptions{Level: "picky"}},
+	}
+
+	for _, tt := range tests {
+		if got := grammarCheckOptions(&tt.user); got != tt.want {
+			t.Errorf("grammarCheckOptions(%s, %s) = %+v, ожидалось %+v", tt.user.LanguageCode, tt.user.EnglishLevel, got, tt.want)
+		}
+	}
+}
+
+func TestCheckGrammarUsesChosenEngine(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	var languageToolCalls, openAICalls atomic.Int64
+	languageToolServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		languageToolCalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"matches": [{"message": "Use 'goes'.", "offset": 4, "length": 2, "rule": {"id": "AGREEMENT"}, "replacements": [{"value": "goes"}]}]}`))
+	}))
+	t.Cleanup(languageToolServer.Close)
+	openAIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		openAICalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "AI explanation"}}]}`))
+	}))
+	t.Cleanup(openAIServer.Close)
+
+	tests := []struct {
+		engine           string
+		wantLanguageTool bool
+		wantOpenAI       bool
+	}{
+		{database.GrammarEngineLanguageTool, true, false},
+		{database.GrammarEngineAI, false, true},
+		{database.GrammarEngineBoth, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.engine, func(t *testing.T) {
+			user := newTestDatabaseUser(t, db)
+			settings, err := db.GetUserSettings(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetUserSettings: %v", err)
+			}
+			settings.GrammarEngine = tt.engine
+			if err := db.UpdateUserSettings(ctx, *settings); err != nil {
+				t.Fatalf("UpdateUserSettings: %v", err)
+			}
+
+			botAPI, _ := newTestBotAPI(t)
+			h := NewHandler(botAPI, db, services.NewOpenAIService("test-key", openAIServer.URL))
+			h.SetLanguageToolService(services.NewLanguageToolService(languageToolServer.URL+"/", 0))
+			languageToolCalls.Store(0)
+			openAICalls.Store(0)
+
+			result, err := h.checkGrammar(ctx, user, "She go to school.")
+			if err != nil {
+				t.Fatalf("checkGrammar: %v", err)
+			}
+
+			if got := languageToolCalls.Load() > 0; got != tt.wantLanguageTool {
+				t.Errorf("запросов к LanguageTool %d, ожидалось обращение: %v", languageToolCalls.Load(), tt.wantLanguageTool)
+			}
+			if got := openAICalls.Load() > 0; got != tt.wantOpenAI {
+				t.Errorf("запросов к OpenAI %d, ожидалось обращение: %v", openAICalls.Load(), tt.wantOpenAI)
+			}
+			if got := strings.Contains(result, "AI explanation"); got != tt.wantOpenAI {
+				t.Errorf("результат %q, ожидалось объяснение ИИ: %v", result, tt.wantOpenAI)
+			}
+		})
+	}
+}
@@ -2,12 +2,11 @@ package bot
 
 import (
 	"context"
-	"encoding/json"
 	"english-bot/internal/database"
 	"english-bot/internal/services"
+	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -19,8 +18,14 @@ const (
 	StateGrammarCheck  = "grammar_check"
 	StateExercise      = "exercise"
 	StateExerciseReply = "exercise_reply"
+	StateVocabReview   = "vocab_review"
+	StateWriting       = "writing"
 )
 
+// chatHistoryLimit - максимальное количество предыдущих реплик, передаваемых в ChatGPT.
+// Под окно контекста модели историю дополнительно обрезает OpenAIService
+const chatHistoryLimit = 20
+
 // Handler обрабатывает сообщения от пользователей
 type Handler struct {
 	bot             *tgbotapi.BotAPI
@@ -29,14 +34,21 @@ type Handler struct {
 	languageTool    *services.LanguageToolService
 	exerciseService *services.ExerciseService
 	progressService *services.ProgressService
+	moderation      bool          // Проверять ли текст пользователя через API модерации перед отправкой в OpenAI
+	feedbackChatIDs []int64       // Чаты администраторов, куда пересылаются отзывы пользователей
+	exercisePool    *exercisePool // Заранее сгенерированные упражнения, nil если пул выключен
+	userLocks       *userLocks    // Не дают обновлениям одного пользователя изменять его сессию одновременно
+	sessions        SessionStore  // Хранилище сессий: база данных или Redis поверх нее
 }
 
 // NewHandler создает новый обработчик сообщений
 func NewHandler(bot *tgbotapi.BotAPI, db *database.PostgresDB, openAI *services.OpenAIService) *Handler {
 	return &Handler{
-		bot:    bot,
-		db:     db,
-		openAI: openAI,
+		bot:       bot,
+		db:        db,
+		openAI:    openAI,
+		userLocks: newUserLocks(),
+		sessions:  newPostgresSessionStore(db),
 	}
 }
 
@@ -50,6 +62,35 @@ func (h *Handler) SetExerciseService(service *services.ExerciseService) {
 	h.exerciseService = service
 }
 
+// SetModeration включает проверку текста пользователя через API модерации OpenAI
+func (h *Handler) SetModeration(enabled bool) {
+	h.moderation = enabled
+}
+
+// SetFeedbackRecipients задает чаты администраторов, которым пересылаются отзывы из /feedback
+func (h *Handler) SetFeedbackRecipients(chatIDs []int64) {
+	h.feedbackChatIDs = chatIDs
+}
+
+// SetSessionStore заменяет хранилище сессий, по умолчанию сессии хранятся в базе данных
+func (h *Handler) SetSessionStore(store SessionStore) {
+	h.sessions = store
+}
+
+// SetExercisePool включает пул заранее сгенерированных упражнений
+// размером size для каждой пары тип/уровень. size 0 выключает пул.
+// Пул пополняется в фоне, пока не отменен ctx
+func (h *Handler) SetExercisePool(ctx context.Context, size int) {
+	if size <= 0 {
+		h.exercisePool = nil
+		return
+	}
+
+	h.exercisePool = newExercisePool(ctx, size, func(ctx context.Context, exerciseType, level string) (*database.Exercise, error) {
+		return h.generateExercise(ctx, exerciseType, level, services.GrammarTopicAny)
+	})
+}
+
 // SetProgressService устанавливает сервис прогресса
 func (h *Handler) SetProgressService(service *services.ProgressService) {
 	h.progressService = service
@@ -57,12 +98,34 @@ func (h *Handler) SetProgressService(service *services.ProgressService) {
 
 // HandleUpdate обрабатывает обновления от Telegram
 func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	// Пока настройки пользователя не загружены, отвечаем на языке его клиента Telegram
+	ctx = withLanguage(ctx, senderLanguage(update))
+
+	// Сессия читается, изменяется и записывается без транзакции,
+	// поэтому обновления одного пользователя обрабатываем по очереди
+	if user := update.SentFrom(); user != nil {
+		unlock := h.userLocks.lock(user.ID)
+		defer unlock()
+	}
+
+	// Нажатия на inline-кнопки обрабатываются отдельно
+	if update.CallbackQuery != nil {
+		h.handleCallback(ctx, update.CallbackQuery)
+		return
+	}
+
+	// Исправленные сообщения в режимах ожидания ответа проверяются заново
+	if update.EditedMessage != nil {
+		h.handleEditedMessage(ctx, update)
+		return
+	}
+
 	// Игнорируем обновления без сообщений
 	if update.Message == nil {
 		return
 	}
 
-	slog.Info("Получено сообщение",
+	slog.InfoContext(ctx, "Получено сообщение",
 		"from", update.Message.From.UserName,
 		"text", update.Message.Text,
 	)
@@ -70,18 +133,20 @@ func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
 	// Получаем или создаем пользователя в БД
 	user, err := h.getOrCreateUser(ctx, update.Message.From)
 	if err != nil {
-		slog.Error("Ошибка получения пользователя", "error", err)
-		h.sendErrorMessage(update.Message.Chat.ID)
+		slog.ErrorContext(ctx, "Ошибка получения пользователя", "error", err)
+		h.sendErrorMessage(ctx, update.Message.Chat.ID, err)
 		return
 	}
+	ctx = withLanguage(ctx, h.userLanguage(ctx, user))
 
 	// Получаем текущую сессию пользователя
-	session, err := h.db.GetOrCreateUserSession(ctx, user.ID)
+	session, err := h.sessions.GetOrCreateUserSession(ctx, user.ID)
 	if err != nil {
-		slog.Error("Ошибка получения сессии", "error", err)
-		h.sendErrorMessage(update.Message.Chat.ID)
+		slog.ErrorContext(ctx, "Ошибка получения сессии", "error", err)
+		h.sendErrorMessage(ctx, update.Message.Chat.ID, err)
 		return
 	}
+	setUpdateState(ctx, session.State)
 
 	// Обрабатываем команды
 	if update.Message.IsCommand() {
@@ -89,10 +154,65 @@ func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
 		return
 	}
 
+	// Голосовые сообщения переводим в текст и обрабатываем как обычные
+	if update.Message.Voice != nil {
+		chatID := update.Message.Chat.ID
+
+		var text string
+		h.withProgress(ctx, chatID, "", func() {
+			text, err = h.transcribeVoice(ctx, update.Message.Voice)
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка обработки голосового сообщения", "error", err)
+			h.sendErrorMessage(ctx, chatID, err)
+			return
+		}
+
+		if text == "" {
+			msg := tgbotapi.NewMessage(chatID, tr(ctx, "voice.empty"))
+			h.bot.Send(msg)
+			return
+		}
+
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "voice.heard", text))
+		h.bot.Send(msg)
+
+		update.Message.Text = text
+	}
+
+	// Стикеры, фото, геопозицию и другие сообщения без текста не обрабатываем,
+	// чтобы не сбить текущий режим пустым ответом
+	if update.Message.Text == "" {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, tr(ctx, "message.unsupported"))
+		h.bot.Send(msg)
+		return
+	}
+
+	// Запоминаем сообщение с ответом, чтобы его исправление можно было проверить заново
+	if resubmitStates[session.State] {
+		h.rememberAnswerMessage(ctx, session, update.Message.MessageID)
+	}
+
 	// Обрабатываем сообщения в зависимости от состояния
 	h.handleMessageByState(ctx, update, user, session)
 }
 
+// handleCancel выходит из любого режима, в том числе неизвестного, и сбрасывает контекст сессии
+func (h *Handler) handleCancel(ctx context.Context, chatID int64, session *database.UserSession) {
+	session.State = StateIdle
+	session.ContextData = []byte("{}")
+	session.ConversationID = ""
+
+	if err := h.sessions.UpdateUserSession(ctx, *session); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сброса сессии", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "menu.cancelled"))
+	h.bot.Send(msg)
+}
+
 // getOrCreateUser получает или создает пользователя в БД
 func (h *Handler) getOrCreateUser(ctx context.Context, tgUser *tgbotapi.User) (*database.User, error) {
 	user, err := h.db.GetUserByTelegramID(ctx, tgUser.ID)
@@ -130,162 +250,111 @@ func (h *Handler) handleCommand(ctx context.Context, update tgbotapi.Update, use
 
 	switch command {
 	case "start":
-		// Сбрасываем состояние на idle
-		session.State = StateIdle
-		h.db.UpdateUserSession(ctx, *session)
-
-		msg := tgbotapi.NewMessage(chatID,
-			"👋 *Welcome to English Learning Bot!*\n\n"+
-				"I'm here to help you learn English in an interactive and fun way. You can:\n"+
-				"• Chat with me in English\n"+
-				"• Check your grammar\n"+
-				"• Get personalized exercises\n"+
-				"• Track your progress\n\n"+
-				"Use /help to see all available commands.")
-		msg.ParseMode = "Markdown"
-		h.bot.Send(msg)
+		h.handleStart(ctx, chatID, user, session, update.Message.CommandArguments())
+
+	case "cancel":
+		h.handleCancel(ctx, chatID, session)
 
 	case "help":
-		msg := tgbotapi.NewMessage(chatID,
-			"*Available commands:*\n\n"+
-				"📝 */chat* - Start a conversation in English\n"+
-				"✅ */check* - Check grammar of your sentence\n"+
-				"📚 */exercise* - Get a new exercise\n"+
-				"📊 */progress* - Show your learning progress\n"+
-				"⚙️ */settings* - Change your preferences")
+		msg := tgbotapi.NewMessage(chatID, helpText(languageFrom(ctx)))
 		msg.ParseMode = "Markdown"
 		h.bot.Send(msg)
 
 	case "chat":
-		// Устанавливаем состояние чата
-		session.State = StateChat
-		h.db.UpdateUserSession(ctx, *session)
-
-		// Начинаем новый диалог
-		conversation, err := h.db.StartConversation(ctx, user.ID, "general", user.EnglishLevel)
-		if err != nil {
-			slog.Error("Ошибка создания диалога", "error", err)
-			h.sendErrorMessage(chatID)
-			return
-		}
+		h.handleChat(ctx, chatID, user, session, update.Message.CommandArguments())
 
-		// Сохраняем ID диалога в сессии
-		session.ConversationID = fmt.Sprintf("%d", conversation.ID)
-		h.db.UpdateUserSession(ctx, *session)
+	case "topics":
+		h.handleTopics(ctx, chatID, user)
 
-		msg := tgbotapi.NewMessage(chatID,
-			"🗣️ *Let's practice English!*\n\n"+
-				"I'll be your conversation partner. Feel free to talk about anything you want.\n"+
-				"Just type your message in English, and I'll respond.")
-		msg.ParseMode = "Markdown"
-		h.bot.Send(msg)
+	case "endchat":
+		h.handleEndChat(ctx, chatID, user, session)
 
 	case "check":
 		// Устанавливаем состояние проверки грамматики
 		session.State = StateGrammarCheck
-		h.db.UpdateUserSession(ctx, *session)
+		h.sessions.UpdateUserSession(ctx, *session)
 
-		msg := tgbotapi.NewMessage(chatID,
-			"✅ *Grammar Check Mode*\n\n"+
-				"Send me a sentence or paragraph in English, and I'll check it for grammar mistakes.\n"+
-				"I'll explain any errors and suggest corrections.")
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "check.started"))
 		msg.ParseMode = "Markdown"
 		h.bot.Send(msg)
 
+	case "write":
+		h.handleWrite(ctx, chatID, session)
+
 	case "exercise":
-		// Устанавливаем состояние упражнения
-		session.State = StateExercise
+		h.handleExercise(ctx, chatID)
 
-		// Сохраняем в контексте тип упражнения (пока генерируем базовое)
-		contextData := map[string]string{
-			"exerciseType": "grammar",
-		}
+	case "practice":
+		h.handlePractice(ctx, chatID, update.Message.CommandArguments())
 
-		contextJSON, _ := json.Marshal(contextData)
-		session.ContextData = contextJSON
-		h.db.UpdateUserSession(ctx, *session)
+	case "quiz":
+		h.handleQuiz(ctx, chatID, user, session)
 
-		// Отправляем сообщение о генерации упражнения
-		msg := tgbotapi.NewMessage(chatID, "🔄 Generating exercise for your level, please wait...")
-		waitMsg, _ := h.bot.Send(msg)
+	case "skip":
+		h.handleSkip(ctx, chatID, user, session)
 
-		// Генерируем упражнение через OpenAI
-		exerciseText, err := h.openAI.GenerateExercise("grammar", user.EnglishLevel)
-		if err != nil {
-			slog.Error("Ошибка генерации упражнения", "error", err)
-			h.sendErrorMessage(chatID)
-			return
-		}
+	case "history":
+		h.handleHistory(ctx, chatID, user)
 
-		// Сохраняем упражнение в БД
-		exercise := database.Exercise{
-			Type:    "grammar",
-			Level:   user.EnglishLevel,
-			Content: exerciseText,
-			// Ответ будет заполнен позже для упражнений с ожидаемым ответом
-		}
+	case "export":
+		h.handleExport(ctx, chatID, user)
 
-		savedExercise, err := h.db.SaveExercise(ctx, exercise)
-		if err != nil {
-			slog.Error("Ошибка сохранения упражнения", "error", err)
-		}
+	case "feedback":
+		h.handleFeedback(ctx, chatID, user, update.Message.CommandArguments())
 
-		// Обновляем контекст сессии, включая ID упражнения
-		contextData["exerciseID"] = fmt.Sprintf("%d", savedExercise.ID)
-		contextJSON, _ = json.Marshal(contextData)
-		session.ContextData = contextJSON
-		session.State = StateExerciseReply
-		h.db.UpdateUserSession(ctx, *session)
+	case "hint":
+		h.handleHint(ctx, chatID, session)
 
-		// Удаляем сообщение "Генерируем упражнение"
-		deleteMsg := tgbotapi.NewDeleteMessage(chatID, waitMsg.MessageID)
-		h.bot.Request(deleteMsg)
+	case "pronounce":
+		h.handlePronounce(ctx, chatID, update.Message.CommandArguments())
 
-		// Отправляем упражнение
-		exerciseMsg := tgbotapi.NewMessage(chatID,
-			"📚 *Exercise*\n\n"+exerciseText+"\n\n"+
-				"Type your answer when ready.")
-		exerciseMsg.ParseMode = "Markdown"
-		h.bot.Send(exerciseMsg)
+	case "settings":
+		h.handleSettings(ctx, chatID, user)
 
-	case "progress":
-		progress, err := h.db.GetUserProgress(ctx, user.ID)
-		if err != nil {
-			slog.Error("Ошибка получения прогресса", "error", err)
-			h.sendErrorMessage(chatID)
-			return
-		}
+	case "me":
+		h.handleMe(ctx, chatID, user)
 
-		// Рассчитываем процент правильных ответов
-		correctPercentage := 0
-		if progress.TotalExercises > 0 {
-			correctPercentage = (progress.CorrectExercises * 100) / progress.TotalExercises
-		}
+	case "timezone":
+		h.handleTimezone(ctx, chatID, user, update.Message.CommandArguments())
 
-		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
-			"📊 *Your Learning Progress*\n\n"+
-				"• English Level: *%s*\n"+
-				"• Exercises Completed: *%d*\n"+
-				"• Correct Answers: *%d (%d%%)*\n"+
-				"• Conversations: *%d*\n"+
-				"• Messages Exchanged: *%d*\n"+
-				"• Current Streak: *%d days*\n"+
-				"• Longest Streak: *%d days*\n\n"+
-				"Keep up the good work! 🌟",
-			user.EnglishLevel,
-			progress.TotalExercises,
-			progress.CorrectExercises,
-			correctPercentage,
-			progress.TotalConversations,
-			progress.TotalMessages,
-			progress.CurrentStreak,
-			progress.LongestStreak,
-		))
-		msg.ParseMode = "Markdown"
-		h.bot.Send(msg)
+	case "level":
+		h.handleLevel(ctx, chatID, user)
+
+	case "vocab":
+		h.handleVocab(ctx, chatID, user, update.Message.CommandArguments())
+
+	case "review":
+		h.startVocabReview(ctx, chatID, user, session)
+
+	case "daily":
+		h.handleDaily(ctx, chatID, user)
+
+	case "achievements":
+		h.handleAchievements(ctx, chatID, user)
+
+	case "leaderboard":
+		h.handleLeaderboard(ctx, chatID, user, update.Message.CommandArguments())
+
+	case "reset":
+		h.handleReset(ctx, chatID)
+
+	case "forgetme":
+		h.handleForgetMe(ctx, chatID)
+
+	case "broadcast":
+		h.handleBroadcast(ctx, chatID, update.Message.CommandArguments())
+
+	case "stats":
+		h.handleStats(ctx, chatID)
+
+	case "reload":
+		h.handleReload(ctx, chatID)
+
+	case "progress":
+		h.handleProgress(ctx, chatID, user)
 
 	default:
-		msg := tgbotapi.NewMessage(chatID, "Unknown command. Use /help to see available commands.")
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "help.unknown_command"))
 		h.bot.Send(msg)
 	}
 }
@@ -297,49 +366,63 @@ func (h *Handler) handleMessageByState(ctx context.Context, update tgbotapi.Upda
 
 	switch session.State {
 	case StateChat:
-		// Получаем ID беседы из сессии
-		conversationID := 0
-		fmt.Sscanf(session.ConversationID, "%d", &conversationID)
+		// Недопустимый текст не сохраняем и не отправляем в OpenAI
+		if h.refuseFlagged(ctx, chatID, text) {
+			return
+		}
 
-		if conversationID == 0 {
-			// Если ID беседы не найден, создаем новую
-			conversation, err := h.db.StartConversation(ctx, user.ID, "general", user.EnglishLevel)
+		// Получаем текущую беседу пользователя
+		conversation, err := h.db.GetActiveConversation(ctx, user.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения диалога", "error", err)
+			h.sendErrorMessage(ctx, chatID, err)
+			return
+		}
+
+		if conversation == nil {
+			// Если активной беседы нет, создаем новую
+			conversation, err = h.db.StartConversation(ctx, user.ID, chatTopicFree, user.EnglishLevel)
 			if err != nil {
-				slog.Error("Ошибка создания диалога", "error", err)
-				h.sendErrorMessage(chatID)
+				slog.ErrorContext(ctx, "Ошибка создания диалога", "error", err)
+				h.sendErrorMessage(ctx, chatID, err)
 				return
 			}
-			conversationID = int(conversation.ID)
-			session.ConversationID = fmt.Sprintf("%d", conversationID)
-			h.db.UpdateUserSession(ctx, *session)
+			session.ConversationID = fmt.Sprintf("%d", conversation.ID)
+			h.sessions.UpdateUserSession(ctx, *session)
+		}
+		conversationID := conversation.ID
+
+		// Загружаем предыдущие реплики диалога до сохранения нового сообщения
+		history, err := h.db.GetConversationMessages(ctx, conversationID, chatHistoryLimit)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения истории диалога", "error", err)
 		}
 
 		// Сохраняем сообщение пользователя
 		userMessage := database.ConversationMessage{
-			ConversationID: int64(conversationID),
+			ConversationID: conversationID,
 			Role:           "user",
 			Content:        text,
 		}
 		h.db.AddConversationMessage(ctx, userMessage)
 
-		// Отправляем сообщение о печатании
-		typingMsg := tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping)
-		h.bot.Request(typingMsg)
-
-		// Создаем системный промпт в зависимости от уровня пользователя
-		systemPrompt := fmt.Sprintf("You are an English tutor speaking with a student at %s level. Be encouraging, correct major mistakes, and adapt your language to their level. Keep responses concise and natural. Respond in English only.", user.EnglishLevel)
+		// Создаем системный промпт в зависимости от уровня пользователя и темы диалога
+		systemPrompt := chatSystemPrompt(user.EnglishLevel, conversation.Topic)
 
-		// Получаем ответ от OpenAI
-		response, err := h.openAI.GenerateResponse(text, systemPrompt)
+		// Получаем ответ от OpenAI с учетом истории диалога, показывая индикатор набора
+		var response string
+		h.withProgress(ctx, chatID, "", func() {
+			response, err = h.openAI.SimulateConversation(ctx, text, buildChatHistory(systemPrompt, history))
+		})
 		if err != nil {
-			slog.Error("Ошибка получения ответа от OpenAI", "error", err)
-			h.sendErrorMessage(chatID)
+			slog.ErrorContext(ctx, "Ошибка получения ответа от OpenAI", "error", err)
+			h.sendErrorMessage(ctx, chatID, err)
 			return
 		}
 
 		// Сохраняем ответ бота
 		botMessage := database.ConversationMessage{
-			ConversationID: int64(conversationID),
+			ConversationID: conversationID,
 			Role:           "bot",
 			Content:        response,
 		}
@@ -352,111 +435,108 @@ func (h *Handler) handleMessageByState(ctx context.Context, update tgbotapi.Upda
 		// Обновляем статистику пользователя
 		h.db.UpdateUserStreak(ctx, user.ID)
 
-	case StateGrammarCheck:
-		// Отправляем сообщение о проверке
-		waitMsg := tgbotapi.NewMessage(chatID, "🔍 Checking grammar...")
-		sentMsg, _ := h.bot.Send(waitMsg)
+	case StateWriting:
+		h.handleWritingSubmission(ctx, chatID, user, session, text)
 
-		// Проверяем грамматику через OpenAI
-		result, err := h.openAI.CheckGrammar(text)
-		if err != nil {
-			slog.Error("Ошибка проверки грамматики", "error", err)
-			h.sendErrorMessage(chatID)
-			return
-		}
-
-		// Удаляем сообщение "Проверяем грамматику"
-		deleteMsg := tgbotapi.NewDeleteMessage(chatID, sentMsg.MessageID)
-		h.bot.Request(deleteMsg)
-
-		// Отправляем результат проверки
-		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ *Grammar Check Result*\n\n%s", result))
-		msg.ParseMode = "Markdown"
-		h.bot.Send(msg)
+	case StateGrammarCheck:
+		h.handleGrammarCheck(ctx, chatID, user, text)
 
 		// Сбрасываем состояние
 		session.State = StateIdle
-		h.db.UpdateUserSession(ctx, *session)
+		h.sessions.UpdateUserSession(ctx, *session)
 
 		// Обновляем статистику пользователя
 		h.db.UpdateUserStreak(ctx, user.ID)
+		h.checkLevelUp(ctx, chatID, user)
 
 	case StateExerciseReply:
-		// Получаем данные контекста
-		var contextData map[string]string
-		if err := json.Unmarshal(session.ContextData, &contextData); err != nil {
-			slog.Error("Ошибка разбора контекста сессии", "error", err)
-			h.sendErrorMessage(chatID)
+		// Загружаем упражнение из контекста сессии для проверки ответа
+		exercise, err := h.currentExercise(ctx, session)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения упражнения", "error", err)
+			h.sendErrorMessage(ctx, chatID, err)
 			return
 		}
 
-		exerciseID := 0
-		fmt.Sscanf(contextData["exerciseID"], "%d", &exerciseID)
-
-		// Отправляем сообщение о проверке ответа
-		waitMsg := tgbotapi.NewMessage(chatID, "🔍 Checking your answer...")
-		sentMsg, _ := h.bot.Send(waitMsg)
+		if exercise == nil {
+			msg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.not_found"))
+			h.bot.Send(msg)
 
-		// Здесь будет проверка ответа через OpenAI
-		// Для демонстрации просто используем базовую проверку
-		isCorrect := strings.Contains(strings.ToLower(text), "correct") // это просто заглушка
+			session.State = StateIdle
+			h.sessions.UpdateUserSession(ctx, *session)
+			return
+		}
 
-		// Сохраняем ответ пользователя
-		userExercise := database.UserExercise{
-			UserID:     user.ID,
-			ExerciseID: int64(exerciseID),
-			UserAnswer: text,
-			IsCorrect:  isCorrect,
+		if isReadingExercise(exercise) {
+			h.answerReadingQuestion(ctx, chatID, user, session, exercise, text)
+			return
 		}
-		h.db.SaveUserExercise(ctx, userExercise)
-
-		// Удаляем сообщение "Проверяем ответ"
-		deleteMsg := tgbotapi.NewDeleteMessage(chatID, sentMsg.MessageID)
-		h.bot.Request(deleteMsg)
-
-		var feedbackMsg string
-		if isCorrect {
-			feedbackMsg = "🎉 *Correct!*\n\nGreat job! Your answer is correct."
-		} else {
-			feedbackMsg = "❌ *Not quite right*\n\nLet me explain:\n\n" +
-				"Your answer has some issues. Here's a better way to answer:\n" +
-				"[Правильный ответ будет здесь]"
+		if isSpeakingExercise(exercise) {
+			h.answerSpeakingExercise(ctx, chatID, user, session, exercise, text, update.Message.Voice != nil)
+			return
 		}
 
-		// Отправляем результат
-		msg := tgbotapi.NewMessage(chatID, feedbackMsg)
-		msg.ParseMode = "Markdown"
-		h.bot.Send(msg)
-
-		// Предлагаем следующее упражнение
-		nextMsg := tgbotapi.NewMessage(chatID, "Would you like another exercise? Use /exercise to get one.")
-		h.bot.Send(nextMsg)
-
-		// Сбрасываем состояние
-		session.State = StateIdle
-		h.db.UpdateUserSession(ctx, *session)
+		h.completeExercise(ctx, chatID, user, session, exercise, text)
 
-		// Обновляем статистику пользователя
-		h.db.UpdateUserStreak(ctx, user.ID)
+	case StateVocabReview:
+		h.handleVocabReviewAnswer(ctx, chatID, user, session, text)
 
 	default:
 		// Для неизвестного состояния предлагаем команды
-		msg := tgbotapi.NewMessage(chatID,
-			"I'm not sure what you want to do. Here are some options:\n\n"+
-				"• Use /chat to practice speaking English\n"+
-				"• Use /check to check grammar\n"+
-				"• Use /exercise to get a learning exercise\n"+
-				"• Use /progress to see your stats")
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "menu.unknown_state"))
 		h.bot.Send(msg)
 
 		// Сбрасываем состояние
 		session.State = StateIdle
-		h.db.UpdateUserSession(ctx, *session)
+		h.sessions.UpdateUserSession(ctx, *session)
 	}
 }
 
-// sendErrorMessage отправляет сообщение об ошибке пользователю
-func (h *Handler) sendErrorMessage(chatID int64) {
-	msg := tgbotapi.NewMessage(chatID, "Sorry, something went wrong. Please try again later.")
+// sendErrorMessage отправляет сообщение об ошибке пользователю на его языке.
+// Для известных ошибок сервисов, например недоступности ИИ или базы данных,
+// сообщение объясняет причину, для остальных отправляется общее сообщение
+func (h *Handler) sendErrorMessage(ctx context.Context, chatID int64, err error) {
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, errorMessageKey(err)))
 	h.bot.Send(msg)
 }
+
+// errorMessageKey возвращает ключ каталога с сообщением об ошибке err
+func errorMessageKey(err error) string {
+	switch {
+	case errors.Is(err, services.ErrCircuitOpen):
+		return "error.ai_unavailable"
+	case errors.Is(err, services.ErrOpenAIRateLimited):
+		return "error.ai_rate_limited"
+	case errors.Is(err, services.ErrOpenAIUnauthorized):
+		return "error.ai_unauthorized"
+	case errors.Is(err, services.ErrOpenAITimeout):
+		return "error.ai_timeout"
+	case errors.Is(err, database.ErrUnavailable):
+		return "error.db_unavailable"
+	case errors.Is(err, errVoiceDownload):
+		return "voice.download_failed"
+	}
+	return "error.generic"
+}
+
+// buildChatHistory преобразует сообщения диалога из БД в историю для ChatGPT
+func buildChatHistory(systemPrompt string, messages []database.ConversationMessage) []services.ChatMessage {
+	history := make([]services.ChatMessage, 0, len(messages)+1)
+	history = append(history, services.ChatMessage{
+		Role:    "system",
+		Content: systemPrompt,
+	})
+
+	for _, message := range messages {
+		role := "user"
+		if message.Role == "bot" {
+			role = "assistant"
+		}
+		history = append(history, services.ChatMessage{
+			Role:    role,
+			Content: message.Content,
+		})
+	}
+
+	return history
+}
@@ -0,0 +1,204 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// memorySessionStore - хранилище сессий в памяти вместо базы данных
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[int64]database.UserSession
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[int64]database.UserSession)}
+}
+
+func (s *memorySessionStore) GetOrCreateUserSession(ctx context.Context, userID int64) (*database.UserSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[userID]
+	if !ok {
+		session = database.UserSession{ID: userID, UserID: userID, State: StateIdle, ContextData: []byte("{}")}
+		s.sessions[userID] = session
+	}
+	return &session, nil
+}
+
+func (s *memorySessionStore) UpdateUserSession(ctx context.Context, session database.UserSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.UserID] = session
+	return nil
+}
+
+func (s *memorySessionStore) InvalidateUserSession(ctx context.Context, userID int64) error {
+	return nil
+}
+
+// session возвращает сохраненную сессию пользователя
+func (s *memorySessionStore) session(userID int64) database.UserSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[userID]
+}
+
+func TestBuildChatHistory(t *testing.T) {
+	messages := []database.ConversationMessage{
+		{Role: "user", Content: "Hi!"},
+		{Role: "bot", Content: "Hello! How are you?"},
+		{Role: "user", Content: "I am fine."},
+	}
+
+	got := buildChatHistory("You are a tutor.", messages)
+
+	want := []services.ChatMessage{
+		{Role: "system", Content: "You are a tutor."},
+		{Role: "user", Content: "Hi!"},
+		{Role: "assistant", Content: "Hello! How are you?"},
+		{Role: "user", Content: "I am fine."},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("buildChatHistory вернул %d сообщений, ожидалось %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("сообщение %d = %+v, ожидалось %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBuildChatHistoryWithoutMessages(t *testing.T) {
+	got := buildChatHistory("You are a tutor.", nil)
+	if len(got) != 1 || got[0].Role != "system" {
+		t.Errorf("buildChatHistory без истории = %+v, ожидался только системный промпт", got)
+	}
+}
+
+func TestHandleCancel(t *testing.T) {
+	states := []string{StateIdle, StateChat, StateGrammarCheck, StateExercise, StateExerciseReply, StateVocabReview, StateWriting, "unknown_state"}
+
+	for _, state := range states {
+		t.Run(state, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			store := newMemorySessionStore()
+			h := &Handler{bot: botAPI, sessions: store}
+
+			session := &database.UserSession{
+				ID:             1,
+				UserID:         1,
+				State:          state,
+				ContextData:    []byte(`{"exerciseId": 42}`),
+				ConversationID: "7",
+			}
+			h.handleCancel(context.Background(), 100, session)
+
+			saved := store.session(1)
+			if saved.State != StateIdle || string(saved.ContextData) != "{}" || saved.ConversationID != "" {
+				t.Errorf("после /cancel сессия %+v, ожидалась пустая сессия в состоянии idle", saved)
+			}
+
+			sent := stub.sent()
+			if len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "menu.cancelled") {
+				t.Errorf("отправлено %q, ожидалось подтверждение возврата в меню", sent)
+			}
+		})
+	}
+}
+
+func TestHandleUpdateUnsupportedMessage(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	tests := []struct {
+		name    string
+		prepare func(*tgbotapi.Message)
+	}{
+		{"фото", func(m *tgbotapi.Message) { m.Photo = []tgbotapi.PhotoSize{{FileID: "photo", Width: 90, Height: 90}} }},
+		{"стикер", func(m *tgbotapi.Message) { m.Sticker = &tgbotapi.Sticker{FileID: "sticker", Emoji: "👍"} }},
+		{"геопозиция", func(m *tgbotapi.Message) { m.Location = &tgbotapi.Location{Latitude: 55.75, Longitude: 37.62} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			store := newMemorySessionStore()
+			h := NewHandler(botAPI, db, nil)
+			h.SetSessionStore(store)
+
+			// Пользователь ведет диалог
+			chat := database.UserSession{ID: user.ID, UserID: user.ID, State: StateChat, ContextData: []byte(`{"topic":"travel"}`), ConversationID: "7"}
+			store.UpdateUserSession(ctx, chat)
+
+			update := textUpdate(1, user.TelegramID, "")
+			tt.prepare(update.Message)
+			h.HandleUpdate(ctx, update)
+
+			sent := stub.sent()
+			if len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "message.unsupported") {
+				t.Errorf("отправлено %q, ожидалась подсказка о поддерживаемых сообщениях", sent)
+			}
+
+			saved := store.session(user.ID)
+			if saved.State != StateChat || saved.ConversationID != chat.ConversationID || string(saved.ContextData) != string(chat.ContextData) {
+				t.Errorf("сессия изменилась: %+v", saved)
+			}
+		})
+	}
+}
+
+func TestErrorMessageKey(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"ИИ временно недоступен", services.ErrCircuitOpen, "error.ai_unavailable"},
+		{"лимит запросов OpenAI", fmt.Errorf("ошибка генерации: %w", services.ErrOpenAIRateLimited), "error.ai_rate_limited"},
+		{"ключ OpenAI отклонен", fmt.Errorf("ошибка генерации: %w", services.ErrOpenAIUnauthorized), "error.ai_unauthorized"},
+		{"OpenAI не ответил вовремя", fmt.Errorf("ошибка отправки запроса: %w", services.ErrOpenAITimeout), "error.ai_timeout"},
+		{"база данных недоступна", fmt.Errorf("ошибка получения пользователя: %w", database.ErrUnavailable), "error.db_unavailable"},
+		{"голосовое сообщение не скачалось", fmt.Errorf("%w: статус 404", errVoiceDownload), "voice.download_failed"},
+		{"неизвестная ошибка", errors.New("что-то пошло не так"), "error.generic"},
+		{"без ошибки", nil, "error.generic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorMessageKey(tt.err); got != tt.want {
+				t.Errorf("errorMessageKey(%v) = %q, ожидалось %q", tt.err, got, tt.want)
+			}
+
+			botAPI, stub := newTestBotAPI(t)
+			h := &Handler{bot: botAPI}
+			h.sendErrorMessage(withLanguage(context.Background(), i18n.Russian), 100, tt.err)
+
+			if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.Russian, tt.want) {
+				t.Errorf("отправлено %q, ожидалось %q", sent, i18n.T(i18n.Russian, tt.want))
+			}
+		})
+	}
+}
+
+func TestSendErrorMessageWhenCircuitOpen(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	err := fmt.Errorf("ошибка отправки запроса: %w", services.ErrCircuitOpen)
+	h.sendErrorMessage(withLanguage(context.Background(), i18n.Russian), 100, err)
+
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.Russian, "error.ai_unavailable") {
+		t.Errorf("отправлено %q, ожидалось сообщение о временной недоступности ИИ", sent)
+	}
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxHints - максимальное количество подсказок к одному упражнению
+	maxHints = 2
+	// hintPenalty - сколько баллов снимается с оценки за каждую подсказку
+	hintPenalty = 10
+)
+
+// handleHint выдает подсказку к текущему упражнению, не больше maxHints на упражнение
+func (h *Handler) handleHint(ctx context.Context, chatID int64, session *database.UserSession) {
+	exercise, err := h.currentExercise(ctx, session)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения упражнения", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	if exercise == nil {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "exercise.none_active"))
+		h.bot.Send(msg)
+		return
+	}
+
+	var contextData map[string]string
+	if err := json.Unmarshal(session.ContextData, &contextData); err != nil || contextData == nil {
+		contextData = map[string]string{}
+	}
+
+	used, _ := strconv.Atoi(contextData["hints"])
+	if used >= maxHints {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "hint.limit"))
+		h.bot.Send(msg)
+		return
+	}
+
+	// В упражнении на произношение подсказка - само предложение, озвученное вслух
+	if isSpeakingExercise(exercise) {
+		h.handlePronounce(ctx, chatID, exercise.Content)
+		return
+	}
+
+	content := exercise.Content
+	var hint string
+	var ok bool
+	if isReadingExercise(exercise) {
+		// К тексту подсказываем по текущему вопросу
+		questions, index, err := currentReadingQuestion(exercise, contextData)
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения вопросов к тексту", "error", err)
+			h.sendErrorMessage(ctx, chatID, err)
+			return
+		}
+		content = services.ReadingQuestionContent(exercise.Content, questions[index])
+	} else {
+		hint, ok = exerciseHint(languageFrom(ctx), exercise, used+1)
+	}
+
+	if !ok {
+		// Ответ заранее неизвестен - просим подсказку у OpenAI
+		h.withProgress(ctx, chatID, "", func() {
+			hint, err = h.exerciseService.GenerateHint(ctx, content, used+1)
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Ошибка получения подсказки", "error", err)
+			h.sendErrorMessage(ctx, chatID, err)
+			return
+		}
+	}
+
+	contextData["hints"] = strconv.Itoa(used + 1)
+	contextJSON, _ := json.Marshal(contextData)
+	session.ContextData = contextJSON
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "hint.message", used+1, maxHints, hint, hintPenalty))
+	h.bot.Send(msg)
+}
+
+// hintsUsed возвращает количество подсказок, использованных в текущем упражнении
+func hintsUsed(session *database.UserSession) int {
+	var contextData map[string]string
+	json.Unmarshal(session.ContextData, &contextData)
+	used, _ := strconv.Atoi(contextData["hints"])
+	return used
+}
+
+// exerciseHint строит подсказку по известному ответу на языке lang.
+// Для словарных упражнений сначала открывается первая буква, затем длина ответа;
+// для остальных - наоборот. Возвращает false, если ответ неизвестен
+func exerciseHint(lang string, exercise *database.Exercise, hintNumber int) (string, bool) {
+	answer := strings.TrimSpace(exercise.Answer)
+	if answer == "" {
+		return "", false
+	}
+
+	// Для ответов с вариантами подсказываем по первому из них
+	if variant, _, found := strings.Cut(answer, "/"); found {
+		answer = strings.TrimSpace(variant)
+	}
+
+	firstLetter, _ := utf8.DecodeRuneInString(answer)
+	letterHint := i18n.T(lang, "hint.first_letter", firstLetter)
+
+	words := len(strings.Fields(answer))
+	lengthHint := i18n.T(lang, "hint.words", words)
+	if words == 1 {
+		lengthHint = i18n.T(lang, "hint.letters", utf8.RuneCountInString(answer))
+	}
+
+	vocabulary := services.ExerciseType(exercise.Type) == services.ExerciseTypeVocabulary
+	if (hintNumber == 1) == vocabulary {
+		return letterHint, true
+	}
+	return lengthHint, true
+}
+
+// applyHintPenalty уменьшает оценку за использованные подсказки
+func applyHintPenalty(score, hints int) int {
+	score -= hints * hintPenalty
+	if score < 0 {
+		return 0
+	}
+	return score
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"strconv"
+	"testing"
+)
+
+func TestExerciseHint(t *testing.T) {
+	tests := []struct {
+		name       string
+		exercise   database.Exercise
+		hintNumber int
+		want       string
+	}{
+		{"словарь: первая буква", database.Exercise{Type: "vocabulary", Answer: "apple"}, 1, i18n.T("en", "hint.first_letter", 'a')},
+		{"словарь: длина слова", database.Exercise{Type: "vocabulary", Answer: "apple"}, 2, i18n.T("en", "hint.letters", 5)},
+		{"грамматика: число слов", database.Exercise{Type: "grammar", Answer: "has been living"}, 1, i18n.T("en", "hint.words", 3)},
+		{"грамматика: первая буква", database.Exercise{Type: "grammar", Answer: "has been living"}, 2, i18n.T("en", "hint.first_letter", 'h')},
+		{"первый из вариантов", database.Exercise{Type: "translation", Answer: "I like tea./I love tea."}, 1, i18n.T("en", "hint.words", 3)},
+		{"кириллица по символам", database.Exercise{Type: "vocabulary", Answer: "яблоко"}, 2, i18n.T("en", "hint.letters", 6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := exerciseHint("en", &tt.exercise, tt.hintNumber)
+			if !ok || got != tt.want {
+				t.Errorf("exerciseHint = %q, %v; ожидалось %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestExerciseHintWithoutAnswer(t *testing.T) {
+	if hint, ok := exerciseHint("en", &database.Exercise{Type: "grammar"}, 1); ok {
+		t.Errorf("подсказка %q для упражнения без ответа", hint)
+	}
+}
+
+func TestApplyHintPenalty(t *testing.T) {
+	tests := []struct {
+		score, hints, want int
+	}{
+		{100, 0, 100},
+		{100, 2, 80},
+		{15, 2, 0},
+	}
+
+	for _, tt := range tests {
+		if got := applyHintPenalty(tt.score, tt.hints); got != tt.want {
+			t.Errorf("applyHintPenalty(%d, %d) = %d, ожидалось %d", tt.score, tt.hints, got, tt.want)
+		}
+	}
+}
+
+func TestHintsUsed(t *testing.T) {
+	session := &database.UserSession{ContextData: []byte(`{"exerciseID": "5", "hints": "2"}`)}
+	if got := hintsUsed(session); got != 2 {
+		t.Errorf("hintsUsed = %d, ожидалось 2", got)
+	}
+
+	if got := hintsUsed(&database.UserSession{ContextData: []byte("{}")}); got != 0 {
+		t.Errorf("hintsUsed без подсказок = %d, ожидалось 0", got)
+	}
+}
+
+func TestHandleHintLimit(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	exercise, err := db.SaveExercise(ctx, database.Exercise{Type: "vocabulary", Level: "A1", Content: "Translate: яблоко", Answer: "apple"})
+	if err != nil {
+		t.Fatalf("SaveExercise: %v", err)
+	}
+
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := NewHandler(botAPI, db, nil)
+	h.SetSessionStore(store)
+
+	contextData, _ := json.Marshal(map[string]string{"exerciseType": "vocabulary", "exerciseID": strconv.FormatInt(exercise.ID, 10)})
+	session := &database.UserSession{ID: 1, UserID: user.ID, State: StateExerciseReply, ContextData: contextData}
+
+	for range maxHints + 1 {
+		h.handleHint(ctx, 100, session)
+	}
+
+	want := []string{
+		i18n.T("en", "hint.message", 1, maxHints, i18n.T("en", "hint.first_letter", 'a'), hintPenalty),
+		i18n.T("en", "hint.message", 2, maxHints, i18n.T("en", "hint.letters", 5), hintPenalty),
+		i18n.T("en", "hint.limit"),
+	}
+	sent := stub.sent()
+	if len(sent) != len(want) {
+		t.Fatalf("отправлено %q, ожидалось %q", sent, want)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Errorf("сообщение %d = %q, ожидалось %q", i, sent[i], want[i])
+		}
+	}
+
+	if got := hintsUsed(session); got != maxHints {
+		t.Errorf("использовано %d подсказок, ожидалось %d", got, maxHints)
+	}
+}
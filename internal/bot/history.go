@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// historyPageSize - количество попыток на одной странице /history
+const historyPageSize = 5
+
+// historyPreviewLength - максимальная длина текста упражнения в истории, в символах
+const historyPreviewLength = 120
+
+// handleHistory показывает первую страницу истории упражнений пользователя
+func (h *Handler) handleHistory(ctx context.Context, chatID int64, user *database.User) {
+	text, keyboard, err := h.historyPage(ctx, user.ID, 0)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения истории упражнений", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	h.bot.Send(msg)
+}
+
+// handleHistoryCallback переключает страницу истории упражнений: history:<смещение>
+func (h *Handler) handleHistoryCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, payload string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	offset, err := strconv.Atoi(payload)
+	if err != nil || offset < 0 {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	text, keyboard, err := h.historyPage(ctx, user.ID, offset)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения истории упражнений", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	h.answerCallback(callback.ID, "")
+
+	var edit tgbotapi.EditMessageTextConfig
+	if keyboard != nil {
+		edit = tgbotapi.NewEditMessageTextAndMarkup(chatID, callback.Message.MessageID, text, *keyboard)
+	} else {
+		edit = tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text)
+	}
+	h.bot.Send(edit)
+}
+
+// historyPage формирует текст страницы истории, начиная с попытки offset, и кнопки навигации.
+// Клавиатура равна nil, если переключать страницы некуда
+func (h *Handler) historyPage(ctx context.Context, userID int64, offset int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	// Запрашиваем на одну попытку больше, чтобы узнать, есть ли следующая страница
+	attempts, err := h.db.GetUserExercises(ctx, userID, historyPageSize+1, offset)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(attempts) == 0 {
+		if offset > 0 {
+			return tr(ctx, "history.page_empty"), historyKeyboard(ctx, 0, false, true), nil
+		}
+		return tr(ctx, "history.empty"), nil, nil
+	}
+
+	hasNext := len(attempts) > historyPageSize
+	if hasNext {
+		attempts = attempts[:historyPageSize]
+	}
+
+	var result strings.Builder
+	result.WriteString(tr(ctx, "history.title", offset/historyPageSize+1) + "\n")
+	for _, attempt := range attempts {
+		result.WriteString("\n" + formatHistoryAttempt(ctx, attempt) + "\n")
+	}
+
+	return result.String(), historyKeyboard(ctx, offset, offset > 0, hasNext), nil
+}
+
+// formatHistoryAttempt форматирует одну попытку: отметку результата, тип, дату, упражнение и ответы
+func formatHistoryAttempt(ctx context.Context, attempt database.ExerciseAttempt) string {
+	mark := "❌"
+	switch {
+	case attempt.Skipped:
+		mark = "⏭️"
+	case attempt.IsCorrect:
+		mark = "✅"
+	}
+
+	typeLabel := attempt.Type
+	if choice, ok := findExerciseTypeChoice(attempt.Type); ok {
+		typeLabel = tr(ctx, choice.LabelKey)
+	}
+
+	text := fmt.Sprintf("%s %s, %s\n%s", mark, typeLabel, attempt.CreatedAt.Format("02.01.2006"),
+		previewText(attempt.Content, historyPreviewLength))
+
+	switch {
+	case attempt.Skipped:
+		text += "\n" + tr(ctx, "history.skipped")
+	case attempt.IsCorrect:
+		text += "\n" + tr(ctx, "history.your_answer", previewText(attempt.UserAnswer, historyPreviewLength))
+	default:
+		text += "\n" + tr(ctx, "history.your_answer", previewText(attempt.UserAnswer, historyPreviewLength))
+		if attempt.Answer != "" {
+			text += "\n" + tr(ctx, "history.correct_answer", previewText(attempt.Answer, historyPreviewLength))
+		}
+	}
+
+	return text
+}
+
+// historyKeyboard создает кнопки перехода к предыдущей и следующей странице истории.
+// Возвращает nil, если обе кнопки не нужны
+func historyKeyboard(ctx context.Context, offset int, hasPrev, hasNext bool) *tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	if hasPrev {
+		prevOffset := max(0, offset-historyPageSize)
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(tr(ctx, "history.prev"),
+			callbackHistory+":"+strconv.Itoa(prevOffset)))
+	}
+	if hasNext {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(tr(ctx, "history.next"),
+			callbackHistory+":"+strconv.Itoa(offset+historyPageSize)))
+	}
+
+	if len(row) == 0 {
+		return nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+	return &keyboard
+}
+
+// previewText сокращает текст до maxLen символов, заменяя конец многоточием
+func previewText(text string, maxLen int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:maxLen-1])) + "…"
+}
@@ -0,0 +1,211 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestPreviewText(t *testing.T) {
+	tests := []struct {
+		text   string
+		maxLen int
+		want   string
+	}{
+		{"short", 10, "short"},
+		{"  many   spaces\nand lines ", 30, "many spaces and lines"},
+		{"exactly ten", 11, "exactly ten"},
+		{"this is too long", 8, "this is…"},
+		{"привет, мир", 7, "привет…"},
+	}
+
+	for _, tt := range tests {
+		if got := previewText(tt.text, tt.maxLen); got != tt.want {
+			t.Errorf("previewText(%q, %d) = %q, ожидалось %q", tt.text, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestFormatHistoryAttempt(t *testing.T) {
+	ctx := withLanguage(context.Background(), i18n.Russian)
+	date := time.Date(2024, 5, 19, 12, 0, 0, 0, time.UTC)
+	grammar := i18n.T(i18n.Russian, "exercise.type.grammar")
+
+	tests := []struct {
+		name    string
+		attempt database.ExerciseAttempt
+		want    string
+	}{
+		{
+			"правильный ответ",
+			database.ExerciseAttempt{Type: "grammar", Content: "She ___ home.", Answer: "goes", UserAnswer: "goes", IsCorrect: true, CreatedAt: date},
+			"✅ " + grammar + ", 19.05.2024\nShe ___ home.\n" + i18n.T(i18n.Russian, "history.your_answer", "goes"),
+		},
+		{
+			"неправильный ответ",
+			database.ExerciseAttempt{Type: "grammar", Content: "She ___ home.", Answer: "goes", UserAnswer: "go", CreatedAt: date},
+			"❌ " + grammar + ", 19.05.2024\nShe ___ home.\n" + i18n.T(i18n.Russian, "history.your_answer", "go") +
+				"\n" + i18n.T(i18n.Russian, "history.correct_answer", "goes"),
+		},
+		{
+			"ответ оценивался без эталона",
+			database.ExerciseAttempt{Type: "speaking", Content: "Describe your day.", UserAnswer: "I worked.", CreatedAt: date},
+			"❌ " + i18n.T(i18n.Russian, "exercise.type.speaking") + ", 19.05.2024\nDescribe your day.\n" + i18n.T(i18n.Russian, "history.your_answer", "I worked."),
+		},
+		{
+			"пропуск",
+			database.ExerciseAttempt{Type: "dictation", Content: "Listen.", Answer: "hello", Skipped: true, CreatedAt: date},
+			"⏭️ dictation, 19.05.2024\nListen.\n" + i18n.T(i18n.Russian, "history.skipped"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatHistoryAttempt(ctx, tt.attempt); got != tt.want {
+				t.Errorf("formatHistoryAttempt =\n%s\nожидалось:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryKeyboard(t *testing.T) {
+	ctx := context.Background()
+	prev := i18n.T(i18n.DefaultLanguage, "history.prev")
+	next := i18n.T(i18n.DefaultLanguage, "history.next")
+
+	tests := []struct {
+		name             string
+		offset           int
+		hasPrev, hasNext bool
+		want             []string // Текст и данные кнопок через "|"
+	}{
+		{"одна страница", 0, false, false, nil},
+		{"первая страница", 0, false, true, []string{next + "|history:5"}},
+		{"середина", 5, true, true, []string{prev + "|history:0", next + "|history:10"}},
+		{"последняя страница", 10, true, false, []string{prev + "|history:5"}},
+		{"смещение не кратно странице", 3, true, false, []string{prev + "|history:0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyboard := historyKeyboard(ctx, tt.offset, tt.hasPrev, tt.hasNext)
+			if tt.want == nil {
+				if keyboard != nil {
+					t.Errorf("клавиатура %+v, ожидалось отсутствие кнопок", keyboard.InlineKeyboard)
+				}
+				return
+			}
+			if keyboard == nil || len(keyboard.InlineKeyboard) != 1 {
+				t.Fatalf("клавиатура %+v, ожидался один ряд", keyboard)
+			}
+
+			var got []string
+			for _, button := range keyboard.InlineKeyboard[0] {
+				got = append(got, button.Text+"|"+*button.CallbackData)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("кнопки %q, ожидались %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleHistoryCallbackInvalidOffset(t *testing.T) {
+	for _, payload := range []string{"abc", "-5", ""} {
+		botAPI, stub := newTestBotAPI(t)
+		// Без базы данных: некорректное смещение не должно до нее доходить
+		h := &Handler{bot: botAPI}
+
+		callback := &tgbotapi.CallbackQuery{
+			ID:      "cb",
+			From:    &tgbotapi.User{ID: 1},
+			Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+		}
+		h.handleHistoryCallback(context.Background(), callback, payload)
+
+		if answers := stub.calls("answerCallbackQuery"); len(answers) != 1 {
+			t.Errorf("%q: ответов на нажатие %d, ожидался один", payload, len(answers))
+		}
+		if edits := stub.calls("editMessageText"); len(edits) != 0 {
+			t.Errorf("%q: сообщение изменено: %v", payload, edits)
+		}
+	}
+}
+
+func TestHandleHistoryNavigation(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+
+	h.handleHistory(ctx, 100, user)
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "history.empty") {
+		t.Fatalf("отправлено %q, ожидалось сообщение о пустой истории", sent)
+	}
+
+	// Семь попыток: страница из пяти последних и страница из двух первых
+	for i := 1; i <= 7; i++ {
+		exercise, err := db.SaveExercise(ctx, database.Exercise{Type: "grammar", Level: "A1", Content: fmt.Sprintf("Exercise %d", i), Answer: "goes"})
+		if err != nil {
+			t.Fatalf("SaveExercise: %v", err)
+		}
+		if _, err := db.SaveUserExercise(ctx, database.UserExercise{UserID: user.ID, ExerciseID: exercise.ID, UserAnswer: "goes", IsCorrect: true}); err != nil {
+			t.Fatalf("SaveUserExercise: %v", err)
+		}
+	}
+
+	// checkPage проверяет номер страницы, упражнения на ней и кнопки навигации
+	checkPage := func(text, markup string, page int, exercises []int, wantButtons []string) {
+		t.Helper()
+		if !strings.HasPrefix(text, i18n.T(i18n.DefaultLanguage, "history.title", page)) {
+			t.Errorf("страница %d начинается не с заголовка:\n%s", page, text)
+		}
+		for i := 1; i <= 7; i++ {
+			if shown := strings.Contains(text, fmt.Sprintf("Exercise %d\n", i)); shown != slices.Contains(exercises, i) {
+				t.Errorf("страница %d: упражнение %d показано %v:\n%s", page, i, shown, text)
+			}
+		}
+
+		var keyboard tgbotapi.InlineKeyboardMarkup
+		json.Unmarshal([]byte(markup), &keyboard)
+		var buttons []string
+		for _, row := range keyboard.InlineKeyboard {
+			for _, button := range row {
+				buttons = append(buttons, *button.CallbackData)
+			}
+		}
+		if strings.Join(buttons, ",") != strings.Join(wantButtons, ",") {
+			t.Errorf("страница %d: кнопки %q, ожидались %q", page, buttons, wantButtons)
+		}
+	}
+
+	h.handleHistory(ctx, 100, user)
+	calls := stub.calls("sendMessage")
+	first := calls[len(calls)-1]
+	checkPage(first.Get("text"), first.Get("reply_markup"), 1, []int{7, 6, 5, 4, 3}, []string{"history:5"})
+
+	callback := &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: user.TelegramID, FirstName: user.FirstName},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+	}
+	h.handleHistoryCallback(ctx, callback, "5")
+	h.handleHistoryCallback(ctx, callback, "0")
+
+	edits := stub.calls("editMessageText")
+	if len(edits) != 2 {
+		t.Fatalf("сообщение изменено %d раз, ожидалось 2", len(edits))
+	}
+	checkPage(edits[0].Get("text"), edits[0].Get("reply_markup"), 2, []int{2, 1}, []string{"history:0"})
+	checkPage(edits[1].Get("text"), edits[1].Get("reply_markup"), 1, []int{7, 6, 5, 4, 3}, []string{"history:5"})
+}
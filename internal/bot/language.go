@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// languageKey - ключ контекста, под которым хранится язык интерфейса пользователя
+type languageKey struct{}
+
+// withLanguage сохраняет язык интерфейса в контексте обработки обновления
+func withLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// languageFrom возвращает язык интерфейса из контекста или язык по умолчанию
+func languageFrom(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok {
+		return lang
+	}
+	return i18n.DefaultLanguage
+}
+
+// tr возвращает сообщение каталога на языке пользователя из контекста
+func tr(ctx context.Context, key string, args ...any) string {
+	return i18n.T(languageFrom(ctx), key, args...)
+}
+
+// senderLanguage определяет язык интерфейса по языку клиента Telegram отправителя.
+// Используется, пока настройки пользователя еще не загружены
+func senderLanguage(update tgbotapi.Update) string {
+	if from := update.SentFrom(); from != nil {
+		return i18n.Resolve("", from.LanguageCode)
+	}
+	return i18n.DefaultLanguage
+}
+
+// userLanguage определяет язык интерфейса пользователя: из настроек,
+// а если их не удалось получить - по языку клиента Telegram
+func (h *Handler) userLanguage(ctx context.Context, user *database.User) string {
+	settings, err := h.db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		slog.WarnContext(ctx, "Не удалось получить язык интерфейса из настроек", "error", err, "user_id", user.ID)
+		return i18n.Resolve("", user.LanguageCode)
+	}
+	return i18n.Resolve(settings.UILanguage, user.LanguageCode)
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/i18n"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// textUpdateFrom создает сообщение от пользователя с языком клиента languageCode
+func textUpdateFrom(languageCode string) tgbotapi.Update {
+	update := textUpdate(1, 10, "hello")
+	update.Message.From.LanguageCode = languageCode
+	return update
+}
+
+func TestSenderLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   string
+	}{
+		{"русский клиент", textUpdateFrom("ru"), i18n.Russian},
+		{"региональный код", textUpdateFrom("ru-RU"), i18n.Russian},
+		{"английский клиент", textUpdateFrom("en"), i18n.English},
+		{"неподдерживаемый язык", textUpdateFrom("de"), i18n.DefaultLanguage},
+		{"без отправителя", tgbotapi.Update{UpdateID: 1}, i18n.DefaultLanguage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := senderLanguage(tt.update); got != tt.want {
+				t.Errorf("senderLanguage = %q, ожидался %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrUsesLanguageFromContext(t *testing.T) {
+	ctx := context.Background()
+	if got := tr(ctx, "exercise.choose_type"); got != i18n.T(i18n.DefaultLanguage, "exercise.choose_type") {
+		t.Errorf("без языка в контексте tr = %q, ожидался язык по умолчанию", got)
+	}
+
+	ctx = withLanguage(ctx, i18n.Russian)
+	if got := tr(ctx, "exercise.choose_type"); got != i18n.T(i18n.Russian, "exercise.choose_type") {
+		t.Errorf("tr = %q, ожидался русский текст", got)
+	}
+}
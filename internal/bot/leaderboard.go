@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// leaderboardSize - количество строк в таблице лидеров
+const leaderboardSize = 10
+
+// leaderboardMedals - медали для первых трех мест
+var leaderboardMedals = []string{"🥇", "🥈", "🥉"}
+
+// handleLeaderboard показывает таблицу лидеров и место пользователя в ней.
+// Аргумент команды выбирает метрику: streak (по умолчанию), exercises или xp
+func (h *Handler) handleLeaderboard(ctx context.Context, chatID int64, user *database.User, args string) {
+	metric := database.LeaderboardByStreak
+	switch strings.TrimSpace(strings.ToLower(args)) {
+	case database.LeaderboardByExercises:
+		metric = database.LeaderboardByExercises
+	case database.LeaderboardByXP:
+		metric = database.LeaderboardByXP
+	}
+
+	entries, err := h.db.GetLeaderboard(ctx, metric, leaderboardSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения таблицы лидеров", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	own, err := h.db.GetLeaderboardEntry(ctx, metric, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения места в таблице лидеров", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	h.bot.Send(tgbotapi.NewMessage(chatID, formatLeaderboard(languageFrom(ctx), metric, entries, own, user.ID)))
+}
+
+// formatLeaderboard форматирует таблицу лидеров на языке lang, выделяя строку пользователя
+func formatLeaderboard(lang, metric string, entries []database.LeaderboardEntry, own *database.LeaderboardEntry, userID int64) string {
+	var title, unit, other string
+	switch metric {
+	case database.LeaderboardByExercises:
+		title, unit, other = i18n.T(lang, "leaderboard.exercises"), i18n.T(lang, "leaderboard.unit_tasks"), "/leaderboard streak, /leaderboard xp"
+	case database.LeaderboardByXP:
+		title, unit, other = i18n.T(lang, "leaderboard.xp"), "XP", "/leaderboard streak, /leaderboard exercises"
+	default:
+		title, unit, other = i18n.T(lang, "leaderboard.streak"), i18n.T(lang, "leaderboard.unit_days"), "/leaderboard exercises, /leaderboard xp"
+	}
+	you := i18n.T(lang, "leaderboard.you")
+
+	var result strings.Builder
+	result.WriteString(title + "\n\n")
+
+	if len(entries) == 0 {
+		result.WriteString(i18n.T(lang, "leaderboard.empty") + "\n")
+	}
+
+	ownListed := false
+	for _, entry := range entries {
+		place := fmt.Sprintf("%d.", entry.Rank)
+		if entry.Rank <= len(leaderboardMedals) {
+			place = leaderboardMedals[entry.Rank-1]
+		}
+
+		line := fmt.Sprintf("%s %s — %d %s", place, entry.Name, entry.Value, unit)
+		if metric == database.LeaderboardByXP {
+			line += " · " + rankTitle(lang, entry.Value)
+		}
+		if entry.UserID == userID {
+			line = "👉 " + line + " " + you
+			ownListed = true
+		}
+		result.WriteString(line + "\n")
+	}
+
+	if !ownListed {
+		if own != nil {
+			line := fmt.Sprintf("%d. %s — %d %s", own.Rank, own.Name, own.Value, unit)
+			if metric == database.LeaderboardByXP {
+				line += " · " + rankTitle(lang, own.Value)
+			}
+			result.WriteString(fmt.Sprintf("…\n👉 %s %s\n", line, you))
+		} else {
+			result.WriteString("\n" + i18n.T(lang, "leaderboard.unranked") + "\n")
+		}
+	}
+
+	result.WriteString("\n" + i18n.T(lang, "leaderboard.footer", other))
+
+	return result.String()
+}
+
+// rankTitle возвращает название звания для опыта xp на языке lang
+func rankTitle(lang string, xp int) string {
+	rank, _ := database.RankForXP(xp)
+	return i18n.T(lang, "rank."+rank.Key)
+}
@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"strings"
+	"testing"
+)
+
+func TestFormatLeaderboard(t *testing.T) {
+	entries := []database.LeaderboardEntry{
+		{Rank: 1, UserID: 10, Name: "Anna", Value: 30},
+		{Rank: 2, UserID: 20, Name: "Boris", Value: 21},
+		{Rank: 3, UserID: 30, Name: "Clara", Value: 14},
+		{Rank: 4, UserID: 40, Name: "Dmitry", Value: 7},
+	}
+	you := i18n.T("en", "leaderboard.you")
+
+	t.Run("медали первым трем местам", func(t *testing.T) {
+		message := formatLeaderboard("en", database.LeaderboardByStreak, entries, &entries[3], 40)
+
+		for _, want := range []string{"🥇 Anna — 30 days", "🥈 Boris — 21 days", "🥉 Clara — 14 days", "👉 4. Dmitry — 7 days " + you} {
+			if !strings.Contains(message, want) {
+				t.Errorf("в таблице нет строки %q:\n%s", want, message)
+			}
+		}
+		if strings.Contains(message, "…") {
+			t.Error("пользователь из таблицы продублирован под ней")
+		}
+	})
+
+	t.Run("пользователь за пределами таблицы", func(t *testing.T) {
+		own := &database.LeaderboardEntry{Rank: 57, UserID: 99, Name: "Eva", Value: 2}
+		message := formatLeaderboard("en", database.LeaderboardByExercises, entries, own, 99)
+
+		if !strings.Contains(message, "…\n👉 57. Eva — 2 exercises "+you) {
+			t.Errorf("место пользователя не показано под таблицей:\n%s", message)
+		}
+	})
+
+	t.Run("пользователь без места", func(t *testing.T) {
+		message := formatLeaderboard("en", database.LeaderboardByStreak, entries, nil, 99)
+
+		if !strings.Contains(message, i18n.T("en", "leaderboard.unranked")) {
+			t.Errorf("нет сообщения об отсутствии в рейтинге:\n%s", message)
+		}
+	})
+
+	t.Run("пустая таблица", func(t *testing.T) {
+		message := formatLeaderboard("en", database.LeaderboardByStreak, nil, nil, 99)
+
+		if !strings.Contains(message, i18n.T("en", "leaderboard.empty")) {
+			t.Errorf("нет сообщения о пустой таблице:\n%s", message)
+		}
+	})
+
+	t.Run("звание в рейтинге по опыту", func(t *testing.T) {
+		message := formatLeaderboard("en", database.LeaderboardByXP, entries[:1], nil, 99)
+
+		if !strings.Contains(message, "🥇 Anna — 30 XP · "+rankTitle("en", 30)) {
+			t.Errorf("нет звания в строке рейтинга по опыту:\n%s", message)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleLevel показывает текущий уровень пользователя с клавиатурой для его изменения
+func (h *Handler) handleLevel(ctx context.Context, chatID int64, user *database.User) {
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "level.current", user.EnglishLevel))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = levelKeyboard(user.EnglishLevel)
+	h.bot.Send(msg)
+}
+
+// handleLevelCallback сохраняет уровень, выбранный на клавиатуре
+func (h *Handler) handleLevelCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, level string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	if !database.IsValidEnglishLevel(level) {
+		h.answerCallback(callback.ID, tr(ctx, "level.unknown"))
+		return
+	}
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	if err := h.db.UpdateUserEnglishLevel(ctx, user.ID, level); err != nil {
+		slog.ErrorContext(ctx, "Ошибка обновления уровня", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	h.answerCallback(callback.ID, tr(ctx, "callback.saved"))
+
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		tr(ctx, "level.changed", level))
+	edit.ParseMode = "Markdown"
+	h.bot.Send(edit)
+}
+
+// levelKeyboard создает клавиатуру со всеми уровнями, по три в ряд
+func levelKeyboard(current string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+
+	for _, level := range database.EnglishLevels {
+		label := level
+		if level == current {
+			label = "✓ " + level
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, callbackLevel+":"+level))
+
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"english-bot/internal/database"
+	"testing"
+)
+
+func TestLevelKeyboard(t *testing.T) {
+	keyboard := levelKeyboard("B1")
+
+	if len(keyboard.InlineKeyboard) != 2 {
+		t.Fatalf("клавиатура из %d рядов, ожидалось 2 ряда по три уровня", len(keyboard.InlineKeyboard))
+	}
+
+	var i int
+	for _, row := range keyboard.InlineKeyboard {
+		if len(row) != 3 {
+			t.Errorf("ряд из %d кнопок, ожидалось 3", len(row))
+		}
+		for _, button := range row {
+			level := database.EnglishLevels[i]
+			wantText := level
+			if level == "B1" {
+				wantText = "✓ B1"
+			}
+			if button.Text != wantText {
+				t.Errorf("кнопка %d: текст %q, ожидался %q", i, button.Text, wantText)
+			}
+			if *button.CallbackData != "level:"+level {
+				t.Errorf("кнопка %d: данные %q, ожидались %q", i, *button.CallbackData, "level:"+level)
+			}
+			i++
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// levelUpCooldown - минимальное время между сменами уровня,
+// чтобы накопленная статистика не поднимала уровень несколько раз подряд
+const levelUpCooldown = 7 * 24 * time.Hour
+
+// checkLevelUp повышает уровень пользователя, если он готов к следующему,
+// выдает достижение и поздравляет пользователя
+func (h *Handler) checkLevelUp(ctx context.Context, chatID int64, user *database.User) {
+	if h.progressService == nil {
+		return
+	}
+
+	isReady, nextLevel, err := h.progressService.IsReadyForNextLevel(user.ID, user.EnglishLevel)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка проверки готовности к следующему уровню", "error", err, "user_id", user.ID)
+		return
+	}
+	if !isReady || nextLevel == user.EnglishLevel {
+		return
+	}
+
+	promoted, err := h.db.PromoteUserLevel(ctx, user.ID, user.EnglishLevel, nextLevel, time.Now().Add(-levelUpCooldown))
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка повышения уровня", "error", err, "user_id", user.ID)
+		return
+	}
+	if !promoted {
+		return
+	}
+
+	slog.InfoContext(ctx, "Уровень пользователя повышен", "user_id", user.ID, "from", user.EnglishLevel, "to", nextLevel)
+	user.EnglishLevel = nextLevel
+
+	if err := h.db.AwardAchievement(ctx, user.ID, database.LevelUpAchievementType(nextLevel)); err != nil {
+		slog.ErrorContext(ctx, "Ошибка добавления достижения", "error", err, "user_id", user.ID)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "level.up", nextLevel))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
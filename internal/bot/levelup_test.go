@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestDatabase подключается к базе из TEST_DATABASE_URL и применяет миграции.
+// Без переменной окружения тест пропускается
+func newTestDatabase(t *testing.T) *database.PostgresDB {
+	t.Helper()
+
+	connString := os.Getenv("TEST_DATABASE_URL")
+	if connString == "" {
+		t.Skip("TEST_DATABASE_URL не задан, тест с базой данных пропущен")
+	}
+
+	db, err := database.NewPostgresDB(connString, 1, time.Second)
+	if err != nil {
+		t.Fatalf("ошибка подключения к тестовой базе: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	if err := db.Migrate(context.Background()); err != nil {
+		t.Fatalf("ошибка применения миграций: %v", err)
+	}
+
+	return db
+}
+
+// newTestDatabaseUser создает пользователя с записью прогресса и удаляет его после теста
+func newTestDatabaseUser(t *testing.T, db *database.PostgresDB) *database.User {
+	t.Helper()
+	ctx := context.Background()
+
+	user, err := db.CreateUser(ctx, database.User{TelegramID: -time.Now().UnixNano(), FirstName: "Test"})
+	if err != nil {
+		t.Fatalf("ошибка создания тестового пользователя: %v", err)
+	}
+	t.Cleanup(func() { db.DeleteUser(context.Background(), user.ID) })
+
+	if _, err := db.CreateUserProgress(ctx, user.ID); err != nil {
+		t.Fatalf("ошибка создания прогресса тестового пользователя: %v", err)
+	}
+
+	return user
+}
+
+func TestCheckLevelUpPromotesOnce(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	// 50 правильных ответов на A1 дают прогресс 100%
+	for range 50 {
+		exercise, err := db.SaveExercise(ctx, database.Exercise{Type: "grammar", Level: "A1", Content: "I ___ happy.", Answer: "am"})
+		if err != nil {
+			t.Fatalf("SaveExercise: %v", err)
+		}
+		if _, err := db.SaveUserExercise(ctx, database.UserExercise{UserID: user.ID, ExerciseID: exercise.ID, UserAnswer: "am", IsCorrect: true}); err != nil {
+			t.Fatalf("SaveUserExercise: %v", err)
+		}
+	}
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+	h.SetProgressService(services.NewProgressService(db))
+
+	// Второе обновление с устаревшей копией пользователя видит тот же уровень A1
+	stale := *user
+	h.checkLevelUp(ctx, 100, user)
+	h.checkLevelUp(ctx, 100, &stale)
+
+	if user.EnglishLevel != "A2" || stale.EnglishLevel != "A1" {
+		t.Errorf("уровни после проверок %s и %s, ожидались A2 и A1", user.EnglishLevel, stale.EnglishLevel)
+	}
+
+	sent := stub.sent()
+	if len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "level.up", "A2") {
+		t.Errorf("отправлены сообщения %q, ожидалось одно поздравление", sent)
+	}
+
+	achievements, err := db.GetUserAchievements(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserAchievements: %v", err)
+	}
+	levelUps := 0
+	for _, achievement := range achievements {
+		if achievement.AchievementType == database.LevelUpAchievementType("A2") {
+			levelUps++
+		}
+	}
+	if levelUps != 1 {
+		t.Errorf("достижение за уровень A2 выдано %d раз, ожидался один", levelUps)
+	}
+}
+
+func TestCheckLevelUpWithoutProgressService(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+	user := &database.User{ID: 1, EnglishLevel: "A1"}
+
+	h.checkLevelUp(context.Background(), 100, user)
+
+	if user.EnglishLevel != "A1" || len(stub.sent()) != 0 {
+		t.Error("без сервиса прогресса уровень не должен меняться")
+	}
+}
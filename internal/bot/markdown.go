@@ -0,0 +1,11 @@
+package bot
+
+import (
+	"english-bot/internal/services"
+)
+
+// escapeMarkdown экранирует служебные символы Markdown в динамическом тексте.
+// Бот и форматирование в services используют одно и то же экранирование
+func escapeMarkdown(text string) string {
+	return services.EscapeMarkdown(text)
+}
@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleMe показывает профиль пользователя: учетную запись, основные настройки и серию дней
+func (h *Handler) handleMe(ctx context.Context, chatID int64, user *database.User) {
+	settings, err := h.db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения настроек", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	progress, err := h.db.GetUserProgress(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения прогресса", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatProfile(languageFrom(ctx), user, settings, progress))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// formatProfile форматирует профиль пользователя на языке lang
+func formatProfile(lang string, user *database.User, settings *database.UserSettings, progress *database.UserProgress) string {
+	username := i18n.T(lang, "me.no_username")
+	if user.Username != "" {
+		username = "@" + user.Username
+	}
+
+	return i18n.T(lang, "me.profile",
+		escapeMarkdown(user.FirstName),
+		escapeMarkdown(username),
+		user.EnglishLevel,
+		user.CreatedAt.Format("2006-01-02"),
+		onOff(lang, settings.DailyReminders),
+		languageName(lang),
+		grammarEngineName(lang, settings.GrammarEngine),
+		escapeMarkdown(timezoneName(lang, user.Timezone)),
+		progress.CurrentStreak,
+		progress.LongestStreak,
+	)
+}
@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"testing"
+	"time"
+)
+
+func TestFormatProfile(t *testing.T) {
+	createdAt := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		user     database.User
+		settings database.UserSettings
+		progress database.UserProgress
+		lang     string
+		want     string
+	}{
+		{
+			"все настройки",
+			database.User{FirstName: "Anna", Username: "anna", EnglishLevel: "B1", Timezone: "Asia/Tokyo", CreatedAt: createdAt},
+			database.UserSettings{DailyReminders: true, GrammarEngine: database.GrammarEngineAI},
+			database.UserProgress{CurrentStreak: 3, LongestStreak: 7},
+			i18n.Russian,
+			i18n.T(i18n.Russian, "me.profile", "Anna", "@anna", "B1", "2024-03-10",
+				i18n.T(i18n.Russian, "settings.on"), "Русский", i18n.T(i18n.Russian, "settings.engine.ai"), "Asia/Tokyo", 3, 7),
+		},
+		{
+			"без имени пользователя и пояса",
+			database.User{FirstName: "Bob", EnglishLevel: "A1", CreatedAt: createdAt},
+			database.UserSettings{GrammarEngine: database.GrammarEngineBoth},
+			database.UserProgress{},
+			i18n.English,
+			i18n.T(i18n.English, "me.profile", "Bob", i18n.T(i18n.English, "me.no_username"), "A1", "2024-03-10",
+				i18n.T(i18n.English, "settings.off"), "English", i18n.T(i18n.English, "settings.engine.both"),
+				i18n.T(i18n.English, "timezone.server"), 0, 0),
+		},
+		{
+			"разметка экранируется",
+			database.User{FirstName: "*star*", Username: "my_name", EnglishLevel: "C1", Timezone: "America/New_York", CreatedAt: createdAt},
+			database.UserSettings{GrammarEngine: database.GrammarEngineLanguageTool},
+			database.UserProgress{CurrentStreak: 1, LongestStreak: 1},
+			i18n.English,
+			i18n.T(i18n.English, "me.profile", escapeMarkdown("*star*"), escapeMarkdown("@my_name"), "C1", "2024-03-10",
+				i18n.T(i18n.English, "settings.off"), "English", i18n.T(i18n.English, "settings.engine.languagetool"),
+				escapeMarkdown("America/New_York"), 1, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatProfile(tt.lang, &tt.user, &tt.settings, &tt.progress); got != tt.want {
+				t.Errorf("formatProfile =\n%s\nожидалось\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleMe(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := withLanguage(context.Background(), i18n.Russian)
+	user := newTestDatabaseUser(t, db)
+
+	settings, err := db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserSettings: %v", err)
+	}
+	settings.DailyReminders = true
+	settings.GrammarEngine = database.GrammarEngineLanguageTool
+	if err := db.UpdateUserSettings(ctx, *settings); err != nil {
+		t.Fatalf("UpdateUserSettings: %v", err)
+	}
+	if err := db.SetUserTimezone(ctx, user.ID, "Europe/Moscow"); err != nil {
+		t.Fatalf("SetUserTimezone: %v", err)
+	}
+	user.Timezone = "Europe/Moscow"
+	if err := db.UpdateUserStreak(ctx, user.ID); err != nil {
+		t.Fatalf("UpdateUserStreak: %v", err)
+	}
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+	h.handleMe(ctx, 100, user)
+
+	want := i18n.T(i18n.Russian, "me.profile", "Test", i18n.T(i18n.Russian, "me.no_username"), user.EnglishLevel,
+		user.CreatedAt.Format("2006-01-02"), i18n.T(i18n.Russian, "settings.on"), "Русский",
+		i18n.T(i18n.Russian, "settings.engine.languagetool"), "Europe/Moscow", 1, 1)
+
+	sent := stub.sent()
+	if len(sent) != 1 || sent[0] != want {
+		t.Errorf("отправлено %q, ожидался профиль\n%s", sent, want)
+	}
+}
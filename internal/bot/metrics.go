@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/metrics"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Значения меток для обновлений, которые не являются командами
+const (
+	labelText     = "text"
+	labelVoice    = "voice"
+	labelCallback = "callback"
+	labelEdited   = "edited"
+	labelUnknown  = "unknown"
+)
+
+// updateLabelsKey - ключ контекста для меток текущего обновления
+type updateLabelsKey struct{}
+
+// updateLabels хранит метки, которые становятся известны только в обработчике
+type updateLabels struct {
+	state string
+}
+
+// setUpdateState сообщает MetricsMiddleware состояние пользователя,
+// в котором обрабатывалось обновление
+func setUpdateState(ctx context.Context, state string) {
+	if labels, ok := ctx.Value(updateLabelsKey{}).(*updateLabels); ok {
+		labels.state = state
+	}
+}
+
+// MetricsMiddleware собирает метрики Prometheus по обработке обновлений
+type MetricsMiddleware struct {
+	next UpdateHandler
+}
+
+// NewMetricsMiddleware создает middleware для сбора метрик
+func NewMetricsMiddleware(next UpdateHandler) *MetricsMiddleware {
+	return &MetricsMiddleware{
+		next: next,
+	}
+}
+
+// HandleUpdate передает обновление дальше и записывает количество и время обработки
+func (m *MetricsMiddleware) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	labels := &updateLabels{state: labelUnknown}
+	ctx = context.WithValue(ctx, updateLabelsKey{}, labels)
+
+	startTime := time.Now()
+	defer func() {
+		command := commandLabel(update)
+		metrics.UpdatesTotal.WithLabelValues(command, labels.state).Inc()
+		metrics.UpdateDuration.WithLabelValues(command, labels.state).Observe(time.Since(startTime).Seconds())
+	}()
+
+	m.next.HandleUpdate(ctx, update)
+}
+
+// commandLabel возвращает значение метки command для обновления
+func commandLabel(update tgbotapi.Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return labelCallback
+	case update.EditedMessage != nil:
+		return labelEdited
+	case update.Message == nil:
+		return labelUnknown
+	case update.Message.IsCommand():
+		// Только команды из списка, чтобы произвольные команды от пользователей
+		// не порождали новые временные ряды
+		if command := update.Message.Command(); isKnownCommand(command) {
+			return command
+		}
+		return labelUnknown
+	case update.Message.Voice != nil:
+		return labelVoice
+	default:
+		return labelText
+	}
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/metrics"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCommandLabel(t *testing.T) {
+	voice := textUpdate(3, 100, "")
+	voice.Message.Voice = &tgbotapi.Voice{FileID: "voice"}
+
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   string
+	}{
+		{"известная команда", textUpdate(1, 100, "/start"), "start"},
+		{"команда с аргументами", textUpdate(1, 100, "/vocab add cat - кошка"), "vocab"},
+		{"неизвестная команда", textUpdate(1, 100, "/whatever"), labelUnknown},
+		{"текст", textUpdate(2, 100, "hello"), labelText},
+		{"голосовое сообщение", voice, labelVoice},
+		{"нажатие кнопки", tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{ID: "1"}}, labelCallback},
+		{"исправленное сообщение", tgbotapi.Update{EditedMessage: textUpdate(3, 100, "hello").Message}, labelEdited},
+		{"пустое обновление", tgbotapi.Update{}, labelUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandLabel(tt.update); got != tt.want {
+				t.Errorf("commandLabel = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// counterValue возвращает текущее значение счетчика Prometheus
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatalf("ошибка чтения счетчика: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+// stateHandler сообщает MetricsMiddleware состояние пользователя, как это делает Handler
+type stateHandler struct {
+	state string
+}
+
+func (h stateHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	setUpdateState(ctx, h.state)
+}
+
+func TestMetricsMiddlewareCountsUpdates(t *testing.T) {
+	counter := metrics.UpdatesTotal.WithLabelValues("help", StateGrammarCheck)
+	before := counterValue(t, counter)
+
+	middleware := NewMetricsMiddleware(stateHandler{state: StateGrammarCheck})
+	middleware.HandleUpdate(context.Background(), textUpdate(1, 100, "/help"))
+	middleware.HandleUpdate(context.Background(), textUpdate(2, 100, "/help"))
+
+	if got := counterValue(t, counter) - before; got != 2 {
+		t.Errorf("счетчик обновлений вырос на %v, ожидалось 2", got)
+	}
+}
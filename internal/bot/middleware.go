@@ -2,21 +2,36 @@ package bot
 
 import (
 	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/logging"
 	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// UpdateHandler обрабатывает обновления от Telegram.
+// Его реализуют Handler и все middleware, что позволяет собирать их в цепочку
+type UpdateHandler interface {
+	HandleUpdate(ctx context.Context, update tgbotapi.Update)
+}
+
 // Middleware предоставляет функциональность для обработки обновлений перед их обработкой основными обработчиками
 type Middleware struct {
-	next Handler
+	next    UpdateHandler
+	timeout time.Duration
 }
 
-// NewMiddleware создает новый middleware
-func NewMiddleware(next Handler) *Middleware {
+// NewMiddleware создает новый middleware, который ограничивает обработку обновления временем timeout
+func NewMiddleware(next UpdateHandler, timeout time.Duration) *Middleware {
 	return &Middleware{
-		next: next,
+		next:    next,
+		timeout: timeout,
 	}
 }
 
@@ -25,19 +40,29 @@ func (m *Middleware) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
 	// Начало обработки запроса
 	startTime := time.Now()
 
+	// Идентификатор запроса связывает все записи журнала об обработке этого обновления
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+
 	// Логирование запроса
 	if update.Message != nil {
-		slog.Info("Incoming message",
+		slog.InfoContext(ctx, "Incoming message",
 			"chat_id", update.Message.Chat.ID,
 			"user_id", update.Message.From.ID,
 			"username", update.Message.From.UserName,
 			"command", update.Message.Command(),
 			"text_length", len(update.Message.Text),
 		)
+	} else if update.EditedMessage != nil {
+		slog.InfoContext(ctx, "Incoming edited message",
+			"chat_id", update.EditedMessage.Chat.ID,
+			"user_id", update.EditedMessage.From.ID,
+			"message_id", update.EditedMessage.MessageID,
+			"text_length", len(update.EditedMessage.Text),
+		)
 	}
 
 	// Создаем контекст с таймаутом для обработки сообщения
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
 	defer cancel()
 
 	// Передаем обновление следующему обработчику
@@ -45,51 +70,367 @@ func (m *Middleware) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
 
 	// Логирование времени обработки
 	duration := time.Since(startTime)
-	slog.Debug("Request processed",
+	slog.DebugContext(ctx, "Request processed",
 		"duration_ms", duration.Milliseconds(),
 	)
 }
 
-// RateLimiter ограничивает количество запросов от одного пользователя
-// Это заготовка для будущей реализации
+// rateLimitTTLFactor определяет, во сколько раз дольше окна ограничения хранится
+// запись о последнем запросе пользователя, прежде чем ее удалит очистка
+const rateLimitTTLFactor = 60
+
+// rateLimitKey - пользователь и команда, для которой ведется отдельный учет запросов.
+// Обычные сообщения и команды без собственного окна учитываются с пустой командой
+type rateLimitKey struct {
+	userID  int64
+	command string
+}
+
+// RateLimiter ограничивает количество запросов от одного пользователя.
+// Дорогие команды могут иметь собственное, более длинное окно.
+// Время запросов хранится в rateLimitStore: в памяти процесса или в базе данных
 type RateLimiter struct {
-	next   Handler
-	limits map[int64]time.Time // user_id -> last_request_time
+	next           UpdateHandler
+	bot            *tgbotapi.BotAPI
+	window         time.Duration            // Минимальный интервал между запросами одного пользователя
+	commandWindows map[string]time.Duration // Окна для отдельных команд: имя команды -> интервал
+	store          rateLimitStore           // Время последних запроса и предупреждения
+}
+
+// NewRateLimiter создает новый ограничитель запросов с окном по умолчанию
+// и отдельными окнами для команд из commandWindows. Ограничения хранятся в памяти процесса
+func NewRateLimiter(next UpdateHandler, bot *tgbotapi.BotAPI, window time.Duration, commandWindows map[string]time.Duration) *RateLimiter {
+	return newRateLimiter(next, bot, window, commandWindows, newMemoryRateLimitStore())
 }
 
-// NewRateLimiter создает новый ограничитель запросов
-func NewRateLimiter(next Handler) *RateLimiter {
+// NewPostgresRateLimiter создает ограничитель запросов, который хранит ограничения в таблице rate_limits.
+// Ограничения переживают перезапуск бота и общие для всех его экземпляров
+func NewPostgresRateLimiter(next UpdateHandler, bot *tgbotapi.BotAPI, db *database.PostgresDB, window time.Duration, commandWindows map[string]time.Duration) *RateLimiter {
+	return newRateLimiter(next, bot, window, commandWindows, &postgresRateLimitStore{db: db})
+}
+
+// newRateLimiter создает ограничитель запросов с указанным хранилищем
+func newRateLimiter(next UpdateHandler, bot *tgbotapi.BotAPI, window time.Duration, commandWindows map[string]time.Duration, store rateLimitStore) *RateLimiter {
 	return &RateLimiter{
-		next:   next,
-		limits: make(map[int64]time.Time),
+		next:           next,
+		bot:            bot,
+		window:         window,
+		commandWindows: commandWindows,
+		store:          store,
 	}
 }
 
+// limitFor возвращает ключ учета и окно ограничения для сообщения:
+// собственные для команд из commandWindows и общие для остальных сообщений
+func (r *RateLimiter) limitFor(message *tgbotapi.Message) (rateLimitKey, time.Duration) {
+	key := rateLimitKey{userID: message.From.ID}
+	if command := message.Command(); command != "" {
+		if window, ok := r.commandWindows[command]; ok {
+			key.command = command
+			return key, window
+		}
+	}
+	return key, r.window
+}
+
+// callbackRateLimitCommands - команды, окно которых действует для кнопок с данным префиксом.
+// Кнопки выбора упражнения и серии запускают генерацию через OpenAI так же, как сами команды
+var callbackRateLimitCommands = map[string]string{
+	callbackExercise:     "exercise",
+	callbackGrammarTopic: "exercise",
+	callbackPractice:     "practice",
+	callbackChat:         "chat",
+}
+
+// limitForCallback возвращает ключ учета и окно ограничения для нажатия кнопки.
+// Кнопки дорогих команд учитываются по префиксу отдельно от самой команды, чтобы выбор
+// на клавиатуре сразу после команды не блокировался, но повторные нажатия ограничивались
+// окном команды. Остальные кнопки ограничиваются общим окном
+func (r *RateLimiter) limitForCallback(callback *tgbotapi.CallbackQuery) (rateLimitKey, time.Duration) {
+	key := rateLimitKey{userID: callback.From.ID}
+	prefix, _, _ := strings.Cut(callback.Data, ":")
+	if command, ok := callbackRateLimitCommands[prefix]; ok {
+		if window, ok := r.commandWindows[command]; ok {
+			key.command = prefix + ":"
+			return key, window
+		}
+	}
+	return key, r.window
+}
+
 // HandleUpdate обрабатывает обновления с применением ограничения запросов
 func (r *RateLimiter) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
-	// Проверяем, есть ли сообщение
-	if update.Message == nil {
+	// Исправленные сообщения тоже могут обращаться к OpenAI, поэтому ограничиваются наравне с новыми
+	message := update.Message
+	if message == nil {
+		message = update.EditedMessage
+	}
+	callback := update.CallbackQuery
+
+	var key rateLimitKey
+	var window time.Duration
+	switch {
+	case message != nil:
+		key, window = r.limitFor(message)
+	case callback != nil && callback.From != nil:
+		key, window = r.limitForCallback(callback)
+	default:
 		r.next.HandleUpdate(ctx, update)
 		return
 	}
 
-	userID := update.Message.From.ID
-	now := time.Now()
+	allowed, notify, err := r.store.allow(ctx, key, time.Now(), window)
+	if err != nil {
+		// Недоступность хранилища не должна блокировать бота: пропускаем запрос
+		slog.ErrorContext(ctx, "Ошибка проверки ограничения запросов", "error", err, "user_id", key.userID, "command", key.command)
+		allowed = true
+	}
 
-	// Проверяем последний запрос пользователя
-	lastRequest, ok := r.limits[userID]
-	if ok {
-		// Если последний запрос был менее 1 секунды назад, ограничиваем
-		if now.Sub(lastRequest) < 1*time.Second {
-			slog.Warn("Rate limit exceeded", "user_id", userID)
-			// Здесь можно отправить сообщение пользователю
-			return
+	if !allowed {
+		slog.WarnContext(ctx, "Rate limit exceeded", "user_id", key.userID, "command", key.command)
+		switch {
+		case callback != nil:
+			// На нажатие нужно ответить, иначе кнопка останется в состоянии загрузки
+			r.answerCallbackNotice(ctx, callback.ID, senderLanguage(update))
+		case notify:
+			// Хранилище разрешает предупреждение не чаще одного раза за окно, чтобы не спамить
+			r.sendNotice(ctx, message.Chat.ID, senderLanguage(update))
 		}
+		return
 	}
 
-	// Обновляем время последнего запроса
-	r.limits[userID] = now
-
 	// Передаем обновление следующему обработчику
 	r.next.HandleUpdate(ctx, update)
 }
+
+// sendNotice сообщает пользователю на языке lang, что его запросы временно ограничены
+func (r *RateLimiter) sendNotice(ctx context.Context, chatID int64, lang string) {
+	if _, err := r.bot.Send(tgbotapi.NewMessage(chatID, i18n.T(lang, "notice.rate_limit"))); err != nil {
+		slog.ErrorContext(ctx, "Ошибка отправки уведомления об ограничении", "error", err, "chat_id", chatID)
+	}
+}
+
+// answerCallbackNotice отвечает на нажатие кнопки всплывающим предупреждением на языке lang
+func (r *RateLimiter) answerCallbackNotice(ctx context.Context, callbackID string, lang string) {
+	if _, err := r.bot.Request(tgbotapi.NewCallback(callbackID, i18n.T(lang, "notice.rate_limit"))); err != nil {
+		slog.ErrorContext(ctx, "Ошибка ответа на нажатие кнопки при ограничении", "error", err, "callback_id", callbackID)
+	}
+}
+
+// StartCleanup запускает периодическое удаление устаревших записей,
+// чтобы хранилище не росло бесконечно с появлением новых пользователей.
+// Очистка останавливается при отмене контекста
+func (r *RateLimiter) StartCleanup(ctx context.Context) {
+	// Записи хранятся дольше самого длинного окна, чтобы очистка не снимала действующие ограничения
+	longest := r.window
+	for _, window := range r.commandWindows {
+		longest = max(longest, window)
+	}
+	ttl := longest * rateLimitTTLFactor
+
+	go func() {
+		ticker := time.NewTicker(ttl)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				removed, err := r.store.evictOlderThan(ctx, now.Add(-ttl))
+				if err != nil {
+					slog.ErrorContext(ctx, "Ошибка очистки ограничений запросов", "error", err)
+					continue
+				}
+				if removed > 0 {
+					slog.DebugContext(ctx, "Rate limiter cleanup", "removed", removed)
+				}
+			}
+		}
+	}()
+}
+
+// RecoveryMiddleware перехватывает панику в обработчиках, чтобы одно
+// проблемное обновление не останавливало бота
+type RecoveryMiddleware struct {
+	next UpdateHandler
+	bot  *tgbotapi.BotAPI
+}
+
+// NewRecoveryMiddleware создает middleware восстановления после паники
+func NewRecoveryMiddleware(next UpdateHandler, bot *tgbotapi.BotAPI) *RecoveryMiddleware {
+	return &RecoveryMiddleware{
+		next: next,
+		bot:  bot,
+	}
+}
+
+// HandleUpdate передает обновление дальше и восстанавливается после паники,
+// записывая стек в лог и извиняясь перед пользователем
+func (m *RecoveryMiddleware) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "Паника при обработке обновления",
+				"panic", r,
+				"update_id", update.UpdateID,
+				"stack", string(debug.Stack()),
+			)
+
+			if chat := update.FromChat(); chat != nil {
+				msg := tgbotapi.NewMessage(chat.ID, i18n.T(senderLanguage(update), "error.generic"))
+				if _, err := m.bot.Send(msg); err != nil {
+					slog.ErrorContext(ctx, "Ошибка отправки сообщения об ошибке", "error", err, "chat_id", chat.ID)
+				}
+			}
+		}
+	}()
+
+	m.next.HandleUpdate(ctx, update)
+}
+
+// StaleUpdateFilter отбрасывает сообщения, отправленные задолго до запуска бота.
+// После перезапуска Telegram присылает накопившиеся обновления, и без фильтра
+// бот отвечал бы на устаревшие сообщения
+type StaleUpdateFilter struct {
+	next     UpdateHandler
+	cutoff   time.Time // Сообщения старше этого момента отбрасываются
+	skipped  atomic.Int64
+	reported atomic.Bool
+}
+
+// NewStaleUpdateFilter создает фильтр, отбрасывающий сообщения старше startTime - threshold
+func NewStaleUpdateFilter(next UpdateHandler, startTime time.Time, threshold time.Duration) *StaleUpdateFilter {
+	return &StaleUpdateFilter{
+		next:   next,
+		cutoff: startTime.Add(-threshold),
+	}
+}
+
+// HandleUpdate передает дальше только свежие обновления
+func (f *StaleUpdateFilter) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	if update.Message != nil && update.Message.Time().Before(f.cutoff) {
+		skipped := f.skipped.Add(1)
+		slog.DebugContext(ctx, "Пропущено устаревшее сообщение",
+			"chat_id", update.Message.Chat.ID,
+			"date", update.Message.Time(),
+			"skipped_total", skipped,
+		)
+		return
+	}
+
+	// Накопившиеся обновления приходят первыми, поэтому итог сообщаем при первом свежем
+	if f.reported.CompareAndSwap(false, true) {
+		if skipped := f.skipped.Load(); skipped > 0 {
+			slog.InfoContext(ctx, "Пропущены устаревшие сообщения, полученные до запуска", "count", skipped)
+		}
+	}
+
+	f.next.HandleUpdate(ctx, update)
+}
+
+// duplicateUpdateMemory - сколько последних идентификаторов обновлений помнит DuplicateUpdateFilter
+const duplicateUpdateMemory = 10000
+
+// DuplicateUpdateFilter отбрасывает повторно доставленные обновления.
+// Если вебхук отвечает медленно, Telegram присылает то же обновление еще раз,
+// и без фильтра бот ответил бы дважды и дважды записал бы данные.
+// Фильтр помнит ограниченное число последних update_id в кольцевом буфере
+type DuplicateUpdateFilter struct {
+	next UpdateHandler
+	mu   sync.Mutex
+	seen map[int]struct{} // Идентификаторы из кольцевого буфера для быстрой проверки
+	ring []int            // Идентификаторы в порядке получения
+	pos  int              // Позиция для следующей записи после заполнения буфера
+}
+
+// NewDuplicateUpdateFilter создает фильтр повторных обновлений
+func NewDuplicateUpdateFilter(next UpdateHandler) *DuplicateUpdateFilter {
+	return &DuplicateUpdateFilter{
+		next: next,
+		seen: make(map[int]struct{}, duplicateUpdateMemory),
+		ring: make([]int, 0, duplicateUpdateMemory),
+	}
+}
+
+// markSeen запоминает обновление и возвращает false, если оно уже обрабатывалось.
+// После заполнения буфера забывается самое старое обновление
+func (f *DuplicateUpdateFilter) markSeen(updateID int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.seen[updateID]; ok {
+		return false
+	}
+
+	if len(f.ring) < cap(f.ring) {
+		f.ring = append(f.ring, updateID)
+	} else {
+		delete(f.seen, f.ring[f.pos])
+		f.ring[f.pos] = updateID
+		f.pos = (f.pos + 1) % len(f.ring)
+	}
+	f.seen[updateID] = struct{}{}
+
+	return true
+}
+
+// HandleUpdate передает дальше только обновления, которые еще не обрабатывались
+func (f *DuplicateUpdateFilter) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	if !f.markSeen(update.UpdateID) {
+		slog.WarnContext(ctx, "Пропущено повторно доставленное обновление", "update_id", update.UpdateID)
+		return
+	}
+
+	f.next.HandleUpdate(ctx, update)
+}
+
+// AdminMiddleware пропускает служебные команды только от администраторов.
+// Остальные обновления передаются дальше без изменений
+type AdminMiddleware struct {
+	next   UpdateHandler
+	bot    *tgbotapi.BotAPI
+	admins map[int64]struct{} // Telegram ID администраторов
+}
+
+// NewAdminMiddleware создает middleware проверки прав администратора
+func NewAdminMiddleware(next UpdateHandler, bot *tgbotapi.BotAPI, adminIDs []int64) *AdminMiddleware {
+	admins := make(map[int64]struct{}, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = struct{}{}
+	}
+
+	return &AdminMiddleware{
+		next:   next,
+		bot:    bot,
+		admins: admins,
+	}
+}
+
+// IsAdmin проверяет, входит ли пользователь Telegram в список администраторов
+func (m *AdminMiddleware) IsAdmin(telegramID int64) bool {
+	_, ok := m.admins[telegramID]
+	return ok
+}
+
+// HandleUpdate отклоняет команды администратора от остальных пользователей
+func (m *AdminMiddleware) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	message := update.Message
+	if message == nil || message.From == nil || !message.IsCommand() || !isAdminCommand(message.Command()) {
+		m.next.HandleUpdate(ctx, update)
+		return
+	}
+
+	if !m.IsAdmin(message.From.ID) {
+		slog.WarnContext(ctx, "Попытка вызова команды администратора",
+			"user_id", message.From.ID,
+			"username", message.From.UserName,
+			"command", message.Command(),
+		)
+		if _, err := m.bot.Send(tgbotapi.NewMessage(message.Chat.ID, i18n.T(senderLanguage(update), "notice.admin_only"))); err != nil {
+			slog.ErrorContext(ctx, "Ошибка отправки отказа в доступе", "error", err, "chat_id", message.Chat.ID)
+		}
+		return
+	}
+
+	m.next.HandleUpdate(ctx, update)
+}
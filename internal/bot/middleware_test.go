@@ -0,0 +1,650 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"english-bot/internal/i18n"
+	"english-bot/internal/logging"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// recordingHandler запоминает обновления, дошедшие до конца цепочки middleware
+type recordingHandler struct {
+	mu      sync.Mutex
+	updates []tgbotapi.Update
+	ctxs    []context.Context
+}
+
+func (h *recordingHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.updates = append(h.updates, update)
+	h.ctxs = append(h.ctxs, ctx)
+}
+
+func (h *recordingHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.updates)
+}
+
+// telegramRequest - вызов метода Bot API, полученный тестовым сервером Telegram
+type telegramRequest struct {
+	method string
+	params url.Values
+	files  map[string][]byte // Содержимое загруженных файлов по имени поля
+}
+
+// telegramStub - тестовый сервер Bot API, который запоминает вызовы методов и отвечает успехом
+type telegramStub struct {
+	mu       sync.Mutex
+	requests []telegramRequest
+	failing  map[string]string // Методы, на которые сервер отвечает ошибкой, и описание ошибки
+}
+
+// newTestBotAPI создает клиент Bot API, подключенный к тестовому серверу
+func newTestBotAPI(t *testing.T) (*tgbotapi.BotAPI, *telegramStub) {
+	t.Helper()
+
+	stub := &telegramStub{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+
+		if method == "getMe" {
+			w.Write([]byte(`{"ok": true, "result": {"id": 1, "is_bot": true, "first_name": "Test", "username": "test_bot"}}`))
+			return
+		}
+
+		// Файлы, например документы, отправляются формой multipart
+		files := make(map[string][]byte)
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			for field, headers := range r.MultipartForm.File {
+				file, err := headers[0].Open()
+				if err != nil {
+					continue
+				}
+				files[field], _ = io.ReadAll(file)
+				file.Close()
+			}
+		} else {
+			r.ParseForm()
+		}
+		stub.mu.Lock()
+		stub.requests = append(stub.requests, telegramRequest{method: method, params: r.PostForm, files: files})
+		description, failing := stub.failing[method]
+		stub.mu.Unlock()
+
+		if failing {
+			json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 400, "description": description})
+			return
+		}
+
+		w.Write([]byte(`{"ok": true, "result": {"message_id": 1, "date": 0, "chat": {"id": 1}}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	botAPI, err := tgbotapi.NewBotAPIWithAPIEndpoint("test-token", server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("ошибка создания клиента Bot API: %v", err)
+	}
+
+	return botAPI, stub
+}
+
+// fail заставляет сервер отвечать на вызовы method ошибкой Bot API с описанием description
+func (s *telegramStub) fail(method, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failing == nil {
+		s.failing = make(map[string]string)
+	}
+	s.failing[method] = description
+}
+
+// sent возвращает тексты сообщений, отправленных методом sendMessage
+func (s *telegramStub) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var texts []string
+	for _, request := range s.requests {
+		if request.method == "sendMessage" {
+			texts = append(texts, request.params.Get("text"))
+		}
+	}
+	return texts
+}
+
+// calls возвращает параметры всех вызовов метода Bot API method
+func (s *telegramStub) calls(method string) []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var params []url.Values
+	for _, request := range s.requests {
+		if request.method == method {
+			params = append(params, request.params)
+		}
+	}
+	return params
+}
+
+// uploads возвращает файлы поля field, загруженные вызовами метода Bot API method
+func (s *telegramStub) uploads(method, field string) [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var files [][]byte
+	for _, request := range s.requests {
+		if file, ok := request.files[field]; ok && request.method == method {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// textUpdate создает обновление с текстовым сообщением пользователя userID
+func textUpdate(updateID int, userID int64, text string) tgbotapi.Update {
+	message := &tgbotapi.Message{
+		MessageID: updateID,
+		From:      &tgbotapi.User{ID: userID},
+		Chat:      &tgbotapi.Chat{ID: userID},
+		Date:      int(time.Now().Unix()),
+		Text:      text,
+	}
+
+	// Команда распознается по сущности bot_command в начале текста
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+
+	return tgbotapi.Update{UpdateID: updateID, Message: message}
+}
+
+func TestMiddlewareForwardsUpdateWithDeadline(t *testing.T) {
+	next := &recordingHandler{}
+	NewMiddleware(next, time.Minute).HandleUpdate(context.Background(), textUpdate(1, 100, "hello"))
+
+	if next.count() != 1 {
+		t.Fatalf("до обработчика дошло %d обновлений, ожидалось 1", next.count())
+	}
+
+	ctx := next.ctxs[0]
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("контекст обработки обновления без таймаута")
+	}
+}
+
+// loggingHandler пишет в журнал с контекстом обработки, как это делают обработчики и сервисы
+type loggingHandler struct{}
+
+func (loggingHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	slog.InfoContext(ctx, "Запрос к OpenAI", "update_id", update.UpdateID)
+}
+
+func TestMiddlewareRequestIDReachesDownstreamLogs(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logging.NewContextHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	middleware := NewMiddleware(loggingHandler{}, time.Minute)
+	middleware.HandleUpdate(context.Background(), textUpdate(1, 100, "/exercise"))
+	middleware.HandleUpdate(context.Background(), textUpdate(2, 100, "/exercise"))
+
+	// Все записи об одном обновлении связаны одним идентификатором, у разных обновлений они разные
+	var ids []map[string]bool
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("не удалось разобрать запись журнала %q: %v", line, err)
+		}
+		if record["msg"] == "Incoming message" {
+			ids = append(ids, make(map[string]bool))
+		}
+		id, _ := record[logging.RequestIDKey].(string)
+		if id == "" || len(ids) == 0 {
+			t.Errorf("запись %q без идентификатора запроса", record["msg"])
+			continue
+		}
+		ids[len(ids)-1][id] = true
+	}
+
+	if len(ids) != 2 || len(ids[0]) != 1 || len(ids[1]) != 1 {
+		t.Fatalf("идентификаторы по обновлениям %v, ожидалось по одному на каждое из двух", ids)
+	}
+	for id := range ids[0] {
+		if ids[1][id] {
+			t.Errorf("у двух обновлений один идентификатор %q", id)
+		}
+	}
+	if count := bytes.Count(buf.Bytes(), []byte("Запрос к OpenAI")); count != 2 {
+		t.Errorf("в журнале %d записей обработчика, ожидалось 2", count)
+	}
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	next := &recordingHandler{}
+	chain := NewMiddleware(NewRateLimiter(next, nil, time.Second, nil), time.Minute)
+
+	chain.HandleUpdate(context.Background(), textUpdate(1, 100, "hello"))
+	chain.HandleUpdate(context.Background(), textUpdate(2, 200, "hi"))
+
+	if next.count() != 2 {
+		t.Errorf("до обработчика дошло %d обновлений, ожидалось 2", next.count())
+	}
+}
+
+func TestRateLimiterBlocksRepeatedMessages(t *testing.T) {
+	botAPI, _ := newTestBotAPI(t)
+	next := &recordingHandler{}
+	limiter := NewRateLimiter(next, botAPI, time.Hour, nil)
+
+	limiter.HandleUpdate(context.Background(), textUpdate(1, 100, "first"))
+	limiter.HandleUpdate(context.Background(), textUpdate(2, 100, "second"))
+	limiter.HandleUpdate(context.Background(), textUpdate(3, 200, "other user"))
+
+	if next.count() != 2 {
+		t.Fatalf("до обработчика дошло %d обновлений, ожидалось 2", next.count())
+	}
+	if next.updates[1].Message.From.ID != 200 {
+		t.Errorf("вместо сообщения другого пользователя пропущено %q", next.updates[1].Message.Text)
+	}
+}
+
+func TestRateLimiterAllowsAfterWindow(t *testing.T) {
+	next := &recordingHandler{}
+	limiter := NewRateLimiter(next, nil, 20*time.Millisecond, nil)
+
+	limiter.HandleUpdate(context.Background(), textUpdate(1, 100, "first"))
+	time.Sleep(30 * time.Millisecond)
+	limiter.HandleUpdate(context.Background(), textUpdate(2, 100, "second"))
+
+	if next.count() != 2 {
+		t.Errorf("до обработчика дошло %d обновлений, ожидалось 2", next.count())
+	}
+}
+
+func TestRateLimiterLimitFor(t *testing.T) {
+	limiter := NewRateLimiter(nil, nil, time.Second, map[string]time.Duration{"exercise": time.Minute})
+
+	tests := []struct {
+		text        string
+		wantCommand string
+		wantWindow  time.Duration
+	}{
+		{"/exercise", "exercise", time.Minute},
+		{"/exercise@english_bot", "exercise", time.Minute},
+		{"/help", "", time.Second},
+		{"hello", "", time.Second},
+	}
+
+	for _, tt := range tests {
+		key, window := limiter.limitFor(textUpdate(1, 100, tt.text).Message)
+		if key != (rateLimitKey{userID: 100, command: tt.wantCommand}) || window != tt.wantWindow {
+			t.Errorf("limitFor(%q) = %+v, %v; ожидалось команда %q и окно %v", tt.text, key, window, tt.wantCommand, tt.wantWindow)
+		}
+	}
+}
+
+func TestRateLimiterCommandWindow(t *testing.T) {
+	botAPI, _ := newTestBotAPI(t)
+	next := &recordingHandler{}
+	limiter := NewRateLimiter(next, botAPI, 20*time.Millisecond, map[string]time.Duration{"exercise": time.Hour})
+
+	// У /exercise свое окно: обычное сообщение сразу после нее не ограничивается
+	limiter.HandleUpdate(context.Background(), textUpdate(1, 100, "/exercise"))
+	limiter.HandleUpdate(context.Background(), textUpdate(2, 100, "hello"))
+	time.Sleep(30 * time.Millisecond)
+
+	// Окно обычных сообщений истекло, окно /exercise - нет
+	limiter.HandleUpdate(context.Background(), textUpdate(3, 100, "/exercise"))
+	limiter.HandleUpdate(context.Background(), textUpdate(4, 100, "hello again"))
+	limiter.HandleUpdate(context.Background(), textUpdate(5, 100, "/help"))
+
+	var passed []string
+	for _, update := range next.updates {
+		passed = append(passed, update.Message.Text)
+	}
+	if want := []string{"/exercise", "hello", "hello again"}; !slices.Equal(passed, want) {
+		t.Errorf("до обработчика дошли %q, ожидались %q", passed, want)
+	}
+}
+
+func TestRateLimiterConcurrentUsers(t *testing.T) {
+	next := &recordingHandler{}
+	limiter := NewRateLimiter(next, nil, time.Hour, nil)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.HandleUpdate(context.Background(), textUpdate(i, int64(i), "hello"))
+		}()
+	}
+	wg.Wait()
+
+	if next.count() != 50 {
+		t.Errorf("до обработчика дошло %d обновлений, ожидалось 50", next.count())
+	}
+}
+
+func TestMemoryRateLimitStoreEviction(t *testing.T) {
+	store := newMemoryRateLimitStore()
+	now := time.Now()
+
+	store.allow(context.Background(), rateLimitKey{userID: 1}, now.Add(-2*time.Hour), time.Second)
+	store.allow(context.Background(), rateLimitKey{userID: 2}, now, time.Second)
+
+	removed, err := store.evictOlderThan(context.Background(), now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("evictOlderThan: %v", err)
+	}
+	if removed != 1 || len(store.limits) != 1 {
+		t.Errorf("удалено %d записей, осталось %d; ожидалось 1 и 1", removed, len(store.limits))
+	}
+	if _, ok := store.limits[rateLimitKey{userID: 2}]; !ok {
+		t.Error("очистка удалила свежую запись")
+	}
+}
+
+func TestRateLimiterNotifiesOncePerWindow(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	limiter := NewRateLimiter(&recordingHandler{}, botAPI, time.Hour, nil)
+
+	for i := range 4 {
+		limiter.HandleUpdate(context.Background(), textUpdate(i, 100, "hello"))
+	}
+
+	sent := stub.sent()
+	if len(sent) != 1 {
+		t.Fatalf("отправлено %d предупреждений, ожидалось одно: %q", len(sent), sent)
+	}
+	if sent[0] != i18n.T(i18n.DefaultLanguage, "notice.rate_limit") {
+		t.Errorf("предупреждение %q", sent[0])
+	}
+}
+
+func TestRateLimiterNoticeInSenderLanguage(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	limiter := NewRateLimiter(&recordingHandler{}, botAPI, time.Hour, nil)
+
+	for i := range 2 {
+		update := textUpdate(i, 100, "привет")
+		update.Message.From.LanguageCode = "ru"
+		limiter.HandleUpdate(context.Background(), update)
+	}
+
+	sent := stub.sent()
+	if len(sent) != 1 || sent[0] != i18n.T(i18n.Russian, "notice.rate_limit") {
+		t.Errorf("предупреждения %q, ожидалось одно на русском", sent)
+	}
+}
+
+// callbackUpdate создает нажатие кнопки с данными data от пользователя userID
+func callbackUpdate(updateID int, userID int64, data string) tgbotapi.Update {
+	return tgbotapi.Update{UpdateID: updateID, CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      strconv.Itoa(updateID),
+		From:    &tgbotapi.User{ID: userID},
+		Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: userID}},
+		Data:    data,
+	}}
+}
+
+func TestRateLimiterLimitForCallback(t *testing.T) {
+	limiter := NewRateLimiter(nil, nil, time.Second, map[string]time.Duration{"exercise": time.Minute, "practice": 2 * time.Minute})
+
+	tests := []struct {
+		data        string
+		wantCommand string
+		wantWindow  time.Duration
+	}{
+		{"exercise:vocabulary", "exercise:", time.Minute},
+		{"grammar:articles", "grammar:", time.Minute},
+		{"practice:5:grammar", "practice:", 2 * time.Minute},
+		// Для диалога окно не задано - действует общее
+		{"chat:travel", "", time.Second},
+		{"answer:1:2", "", time.Second},
+		{"settings", "", time.Second},
+	}
+
+	for _, tt := range tests {
+		key, window := limiter.limitForCallback(callbackUpdate(1, 100, tt.data).CallbackQuery)
+		if key != (rateLimitKey{userID: 100, command: tt.wantCommand}) || window != tt.wantWindow {
+			t.Errorf("limitForCallback(%q) = %+v, %v; ожидалось команда %q и окно %v", tt.data, key, window, tt.wantCommand, tt.wantWindow)
+		}
+	}
+}
+
+func TestRateLimiterThrottlesExerciseCallbacks(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	next := &recordingHandler{}
+	limiter := NewRateLimiter(next, botAPI, time.Millisecond, map[string]time.Duration{"exercise": time.Hour})
+
+	// Кнопка сразу после /exercise не блокируется окном самой команды
+	limiter.HandleUpdate(context.Background(), textUpdate(1, 100, "/exercise"))
+	for i := range 3 {
+		limiter.HandleUpdate(context.Background(), callbackUpdate(2+i, 100, "exercise:vocabulary"))
+	}
+
+	if next.count() != 2 {
+		t.Errorf("обработано %d обновлений, ожидалось 2: команда и первое нажатие", next.count())
+	}
+
+	// На каждое заблокированное нажатие дан ответ, чтобы кнопка не зависала
+	answers := stub.calls("answerCallbackQuery")
+	if len(answers) != 2 {
+		t.Fatalf("отправлено %d ответов на нажатия, ожидалось 2", len(answers))
+	}
+	for _, answer := range answers {
+		if answer.Get("text") != i18n.T(i18n.DefaultLanguage, "notice.rate_limit") {
+			t.Errorf("ответ на нажатие %q, ожидалось предупреждение об ограничении", answer.Get("text"))
+		}
+	}
+}
+
+// panickingHandler падает при обработке любого обновления
+type panickingHandler struct{}
+
+func (panickingHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	panic("обработчик упал")
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	recovery := NewRecoveryMiddleware(panickingHandler{}, botAPI)
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("паника вышла за пределы middleware: %v", r)
+			}
+		}()
+		recovery.HandleUpdate(context.Background(), textUpdate(1, 100, "hello"))
+	}()
+
+	sent := stub.sent()
+	if len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "error.generic") {
+		t.Errorf("пользователю отправлено %q, ожидалось сообщение об ошибке", sent)
+	}
+}
+
+func TestRecoveryMiddlewarePassesUpdates(t *testing.T) {
+	next := &recordingHandler{}
+	NewRecoveryMiddleware(next, nil).HandleUpdate(context.Background(), textUpdate(1, 100, "hello"))
+
+	if next.count() != 1 {
+		t.Errorf("до обработчика дошло %d обновлений, ожидалось 1", next.count())
+	}
+}
+
+func TestStaleUpdateFilter(t *testing.T) {
+	next := &recordingHandler{}
+	start := time.Now()
+	filter := NewStaleUpdateFilter(next, start, 2*time.Minute)
+
+	old := textUpdate(1, 100, "sent while the bot was down")
+	old.Message.Date = int(start.Add(-10 * time.Minute).Unix())
+	recent := textUpdate(2, 100, "sent a minute before start")
+	recent.Message.Date = int(start.Add(-time.Minute).Unix())
+	callback := tgbotapi.Update{UpdateID: 3, CallbackQuery: &tgbotapi.CallbackQuery{ID: "1", From: &tgbotapi.User{ID: 100}}}
+
+	for _, update := range []tgbotapi.Update{old, recent, callback, textUpdate(4, 100, "fresh")} {
+		filter.HandleUpdate(context.Background(), update)
+	}
+
+	if next.count() != 3 {
+		t.Fatalf("до обработчика дошло %d обновлений, ожидалось 3", next.count())
+	}
+	if next.updates[0].UpdateID != 2 {
+		t.Errorf("первым пропущено обновление %d, ожидалось 2", next.updates[0].UpdateID)
+	}
+	if skipped := filter.skipped.Load(); skipped != 1 {
+		t.Errorf("отброшено %d сообщений, ожидалось 1", skipped)
+	}
+}
+
+// handledIDs возвращает update_id обновлений, дошедших до обработчика, в порядке получения
+func (h *recordingHandler) handledIDs() []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ids := make([]int, 0, len(h.updates))
+	for _, update := range h.updates {
+		ids = append(ids, update.UpdateID)
+	}
+	return ids
+}
+
+func TestDuplicateUpdateFilter(t *testing.T) {
+	next := &recordingHandler{}
+	filter := NewDuplicateUpdateFilter(next)
+
+	for _, id := range []int{1, 2, 1, 3, 2, 3, 4} {
+		filter.HandleUpdate(context.Background(), textUpdate(id, 100, "hello"))
+	}
+
+	if got := next.handledIDs(); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("до обработчика дошли обновления %v, ожидалось [1 2 3 4]", got)
+	}
+}
+
+func TestDuplicateUpdateFilterForgetsOldestUpdates(t *testing.T) {
+	next := &recordingHandler{}
+	// Маленький буфер вместо duplicateUpdateMemory, чтобы проверить вытеснение
+	filter := &DuplicateUpdateFilter{next: next, seen: make(map[int]struct{}), ring: make([]int, 0, 3)}
+
+	// После 4 забыто 1, после повторного 1 - забыто 2, а 3 и 4 еще помнятся
+	for _, id := range []int{1, 2, 3, 4, 1, 3, 4, 2} {
+		filter.HandleUpdate(context.Background(), textUpdate(id, 100, "hello"))
+	}
+
+	if got := next.handledIDs(); !slices.Equal(got, []int{1, 2, 3, 4, 1, 2}) {
+		t.Errorf("до обработчика дошли обновления %v, ожидалось [1 2 3 4 1 2]", got)
+	}
+	if len(filter.seen) != 3 || len(filter.ring) != 3 {
+		t.Errorf("фильтр помнит %d обновлений в буфере из %d, ожидалось не больше 3", len(filter.seen), len(filter.ring))
+	}
+}
+
+func TestDuplicateUpdateFilterConcurrentRedelivery(t *testing.T) {
+	next := &recordingHandler{}
+	filter := NewDuplicateUpdateFilter(next)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			filter.HandleUpdate(context.Background(), textUpdate(42, 100, "hello"))
+		}()
+	}
+	wg.Wait()
+
+	if next.count() != 1 {
+		t.Errorf("обновление обработано %d раз, ожидалось один", next.count())
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   int64
+		text     string
+		passes   bool
+		refusals int
+	}{
+		{"администратор", 1, "/stats", true, 0},
+		{"обычный пользователь", 100, "/broadcast hello", false, 1},
+		{"обычная команда", 100, "/progress", true, 0},
+		{"текст без команды", 100, "stats please", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			next := &recordingHandler{}
+			admin := NewAdminMiddleware(next, botAPI, []int64{1, 2})
+
+			admin.HandleUpdate(context.Background(), textUpdate(1, tt.userID, tt.text))
+
+			if passed := next.count() == 1; passed != tt.passes {
+				t.Errorf("обновление передано дальше: %v, ожидалось %v", passed, tt.passes)
+			}
+			sent := stub.sent()
+			if len(sent) != tt.refusals {
+				t.Fatalf("отправлено %q, ожидалось отказов: %d", sent, tt.refusals)
+			}
+			if tt.refusals > 0 && sent[0] != i18n.T(i18n.DefaultLanguage, "notice.admin_only") {
+				t.Errorf("текст отказа %q", sent[0])
+			}
+		})
+	}
+}
+
+func TestAdminMiddlewareIsAdmin(t *testing.T) {
+	admin := NewAdminMiddleware(&recordingHandler{}, nil, []int64{42})
+	if !admin.IsAdmin(42) || admin.IsAdmin(43) {
+		t.Error("IsAdmin распознает администраторов неверно")
+	}
+
+	if NewAdminMiddleware(&recordingHandler{}, nil, nil).IsAdmin(0) {
+		t.Error("без списка администраторов IsAdmin должен отклонять всех")
+	}
+}
+
+func TestPostgresRateLimiterBlocksRepeatedMessages(t *testing.T) {
+	db := newTestDatabase(t)
+	botAPI, stub := newTestBotAPI(t)
+	next := &recordingHandler{}
+	limiter := NewPostgresRateLimiter(next, botAPI, db, time.Hour, nil)
+
+	userID := -time.Now().UnixNano()
+	limiter.HandleUpdate(context.Background(), textUpdate(1, userID, "first"))
+	limiter.HandleUpdate(context.Background(), textUpdate(2, userID, "second"))
+
+	if next.count() != 1 {
+		t.Errorf("до обработчика дошло %d обновлений, ожидалось 1", next.count())
+	}
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "notice.rate_limit") {
+		t.Errorf("предупреждения %q, ожидалось одно", sent)
+	}
+}
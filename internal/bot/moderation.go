@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// refuseFlagged проверяет текст пользователя через API модерации, если она включена.
+// Если текст нарушает правила, вежливо отказывает и возвращает true.
+// При недоступности модерации текст пропускается, чтобы не блокировать обучение
+func (h *Handler) refuseFlagged(ctx context.Context, chatID int64, text string) bool {
+	if !h.moderation {
+		return false
+	}
+
+	flagged, err := h.openAI.Moderate(ctx, text)
+	if err != nil {
+		slog.WarnContext(ctx, "Ошибка модерации текста, пропускаем проверку", "error", err)
+		return false
+	}
+	if !flagged {
+		return false
+	}
+
+	slog.InfoContext(ctx, "Текст пользователя отклонен модерацией", "chat_id", chatID)
+	h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "moderation.refused")))
+	return true
+}
@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+)
+
+// newModerationOpenAI запускает тестовый OpenAI, который отвечает на модерацию ответом moderation
+// и запоминает пути всех запросов
+func newModerationOpenAI(t *testing.T, status int, moderation string) (*services.OpenAIService, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, path.Base(r.URL.Path))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(moderation))
+	}))
+	t.Cleanup(server.Close)
+
+	requests := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+	return services.NewOpenAIService("test-key", server.URL), requests
+}
+
+func TestRefuseFlagged(t *testing.T) {
+	const (
+		flagged   = `{"results": [{"flagged": true}]}`
+		unflagged = `{"results": [{"flagged": false}]}`
+	)
+
+	tests := []struct {
+		name         string
+		enabled      bool
+		status       int
+		response     string
+		wantRefused  bool
+		wantRequests int
+	}{
+		{"нарушение правил", true, http.StatusOK, flagged, true, 1},
+		{"обычный текст", true, http.StatusOK, unflagged, false, 1},
+		{"модерация недоступна", true, http.StatusInternalServerError, `{"error": {"message": "server error", "type": "server_error"}}`, false, 1},
+		{"модерация выключена", false, http.StatusOK, flagged, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openAI, requests := newModerationOpenAI(t, tt.status, tt.response)
+			botAPI, stub := newTestBotAPI(t)
+			h := &Handler{bot: botAPI, openAI: openAI}
+			h.SetModeration(tt.enabled)
+
+			refused := h.refuseFlagged(withLanguage(context.Background(), i18n.Russian), 100, "some text")
+			if refused != tt.wantRefused {
+				t.Errorf("refuseFlagged = %v, ожидалось %v", refused, tt.wantRefused)
+			}
+			if got := len(requests()); got != tt.wantRequests {
+				t.Errorf("запросов к модерации %d, ожидалось %d", got, tt.wantRequests)
+			}
+
+			sent := stub.sent()
+			if tt.wantRefused && (len(sent) != 1 || sent[0] != i18n.T(i18n.Russian, "moderation.refused")) {
+				t.Errorf("отправлено %q, ожидался вежливый отказ", sent)
+			}
+			if !tt.wantRefused && len(sent) != 0 {
+				t.Errorf("текст пропущен, но отправлено %q", sent)
+			}
+		})
+	}
+}
+
+func TestHandleGrammarCheckRefusesFlaggedText(t *testing.T) {
+	openAI, requests := newModerationOpenAI(t, http.StatusOK, `{"results": [{"flagged": true}]}`)
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI, openAI: openAI}
+	h.SetModeration(true)
+
+	h.handleGrammarCheck(context.Background(), 100, &database.User{ID: 1}, "some text")
+
+	// Отклоненный текст не отправляется на проверку грамматики
+	if got := requests(); len(got) != 1 || got[0] != "moderations" {
+		t.Errorf("запросы к OpenAI %q, ожидалась только модерация", got)
+	}
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "moderation.refused") {
+		t.Errorf("отправлено %q, ожидался вежливый отказ", sent)
+	}
+}
@@ -0,0 +1,272 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// defaultPracticeSize - количество упражнений в серии, если оно не указано
+	defaultPracticeSize = 5
+	// maxPracticeSize - максимальное количество упражнений в серии
+	maxPracticeSize = 10
+)
+
+// practiceResult - результат одного упражнения серии
+type practiceResult struct {
+	ExerciseID int64  `json:"exerciseID"`
+	Answer     string `json:"answer,omitempty"`
+	Score      int    `json:"score"`
+	Skipped    bool   `json:"skipped,omitempty"` // Пропуск сохраняется в БД сразу, а не в конце серии
+}
+
+// practiceSet - серия упражнений одного типа или викторина. Хранится в контексте сессии
+// под ключом "practice", поэтому переживает перезапуск бота
+type practiceSet struct {
+	Type    string           `json:"type"`
+	Total   int              `json:"total"`
+	Results []practiceResult `json:"results"`
+	Quiz    bool             `json:"quiz,omitempty"` // Викторина /quiz: тип меняется от вопроса к вопросу
+}
+
+// practiceSummary - итоги серии упражнений
+type practiceSummary struct {
+	Correct      int
+	Skipped      int
+	AverageScore int
+}
+
+// parsePracticeSize разбирает количество упражнений из аргументов команды /practice
+func parsePracticeSize(args string) (int, bool) {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return defaultPracticeSize, true
+	}
+
+	size, err := strconv.Atoi(args)
+	if err != nil || size < 1 || size > maxPracticeSize {
+		return 0, false
+	}
+	return size, true
+}
+
+// handlePractice предлагает выбрать тип упражнений для серии
+func (h *Handler) handlePractice(ctx context.Context, chatID int64, args string) {
+	size, ok := parsePracticeSize(args)
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "practice.usage", maxPracticeSize, defaultPracticeSize))
+		h.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "practice.choose_type", size))
+	msg.ReplyMarkup = exerciseTypeKeyboard(languageFrom(ctx), fmt.Sprintf("%s:%d", callbackPractice, size))
+	h.bot.Send(msg)
+}
+
+// handlePracticeCallback начинает серию упражнений типа, выбранного на клавиатуре
+func (h *Handler) handlePracticeCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, payload string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	sizeStr, exerciseType, _ := strings.Cut(payload, ":")
+	size, ok := parsePracticeSize(sizeStr)
+	choice, known := findExerciseTypeChoice(exerciseType)
+	if !ok || !known {
+		h.answerCallback(callback.ID, tr(ctx, "exercise.unknown_type"))
+		return
+	}
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	session, err := h.sessions.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения сессии", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	h.answerCallback(callback.ID, "")
+
+	// Убираем клавиатуру, чтобы нельзя было начать серию повторно
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
+		tr(ctx, "practice.started", size, tr(ctx, choice.LabelKey)))
+	h.bot.Send(edit)
+
+	set := &practiceSet{
+		Type:  string(choice.Type),
+		Total: size,
+	}
+	h.startPracticeExercise(ctx, chatID, session, set, user.EnglishLevel)
+}
+
+// practiceFromSession возвращает серию упражнений из контекста сессии, если пользователь ее проходит
+func practiceFromSession(ctx context.Context, session *database.UserSession) (*practiceSet, bool) {
+	var contextData map[string]string
+	if err := json.Unmarshal(session.ContextData, &contextData); err != nil {
+		return nil, false
+	}
+
+	data, ok := contextData["practice"]
+	if !ok {
+		return nil, false
+	}
+
+	var set practiceSet
+	if err := json.Unmarshal([]byte(data), &set); err != nil || set.Total <= 0 {
+		slog.WarnContext(ctx, "Некорректное состояние серии упражнений в сессии", "error", err, "user_id", session.UserID)
+		return nil, false
+	}
+
+	return &set, true
+}
+
+// startPracticeExercise выдает следующее упражнение серии
+func (h *Handler) startPracticeExercise(ctx context.Context, chatID int64, session *database.UserSession, set *practiceSet, level string) {
+	exerciseType := set.Type
+	nextKey := "practice.next"
+	if set.Quiz {
+		exerciseType = quizQuestionType(len(set.Results))
+		nextKey = "quiz.next"
+	}
+
+	data, _ := json.Marshal(set)
+	contextData := map[string]string{
+		"exerciseType": exerciseType,
+		"practice":     string(data),
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, nextKey, len(set.Results)+1, set.Total))
+	h.bot.Send(msg)
+
+	h.startExerciseWithContext(ctx, chatID, session, contextData, level)
+}
+
+// advancePractice переходит к следующему упражнению серии или завершает ее
+func (h *Handler) advancePractice(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, set *practiceSet) {
+	if len(set.Results) < set.Total {
+		// В викторине после каждого ответа показываем текущий счет
+		if set.Quiz {
+			msg := tgbotapi.NewMessage(chatID, tr(ctx, "quiz.score", summarizePractice(set).Correct, len(set.Results)))
+			h.bot.Send(msg)
+		}
+		h.startPracticeExercise(ctx, chatID, session, set, user.EnglishLevel)
+		return
+	}
+
+	h.finishPractice(ctx, chatID, user, session, set)
+}
+
+// finishPractice сохраняет результаты серии одним пакетом, показывает итоги
+// и возвращает пользователя в главное меню
+func (h *Handler) finishPractice(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, set *practiceSet) {
+	userExercises := make([]database.UserExercise, 0, len(set.Results))
+	for _, result := range set.Results {
+		if result.Skipped {
+			continue
+		}
+		userExercises = append(userExercises, database.UserExercise{
+			UserID:     user.ID,
+			ExerciseID: result.ExerciseID,
+			UserAnswer: result.Answer,
+			IsCorrect:  result.Score >= passingScore,
+		})
+	}
+
+	// Опыт за серию начисляется вместе с ответами, у викторины он свой
+	summary := summarizePractice(set)
+	xp := database.ExerciseXP(len(userExercises), summary.Correct)
+	if set.Quiz {
+		xp = quizXP(summary.Correct, set.Total)
+	}
+
+	totalXP, err := h.db.SaveUserExercises(ctx, user.ID, userExercises, xp)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения результатов серии упражнений", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		xp = 0 // Ответы не сохранились, опыт не начислен
+	}
+
+	if set.Quiz {
+		h.finishQuiz(ctx, chatID, user, set, xp, totalXP)
+	} else {
+		msg := tgbotapi.NewMessage(chatID, formatPracticeSummary(languageFrom(ctx), set))
+		msg.ParseMode = "Markdown"
+		h.bot.Send(msg)
+	}
+
+	// Сбрасываем состояние вместе с серией
+	session.State = StateIdle
+	session.ContextData = []byte("{}")
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	// Обновляем статистику пользователя
+	h.db.UpdateUserStreak(ctx, user.ID)
+	h.checkLevelUp(ctx, chatID, user)
+}
+
+// summarizePractice считает итоги серии. Пропущенные упражнения входят в среднюю оценку с нулем
+func summarizePractice(set *practiceSet) practiceSummary {
+	var summary practiceSummary
+	if len(set.Results) == 0 {
+		return summary
+	}
+
+	total := 0
+	for _, result := range set.Results {
+		switch {
+		case result.Skipped:
+			summary.Skipped++
+		case result.Score >= passingScore:
+			summary.Correct++
+		}
+		total += result.Score
+	}
+	summary.AverageScore = total / len(set.Results)
+
+	return summary
+}
+
+// formatPracticeSummary форматирует итоги серии упражнений на языке lang
+// с результатом по каждому упражнению
+func formatPracticeSummary(lang string, set *practiceSet) string {
+	summary := summarizePractice(set)
+
+	var result strings.Builder
+	result.WriteString(i18n.T(lang, "practice.complete") + "\n\n")
+	result.WriteString(i18n.T(lang, "practice.correct", summary.Correct, len(set.Results)) + "\n")
+	if summary.Skipped > 0 {
+		result.WriteString(i18n.T(lang, "practice.skipped", summary.Skipped) + "\n")
+	}
+	result.WriteString(i18n.T(lang, "practice.average", summary.AverageScore) + "\n\n")
+
+	for i, exercise := range set.Results {
+		switch {
+		case exercise.Skipped:
+			result.WriteString(i18n.T(lang, "practice.item_skipped", i+1) + "\n")
+		case exercise.Score >= passingScore:
+			result.WriteString(fmt.Sprintf("%d. ✅ %d/100\n", i+1, exercise.Score))
+		default:
+			result.WriteString(fmt.Sprintf("%d. ❌ %d/100\n", i+1, exercise.Score))
+		}
+	}
+
+	result.WriteString("\n" + i18n.T(lang, "practice.again"))
+
+	return result.String()
+}
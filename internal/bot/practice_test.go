@@ -0,0 +1,215 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParsePracticeSize(t *testing.T) {
+	tests := []struct {
+		args string
+		want int
+		ok   bool
+	}{
+		{"", defaultPracticeSize, true},
+		{"  ", defaultPracticeSize, true},
+		{"3", 3, true},
+		{" 10 ", maxPracticeSize, true},
+		{"1", 1, true},
+		{"0", 0, false},
+		{"11", 0, false},
+		{"-2", 0, false},
+		{"five", 0, false},
+	}
+
+	for _, tt := range tests {
+		size, ok := parsePracticeSize(tt.args)
+		if size != tt.want || ok != tt.ok {
+			t.Errorf("parsePracticeSize(%q) = %d, %v, ожидалось %d, %v", tt.args, size, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPracticeFromSession(t *testing.T) {
+	set, _ := json.Marshal(practiceSet{Type: "grammar", Total: 3, Results: []practiceResult{{ExerciseID: 7, Answer: "goes", Score: 100}}})
+
+	tests := []struct {
+		name        string
+		contextData map[string]string
+		ok          bool
+	}{
+		{"серия в контексте", map[string]string{"exerciseType": "grammar", "practice": string(set)}, true},
+		{"одиночное упражнение", map[string]string{"exerciseType": "grammar"}, false},
+		{"испорченная серия", map[string]string{"practice": "{"}, false},
+		{"серия без упражнений", map[string]string{"practice": `{"type": "grammar", "total": 0}`}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contextData, _ := json.Marshal(tt.contextData)
+			session := &database.UserSession{UserID: 1, ContextData: contextData}
+
+			got, ok := practiceFromSession(context.Background(), session)
+			if ok != tt.ok {
+				t.Fatalf("practiceFromSession = %v, ожидалось %v", ok, tt.ok)
+			}
+			if ok && (got.Type != "grammar" || got.Total != 3 || len(got.Results) != 1 || got.Results[0].Answer != "goes") {
+				t.Errorf("серия восстановлена как %+v", got)
+			}
+		})
+	}
+}
+
+func TestSummarizePractice(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []practiceResult
+		want    practiceSummary
+	}{
+		{"без ответов", nil, practiceSummary{}},
+		{"все правильно", []practiceResult{{Score: 100}, {Score: 80}}, practiceSummary{Correct: 2, AverageScore: 90}},
+		{"ниже проходного балла", []practiceResult{{Score: 100}, {Score: 79}}, practiceSummary{Correct: 1, AverageScore: 89}},
+		{"пропуск входит в среднюю с нулем", []practiceResult{{Score: 90}, {Skipped: true}, {Score: 60}}, practiceSummary{Correct: 1, Skipped: 1, AverageScore: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizePractice(&practiceSet{Total: 3, Results: tt.results}); got != tt.want {
+				t.Errorf("summarizePractice = %+v, ожидалось %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPracticeSummary(t *testing.T) {
+	set := &practiceSet{Type: "grammar", Total: 3, Results: []practiceResult{{Score: 100}, {Skipped: true}, {Score: 50}}}
+
+	text := formatPracticeSummary(i18n.Russian, set)
+
+	for _, want := range []string{
+		i18n.T(i18n.Russian, "practice.complete"),
+		i18n.T(i18n.Russian, "practice.correct", 1, 3),
+		i18n.T(i18n.Russian, "practice.skipped", 1),
+		i18n.T(i18n.Russian, "practice.average", 50),
+		"1. ✅ 100/100",
+		i18n.T(i18n.Russian, "practice.item_skipped", 2),
+		"3. ❌ 50/100",
+		i18n.T(i18n.Russian, "practice.again"),
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("в итогах нет %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatPracticeSummaryWithoutSkips(t *testing.T) {
+	set := &practiceSet{Type: "grammar", Total: 1, Results: []practiceResult{{Score: 100}}}
+
+	skipped := strings.TrimSuffix(i18n.T(i18n.English, "practice.skipped", 0), "*0*")
+	if text := formatPracticeSummary(i18n.English, set); strings.Contains(text, skipped) {
+		t.Errorf("итоги без пропусков содержат строку о пропусках:\n%s", text)
+	}
+}
+
+func TestPracticeSetAdvancesAndFinishes(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Translate: Я люблю чай."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := NewHandler(botAPI, db, openAI)
+	h.SetExerciseService(services.NewExerciseService(openAI))
+	h.SetSessionStore(store)
+
+	session, err := store.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+
+	// currentPractice возвращает серию и упражнение, на которое пользователь сейчас отвечает
+	currentPractice := func() (*practiceSet, *database.Exercise) {
+		t.Helper()
+		saved := store.session(user.ID)
+		set, ok := practiceFromSession(ctx, &saved)
+		if !ok || saved.State != StateExerciseReply {
+			t.Fatalf("сессия %s с контекстом %s, ожидалась серия упражнений", saved.State, saved.ContextData)
+		}
+		var contextData map[string]string
+		json.Unmarshal(saved.ContextData, &contextData)
+		exerciseID, _ := strconv.ParseInt(contextData["exerciseID"], 10, 64)
+		exercise, err := db.GetExerciseByID(ctx, exerciseID)
+		if err != nil || exercise == nil {
+			t.Fatalf("упражнение %d серии не найдено: %v", exerciseID, err)
+		}
+		return set, exercise
+	}
+
+	h.startPracticeExercise(ctx, 100, session, &practiceSet{Type: "translation", Total: 2}, user.EnglishLevel)
+	set, first := currentPractice()
+	if len(set.Results) != 0 {
+		t.Fatalf("новая серия уже содержит результаты %+v", set.Results)
+	}
+
+	h.finishExercise(ctx, 100, user, session, first, "I love tea.", 90, "")
+	set, second := currentPractice()
+	if len(set.Results) != 1 || set.Results[0].ExerciseID != first.ID || set.Results[0].Score != 90 {
+		t.Fatalf("после первого ответа результаты серии %+v", set.Results)
+	}
+	if second.ID == first.ID {
+		t.Errorf("после первого ответа выдано то же упражнение %d", first.ID)
+	}
+
+	// До конца серии ответы не сохраняются
+	progress, err := db.GetUserProgress(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserProgress: %v", err)
+	}
+	if progress.TotalExercises != 0 {
+		t.Errorf("до конца серии сохранено %d ответов", progress.TotalExercises)
+	}
+
+	h.finishExercise(ctx, 100, user, session, second, "I like tea.", 40, "")
+
+	saved := store.session(user.ID)
+	if saved.State != StateIdle || string(saved.ContextData) != "{}" {
+		t.Errorf("после серии сессия %s с контекстом %s, ожидался сброс", saved.State, saved.ContextData)
+	}
+
+	progress, err = db.GetUserProgress(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserProgress: %v", err)
+	}
+	if progress.TotalExercises != 2 || progress.CorrectExercises != 1 {
+		t.Errorf("после серии %d ответов, из них %d правильных, ожидалось 2 и 1", progress.TotalExercises, progress.CorrectExercises)
+	}
+
+	want := formatPracticeSummary(i18n.DefaultLanguage, &practiceSet{
+		Type:    "translation",
+		Total:   2,
+		Results: []practiceResult{{ExerciseID: first.ID, Score: 90}, {ExerciseID: second.ID, Score: 40}},
+	})
+	sent := stub.sent()
+	if !slices.Contains(sent, i18n.T(i18n.DefaultLanguage, "practice.next", 2, 2)) {
+		t.Errorf("не объявлено второе упражнение серии, отправлено %q", sent)
+	}
+	if !slices.Contains(sent, want) {
+		t.Errorf("итоги серии не отправлены, отправлено %q", sent)
+	}
+}
@@ -0,0 +1,24 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleProgress показывает прогресс пользователя: статистику, прогресс на уровне,
+// сильные и слабые навыки и рекомендации
+func (h *Handler) handleProgress(ctx context.Context, chatID int64, user *database.User) {
+	stats, err := h.progressService.GetUserStats(user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения прогресса", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, h.progressService.FormatProgressMessage(languageFrom(ctx), stats, user.EnglishLevel))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// chatClient отправляет сообщения и служебные запросы в Telegram. Его реализует *tgbotapi.BotAPI
+type chatClient interface {
+	messageSender
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+// withProgress показывает индикатор набора текста и сообщение-заглушку на время
+// долгой операции fn, например запроса к OpenAI. Заглушка удаляется после завершения fn,
+// даже если fn вернула ошибку или запаниковала. Пустой placeholder - только индикатор набора
+func (h *Handler) withProgress(ctx context.Context, chatID int64, placeholder string, fn func()) {
+	showProgress(ctx, h.bot, chatID, placeholder, fn)
+}
+
+// showProgress - реализация withProgress, принимающая клиент Telegram
+func showProgress(ctx context.Context, client chatClient, chatID int64, placeholder string, fn func()) {
+	if _, err := client.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping)); err != nil {
+		slog.DebugContext(ctx, "Ошибка отправки индикатора набора", "error", err, "chat_id", chatID)
+	}
+
+	if placeholder != "" {
+		sent, err := client.Send(tgbotapi.NewMessage(chatID, placeholder))
+		if err != nil {
+			slog.DebugContext(ctx, "Ошибка отправки сообщения-заглушки", "error", err, "chat_id", chatID)
+		} else {
+			defer func() {
+				if _, err := client.Request(tgbotapi.NewDeleteMessage(chatID, sent.MessageID)); err != nil {
+					slog.DebugContext(ctx, "Ошибка удаления сообщения-заглушки", "error", err, "chat_id", chatID)
+				}
+			}()
+		}
+	}
+
+	fn()
+}
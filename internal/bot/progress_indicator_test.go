@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// progressClient записывает запросы к Telegram по порядку. Если sendErr задана,
+// отправка сообщения завершается ошибкой
+type progressClient struct {
+	calls   []string
+	sendErr error
+}
+
+func (c *progressClient) Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	msg := chattable.(tgbotapi.MessageConfig)
+	c.calls = append(c.calls, "send:"+msg.Text)
+	if c.sendErr != nil {
+		return tgbotapi.Message{}, c.sendErr
+	}
+	return tgbotapi.Message{MessageID: 42}, nil
+}
+
+func (c *progressClient) Request(chattable tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	switch config := chattable.(type) {
+	case tgbotapi.ChatActionConfig:
+		c.calls = append(c.calls, "action:"+config.Action)
+	case tgbotapi.DeleteMessageConfig:
+		c.calls = append(c.calls, fmt.Sprintf("delete:%d", config.MessageID))
+	default:
+		c.calls = append(c.calls, fmt.Sprintf("request:%T", chattable))
+	}
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func TestShowProgress(t *testing.T) {
+	errGeneration := errors.New("OpenAI недоступен")
+
+	tests := []struct {
+		name        string
+		placeholder string
+		sendErr     error
+		fnErr       error
+		want        []string
+	}{
+		{"заглушка удаляется после операции", "Generating...", nil, nil,
+			[]string{"action:typing", "send:Generating...", "fn", "delete:42"}},
+		{"заглушка удаляется после ошибки", "Generating...", nil, errGeneration,
+			[]string{"action:typing", "send:Generating...", "fn", "delete:42"}},
+		{"только индикатор набора", "", nil, nil,
+			[]string{"action:typing", "fn"}},
+		{"заглушка не отправилась", "Generating...", errors.New("chat not found"), nil,
+			[]string{"action:typing", "send:Generating...", "fn"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &progressClient{sendErr: tt.sendErr}
+
+			var err error
+			showProgress(context.Background(), client, 100, tt.placeholder, func() {
+				client.calls = append(client.calls, "fn")
+				err = tt.fnErr
+			})
+
+			if !errors.Is(err, tt.fnErr) {
+				t.Errorf("ошибка операции %v, ожидалась %v", err, tt.fnErr)
+			}
+			if !slices.Equal(client.calls, tt.want) {
+				t.Errorf("запросы %q, ожидались %q", client.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowProgressCleansUpOnPanic(t *testing.T) {
+	client := &progressClient{}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("паника операции не дошла до вызывающего")
+			}
+		}()
+		showProgress(context.Background(), client, 100, "Checking...", func() {
+			panic("ошибка разбора ответа")
+		})
+	}()
+
+	want := []string{"action:typing", "send:Checking...", "delete:42"}
+	if !slices.Equal(client.calls, want) {
+		t.Errorf("запросы %q, ожидались %q", client.calls, want)
+	}
+}
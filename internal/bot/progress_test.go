@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"strings"
+	"testing"
+)
+
+func TestHandleProgressShowsRecommendations(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := withLanguage(context.Background(), i18n.Russian)
+	user := newTestDatabaseUser(t, db)
+
+	// Грамматика дается хуже словарного запаса: рекомендации должны быть по грамматике
+	answers := []struct {
+		exerciseType string
+		isCorrect    bool
+	}{
+		{"grammar", true}, {"grammar", false}, {"grammar", false}, {"grammar", false},
+		{"vocabulary", true}, {"vocabulary", true}, {"vocabulary", true},
+	}
+	for _, answer := range answers {
+		exercise, err := db.SaveExercise(ctx, database.Exercise{Type: answer.exerciseType, Level: "A1", Content: "I ___ happy.", Answer: "am"})
+		if err != nil {
+			t.Fatalf("SaveExercise: %v", err)
+		}
+		if _, err := db.SaveUserExercise(ctx, database.UserExercise{UserID: user.ID, ExerciseID: exercise.ID, UserAnswer: "am", IsCorrect: answer.isCorrect}); err != nil {
+			t.Fatalf("SaveUserExercise: %v", err)
+		}
+	}
+
+	botAPI, stub := newTestBotAPI(t)
+	progressService := services.NewProgressService(db)
+	h := NewHandler(botAPI, db, nil)
+	h.SetProgressService(progressService)
+
+	h.handleProgress(ctx, 100, user)
+
+	calls := stub.calls("sendMessage")
+	if len(calls) != 1 {
+		t.Fatalf("отправлено %d сообщений, ожидалось одно", len(calls))
+	}
+	text := calls[0].Get("text")
+
+	stats, err := progressService.GetUserStats(user.ID)
+	if err != nil {
+		t.Fatalf("GetUserStats: %v", err)
+	}
+	if want := progressService.FormatProgressMessage(i18n.Russian, stats, user.EnglishLevel); text != want {
+		t.Errorf("сообщение о прогрессе:\n%s\nожидалось:\n%s", text, want)
+	}
+
+	for _, key := range []string{
+		"progress.recommendations",
+		"progress.recommend.grammar.tenses",
+		"progress.recommend.grammar.conditionals",
+	} {
+		if want := i18n.T(i18n.Russian, key); !strings.Contains(text, want) {
+			t.Errorf("в сообщении о прогрессе нет %q:\n%s", want, text)
+		}
+	}
+	if mode := calls[0].Get("parse_mode"); mode != "Markdown" {
+		t.Errorf("режим разметки %q", mode)
+	}
+}
@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// quizSize - количество вопросов викторины
+const quizSize = 5
+
+// quizTypes - типы вопросов викторины, которые чередуются по кругу
+var quizTypes = []services.ExerciseType{
+	services.ExerciseTypeVocabulary,
+	services.ExerciseTypeGrammar,
+	services.ExerciseTypeTranslation,
+}
+
+// quizQuestionType возвращает тип вопроса викторины с номером index (с нуля)
+func quizQuestionType(index int) string {
+	return string(quizTypes[index%len(quizTypes)])
+}
+
+// handleQuiz начинает викторину из вопросов разных типов.
+// Викторина проходит как серия упражнений, поэтому ее состояние хранится в контексте сессии
+func (h *Handler) handleQuiz(ctx context.Context, chatID int64, user *database.User, session *database.UserSession) {
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "quiz.started", quizSize))
+	h.bot.Send(msg)
+
+	set := &practiceSet{
+		Quiz:  true,
+		Total: quizSize,
+	}
+	h.startPracticeExercise(ctx, chatID, session, set, user.EnglishLevel)
+}
+
+// quizXP возвращает опыт за викторину с correct правильными ответами из total
+func quizXP(correct, total int) int {
+	xp := correct * database.XPQuizCorrect
+	if total > 0 && correct == total {
+		xp += database.XPQuizPerfectBonus
+	}
+	return xp
+}
+
+// quizGradeKey возвращает ключ каталога с итоговой оценкой викторины
+func quizGradeKey(correct, total int) string {
+	switch {
+	case total > 0 && correct == total:
+		return "quiz.grade.perfect"
+	case correct*100 >= total*80:
+		return "quiz.grade.great"
+	case correct*100 >= total*60:
+		return "quiz.grade.good"
+	default:
+		return "quiz.grade.keep_going"
+	}
+}
+
+// finishQuiz выдает достижение за викторину без ошибок и показывает итоги
+// с начисленным опытом xp и общим опытом пользователя totalXP.
+// Опыт начисляется при сохранении ответов в finishPractice
+func (h *Handler) finishQuiz(ctx context.Context, chatID int64, user *database.User, set *practiceSet, xp, totalXP int) {
+	if summarizePractice(set).Correct == set.Total {
+		if err := h.db.AwardAchievement(ctx, user.ID, database.QuizMasterAchievementType); err != nil {
+			slog.ErrorContext(ctx, "Ошибка добавления достижения", "error", err, "user_id", user.ID)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatQuizSummary(languageFrom(ctx), set, xp, totalXP))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// formatQuizSummary форматирует итоги викторины на языке lang: оценку, результаты по вопросам
+// и начисленный опыт. totalXP 0 означает, что общий опыт неизвестен
+func formatQuizSummary(lang string, set *practiceSet, xp, totalXP int) string {
+	summary := summarizePractice(set)
+
+	var result strings.Builder
+	result.WriteString(i18n.T(lang, "quiz.complete") + "\n\n")
+	result.WriteString(i18n.T(lang, "quiz.result", summary.Correct, set.Total) + "\n")
+	result.WriteString(i18n.T(lang, quizGradeKey(summary.Correct, set.Total)) + "\n\n")
+
+	for i, question := range set.Results {
+		label := i18n.T(lang, "exercise.type."+quizQuestionType(i))
+		switch {
+		case question.Skipped:
+			result.WriteString(fmt.Sprintf("%d. ⏭️ %s\n", i+1, label))
+		case question.Score >= passingScore:
+			result.WriteString(fmt.Sprintf("%d. ✅ %s\n", i+1, label))
+		default:
+			result.WriteString(fmt.Sprintf("%d. ❌ %s\n", i+1, label))
+		}
+	}
+
+	if xp > 0 {
+		result.WriteString("\n" + i18n.T(lang, "quiz.xp", xp))
+		if totalXP > 0 {
+			result.WriteString(" " + i18n.T(lang, "quiz.xp_total", totalXP))
+		}
+	}
+	if summary.Correct == set.Total {
+		result.WriteString("\n" + i18n.T(lang, "quiz.master"))
+	}
+	result.WriteString("\n\n" + i18n.T(lang, "quiz.again"))
+
+	return result.String()
+}
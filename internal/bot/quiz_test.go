@@ -0,0 +1,225 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestQuizQuestionType(t *testing.T) {
+	want := []string{"vocabulary", "grammar", "translation", "vocabulary", "grammar"}
+	for i, exerciseType := range want {
+		if got := quizQuestionType(i); got != exerciseType {
+			t.Errorf("quizQuestionType(%d) = %q, ожидалось %q", i, got, exerciseType)
+		}
+	}
+}
+
+func TestQuizXP(t *testing.T) {
+	tests := []struct {
+		correct int
+		total   int
+		want    int
+	}{
+		{5, 5, 5*database.XPQuizCorrect + database.XPQuizPerfectBonus},
+		{4, 5, 4 * database.XPQuizCorrect},
+		{1, 5, database.XPQuizCorrect},
+		{0, 5, 0},
+		{0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := quizXP(tt.correct, tt.total); got != tt.want {
+			t.Errorf("quizXP(%d, %d) = %d, ожидалось %d", tt.correct, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestQuizGradeKey(t *testing.T) {
+	tests := []struct {
+		correct int
+		total   int
+		want    string
+	}{
+		{5, 5, "quiz.grade.perfect"},
+		{4, 5, "quiz.grade.great"},
+		{3, 5, "quiz.grade.good"},
+		{2, 5, "quiz.grade.keep_going"},
+		{0, 5, "quiz.grade.keep_going"},
+	}
+
+	for _, tt := range tests {
+		if got := quizGradeKey(tt.correct, tt.total); got != tt.want {
+			t.Errorf("quizGradeKey(%d, %d) = %q, ожидалось %q", tt.correct, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestFormatQuizSummary(t *testing.T) {
+	lang := i18n.Russian
+	perfect := &practiceSet{Quiz: true, Total: 3, Results: []practiceResult{{Score: 100}, {Score: 90}, {Score: 80}}}
+	mixed := &practiceSet{Quiz: true, Total: 3, Results: []practiceResult{{Score: 100}, {Score: 40}, {Skipped: true}}}
+
+	tests := []struct {
+		name     string
+		set      *practiceSet
+		xp       int
+		totalXP  int
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "без ошибок",
+			set:      perfect,
+			xp:       quizXP(3, 3),
+			totalXP:  150,
+			contains: []string{i18n.T(lang, "quiz.result", 3, 3), i18n.T(lang, "quiz.grade.perfect"), i18n.T(lang, "quiz.xp", 50) + " " + i18n.T(lang, "quiz.xp_total", 150), i18n.T(lang, "quiz.master")},
+		},
+		{
+			name:     "с ошибкой и пропуском",
+			set:      mixed,
+			xp:       quizXP(1, 3),
+			contains: []string{i18n.T(lang, "quiz.result", 1, 3), "2. ❌ " + i18n.T(lang, "exercise.type.grammar"), "3. ⏭️ " + i18n.T(lang, "exercise.type.translation"), i18n.T(lang, "quiz.xp", 10)},
+			excludes: []string{i18n.T(lang, "quiz.xp_total", 0), i18n.T(lang, "quiz.master")},
+		},
+		// Ответы не сохранились - опыт не начислен и не показывается
+		{
+			name:     "опыт не начислен",
+			set:      perfect,
+			contains: []string{i18n.T(lang, "quiz.master")},
+			excludes: []string{"XP"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := formatQuizSummary(lang, tt.set, tt.xp, tt.totalXP)
+			for _, part := range tt.contains {
+				if !strings.Contains(summary, part) {
+					t.Errorf("в итогах нет %q:\n%s", part, summary)
+				}
+			}
+			for _, part := range tt.excludes {
+				if strings.Contains(summary, part) {
+					t.Errorf("в итогах лишнее %q:\n%s", part, summary)
+				}
+			}
+			if tt.xp > 0 && strings.Count(summary, i18n.T(lang, "quiz.xp", tt.xp)) != 1 {
+				t.Errorf("начисленный опыт должен быть показан один раз:\n%s", summary)
+			}
+		})
+	}
+}
+
+func TestQuizProgressesAndScores(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	// OpenAI недоступен, поэтому вопросы выдает локальный генератор
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"message": "overloaded"}}`, http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	tests := []struct {
+		name        string
+		scores      []int
+		wantCorrect int
+	}{
+		{"без ошибок", []int{100, 100, 90, 100, 80}, 5},
+		{"с ошибками", []int{100, 40, 90, 0, 60}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestDatabaseUser(t, db)
+			botAPI, stub := newTestBotAPI(t)
+			store := newMemorySessionStore()
+			h := NewHandler(botAPI, db, openAI)
+			h.SetExerciseService(services.NewExerciseService(openAI))
+			h.SetSessionStore(store)
+
+			session, err := store.GetOrCreateUserSession(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetOrCreateUserSession: %v", err)
+			}
+
+			h.handleQuiz(ctx, 100, user, session)
+
+			want := &practiceSet{Quiz: true, Total: quizSize}
+			for i, score := range tt.scores {
+				saved := store.session(user.ID)
+				set, ok := practiceFromSession(ctx, &saved)
+				if !ok || saved.State != StateExerciseReply || len(set.Results) != i {
+					t.Fatalf("перед вопросом %d сессия %s с контекстом %s", i+1, saved.State, saved.ContextData)
+				}
+
+				var contextData map[string]string
+				json.Unmarshal(saved.ContextData, &contextData)
+				exerciseID, _ := strconv.ParseInt(contextData["exerciseID"], 10, 64)
+				exercise, err := db.GetExerciseByID(ctx, exerciseID)
+				if err != nil || exercise == nil {
+					t.Fatalf("вопрос %d не найден: %v", i+1, err)
+				}
+				if exercise.Type != quizQuestionType(i) {
+					t.Errorf("вопрос %d типа %q, ожидался %q", i+1, exercise.Type, quizQuestionType(i))
+				}
+
+				h.finishExercise(ctx, 100, user, &saved, exercise, "answer", score, "")
+				want.Results = append(want.Results, practiceResult{ExerciseID: exercise.ID, Score: score})
+			}
+
+			saved := store.session(user.ID)
+			if saved.State != StateIdle || string(saved.ContextData) != "{}" {
+				t.Errorf("после викторины сессия %s с контекстом %s, ожидался сброс", saved.State, saved.ContextData)
+			}
+
+			// Опыт за викторину начисляется один раз, вместе с ответами
+			xp := quizXP(tt.wantCorrect, quizSize)
+			progress, err := db.GetUserProgress(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetUserProgress: %v", err)
+			}
+			if progress.XP != xp {
+				t.Errorf("начислено %d XP, ожидалось %d", progress.XP, xp)
+			}
+			if progress.TotalExercises != quizSize || progress.CorrectExercises != tt.wantCorrect {
+				t.Errorf("сохранено %d ответов, из них %d правильных, ожидалось %d и %d", progress.TotalExercises, progress.CorrectExercises, quizSize, tt.wantCorrect)
+			}
+
+			achievements, err := db.GetUserAchievements(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetUserAchievements: %v", err)
+			}
+			quizMaster := slices.ContainsFunc(achievements, func(a database.UserAchievement) bool {
+				return a.AchievementType == database.QuizMasterAchievementType
+			})
+			if quizMaster != (tt.wantCorrect == quizSize) {
+				t.Errorf("достижение за викторину выдано: %v, ожидалось: %v", quizMaster, tt.wantCorrect == quizSize)
+			}
+
+			sent := stub.sent()
+			lang := i18n.DefaultLanguage
+			for _, message := range []string{
+				i18n.T(lang, "quiz.started", quizSize),
+				i18n.T(lang, "quiz.next", quizSize, quizSize),
+				// Первый ответ в обоих случаях правильный
+				i18n.T(lang, "quiz.score", 1, 1),
+				formatQuizSummary(lang, want, xp, xp),
+			} {
+				if !slices.Contains(sent, message) {
+					t.Errorf("не отправлено %q, отправлено %q", message, sent)
+				}
+			}
+		})
+	}
+}
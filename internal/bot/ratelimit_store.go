@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"sync"
+	"time"
+)
+
+// rateLimitStore хранит время последних запросов и предупреждений для RateLimiter
+type rateLimitStore interface {
+	// allow отмечает запрос, если с предыдущего запроса по ключу прошло не меньше window.
+	// Для отклоненного запроса notify сообщает, что пользователя пора предупредить:
+	// предупреждение разрешается не чаще одного раза за window
+	allow(ctx context.Context, key rateLimitKey, now time.Time, window time.Duration) (allowed, notify bool, err error)
+	// evictOlderThan удаляет записи о запросах, сделанных раньше cutoff,
+	// и возвращает количество удаленных записей
+	evictOlderThan(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// rateLimitEntry хранит время последнего запроса пользователя и последнего предупреждения
+type rateLimitEntry struct {
+	lastRequest time.Time
+	lastNotice  time.Time
+}
+
+// memoryRateLimitStore хранит ограничения в памяти процесса.
+// Ограничения сбрасываются при перезапуске и не разделяются между экземплярами бота
+type memoryRateLimitStore struct {
+	mu     sync.Mutex
+	limits map[rateLimitKey]rateLimitEntry
+}
+
+// newMemoryRateLimitStore создает хранилище ограничений в памяти
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{
+		limits: make(map[rateLimitKey]rateLimitEntry),
+	}
+}
+
+func (s *memoryRateLimitStore) allow(_ context.Context, key rateLimitKey, now time.Time, window time.Duration) (bool, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Проверяем последний запрос пользователя
+	entry, ok := s.limits[key]
+	if ok && now.Sub(entry.lastRequest) < window {
+		notify := now.Sub(entry.lastNotice) >= window
+		if notify {
+			entry.lastNotice = now
+			s.limits[key] = entry
+		}
+		return false, notify, nil
+	}
+
+	// Обновляем время последнего запроса
+	entry.lastRequest = now
+	s.limits[key] = entry
+	return true, false, nil
+}
+
+func (s *memoryRateLimitStore) evictOlderThan(_ context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key, entry := range s.limits {
+		if entry.lastRequest.Before(cutoff) {
+			delete(s.limits, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// postgresRateLimitStore хранит ограничения в таблице rate_limits.
+// Проверка и отметка запроса выполняются одним запросом, поэтому
+// несколько экземпляров бота могут безопасно использовать одну таблицу
+type postgresRateLimitStore struct {
+	db *database.PostgresDB
+}
+
+func (s *postgresRateLimitStore) allow(ctx context.Context, key rateLimitKey, now time.Time, window time.Duration) (bool, bool, error) {
+	allowed, err := s.db.TryRateLimitedRequest(ctx, key.userID, key.command, now, window)
+	if err != nil || allowed {
+		return allowed, false, err
+	}
+
+	notify, err := s.db.TryRateLimitNotice(ctx, key.userID, key.command, now, window)
+	if err != nil {
+		return false, false, err
+	}
+	return false, notify, nil
+}
+
+func (s *postgresRateLimitStore) evictOlderThan(ctx context.Context, cutoff time.Time) (int, error) {
+	removed, err := s.db.DeleteRateLimitsOlderThan(ctx, cutoff)
+	return int(removed), err
+}
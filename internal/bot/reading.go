@@ -0,0 +1,190 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/services"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// isReadingExercise проверяет, что упражнение - на понимание прочитанного
+func isReadingExercise(exercise *database.Exercise) bool {
+	return services.ExerciseType(exercise.Type) == services.ExerciseTypeReading
+}
+
+// generateReadingExercise генерирует текст с вопросами и готовит его к сохранению в БД
+func (h *Handler) generateReadingExercise(ctx context.Context, level string) (*database.Exercise, error) {
+	reading, err := h.exerciseService.GenerateReadingExercise(ctx, services.EnglishLevel(level))
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := json.Marshal(reading.Questions)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации вопросов к тексту: %w", err)
+	}
+
+	return &database.Exercise{
+		Type:      string(services.ExerciseTypeReading),
+		Level:     level,
+		Content:   reading.Passage,
+		Questions: questions,
+	}, nil
+}
+
+// readingQuestions разбирает вопросы упражнения на чтение
+func readingQuestions(exercise *database.Exercise) ([]services.ReadingQuestion, error) {
+	var questions []services.ReadingQuestion
+	if err := json.Unmarshal(exercise.Questions, &questions); err != nil {
+		return nil, fmt.Errorf("ошибка разбора вопросов к тексту: %w", err)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("у упражнения на чтение %d нет вопросов", exercise.ID)
+	}
+	return questions, nil
+}
+
+// currentReadingQuestion возвращает вопросы упражнения и номер (с нуля) вопроса,
+// на который пользователь отвечает сейчас
+func currentReadingQuestion(exercise *database.Exercise, contextData map[string]string) ([]services.ReadingQuestion, int, error) {
+	questions, err := readingQuestions(exercise)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	index, _ := strconv.Atoi(contextData["question"])
+	if index < 0 {
+		index = 0
+	} else if index >= len(questions) {
+		index = len(questions) - 1
+	}
+
+	return questions, index, nil
+}
+
+// sendReadingExercise отправляет текст упражнения и первый вопрос к нему
+func (h *Handler) sendReadingExercise(ctx context.Context, chatID int64, exercise *database.Exercise) {
+	questions, err := readingQuestions(exercise)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения вопросов к тексту", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "reading.intro", escapeMarkdown(exercise.Content), len(questions)))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+
+	h.sendReadingQuestion(ctx, chatID, questions, 0)
+}
+
+// sendReadingQuestion отправляет вопрос к тексту с заданным номером
+func (h *Handler) sendReadingQuestion(ctx context.Context, chatID int64, questions []services.ReadingQuestion, index int) {
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "reading.question",
+		index+1, len(questions), escapeMarkdown(questions[index].Question)))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// answerReadingQuestion оценивает ответ на текущий вопрос к тексту и задает следующий.
+// После последнего вопроса упражнение завершается с оценкой, равной средней по всем вопросам
+func (h *Handler) answerReadingQuestion(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, exercise *database.Exercise, answer string) {
+	var contextData map[string]string
+	if err := json.Unmarshal(session.ContextData, &contextData); err != nil || contextData == nil {
+		contextData = map[string]string{}
+	}
+
+	questions, index, err := currentReadingQuestion(exercise, contextData)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения вопросов к тексту", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	question := questions[index]
+
+	var score int
+	var explanation string
+	h.withProgress(ctx, chatID, tr(ctx, "exercise.checking"), func() {
+		score, explanation, err = h.exerciseService.GradeReadingAnswer(ctx, languageFrom(ctx), exercise.Content, question, answer)
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка проверки ответа на вопрос к тексту", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	// Подсказки к вопросу снижают оценку за него
+	if hints := hintsUsed(session); hints > 0 {
+		score = applyHintPenalty(score, hints)
+		explanation = tr(ctx, "exercise.hints_used", explanation, hints, hints*hintPenalty)
+	}
+
+	var feedback string
+	if score >= passingScore {
+		feedback = tr(ctx, "reading.correct",
+			index+1, len(questions), score, escapeMarkdown(explanation))
+	} else {
+		feedback = tr(ctx, "reading.incorrect",
+			index+1, len(questions), score, escapeMarkdown(explanation), escapeMarkdown(question.Answer))
+	}
+	msg := tgbotapi.NewMessage(chatID, feedback)
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+
+	// Накапливаем результаты в контексте сессии
+	totalScore, _ := strconv.Atoi(contextData["readingScore"])
+	correct, _ := strconv.Atoi(contextData["readingCorrect"])
+	totalScore += score
+	if score >= passingScore {
+		correct++
+	}
+
+	answers := contextData["readingAnswers"]
+	if answers != "" {
+		answers += "\n"
+	}
+	answers += answer
+
+	if index+1 < len(questions) {
+		contextData["question"] = strconv.Itoa(index + 1)
+		contextData["readingScore"] = strconv.Itoa(totalScore)
+		contextData["readingCorrect"] = strconv.Itoa(correct)
+		contextData["readingAnswers"] = answers
+		contextData["hints"] = "0"
+		// Ответ на прошлый вопрос не относится к следующему
+		delete(contextData, answerMessageKey)
+		delete(contextData, answerStateKey)
+
+		contextJSON, _ := json.Marshal(contextData)
+		session.ContextData = contextJSON
+		h.sessions.UpdateUserSession(ctx, *session)
+
+		h.sendReadingQuestion(ctx, chatID, questions, index+1)
+		return
+	}
+
+	summary := tr(ctx, "reading.summary", correct, len(questions))
+	h.finishExercise(ctx, chatID, user, session, exercise, answers, totalScore/len(questions), summary)
+}
+
+// readingAnswerKey формирует список правильных ответов на вопросы к тексту
+func readingAnswerKey(exercise *database.Exercise) (string, error) {
+	questions, err := readingQuestions(exercise)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(questions))
+	for i, question := range questions {
+		lines = append(lines, fmt.Sprintf("%d. %s\n%s", i+1, question.Question, question.Answer))
+	}
+
+	return strings.Join(lines, "\n\n"), nil
+}
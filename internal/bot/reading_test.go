@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/services"
+	"testing"
+)
+
+// readingExercise создает упражнение на чтение с вопросами questions
+func readingExercise(t *testing.T, questions ...services.ReadingQuestion) *database.Exercise {
+	t.Helper()
+
+	data, err := json.Marshal(questions)
+	if err != nil {
+		t.Fatalf("ошибка сериализации вопросов: %v", err)
+	}
+	return &database.Exercise{ID: 1, Type: "reading", Content: "Tom lives in London.", Questions: data}
+}
+
+func TestCurrentReadingQuestion(t *testing.T) {
+	exercise := readingExercise(t,
+		services.ReadingQuestion{Question: "Where does Tom live?", Answer: "In London"},
+		services.ReadingQuestion{Question: "Is it a city?", Answer: "Yes"},
+	)
+
+	tests := []struct {
+		question string
+		want     int
+	}{
+		{"", 0},
+		{"1", 1},
+		{"5", 1},
+		{"-1", 0},
+	}
+
+	for _, tt := range tests {
+		_, index, err := currentReadingQuestion(exercise, map[string]string{"question": tt.question})
+		if err != nil {
+			t.Fatalf("currentReadingQuestion: %v", err)
+		}
+		if index != tt.want {
+			t.Errorf("вопрос %q: номер %d, ожидался %d", tt.question, index, tt.want)
+		}
+	}
+}
+
+func TestReadingQuestionsWithoutQuestions(t *testing.T) {
+	if _, err := readingQuestions(readingExercise(t)); err == nil {
+		t.Error("упражнение без вопросов принято")
+	}
+	if _, err := readingQuestions(&database.Exercise{Type: "reading", Questions: []byte("not json")}); err == nil {
+		t.Error("поврежденные вопросы приняты")
+	}
+}
+
+func TestReadingAnswerKey(t *testing.T) {
+	exercise := readingExercise(t,
+		services.ReadingQuestion{Question: "Where does Tom live?", Answer: "In London"},
+		services.ReadingQuestion{Question: "Is it a city?", Answer: "Yes"},
+	)
+
+	key, err := readingAnswerKey(exercise)
+	if err != nil {
+		t.Fatalf("readingAnswerKey: %v", err)
+	}
+	want := "1. Where does Tom live?\nIn London\n\n2. Is it a city?\nYes"
+	if key != want {
+		t.Errorf("ответы %q, ожидалось %q", key, want)
+	}
+}
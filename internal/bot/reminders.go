@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SendStudyReminders напоминает о занятиях пользователям, чья серия дней может прерваться.
+// Предназначен для ежечасного запуска планировщиком
+func (h *Handler) SendStudyReminders(ctx context.Context) {
+	now := time.Now()
+
+	recipients, err := h.db.GetUsersNeedingReminder(ctx, startOfDay(now))
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения пользователей для напоминания", "error", err)
+		return
+	}
+
+	for _, recipient := range recipients {
+		if !isEligibleForReminder(now, recipient) {
+			continue
+		}
+
+		msg := tgbotapi.NewMessage(recipient.TelegramID,
+			i18n.T(recipient.UILanguage, "reminder.streak", recipient.CurrentStreak))
+		if _, err := h.bot.Send(msg); err != nil {
+			slog.ErrorContext(ctx, "Ошибка отправки напоминания", "user_id", recipient.UserID, "error", err)
+			continue
+		}
+
+		if err := h.db.MarkReminderSent(ctx, recipient.UserID, now); err != nil {
+			slog.ErrorContext(ctx, "Ошибка отметки отправки напоминания", "user_id", recipient.UserID, "error", err)
+		}
+	}
+}
+
+// isEligibleForReminder решает, нужно ли напомнить пользователю о занятиях:
+// последняя активность была вчера, наступил час уведомлений и сегодня напоминание еще не отправлялось.
+// Сутки и час уведомлений считаются в часовом поясе пользователя
+func isEligibleForReminder(now time.Time, recipient database.NotificationRecipient) bool {
+	now = now.In(recipient.Location())
+	today := startOfDay(now)
+	yesterday := today.AddDate(0, 0, -1)
+
+	if recipient.LastActivityDate.Before(yesterday) || !recipient.LastActivityDate.Before(today) {
+		return false
+	}
+
+	if now.Hour() < recipient.NotificationHour {
+		return false
+	}
+
+	return recipient.LastSentAt.Before(today)
+}
@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"english-bot/internal/database"
+	"testing"
+	"time"
+)
+
+func TestIsEligibleForReminder(t *testing.T) {
+	now := time.Date(2024, 5, 20, 19, 0, 0, 0, time.UTC)
+	yesterday := time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		recipient database.NotificationRecipient
+		want      bool
+	}{
+		{"занимался вчера, наступил час", database.NotificationRecipient{LastActivityDate: yesterday, NotificationHour: 18}, true},
+		{"час еще не наступил", database.NotificationRecipient{LastActivityDate: yesterday, NotificationHour: 20}, false},
+		{"уже занимался сегодня", database.NotificationRecipient{LastActivityDate: now.Add(-time.Hour), NotificationHour: 18}, false},
+		{"серия уже прервана", database.NotificationRecipient{LastActivityDate: yesterday.AddDate(0, 0, -1), NotificationHour: 18}, false},
+		{"ни разу не занимался", database.NotificationRecipient{NotificationHour: 18}, false},
+		{"напоминание уже отправлено", database.NotificationRecipient{LastActivityDate: yesterday, NotificationHour: 18, LastSentAt: now.Add(-30 * time.Minute)}, false},
+		{"напоминание отправлялось вчера", database.NotificationRecipient{LastActivityDate: yesterday, NotificationHour: 18, LastSentAt: yesterday.Add(18 * time.Hour)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.recipient.Timezone = "UTC"
+			if got := isEligibleForReminder(now, tt.recipient); got != tt.want {
+				t.Errorf("isEligibleForReminder = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Действия на клавиатуре подтверждения сброса прогресса
+const (
+	resetKeepAchievements = "keep"
+	resetEverything       = "all"
+	resetCancel           = "cancel"
+)
+
+// handleReset просит пользователя подтвердить сброс прогресса и выбрать, сохранить ли достижения
+func (h *Handler) handleReset(ctx context.Context, chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "reset.prompt"))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(ctx, "reset.keep_button"), fmt.Sprintf("%s:%s", callbackReset, resetKeepAchievements)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(ctx, "reset.all_button"), fmt.Sprintf("%s:%s", callbackReset, resetEverything)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(ctx, "reset.cancel_button"), fmt.Sprintf("%s:%s", callbackReset, resetCancel)),
+		),
+	)
+	h.bot.Send(msg)
+}
+
+// handleResetCallback сбрасывает прогресс пользователя после подтверждения
+func (h *Handler) handleResetCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, action string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	if action != resetKeepAchievements && action != resetEverything {
+		h.answerCallback(callback.ID, "")
+		h.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, tr(ctx, "reset.cancelled")))
+		return
+	}
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	keepAchievements := action == resetKeepAchievements
+	if err := h.db.ResetUserProgress(ctx, user.ID, keepAchievements); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сброса прогресса пользователя", "error", err, "user_id", user.ID)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	if err := h.sessions.InvalidateUserSession(ctx, user.ID); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сброса сохраненной сессии", "error", err, "user_id", user.ID)
+	}
+	slog.InfoContext(ctx, "Прогресс пользователя сброшен", "user_id", user.ID, "keep_achievements", keepAchievements)
+
+	h.answerCallback(callback.ID, tr(ctx, "reset.done_toast"))
+	h.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, tr(ctx, "reset.done")))
+}
@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/i18n"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestHandleResetAsksForConfirmation(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	h.handleReset(withLanguage(context.Background(), i18n.Russian), 100)
+
+	calls := stub.calls("sendMessage")
+	if len(calls) != 1 {
+		t.Fatalf("отправлено %d сообщений, ожидалось одно", len(calls))
+	}
+	if text := calls[0].Get("text"); text != i18n.T(i18n.Russian, "reset.prompt") {
+		t.Errorf("текст запроса подтверждения %q", text)
+	}
+
+	var keyboard tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(calls[0].Get("reply_markup")), &keyboard); err != nil {
+		t.Fatalf("не удалось разобрать клавиатуру: %v", err)
+	}
+	want := []string{"reset:keep", "reset:all", "reset:cancel"}
+	if len(keyboard.InlineKeyboard) != len(want) {
+		t.Fatalf("клавиатура %+v, ожидалось %d ряда", keyboard.InlineKeyboard, len(want))
+	}
+	for i, row := range keyboard.InlineKeyboard {
+		if len(row) != 1 || row[0].CallbackData == nil || *row[0].CallbackData != want[i] {
+			t.Errorf("ряд %d: %+v, ожидалась кнопка %q", i, row, want[i])
+		}
+	}
+}
+
+func TestHandleResetCallbackCancel(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+	}{
+		{"отмена", resetCancel},
+		{"неизвестное действие", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			// Без базы данных: отмена не должна ничего сбрасывать
+			h := &Handler{bot: botAPI}
+
+			callback := &tgbotapi.CallbackQuery{
+				ID:      "callback-1",
+				From:    &tgbotapi.User{ID: 100},
+				Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 100}},
+			}
+			h.handleResetCallback(context.Background(), callback, tt.action)
+
+			edits := stub.calls("editMessageText")
+			if len(edits) != 1 || edits[0].Get("text") != i18n.T(i18n.DefaultLanguage, "reset.cancelled") {
+				t.Errorf("изменения сообщения %v, ожидалось сообщение об отмене", edits)
+			}
+		})
+	}
+}
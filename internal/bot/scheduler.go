@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Job описывает периодическую фоновую задачу
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context)
+}
+
+// Scheduler запускает фоновые задачи с заданной периодичностью
+type Scheduler struct {
+	jobs []Job
+}
+
+// NewScheduler создает новый планировщик задач
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add регистрирует задачу, которая будет выполняться каждые interval
+func (s *Scheduler) Add(name string, interval time.Duration, run func(ctx context.Context)) {
+	s.jobs = append(s.jobs, Job{
+		Name:     name,
+		Interval: interval,
+		Run:      run,
+	})
+}
+
+// Start запускает все зарегистрированные задачи, каждую в своей горутине.
+// Задачи останавливаются при отмене контекста
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.runJob(ctx, job)
+	}
+}
+
+// runJob выполняет задачу сразу после запуска и далее по таймеру
+func (s *Scheduler) runJob(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	slog.InfoContext(ctx, "Фоновая задача запущена", "job", job.Name, "interval", job.Interval.String())
+
+	for {
+		s.execute(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// execute выполняет задачу один раз, не давая панике остановить планировщик
+func (s *Scheduler) execute(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "Паника в фоновой задаче", "job", job.Name, "panic", r)
+		}
+	}()
+
+	startTime := time.Now()
+	job.Run(ctx)
+
+	slog.DebugContext(ctx, "Фоновая задача выполнена",
+		"job", job.Name,
+		"duration_ms", time.Since(startTime).Milliseconds(),
+	)
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sessionTouchInterval - как часто RedisSessionStore переносит время активности в Postgres.
+// Время активности в базе нужно для сброса забытых упражнений, поэтому оно может отставать
+// от настоящего не больше чем на этот интервал
+const sessionTouchInterval = time.Minute
+
+// sessionKeyPrefix - префикс ключей сессий в Redis
+const sessionKeyPrefix = "english-bot:session:"
+
+// SessionStore хранит состояние диалога пользователя с ботом
+type SessionStore interface {
+	// GetOrCreateUserSession возвращает сессию пользователя, создавая ее при первом обращении
+	GetOrCreateUserSession(ctx context.Context, userID int64) (*database.UserSession, error)
+	// UpdateUserSession сохраняет изменения сессии
+	UpdateUserSession(ctx context.Context, session database.UserSession) error
+	// InvalidateUserSession забывает сохраненную копию сессии после того,
+	// как сессию изменили или удалили в базе данных в обход хранилища
+	InvalidateUserSession(ctx context.Context, userID int64) error
+}
+
+// postgresSessionStore хранит сессии только в таблице user_sessions
+type postgresSessionStore struct {
+	db *database.PostgresDB
+}
+
+// newPostgresSessionStore создает хранилище сессий в базе данных
+func newPostgresSessionStore(db *database.PostgresDB) *postgresSessionStore {
+	return &postgresSessionStore{db: db}
+}
+
+func (s *postgresSessionStore) GetOrCreateUserSession(ctx context.Context, userID int64) (*database.UserSession, error) {
+	return s.db.GetOrCreateUserSession(ctx, userID)
+}
+
+func (s *postgresSessionStore) UpdateUserSession(ctx context.Context, session database.UserSession) error {
+	return s.db.UpdateUserSession(ctx, session)
+}
+
+// InvalidateUserSession ничего не делает: копий сессий вне базы нет
+func (s *postgresSessionStore) InvalidateUserSession(context.Context, int64) error {
+	return nil
+}
+
+// RedisSessionStore читает сессии из Redis, чтобы не обращаться к Postgres на каждое сообщение.
+// Изменения записываются и в Postgres, и в Redis, поэтому после истечения ttl или перезапуска Redis
+// сессия снова читается из базы. Если Redis недоступен, хранилище работает напрямую с Postgres
+type RedisSessionStore struct {
+	client *redis.Client
+	db     *database.PostgresDB
+	ttl    time.Duration // Время жизни сессии в Redis с последнего обращения
+}
+
+// NewRedisSessionStore создает хранилище сессий в Redis поверх базы данных
+func NewRedisSessionStore(client *redis.Client, db *database.PostgresDB, ttl time.Duration) *RedisSessionStore {
+	return &RedisSessionStore{
+		client: client,
+		db:     db,
+		ttl:    ttl,
+	}
+}
+
+// GetOrCreateUserSession возвращает сессию из Redis, а если ее там нет - из Postgres, и кэширует ее
+func (s *RedisSessionStore) GetOrCreateUserSession(ctx context.Context, userID int64) (*database.UserSession, error) {
+	session, err := s.get(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "Ошибка чтения сессии из Redis", "user_id", userID, "error", err)
+	}
+
+	if session == nil {
+		session, err = s.db.GetOrCreateUserSession(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		s.cache(ctx, *session)
+		return session, nil
+	}
+
+	// Время активности переносим в базу не на каждое сообщение, а не чаще sessionTouchInterval
+	now := time.Now()
+	if now.Sub(session.LastActivity) >= sessionTouchInterval {
+		if err := s.db.TouchUserSession(ctx, session.ID, now); err != nil {
+			slog.ErrorContext(ctx, "Ошибка обновления времени активности сессии", "error", err)
+		} else {
+			session.LastActivity = now
+		}
+	}
+	s.cache(ctx, *session)
+
+	return session, nil
+}
+
+// UpdateUserSession сохраняет сессию в Postgres, а затем обновляет ее копию в Redis
+func (s *RedisSessionStore) UpdateUserSession(ctx context.Context, session database.UserSession) error {
+	if err := s.db.UpdateUserSession(ctx, session); err != nil {
+		return err
+	}
+
+	session.LastActivity = time.Now()
+	session.UpdatedAt = session.LastActivity
+	s.cache(ctx, session)
+
+	return nil
+}
+
+// InvalidateUserSession удаляет сессию пользователя из Redis
+func (s *RedisSessionStore) InvalidateUserSession(ctx context.Context, userID int64) error {
+	if err := s.client.Del(ctx, sessionKey(userID)).Err(); err != nil {
+		return fmt.Errorf("ошибка удаления сессии из Redis: %w", err)
+	}
+	return nil
+}
+
+// get читает сессию из Redis. Возвращает nil, если сессии там нет
+func (s *RedisSessionStore) get(ctx context.Context, userID int64) (*database.UserSession, error) {
+	data, err := s.client.Get(ctx, sessionKey(userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения сессии из Redis: %w", err)
+	}
+
+	var session database.UserSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("ошибка разбора сессии из Redis: %w", err)
+	}
+
+	return &session, nil
+}
+
+// cache записывает сессию в Redis. Если записать не удалось, старая копия удаляется,
+// чтобы следующее чтение не вернуло устаревшее состояние
+func (s *RedisSessionStore) cache(ctx context.Context, session database.UserSession) {
+	data, err := json.Marshal(session)
+	if err == nil {
+		err = s.client.Set(ctx, sessionKey(session.UserID), data, s.ttl).Err()
+	}
+	if err == nil {
+		return
+	}
+
+	slog.WarnContext(ctx, "Ошибка записи сессии в Redis", "user_id", session.UserID, "error", err)
+	if err := s.InvalidateUserSession(ctx, session.UserID); err != nil {
+		slog.ErrorContext(ctx, "Ошибка удаления устаревшей сессии из Redis", "user_id", session.UserID, "error", err)
+	}
+}
+
+// sessionKey возвращает ключ сессии пользователя в Redis
+func sessionKey(userID int64) string {
+	return sessionKeyPrefix + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisSessionStore запускает miniredis и создает хранилище сессий поверх db
+func newTestRedisSessionStore(t *testing.T, db *database.PostgresDB, ttl time.Duration) (*RedisSessionStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisSessionStore(client, db, ttl), server
+}
+
+func TestRedisSessionStoreCachesSession(t *testing.T) {
+	store, server := newTestRedisSessionStore(t, nil, time.Hour)
+	ctx := context.Background()
+
+	if session, err := store.get(ctx, 1); session != nil || err != nil {
+		t.Fatalf("get пустого хранилища = %+v, %v; ожидалось nil", session, err)
+	}
+
+	want := database.UserSession{ID: 10, UserID: 1, State: StateGrammarCheck, ContextData: []byte(`{"exerciseID":"7"}`), LastActivity: time.Now()}
+	store.cache(ctx, want)
+
+	if ttl := server.TTL(sessionKey(1)); ttl != time.Hour {
+		t.Errorf("время жизни сессии %v, ожидался час", ttl)
+	}
+
+	// Недавно активная сессия читается из Redis без обращения к базе
+	session, err := store.GetOrCreateUserSession(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	if session.ID != want.ID || session.State != want.State || string(session.ContextData) != string(want.ContextData) {
+		t.Errorf("прочитана сессия %+v, ожидалась %+v", session, want)
+	}
+}
+
+func TestRedisSessionStoreExpiresSession(t *testing.T) {
+	store, server := newTestRedisSessionStore(t, nil, time.Minute)
+	ctx := context.Background()
+
+	store.cache(ctx, database.UserSession{ID: 10, UserID: 1, State: StateIdle})
+
+	server.FastForward(30 * time.Second)
+	if session, _ := store.get(ctx, 1); session == nil {
+		t.Fatal("сессия удалена раньше времени жизни")
+	}
+
+	server.FastForward(31 * time.Second)
+	if session, err := store.get(ctx, 1); session != nil || err != nil {
+		t.Errorf("после истечения времени жизни прочитана сессия %+v, %v", session, err)
+	}
+}
+
+func TestRedisSessionStoreInvalidate(t *testing.T) {
+	store, server := newTestRedisSessionStore(t, nil, time.Hour)
+	ctx := context.Background()
+
+	store.cache(ctx, database.UserSession{ID: 10, UserID: 1, State: StateIdle})
+	store.cache(ctx, database.UserSession{ID: 20, UserID: 2, State: StateIdle})
+
+	if err := store.InvalidateUserSession(ctx, 1); err != nil {
+		t.Fatalf("InvalidateUserSession: %v", err)
+	}
+	if server.Exists(sessionKey(1)) || !server.Exists(sessionKey(2)) {
+		t.Errorf("в Redis остались ключи %v, ожидалась только сессия второго пользователя", server.Keys())
+	}
+}
+
+func TestRedisSessionStoreRejectsBrokenSession(t *testing.T) {
+	store, server := newTestRedisSessionStore(t, nil, time.Hour)
+
+	server.Set(sessionKey(1), "not json")
+
+	if session, err := store.get(context.Background(), 1); session != nil || err == nil {
+		t.Errorf("get = %+v, %v; ожидалась ошибка разбора", session, err)
+	}
+}
+
+func TestRedisSessionStoreWithPostgres(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+	store, server := newTestRedisSessionStore(t, db, time.Hour)
+
+	// Первое чтение создает сессию в базе и кэширует ее
+	session, err := store.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	if !server.Exists(sessionKey(user.ID)) {
+		t.Fatal("сессия из базы не сохранена в Redis")
+	}
+
+	session.State = StateGrammarCheck
+	if err := store.UpdateUserSession(ctx, *session); err != nil {
+		t.Fatalf("UpdateUserSession: %v", err)
+	}
+
+	saved, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession из базы: %v", err)
+	}
+	cached, err := store.get(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if saved.State != StateGrammarCheck || cached == nil || cached.State != StateGrammarCheck {
+		t.Errorf("в базе состояние %q, в Redis %+v; ожидалось %q в обоих", saved.State, cached, StateGrammarCheck)
+	}
+
+	// После истечения времени жизни сессия снова читается из базы
+	server.FastForward(2 * time.Hour)
+	session, err = store.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession после истечения: %v", err)
+	}
+	if session.State != StateGrammarCheck {
+		t.Errorf("из базы прочитано состояние %q, ожидалось %q", session.State, StateGrammarCheck)
+	}
+
+	// Без Redis хранилище работает напрямую с базой
+	server.Close()
+	session.State = StateIdle
+	if err := store.UpdateUserSession(ctx, *session); err != nil {
+		t.Fatalf("UpdateUserSession без Redis: %v", err)
+	}
+	session, err = store.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession без Redis: %v", err)
+	}
+	if session.State != StateIdle {
+		t.Errorf("без Redis прочитано состояние %q, ожидалось %q", session.State, StateIdle)
+	}
+}
@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// notificationHours - часы, которые можно выбрать для уведомлений
+var notificationHours = []int{8, 12, 18, 21}
+
+// grammarEngines - способы проверки грамматики в порядке переключения кнопкой настроек
+var grammarEngines = []string{database.GrammarEngineBoth, database.GrammarEngineLanguageTool, database.GrammarEngineAI}
+
+// handleSettings показывает текущие настройки пользователя с клавиатурой для их изменения
+func (h *Handler) handleSettings(ctx context.Context, chatID int64, user *database.User) {
+	settings, err := h.db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения настроек", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	lang := languageFrom(ctx)
+	msg := tgbotapi.NewMessage(chatID, formatSettings(lang, settings, user.Timezone))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = settingsKeyboard(lang, settings)
+	h.bot.Send(msg)
+}
+
+// handleSettingsCallback применяет изменение настройки, выбранное на клавиатуре
+func (h *Handler) handleSettingsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, payload string) {
+	if callback.Message == nil {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+	chatID := callback.Message.Chat.ID
+
+	user, ok := h.callbackUser(ctx, callback, chatID)
+	if !ok {
+		return
+	}
+
+	settings, err := h.db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения настроек", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	if !applySettingsChange(settings, payload) {
+		h.answerCallback(callback.ID, "")
+		return
+	}
+
+	if err := h.db.UpdateUserSettings(ctx, *settings); err != nil {
+		slog.ErrorContext(ctx, "Ошибка обновления настроек", "error", err)
+		h.answerCallback(callback.ID, "")
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	// После смены языка интерфейса сразу отвечаем на новом языке
+	lang := i18n.Resolve(settings.UILanguage, user.LanguageCode)
+	h.answerCallback(callback.ID, i18n.T(lang, "callback.saved"))
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, callback.Message.MessageID,
+		formatSettings(lang, settings, user.Timezone), settingsKeyboard(lang, settings))
+	edit.ParseMode = "Markdown"
+	h.bot.Send(edit)
+}
+
+// applySettingsChange изменяет настройки согласно данным кнопки.
+// Возвращает false, если данные не распознаны
+func applySettingsChange(settings *database.UserSettings, payload string) bool {
+	switch payload {
+	case "reminders":
+		settings.DailyReminders = !settings.DailyReminders
+		return true
+
+	case "daily_word":
+		settings.DailyWord = !settings.DailyWord
+		return true
+
+	case "weekly_report":
+		settings.WeeklyReport = !settings.WeeklyReport
+		return true
+
+	case "leaderboard":
+		settings.LeaderboardVisible = !settings.LeaderboardVisible
+		return true
+
+	case "grammar":
+		settings.GrammarEngine = nextGrammarEngine(settings.GrammarEngine)
+		return true
+
+	case "lang":
+		if settings.UILanguage == i18n.Russian {
+			settings.UILanguage = i18n.English
+		} else {
+			settings.UILanguage = i18n.Russian
+		}
+		return true
+	}
+
+	// Выбор часа уведомлений: hour:<час>
+	var hour int
+	if _, err := fmt.Sscanf(payload, "hour:%d", &hour); err == nil && hour >= 0 && hour < 24 {
+		settings.NotificationHour = hour
+		return true
+	}
+
+	return false
+}
+
+// formatSettings форматирует текущие настройки пользователя и его часовой пояс на языке lang
+func formatSettings(lang string, settings *database.UserSettings, timezone string) string {
+	return i18n.T(lang, "settings.title",
+		onOff(lang, settings.DailyReminders),
+		onOff(lang, settings.DailyWord),
+		onOff(lang, settings.WeeklyReport),
+		onOff(lang, settings.LeaderboardVisible),
+		grammarEngineName(lang, settings.GrammarEngine),
+		languageName(lang),
+		settings.NotificationHour,
+		escapeMarkdown(timezoneName(lang, timezone)),
+	)
+}
+
+// settingsKeyboard создает клавиатуру для изменения настроек
+func settingsKeyboard(lang string, settings *database.UserSettings) tgbotapi.InlineKeyboardMarkup {
+	hourButtons := make([]tgbotapi.InlineKeyboardButton, 0, len(notificationHours))
+	for _, hour := range notificationHours {
+		label := fmt.Sprintf("%02d:00", hour)
+		if hour == settings.NotificationHour {
+			label = "✓ " + label
+		}
+		hourButtons = append(hourButtons, tgbotapi.NewInlineKeyboardButtonData(label,
+			callbackSettings+":hour:"+strconv.Itoa(hour)))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "settings.reminders", onOff(lang, settings.DailyReminders)), callbackSettings+":reminders"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "settings.daily_word", onOff(lang, settings.DailyWord)), callbackSettings+":daily_word"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "settings.weekly_report", onOff(lang, settings.WeeklyReport)), callbackSettings+":weekly_report"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "settings.leaderboard", onOff(lang, settings.LeaderboardVisible)), callbackSettings+":leaderboard"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "settings.grammar_engine", grammarEngineName(lang, settings.GrammarEngine)), callbackSettings+":grammar"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "settings.language", languageName(lang)), callbackSettings+":lang"),
+		),
+		hourButtons,
+	)
+}
+
+// nextGrammarEngine возвращает способ проверки грамматики, следующий за текущим.
+// Неизвестное значение переключается на первый способ
+func nextGrammarEngine(engine string) string {
+	for i, e := range grammarEngines {
+		if e == engine {
+			return grammarEngines[(i+1)%len(grammarEngines)]
+		}
+	}
+	return grammarEngines[0]
+}
+
+// grammarEngineName возвращает название способа проверки грамматики на языке lang
+func grammarEngineName(lang, engine string) string {
+	switch engine {
+	case database.GrammarEngineAI:
+		return i18n.T(lang, "settings.engine.ai")
+	case database.GrammarEngineLanguageTool:
+		return i18n.T(lang, "settings.engine.languagetool")
+	}
+	return i18n.T(lang, "settings.engine.both")
+}
+
+// languageName возвращает название языка интерфейса
+func languageName(code string) string {
+	if code == i18n.Russian {
+		return "Русский"
+	}
+	return "English"
+}
+
+// onOff возвращает текстовое представление переключателя на языке lang
+func onOff(lang string, enabled bool) string {
+	if enabled {
+		return i18n.T(lang, "settings.on")
+	}
+	return i18n.T(lang, "settings.off")
+}
@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"strings"
+	"testing"
+)
+
+func TestApplySettingsChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    func(*database.UserSettings) bool
+		ok      bool
+	}{
+		{"напоминания", "reminders", func(s *database.UserSettings) bool { return !s.DailyReminders }, true},
+		{"слово дня", "daily_word", func(s *database.UserSettings) bool { return s.DailyWord }, true},
+		{"час уведомлений", "hour:21", func(s *database.UserSettings) bool { return s.NotificationHour == 21 }, true},
+		{"еженедельный отчет", "weekly_report", func(s *database.UserSettings) bool { return s.WeeklyReport }, true},
+		{"рейтинг", "leaderboard", func(s *database.UserSettings) bool { return !s.LeaderboardVisible }, true},
+		{"язык интерфейса", "lang", func(s *database.UserSettings) bool { return s.UILanguage == i18n.Russian }, true},
+		{"полночь", "hour:0", func(s *database.UserSettings) bool { return s.NotificationHour == 0 }, true},
+		{"час вне диапазона", "hour:24", func(s *database.UserSettings) bool { return s.NotificationHour == 18 }, false},
+		{"отрицательный час", "hour:-1", func(s *database.UserSettings) bool { return s.NotificationHour == 18 }, false},
+		{"неизвестная настройка", "unknown", func(s *database.UserSettings) bool { return s.DailyReminders }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &database.UserSettings{DailyReminders: true, LeaderboardVisible: true, NotificationHour: 18}
+
+			if ok := applySettingsChange(settings, tt.payload); ok != tt.ok {
+				t.Errorf("applySettingsChange(%q) = %v, ожидалось %v", tt.payload, ok, tt.ok)
+			}
+			if !tt.want(settings) {
+				t.Errorf("после %q настройки стали %+v", tt.payload, settings)
+			}
+		})
+	}
+}
+
+func TestApplySettingsChangeTogglesLanguage(t *testing.T) {
+	settings := &database.UserSettings{UILanguage: i18n.Russian}
+
+	for _, want := range []string{i18n.English, i18n.Russian} {
+		applySettingsChange(settings, "lang")
+		if settings.UILanguage != want {
+			t.Errorf("язык после переключения %q, ожидался %q", settings.UILanguage, want)
+		}
+	}
+}
+
+func TestSettingsKeyboardMarksNotificationHour(t *testing.T) {
+	keyboard := settingsKeyboard("en", &database.UserSettings{NotificationHour: 12})
+
+	hours := keyboard.InlineKeyboard[len(keyboard.InlineKeyboard)-1]
+	if len(hours) != len(notificationHours) {
+		t.Fatalf("ряд часов из %d кнопок, ожидалось %d", len(hours), len(notificationHours))
+	}
+
+	for i, button := range hours {
+		selected := notificationHours[i] == 12
+		if marked := strings.HasPrefix(button.Text, "✓ "); marked != selected {
+			t.Errorf("кнопка %q: отмечена %v, ожидалось %v", button.Text, marked, selected)
+		}
+	}
+}
+
+func TestNextGrammarEngine(t *testing.T) {
+	tests := []struct {
+		engine string
+		want   string
+	}{
+		{database.GrammarEngineBoth, database.GrammarEngineLanguageTool},
+		{database.GrammarEngineLanguageTool, database.GrammarEngineAI},
+		{database.GrammarEngineAI, database.GrammarEngineBoth},
+		{"", database.GrammarEngineBoth},
+		{"gpt", database.GrammarEngineBoth},
+	}
+
+	for _, tt := range tests {
+		if got := nextGrammarEngine(tt.engine); got != tt.want {
+			t.Errorf("nextGrammarEngine(%q) = %q, ожидалось %q", tt.engine, got, tt.want)
+		}
+	}
+}
+
+func TestApplySettingsChangeCyclesGrammarEngine(t *testing.T) {
+	settings := &database.UserSettings{GrammarEngine: database.GrammarEngineBoth}
+
+	for _, want := range []string{database.GrammarEngineLanguageTool, database.GrammarEngineAI, database.GrammarEngineBoth} {
+		if !applySettingsChange(settings, "grammar") {
+			t.Fatal("applySettingsChange не принял переключение способа проверки")
+		}
+		if settings.GrammarEngine != want {
+			t.Errorf("способ проверки после переключения %q, ожидался %q", settings.GrammarEngine, want)
+		}
+	}
+}
+
+func TestSettingsShowGrammarEngine(t *testing.T) {
+	tests := []struct {
+		engine string
+		key    string
+	}{
+		{database.GrammarEngineBoth, "settings.engine.both"},
+		{database.GrammarEngineLanguageTool, "settings.engine.languagetool"},
+		{database.GrammarEngineAI, "settings.engine.ai"},
+		{"", "settings.engine.both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			settings := &database.UserSettings{GrammarEngine: tt.engine, NotificationHour: 18}
+			name := i18n.T(i18n.Russian, tt.key)
+
+			if got := grammarEngineName(i18n.Russian, tt.engine); got != name {
+				t.Errorf("grammarEngineName(%q) = %q, ожидалось %q", tt.engine, got, name)
+			}
+			if text := formatSettings(i18n.Russian, settings, "UTC"); !strings.Contains(text, name) {
+				t.Errorf("в настройках нет способа проверки %q:\n%s", name, text)
+			}
+
+			wantButton := i18n.T(i18n.Russian, "settings.grammar_engine", name)
+			found := false
+			for _, row := range settingsKeyboard(i18n.Russian, settings).InlineKeyboard {
+				for _, button := range row {
+					if button.CallbackData != nil && *button.CallbackData == callbackSettings+":grammar" {
+						found = button.Text == wantButton
+					}
+				}
+			}
+			if !found {
+				t.Errorf("на клавиатуре нет кнопки %q", wantButton)
+			}
+		})
+	}
+}
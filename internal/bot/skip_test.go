@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestHandleSkipWithoutExercise(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	h := &Handler{bot: botAPI}
+
+	session := &database.UserSession{UserID: 1, State: StateIdle, ContextData: []byte("{}")}
+	h.handleSkip(context.Background(), 100, &database.User{ID: 1}, session)
+
+	sent := stub.sent()
+	if len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "exercise.none_active") {
+		t.Errorf("отправлено %q, ожидалось сообщение об отсутствии упражнения", sent)
+	}
+}
+
+func TestHandleSkipStartsNewExercise(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Translate: Кошка спит."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := NewHandler(botAPI, db, openAI)
+	h.SetExerciseService(services.NewExerciseService(openAI))
+	h.SetSessionStore(store)
+
+	skipped, err := db.SaveExercise(ctx, database.Exercise{Type: "translation", Level: "A1", Content: "Translate: Я люблю чай.", Answer: "I love tea."})
+	if err != nil {
+		t.Fatalf("SaveExercise: %v", err)
+	}
+	contextData, _ := json.Marshal(map[string]string{"exerciseType": "translation", "exerciseID": strconv.FormatInt(skipped.ID, 10)})
+	session := &database.UserSession{ID: 1, UserID: user.ID, State: StateExerciseReply, ContextData: contextData}
+
+	h.handleSkip(ctx, 100, user, session)
+
+	if sent := stub.sent(); !slices.Contains(sent, i18n.T(i18n.DefaultLanguage, "exercise.skipped", "I love tea.")) {
+		t.Errorf("правильный ответ не показан, отправлено %q", sent)
+	}
+
+	saved := store.session(user.ID)
+	var savedContext map[string]string
+	json.Unmarshal(saved.ContextData, &savedContext)
+	if saved.State != StateExerciseReply || savedContext["exerciseType"] != "translation" {
+		t.Errorf("после пропуска сессия %s с контекстом %v, ожидалось новое упражнение на перевод", saved.State, savedContext)
+	}
+	if savedContext["exerciseID"] == "" || savedContext["exerciseID"] == strconv.FormatInt(skipped.ID, 10) {
+		t.Errorf("после пропуска осталось упражнение %q", savedContext["exerciseID"])
+	}
+
+	results, err := db.GetRecentExerciseResults(ctx, user.ID, "translation", 5)
+	if err != nil {
+		t.Fatalf("GetRecentExerciseResults: %v", err)
+	}
+	if len(results) != 1 || results[0] {
+		t.Errorf("результаты после пропуска %v, ожидался один неверный ответ", results)
+	}
+}
+
+func TestHandleSkipAdaptsFromUserLevel(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+	if err := db.UpdateUserEnglishLevel(ctx, user.ID, "B1"); err != nil {
+		t.Fatalf("UpdateUserEnglishLevel: %v", err)
+	}
+	user.EnglishLevel = "B1"
+
+	// Все последние ответы неверные: сложность понижается на одну ступень от уровня пользователя
+	for range services.AdaptiveWindow {
+		exercise, err := db.SaveExercise(ctx, database.Exercise{Type: "translation", Level: "A2", Content: "Translate: Я люблю чай.", Answer: "I love tea."})
+		if err != nil {
+			t.Fatalf("SaveExercise: %v", err)
+		}
+		if _, err := db.SaveUserExercise(ctx, database.UserExercise{UserID: user.ID, ExerciseID: exercise.ID, UserAnswer: "tea", IsCorrect: false}); err != nil {
+			t.Fatalf("SaveUserExercise: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Translate: Кошка спит."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	botAPI, _ := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := NewHandler(botAPI, db, openAI)
+	h.SetExerciseService(services.NewExerciseService(openAI))
+	h.SetSessionStore(store)
+
+	// Пропускаем уже пониженное упражнение уровня A2
+	skipped, err := db.SaveExercise(ctx, database.Exercise{Type: "translation", Level: "A2", Content: "Translate: Я пью кофе.", Answer: "I drink coffee."})
+	if err != nil {
+		t.Fatalf("SaveExercise: %v", err)
+	}
+	contextData, _ := json.Marshal(map[string]string{"exerciseType": "translation", "exerciseID": strconv.FormatInt(skipped.ID, 10)})
+	session := &database.UserSession{ID: 1, UserID: user.ID, State: StateExerciseReply, ContextData: contextData}
+
+	h.handleSkip(ctx, 100, user, session)
+
+	saved := store.session(user.ID)
+	var savedContext map[string]string
+	json.Unmarshal(saved.ContextData, &savedContext)
+	exerciseID, _ := strconv.ParseInt(savedContext["exerciseID"], 10, 64)
+	exercise, err := db.GetExerciseByID(ctx, exerciseID)
+	if err != nil || exercise == nil || exercise.ID == skipped.ID {
+		t.Fatalf("после пропуска нет нового упражнения: %v", err)
+	}
+	if exercise.Level != "A2" {
+		t.Errorf("новое упражнение уровня %s, ожидался A2: на ступень ниже уровня пользователя B1", exercise.Level)
+	}
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/services"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// isSpeakingExercise проверяет, что упражнение - на чтение предложения вслух
+func isSpeakingExercise(exercise *database.Exercise) bool {
+	return services.ExerciseType(exercise.Type) == services.ExerciseTypeSpeaking
+}
+
+// generateSpeakingExercise генерирует предложение для чтения вслух и готовит его к сохранению в БД
+func (h *Handler) generateSpeakingExercise(ctx context.Context, level string) (*database.Exercise, error) {
+	exercise, err := h.exerciseService.GenerateSpeakingExercise(ctx, services.EnglishLevel(level))
+	if err != nil {
+		return nil, err
+	}
+
+	return &database.Exercise{
+		Type:    string(exercise.Type),
+		Level:   level,
+		Content: exercise.Content,
+		Answer:  exercise.Answer,
+		Data: database.ExerciseData{
+			Instruction: exercise.Instruction,
+			Prompt:      exercise.Content,
+		},
+	}, nil
+}
+
+// sendSpeakingExercise отправляет предложение, которое нужно прочитать вслух
+func (h *Handler) sendSpeakingExercise(ctx context.Context, chatID int64, exercise *database.Exercise) {
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "speaking.intro", escapeMarkdown(exercise.Content)))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// answerSpeakingExercise оценивает, насколько распознанная речь совпадает с предложением,
+// и сохраняет оценку. Текстовый ответ не принимается: предложение нужно прочитать голосом
+func (h *Handler) answerSpeakingExercise(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, exercise *database.Exercise, transcript string, voice bool) {
+	if !voice {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "speaking.voice_required"))
+		h.bot.Send(msg)
+		return
+	}
+
+	result := services.ScoreSpeaking(exercise.Answer, transcript)
+
+	score := database.SpeakingScore{
+		UserID:     user.ID,
+		ExerciseID: exercise.ID,
+		Target:     exercise.Answer,
+		Transcript: transcript,
+		Score:      result.Score,
+	}
+	if err := h.db.AddSpeakingScore(ctx, score); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения оценки произношения", "error", err)
+	}
+
+	explanation := tr(ctx, "speaking.all_words")
+	if len(result.Missed) > 0 {
+		explanation = tr(ctx, "speaking.missed", strings.Join(result.Missed, ", "))
+	}
+
+	h.finishExercise(ctx, chatID, user, session, exercise, transcript, result.Score, explanation)
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"slices"
+	"testing"
+)
+
+func TestAnswerSpeakingExerciseRequiresVoice(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := &Handler{bot: botAPI, sessions: store}
+	ctx := withLanguage(context.Background(), i18n.Russian)
+
+	session, _ := store.GetOrCreateUserSession(ctx, 1)
+	session.State = StateExerciseReply
+	store.UpdateUserSession(ctx, *session)
+
+	exercise := &database.Exercise{ID: 7, Type: "speaking", Content: "I like tea.", Answer: "I like tea."}
+	h.answerSpeakingExercise(ctx, 100, &database.User{ID: 1}, session, exercise, "I like tea.", false)
+
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.Russian, "speaking.voice_required") {
+		t.Errorf("отправлено %q, ожидалась просьба прочитать вслух", sent)
+	}
+	if state := store.session(1).State; state != StateExerciseReply {
+		t.Errorf("состояние %q, упражнение должно ждать голосового ответа", state)
+	}
+}
+
+func TestAnswerSpeakingExercise(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := withLanguage(context.Background(), i18n.Russian)
+
+	exercise, err := db.SaveExercise(ctx, database.Exercise{Type: "speaking", Level: "A2", Content: "I like green tea.", Answer: "I like green tea."})
+	if err != nil {
+		t.Fatalf("SaveExercise: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		transcript  string
+		wantScore   int
+		explanation string
+	}{
+		{"все слова", "I like green tea", 100, i18n.T(i18n.Russian, "speaking.all_words")},
+		// Из 16 символов "i like green tea" распознаны иначе 3
+		{"слово прозвучало иначе", "I like cream tea", 81, i18n.T(i18n.Russian, "speaking.missed", "green")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestDatabaseUser(t, db)
+			botAPI, stub := newTestBotAPI(t)
+			store := newMemorySessionStore()
+			h := NewHandler(botAPI, db, nil)
+			h.SetSessionStore(store)
+
+			session, _ := store.GetOrCreateUserSession(ctx, user.ID)
+			session.State = StateExerciseReply
+			h.answerSpeakingExercise(ctx, 100, user, session, exercise, tt.transcript, true)
+
+			export, err := db.ExportUserData(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("ExportUserData: %v", err)
+			}
+			if len(export.SpeakingScores) != 1 || export.SpeakingScores[0].Transcript != tt.transcript {
+				t.Fatalf("сохранены оценки произношения %+v", export.SpeakingScores)
+			}
+
+			if score := export.SpeakingScores[0].Score; score != tt.wantScore {
+				t.Errorf("сохранена оценка %d, ожидалось %d", score, tt.wantScore)
+			}
+
+			key := "exercise.incorrect"
+			if tt.wantScore >= passingScore {
+				key = "exercise.correct"
+			}
+			if want := i18n.T(i18n.Russian, key, tt.wantScore, escapeMarkdown(tt.explanation)); !slices.Contains(stub.sent(), want) {
+				t.Errorf("отправлено %q, ожидалось %q", stub.sent(), want)
+			}
+			if state := store.session(user.ID).State; state != StateIdle {
+				t.Errorf("состояние %q, ожидалось %q", state, StateIdle)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Префиксы параметра ссылки t.me/<bot>?start=<параметр>
+const (
+	startPayloadTopic    = "topic_" // topic_<тема> сразу начинает диалог на тему
+	startPayloadReferral = "ref_"   // ref_<telegram_id> записывает пригласившего пользователя
+)
+
+// handleStart приветствует пользователя и обрабатывает параметр глубокой ссылки.
+// Неизвестный или некорректный параметр игнорируется
+func (h *Handler) handleStart(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, payload string) {
+	// Сбрасываем состояние на idle
+	session.State = StateIdle
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	payload = strings.TrimSpace(payload)
+	switch {
+	case strings.HasPrefix(payload, startPayloadTopic):
+		if topic, ok := findChatTopic(strings.TrimPrefix(payload, startPayloadTopic)); ok {
+			h.startChat(ctx, chatID, user, session, topic)
+			return
+		}
+		slog.InfoContext(ctx, "Неизвестная тема в ссылке /start", "payload", payload)
+
+	case strings.HasPrefix(payload, startPayloadReferral):
+		h.recordReferral(ctx, user, strings.TrimPrefix(payload, startPayloadReferral))
+
+	case payload != "":
+		slog.InfoContext(ctx, "Неизвестный параметр ссылки /start", "payload", payload)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "start.welcome"))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// recordReferral записывает пригласившего пользователя по его Telegram ID из ссылки.
+// Ошибки только логируются: приглашение не должно мешать приветствию
+func (h *Handler) recordReferral(ctx context.Context, user *database.User, value string) {
+	telegramID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || telegramID == user.TelegramID {
+		return
+	}
+
+	referrer, err := h.db.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения пригласившего пользователя", "error", err)
+		return
+	}
+	if referrer == nil {
+		return
+	}
+
+	recorded, err := h.db.SetUserReferrer(ctx, user.ID, referrer.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка записи приглашения", "error", err)
+		return
+	}
+	if recorded {
+		slog.InfoContext(ctx, "Пользователь пришел по приглашению", "user_id", user.ID, "referrer_id", referrer.ID)
+	}
+}
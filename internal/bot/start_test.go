@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"strconv"
+	"testing"
+)
+
+func TestHandleStartIgnoresUnusablePayload(t *testing.T) {
+	user := &database.User{ID: 1, TelegramID: 10}
+
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"без параметра", ""},
+		{"пробелы", "   "},
+		{"неизвестный параметр", "promo_spring"},
+		{"неизвестная тема", "topic_sports"},
+		{"некорректный пригласивший", "ref_abc"},
+		{"приглашение самого себя", "ref_" + strconv.FormatInt(user.TelegramID, 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			store := newMemorySessionStore()
+			// Без базы данных: такие параметры не должны к ней обращаться
+			h := &Handler{bot: botAPI, sessions: store}
+
+			session := &database.UserSession{UserID: user.ID, State: StateWriting}
+			h.handleStart(context.Background(), 100, user, session, tt.payload)
+
+			if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "start.welcome") {
+				t.Errorf("отправлено %q, ожидалось приветствие", sent)
+			}
+			if saved := store.session(user.ID); saved.State != StateIdle {
+				t.Errorf("после /start состояние %s, ожидалось %s", saved.State, StateIdle)
+			}
+		})
+	}
+}
+
+func TestHandleStartTopicDeepLink(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+
+	session, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	h.handleCommand(ctx, textUpdate(1, user.TelegramID, "/start topic_travel"), user, session)
+
+	conversation, err := db.GetActiveConversation(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetActiveConversation: %v", err)
+	}
+	if conversation == nil || conversation.Topic != "travel" {
+		t.Fatalf("активный диалог %+v, ожидался диалог о путешествиях", conversation)
+	}
+
+	saved, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	if saved.State != StateChat {
+		t.Errorf("после ссылки состояние %s, ожидался режим чата", saved.State)
+	}
+
+	want := i18n.T(i18n.DefaultLanguage, "chat.started_topic", i18n.T(i18n.DefaultLanguage, "chat.topic.travel"))
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != want {
+		t.Errorf("отправлено %q, ожидалось только начало диалога %q", sent, want)
+	}
+}
+
+func TestHandleStartReferralDeepLink(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	referrer := newTestDatabaseUser(t, db)
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+	h.SetSessionStore(newMemorySessionStore())
+
+	h.handleStart(ctx, 100, user, &database.UserSession{UserID: user.ID}, "ref_"+strconv.FormatInt(referrer.TelegramID, 10))
+
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "start.welcome") {
+		t.Errorf("отправлено %q, ожидалось приветствие", sent)
+	}
+
+	// Пригласивший записывается один раз: повторная запись уже не проходит
+	recorded, err := db.SetUserReferrer(ctx, user.ID, referrer.ID)
+	if err != nil {
+		t.Fatalf("SetUserReferrer: %v", err)
+	}
+	if recorded {
+		t.Error("пригласивший по ссылке не записан")
+	}
+}
@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleTimezone показывает или меняет часовой пояс пользователя.
+// По нему считаются границы суток для серии дней и время уведомлений
+func (h *Handler) handleTimezone(ctx context.Context, chatID int64, user *database.User, args string) {
+	name := strings.TrimSpace(args)
+	if name == "" {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "timezone.current", timezoneName(languageFrom(ctx), user.Timezone))))
+		return
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil || strings.EqualFold(name, "Local") {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "timezone.invalid", name)))
+		return
+	}
+
+	if err := h.db.SetUserTimezone(ctx, user.ID, loc.String()); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения часового пояса", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "timezone.saved", loc.String(), time.Now().In(loc).Format("15:04"))))
+}
+
+// timezoneName возвращает название часового пояса для показа пользователю на языке lang
+func timezoneName(lang, timezone string) string {
+	if timezone == "" {
+		return i18n.T(lang, "timezone.server")
+	}
+	return timezone
+}
@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"testing"
+	"time"
+)
+
+func TestTimezoneName(t *testing.T) {
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"", i18n.T(i18n.Russian, "timezone.server")},
+		{"Europe/Moscow", "Europe/Moscow"},
+	}
+
+	for _, tt := range tests {
+		if got := timezoneName(i18n.Russian, tt.timezone); got != tt.want {
+			t.Errorf("timezoneName(%q) = %q, ожидалось %q", tt.timezone, got, tt.want)
+		}
+	}
+}
+
+func TestHandleTimezoneWithoutChange(t *testing.T) {
+	ctx := withLanguage(context.Background(), i18n.Russian)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"текущий пояс", "  ", i18n.T(i18n.Russian, "timezone.current", "Asia/Almaty")},
+		{"неизвестный пояс", "Mars/Olympus", i18n.T(i18n.Russian, "timezone.invalid", "Mars/Olympus")},
+		{"пояс сервера", "Local", i18n.T(i18n.Russian, "timezone.invalid", "Local")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			h := &Handler{bot: botAPI}
+
+			h.handleTimezone(ctx, 100, &database.User{ID: 1, Timezone: "Asia/Almaty"}, tt.args)
+
+			if sent := stub.sent(); len(sent) != 1 || sent[0] != tt.want {
+				t.Errorf("отправлено %q, ожидалось %q", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleTimezoneSaves(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := withLanguage(context.Background(), i18n.Russian)
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	h := NewHandler(botAPI, db, nil)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("часовой пояс Asia/Tokyo недоступен: %v", err)
+	}
+
+	// Подтверждение показывает местное время, которое может смениться во время вызова
+	before := time.Now()
+	h.handleTimezone(ctx, 100, user, " Asia/Tokyo ")
+	after := time.Now()
+
+	sent := stub.sent()
+	if len(sent) != 1 ||
+		(sent[0] != i18n.T(i18n.Russian, "timezone.saved", "Asia/Tokyo", before.In(tokyo).Format("15:04")) &&
+			sent[0] != i18n.T(i18n.Russian, "timezone.saved", "Asia/Tokyo", after.In(tokyo).Format("15:04"))) {
+		t.Errorf("отправлено %q, ожидалось подтверждение пояса Asia/Tokyo", sent)
+	}
+
+	saved, err := db.GetUserByTelegramID(ctx, user.TelegramID)
+	if err != nil {
+		t.Fatalf("GetUserByTelegramID: %v", err)
+	}
+	if saved.Timezone != "Asia/Tokyo" {
+		t.Errorf("сохранен пояс %q, ожидался Asia/Tokyo", saved.Timezone)
+	}
+}
+
+func TestIsEligibleForReminderInUserTimezone(t *testing.T) {
+	// 19:00 по UTC 20 мая - это 04:00 21 мая в Токио и 09:00 20 мая в Гонолулу
+	now := time.Date(2024, 5, 20, 19, 0, 0, 0, time.UTC)
+	activity := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		timezone string
+		hour     int
+		lastSent time.Time
+		want     bool
+	}{
+		{"в Токио занимался вчера", "Asia/Tokyo", 3, time.Time{}, true},
+		{"в Токио час еще не наступил", "Asia/Tokyo", 5, time.Time{}, false},
+		{"в Токио напоминание отправлено после полуночи", "Asia/Tokyo", 3, time.Date(2024, 5, 20, 15, 30, 0, 0, time.UTC), false},
+		{"в Гонолулу занимался сегодня", "Pacific/Honolulu", 3, time.Time{}, false},
+		{"по UTC занимался сегодня", "UTC", 3, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipient := database.NotificationRecipient{
+				Timezone:         tt.timezone,
+				NotificationHour: tt.hour,
+				LastActivityDate: activity,
+				LastSentAt:       tt.lastSent,
+			}
+			if got := isEligibleForReminder(now, recipient); got != tt.want {
+				t.Errorf("isEligibleForReminder = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
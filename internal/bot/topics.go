@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"slices"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// topicSuggestion - тема, которую /topics предлагает для практики.
+// Кнопки используют данные кнопок /chat и /exercise, поэтому тема запускает тот же диалог или упражнение
+type topicSuggestion struct {
+	LabelKey string // Ключ названия кнопки в каталоге сообщений
+	Data     string // Данные кнопки
+	MinLevel string // Уровень, начиная с которого тема предлагается
+	Chat     bool   // Тема диалога; иначе тема упражнения
+}
+
+// topicSuggestions - все темы в порядке показа
+var topicSuggestions = []topicSuggestion{
+	{LabelKey: "chat.topic.smalltalk", Data: callbackChat + ":smalltalk", MinLevel: "A1", Chat: true},
+	{LabelKey: "chat.topic.food", Data: callbackChat + ":food", MinLevel: "A1", Chat: true},
+	{LabelKey: "chat.topic.travel", Data: callbackChat + ":travel", MinLevel: "A2", Chat: true},
+	{LabelKey: "chat.topic.business", Data: callbackChat + ":business", MinLevel: "B1", Chat: true},
+	{LabelKey: "exercise.topic.articles", Data: callbackGrammarTopic + ":" + string(services.GrammarTopicArticles), MinLevel: "A1"},
+	{LabelKey: "exercise.topic.prepositions", Data: callbackGrammarTopic + ":" + string(services.GrammarTopicPrepositions), MinLevel: "A1"},
+	{LabelKey: "exercise.topic.tenses", Data: callbackGrammarTopic + ":" + string(services.GrammarTopicTenses), MinLevel: "A2"},
+	{LabelKey: "exercise.type.vocabulary", Data: callbackExercise + ":" + string(services.ExerciseTypeVocabulary), MinLevel: "A1"},
+	{LabelKey: "exercise.type.speaking", Data: callbackExercise + ":" + string(services.ExerciseTypeSpeaking), MinLevel: "A1"},
+	{LabelKey: "exercise.type.reading", Data: callbackExercise + ":" + string(services.ExerciseTypeReading), MinLevel: "A2"},
+	{LabelKey: "exercise.type.translation", Data: callbackExercise + ":" + string(services.ExerciseTypeTranslation), MinLevel: "B1"},
+}
+
+// topicsByLevel - темы, доступные на каждом уровне. Список зависит только от уровня,
+// поэтому собирается один раз при запуске
+var topicsByLevel = buildTopicsByLevel()
+
+// buildTopicsByLevel отбирает для каждого уровня темы, минимальный уровень которых не выше его
+func buildTopicsByLevel() map[string][]topicSuggestion {
+	byLevel := make(map[string][]topicSuggestion, len(database.EnglishLevels))
+	for i, level := range database.EnglishLevels {
+		for _, topic := range topicSuggestions {
+			if slices.Index(database.EnglishLevels, topic.MinLevel) <= i {
+				byLevel[level] = append(byLevel[level], topic)
+			}
+		}
+	}
+	return byLevel
+}
+
+// topicsForLevel возвращает темы для уровня level. Неизвестный уровень считается начальным
+func topicsForLevel(level string) []topicSuggestion {
+	if topics, ok := topicsByLevel[level]; ok {
+		return topics
+	}
+	return topicsByLevel[database.EnglishLevels[0]]
+}
+
+// handleTopics показывает темы для диалога и упражнений, подходящие уровню пользователя
+func (h *Handler) handleTopics(ctx context.Context, chatID int64, user *database.User) {
+	topics := topicsForLevel(user.EnglishLevel)
+
+	msg := tgbotapi.NewMessage(chatID, formatTopics(languageFrom(ctx), user.EnglishLevel, topics))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = topicsKeyboard(languageFrom(ctx), topics)
+	h.bot.Send(msg)
+}
+
+// formatTopics форматирует список тем на языке lang: сначала темы диалога, затем упражнений
+func formatTopics(lang, level string, topics []topicSuggestion) string {
+	var chat, exercises strings.Builder
+	for _, topic := range topics {
+		line := "• " + i18n.T(lang, topic.LabelKey) + "\n"
+		if topic.Chat {
+			chat.WriteString(line)
+		} else {
+			exercises.WriteString(line)
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(i18n.T(lang, "topics.title", level) + "\n\n")
+	result.WriteString(i18n.T(lang, "topics.chat") + "\n" + chat.String() + "\n")
+	result.WriteString(i18n.T(lang, "topics.exercises") + "\n" + exercises.String() + "\n")
+	result.WriteString(i18n.T(lang, "topics.footer"))
+
+	return result.String()
+}
+
+// topicsKeyboard создает клавиатуру тем на языке lang, по две в ряд.
+// Темы диалога и упражнений не смешиваются в одном ряду
+func topicsKeyboard(lang string, topics []topicSuggestion) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+
+	for i, topic := range topics {
+		if len(row) > 0 && topic.Chat != topics[i-1].Chat {
+			rows = append(rows, row)
+			row = nil
+		}
+
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, topic.LabelKey), topic.Data))
+
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,232 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// topicData возвращает данные кнопок тем в порядке показа
+func topicData(topics []topicSuggestion) []string {
+	data := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		data = append(data, topic.Data)
+	}
+	return data
+}
+
+func TestTopicsForLevel(t *testing.T) {
+	tests := []struct {
+		level    string
+		includes []string
+		excludes []string
+	}{
+		{"A1", []string{"chat:smalltalk", "grammar:articles", "exercise:vocabulary"}, []string{"chat:travel", "chat:business", "grammar:tenses", "exercise:reading"}},
+		{"A2", []string{"chat:travel", "grammar:tenses", "exercise:reading"}, []string{"chat:business", "exercise:translation"}},
+		{"B1", []string{"chat:business", "exercise:translation"}, nil},
+		{"C2", topicData(topicSuggestions), nil},
+		// Неизвестный уровень считается начальным
+		{"", []string{"chat:smalltalk"}, []string{"chat:travel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			data := topicData(topicsForLevel(tt.level))
+			for _, want := range tt.includes {
+				if !slices.Contains(data, want) {
+					t.Errorf("среди тем %q нет %q", data, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if slices.Contains(data, unwanted) {
+					t.Errorf("среди тем %q есть тема выше уровня %q", data, unwanted)
+				}
+			}
+		})
+	}
+
+	// С ростом уровня темы только добавляются
+	for i := 1; i < len(database.EnglishLevels); i++ {
+		lower, higher := topicData(topicsForLevel(database.EnglishLevels[i-1])), topicData(topicsForLevel(database.EnglishLevels[i]))
+		for _, data := range lower {
+			if !slices.Contains(higher, data) {
+				t.Errorf("тема %q уровня %s пропала на уровне %s", data, database.EnglishLevels[i-1], database.EnglishLevels[i])
+			}
+		}
+	}
+}
+
+func TestTopicSuggestionsStartKnownFlows(t *testing.T) {
+	for _, topic := range topicSuggestions {
+		t.Run(topic.Data, func(t *testing.T) {
+			prefix, payload, _ := strings.Cut(topic.Data, ":")
+
+			var known bool
+			switch prefix {
+			case callbackChat:
+				_, known = findChatTopic(payload)
+			case callbackGrammarTopic:
+				_, known = findGrammarTopicChoice(payload)
+			case callbackExercise:
+				_, known = findExerciseTypeChoice(payload)
+			}
+			if !known {
+				t.Errorf("кнопка %q не запускает известный диалог или упражнение", topic.Data)
+			}
+			if topic.Chat != (prefix == callbackChat) {
+				t.Errorf("тема %q отмечена как тема диалога: %v", topic.Data, topic.Chat)
+			}
+			if !slices.Contains(database.EnglishLevels, topic.MinLevel) {
+				t.Errorf("тема %q с неизвестным уровнем %q", topic.Data, topic.MinLevel)
+			}
+
+			for _, lang := range []string{i18n.Russian, i18n.English} {
+				if _, ok := i18n.Lookup(lang, topic.LabelKey); !ok {
+					t.Errorf("нет перевода %q на %s", topic.LabelKey, lang)
+				}
+			}
+		})
+	}
+}
+
+func TestTopicsKeyboard(t *testing.T) {
+	topics := topicsForLevel("C2")
+	keyboard := topicsKeyboard(i18n.English, topics)
+
+	var data []string
+	for _, row := range keyboard.InlineKeyboard {
+		if len(row) > 2 {
+			t.Errorf("в ряду %d кнопок, ожидалось не больше двух", len(row))
+		}
+		for _, button := range row {
+			data = append(data, *button.CallbackData)
+		}
+
+		// Темы диалога и упражнений не смешиваются в одном ряду
+		chat := strings.HasPrefix(*row[0].CallbackData, callbackChat+":")
+		for _, button := range row[1:] {
+			if strings.HasPrefix(*button.CallbackData, callbackChat+":") != chat {
+				t.Errorf("в одном ряду темы диалога и упражнения: %s, %s", *row[0].CallbackData, *button.CallbackData)
+			}
+		}
+	}
+
+	if want := topicData(topics); !slices.Equal(data, want) {
+		t.Errorf("кнопки %q, ожидалось %q", data, want)
+	}
+}
+
+func TestFormatTopics(t *testing.T) {
+	lang := i18n.Russian
+	message := formatTopics(lang, "A1", topicsForLevel("A1"))
+
+	if !strings.HasPrefix(message, i18n.T(lang, "topics.title", "A1")) {
+		t.Errorf("список тем без заголовка:\n%s", message)
+	}
+
+	// Темы диалога перечислены до упражнений
+	exercises := strings.Index(message, i18n.T(lang, "topics.exercises"))
+	for _, topic := range topicsForLevel("A1") {
+		index := strings.Index(message, "• "+i18n.T(lang, topic.LabelKey))
+		if index < 0 {
+			t.Errorf("в списке нет темы %q:\n%s", topic.LabelKey, message)
+			continue
+		}
+		if (index < exercises) != topic.Chat {
+			t.Errorf("тема %q в чужом разделе:\n%s", topic.LabelKey, message)
+		}
+	}
+}
+
+func TestHandleTopicsButtonStartsFlow(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "I have ___ apple."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	openAI := services.NewOpenAIService("test-key", server.URL)
+
+	tests := []struct {
+		name  string
+		label string
+		check func(t *testing.T, user *database.User, session *database.UserSession)
+	}{
+		{"тема диалога", "chat.topic.food", func(t *testing.T, user *database.User, session *database.UserSession) {
+			conversation, err := db.GetActiveConversation(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetActiveConversation: %v", err)
+			}
+			if conversation == nil || conversation.Topic != "food" || session.State != StateChat {
+				t.Errorf("сессия %s, активный диалог %+v; ожидался диалог на тему food", session.State, conversation)
+			}
+		}},
+		{"тема упражнения", "exercise.topic.articles", func(t *testing.T, user *database.User, session *database.UserSession) {
+			var contextData map[string]string
+			json.Unmarshal(session.ContextData, &contextData)
+			if session.State != StateExerciseReply || contextData["grammarTopic"] != string(services.GrammarTopicArticles) {
+				t.Errorf("сессия %s с контекстом %v, ожидалось упражнение на артикли", session.State, contextData)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestDatabaseUser(t, db)
+			botAPI, stub := newTestBotAPI(t)
+			h := NewHandler(botAPI, db, openAI)
+			h.SetExerciseService(services.NewExerciseService(openAI))
+
+			update := textUpdate(1, user.TelegramID, "/topics")
+			update.Message.From.FirstName = user.FirstName
+			h.HandleUpdate(ctx, update)
+
+			messages := stub.calls("sendMessage")
+			if len(messages) != 1 {
+				t.Fatalf("отправлено %d сообщений, ожидался список тем", len(messages))
+			}
+			var keyboard tgbotapi.InlineKeyboardMarkup
+			if err := json.Unmarshal([]byte(messages[0].Get("reply_markup")), &keyboard); err != nil {
+				t.Fatalf("клавиатура тем не разбирается: %v", err)
+			}
+
+			// Нажимаем кнопку с названием темы
+			label := i18n.T(i18n.English, tt.label)
+			var data string
+			for _, row := range keyboard.InlineKeyboard {
+				for _, button := range row {
+					if button.Text == label {
+						data = *button.CallbackData
+					}
+				}
+			}
+			if data == "" {
+				t.Fatalf("на клавиатуре нет темы %q", label)
+			}
+
+			h.HandleUpdate(ctx, tgbotapi.Update{UpdateID: 2, CallbackQuery: &tgbotapi.CallbackQuery{
+				ID:      "cb",
+				From:    &tgbotapi.User{ID: user.TelegramID, FirstName: user.FirstName},
+				Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: user.TelegramID}},
+				Data:    data,
+			}})
+
+			session, err := db.GetOrCreateUserSession(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetOrCreateUserSession: %v", err)
+			}
+			tt.check(t, user, session)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package bot
+
+import "sync"
+
+// userLocks выдает мьютекс на каждого пользователя, чтобы обновления одного пользователя
+// не читали и не записывали его сессию одновременно.
+// Мьютекс удаляется, когда его больше никто не держит и не ждет
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[int64]*userLock
+}
+
+// userLock - мьютекс пользователя и число обновлений, которые его держат или ждут
+type userLock struct {
+	mu      sync.Mutex
+	waiters int
+}
+
+// newUserLocks создает пустой набор мьютексов пользователей
+func newUserLocks() *userLocks {
+	return &userLocks{locks: make(map[int64]*userLock)}
+}
+
+// lock захватывает мьютекс пользователя и возвращает функцию, которая его освобождает
+func (l *userLocks) lock(userID int64) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[userID]
+	if !ok {
+		lock = &userLock{}
+		l.locks[userID] = lock
+	}
+	lock.waiters++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+
+	return func() {
+		lock.mu.Unlock()
+
+		l.mu.Lock()
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(l.locks, userID)
+		}
+		l.mu.Unlock()
+	}
+}
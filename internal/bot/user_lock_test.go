@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// overlapSessionStore замедляет чтение сессии и отмечает, если сессию пользователя
+// прочитали снова, пока предыдущее обновление еще не записало свои изменения
+type overlapSessionStore struct {
+	*memorySessionStore
+
+	mu       sync.Mutex
+	reading  map[int64]bool
+	overlaps int
+}
+
+func (s *overlapSessionStore) GetOrCreateUserSession(ctx context.Context, userID int64) (*database.UserSession, error) {
+	s.mu.Lock()
+	if s.reading[userID] {
+		s.overlaps++
+	}
+	s.reading[userID] = true
+	s.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	return s.memorySessionStore.GetOrCreateUserSession(ctx, userID)
+}
+
+func (s *overlapSessionStore) UpdateUserSession(ctx context.Context, session database.UserSession) error {
+	s.mu.Lock()
+	s.reading[session.UserID] = false
+	s.mu.Unlock()
+
+	return s.memorySessionStore.UpdateUserSession(ctx, session)
+}
+
+func TestUserLocksSerializeOneUser(t *testing.T) {
+	locks := newUserLocks()
+
+	// Счетчик без синхронизации: гонку за него поймает -race, если мьютекс не работает
+	counter := 0
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock(1)
+			defer unlock()
+
+			value := counter
+			time.Sleep(time.Millisecond)
+			counter = value + 1
+		}()
+	}
+	wg.Wait()
+
+	if counter != 20 {
+		t.Errorf("счетчик %d, ожидалось 20: обновления одного пользователя выполнялись одновременно", counter)
+	}
+	if len(locks.locks) != 0 {
+		t.Errorf("после освобождения осталось %d мьютексов", len(locks.locks))
+	}
+}
+
+func TestUserLocksDoNotBlockOtherUsers(t *testing.T) {
+	locks := newUserLocks()
+
+	unlock := locks.lock(1)
+	defer unlock()
+
+	done := make(chan struct{})
+	go func() {
+		locks.lock(2)()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("мьютекс одного пользователя заблокировал другого")
+	}
+}
+
+func TestHandleUpdateSerializesOneUser(t *testing.T) {
+	db := newTestDatabase(t)
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	store := &overlapSessionStore{memorySessionStore: newMemorySessionStore(), reading: make(map[int64]bool)}
+	h := NewHandler(botAPI, db, nil)
+	h.SetSessionStore(store)
+
+	// Обе команды читают и записывают сессию, сообщения приходят одновременно
+	updates := []string{"/check", "/cancel"}
+	var wg sync.WaitGroup
+	for i, text := range updates {
+		update := textUpdate(i+1, user.TelegramID, text)
+		update.Message.From.FirstName = user.FirstName
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.HandleUpdate(context.Background(), update)
+		}()
+	}
+	wg.Wait()
+
+	if store.overlaps != 0 {
+		t.Errorf("сессию прочитали %d раз до записи предыдущего обновления", store.overlaps)
+	}
+
+	// Настройки пользователя без языка клиента создаются на английском
+	lang := i18n.English
+	sent := stub.sent()
+	if len(sent) != 2 {
+		t.Fatalf("отправлено %q, ожидалось два ответа", sent)
+	}
+
+	// Итоговое состояние соответствует команде, обработанной последней
+	wantState := map[string]string{
+		i18n.T(lang, "check.started"):  StateGrammarCheck,
+		i18n.T(lang, "menu.cancelled"): StateIdle,
+	}
+	if state, ok := wantState[sent[1]]; !ok || store.session(user.ID).State != state {
+		t.Errorf("последний ответ %q, итоговое состояние %q", sent[1], store.session(user.ID).State)
+	}
+	if !slices.Contains(sent, i18n.T(lang, "check.started")) || !slices.Contains(sent, i18n.T(lang, "menu.cancelled")) {
+		t.Errorf("отправлено %q, ожидались ответы на обе команды", sent)
+	}
+}
@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/database"
+	"english-bot/internal/services"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// vocabularyPageSize - количество слов на одной странице /vocab list
+const vocabularyPageSize = 20
+
+// handleVocab обрабатывает команду /vocab и ее подкоманды
+func (h *Handler) handleVocab(ctx context.Context, chatID int64, user *database.User, args string) {
+	subcommand, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+
+	switch subcommand {
+	case "add":
+		h.handleVocabAdd(ctx, chatID, user, rest)
+
+	case "list":
+		page := 1
+		if n, err := strconv.Atoi(strings.TrimSpace(rest)); err == nil && n > 0 {
+			page = n
+		}
+		h.handleVocabList(ctx, chatID, user, page)
+
+	default:
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "vocab.help"))
+		msg.ParseMode = "Markdown"
+		h.bot.Send(msg)
+	}
+}
+
+// handleVocabAdd сохраняет слово с переводом в словарь пользователя
+func (h *Handler) handleVocabAdd(ctx context.Context, chatID int64, user *database.User, args string) {
+	word, translation, ok := strings.Cut(args, " - ")
+	word = strings.ToLower(strings.TrimSpace(word))
+	translation = strings.TrimSpace(translation)
+
+	if !ok || word == "" || translation == "" {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "vocab.add_usage"))
+		h.bot.Send(msg)
+		return
+	}
+
+	_, err := h.db.AddVocabulary(ctx, database.UserVocabulary{
+		UserID:      user.ID,
+		Word:        word,
+		Translation: translation,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка добавления слова", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "vocab.saved", word, translation))
+	h.bot.Send(msg)
+}
+
+// handleVocabList показывает страницу словаря пользователя
+func (h *Handler) handleVocabList(ctx context.Context, chatID int64, user *database.User, page int) {
+	words, err := h.db.ListVocabulary(ctx, user.ID, vocabularyPageSize, (page-1)*vocabularyPageSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения словаря", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	if len(words) == 0 {
+		text := tr(ctx, "vocab.empty")
+		if page > 1 {
+			text = tr(ctx, "vocab.page_empty")
+		}
+		h.bot.Send(tgbotapi.NewMessage(chatID, text))
+		return
+	}
+
+	var result strings.Builder
+	result.WriteString(tr(ctx, "vocab.page_title", page) + "\n\n")
+	for _, word := range words {
+		result.WriteString(fmt.Sprintf("• %s - %s %s\n", word.Word, word.Translation, masteryStars(word.Mastery)))
+	}
+	if len(words) == vocabularyPageSize {
+		result.WriteString("\n" + tr(ctx, "vocab.next_page", page+1))
+	}
+
+	h.bot.Send(tgbotapi.NewMessage(chatID, result.String()))
+}
+
+// masteryStars отображает степень усвоения слова (0-5) звездочками
+func masteryStars(mastery int) string {
+	mastery = max(0, min(mastery, 5))
+	return strings.Repeat("★", mastery) + strings.Repeat("☆", 5-mastery)
+}
+
+// startVocabReview начинает повторение слов, срок которых подошел
+func (h *Handler) startVocabReview(ctx context.Context, chatID int64, user *database.User, session *database.UserSession) {
+	if !h.askNextReviewWord(ctx, chatID, user, session) {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "vocab.nothing_due")))
+	}
+}
+
+// askNextReviewWord задает вопрос по следующему слову для повторения.
+// Возвращает false, если повторять больше нечего
+func (h *Handler) askNextReviewWord(ctx context.Context, chatID int64, user *database.User, session *database.UserSession) bool {
+	words, err := h.db.GetVocabularyForReview(ctx, user.ID, 1)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения слов для повторения", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return true
+	}
+
+	if len(words) == 0 {
+		session.State = StateIdle
+		session.ContextData = []byte("{}")
+		h.sessions.UpdateUserSession(ctx, *session)
+		return false
+	}
+
+	word := words[0]
+
+	// Чередуем направление: на четных степенях усвоения спрашиваем слово по переводу
+	askWord := word.Mastery%2 == 0
+
+	contextJSON, _ := json.Marshal(map[string]string{
+		"reviewWordID": strconv.FormatInt(word.ID, 10),
+		"askWord":      strconv.FormatBool(askWord),
+	})
+	session.State = StateVocabReview
+	session.ContextData = contextJSON
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	var question string
+	if askWord {
+		question = tr(ctx, "vocab.ask_word", escapeMarkdown(word.Translation))
+	} else {
+		question = tr(ctx, "vocab.ask_translation", escapeMarkdown(word.Word))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, question)
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+
+	return true
+}
+
+// handleVocabReviewAnswer проверяет ответ при повторении, переносит слово и задает следующий вопрос
+func (h *Handler) handleVocabReviewAnswer(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, text string) {
+	var contextData map[string]string
+	if err := json.Unmarshal(session.ContextData, &contextData); err != nil {
+		slog.ErrorContext(ctx, "Ошибка разбора контекста сессии", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	wordID, _ := strconv.ParseInt(contextData["reviewWordID"], 10, 64)
+	askWord := contextData["askWord"] == "true"
+
+	word, err := h.db.GetVocabularyByID(ctx, wordID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения слова", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+	if word == nil || word.UserID != user.ID {
+		h.startVocabReview(ctx, chatID, user, session)
+		return
+	}
+
+	expected := word.Translation
+	if askWord {
+		expected = word.Word
+	}
+
+	correct := services.CheckVocabularyAnswer(expected, text)
+	mastery := services.NextMastery(word.Mastery, correct)
+	nextReview := services.ScheduleNextReview(mastery, time.Now())
+
+	if err := h.db.UpdateVocabularyMastery(ctx, word.ID, mastery, nextReview); err != nil {
+		slog.ErrorContext(ctx, "Ошибка обновления степени усвоения", "error", err)
+	}
+
+	if correct {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "vocab.review_correct", word.Word, word.Translation, masteryStars(mastery))))
+	} else {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "vocab.review_incorrect", word.Word, word.Translation)))
+	}
+
+	h.db.UpdateUserStreak(ctx, user.ID)
+
+	if !h.askNextReviewWord(ctx, chatID, user, session) {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "vocab.review_done")))
+	}
+}
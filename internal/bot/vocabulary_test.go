@@ -0,0 +1,22 @@
+package bot
+
+import "testing"
+
+func TestMasteryStars(t *testing.T) {
+	tests := []struct {
+		mastery int
+		want    string
+	}{
+		{0, "☆☆☆☆☆"},
+		{3, "★★★☆☆"},
+		{5, "★★★★★"},
+		{-1, "☆☆☆☆☆"},
+		{7, "★★★★★"},
+	}
+
+	for _, tt := range tests {
+		if got := masteryStars(tt.mastery); got != tt.want {
+			t.Errorf("masteryStars(%d) = %q, ожидалось %q", tt.mastery, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// errVoiceDownload - голосовое сообщение не удалось скачать из Telegram,
+// например файл уже недоступен или пропала сеть. Пользователь может просто отправить его еще раз
+var errVoiceDownload = errors.New("не удалось скачать голосовое сообщение")
+
+// transcribeVoice скачивает голосовое сообщение из Telegram и распознает его через Whisper
+func (h *Handler) transcribeVoice(ctx context.Context, voice *tgbotapi.Voice) (string, error) {
+	audio, err := h.downloadVoice(ctx, voice)
+	if err != nil {
+		return "", err
+	}
+	defer audio.Close()
+
+	// Telegram присылает голосовые сообщения в формате OGG/Opus
+	text, err := h.openAI.TranscribeAudio(ctx, audio, "voice.ogg")
+	if err != nil {
+		return "", fmt.Errorf("ошибка распознавания речи: %w", err)
+	}
+
+	return text, nil
+}
+
+// downloadVoice открывает файл голосового сообщения на серверах Telegram.
+// Любая ошибка загрузки оборачивает errVoiceDownload
+func (h *Handler) downloadVoice(ctx context.Context, voice *tgbotapi.Voice) (io.ReadCloser, error) {
+	fileURL, err := h.bot.GetFileDirectURL(voice.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ошибка получения ссылки на файл: %w", errVoiceDownload, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ошибка создания запроса: %w", errVoiceDownload, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errVoiceDownload, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: статус %d", errVoiceDownload, resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// handlePronounce озвучивает переданный текст и отправляет его голосовым сообщением
+func (h *Handler) handlePronounce(ctx context.Context, chatID int64, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		msg := tgbotapi.NewMessage(chatID, tr(ctx, "pronounce.usage"))
+		h.bot.Send(msg)
+		return
+	}
+
+	recordMsg := tgbotapi.NewChatAction(chatID, tgbotapi.ChatRecordVoice)
+	h.bot.Request(recordMsg)
+
+	audio, err := h.openAI.SynthesizeSpeech(ctx, text, "")
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка синтеза речи", "error", err)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	voice := tgbotapi.NewVoice(chatID, tgbotapi.FileBytes{
+		Name:  "pronunciation.mp3",
+		Bytes: audio,
+	})
+	h.bot.Send(voice)
+}
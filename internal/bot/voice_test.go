@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/i18n"
+	"errors"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestDownloadVoiceFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(stub *telegramStub, cancel context.CancelFunc)
+	}{
+		{"Telegram не выдал ссылку на файл", func(stub *telegramStub, cancel context.CancelFunc) {
+			stub.fail("getFile", "Bad Request: file is too big")
+		}},
+		// Отмененный контекст прерывает загрузку файла до обращения к сети
+		{"файл не скачался", func(stub *telegramStub, cancel context.CancelFunc) {
+			cancel()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botAPI, stub := newTestBotAPI(t)
+			h := &Handler{bot: botAPI}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			tt.prepare(stub, cancel)
+
+			audio, err := h.downloadVoice(ctx, &tgbotapi.Voice{FileID: "voice"})
+			if audio != nil {
+				audio.Close()
+			}
+			if !errors.Is(err, errVoiceDownload) {
+				t.Errorf("downloadVoice вернул ошибку %v, ожидалась errVoiceDownload", err)
+			}
+		})
+	}
+}
+
+func TestHandleUndownloadableVoice(t *testing.T) {
+	db := newTestDatabase(t)
+	user := newTestDatabaseUser(t, db)
+
+	botAPI, stub := newTestBotAPI(t)
+	stub.fail("getFile", "Bad Request: file is too big")
+	store := newMemorySessionStore()
+	h := NewHandler(botAPI, db, nil)
+	h.SetSessionStore(store)
+
+	ctx := context.Background()
+	session, _ := store.GetOrCreateUserSession(ctx, user.ID)
+	session.State = StateGrammarCheck
+	store.UpdateUserSession(ctx, *session)
+
+	update := textUpdate(1, user.TelegramID, "")
+	update.Message.From.FirstName = user.FirstName
+	update.Message.Voice = &tgbotapi.Voice{FileID: "voice", Duration: 3}
+	h.HandleUpdate(ctx, update)
+
+	// Настройки пользователя без языка клиента создаются на английском
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.English, "voice.download_failed") {
+		t.Errorf("отправлено %q, ожидалась просьба отправить голосовое сообщение еще раз", sent)
+	}
+	if state := store.session(user.ID).State; state != StateGrammarCheck {
+		t.Errorf("состояние %q, режим проверки грамматики должен сохраниться", state)
+	}
+}
@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// weeklyReportDay - день недели, в который отправляется еженедельный отчет
+const weeklyReportDay = time.Sunday
+
+// weekStats - показатели пользователя за одну неделю
+type weekStats struct {
+	Exercises int // Ответы на упражнения
+	Correct   int // Верные ответы
+	NewWords  int // Слова, добавленные в словарь
+}
+
+// SuccessRate возвращает процент верных ответов за неделю
+func (s weekStats) SuccessRate() int {
+	if s.Exercises == 0 {
+		return 0
+	}
+	return s.Correct * 100 / s.Exercises
+}
+
+// SendWeeklyReports рассылает подписчикам отчет о прогрессе за неделю.
+// Предназначен для ежечасного запуска планировщиком
+func (h *Handler) SendWeeklyReports(ctx context.Context) {
+	now := time.Now()
+
+	// День отчета наступает в разных часовых поясах в разное время, поэтому
+	// проверяем его с запасом в сутки, а точно - в shouldSendWeeklyReport
+	if !isNearWeeklyReportDay(now) {
+		return
+	}
+
+	recipients, err := h.db.GetUsersForWeeklyReport(ctx, startOfWeek(now).AddDate(0, 0, 1))
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка получения подписчиков еженедельного отчета", "error", err)
+		return
+	}
+
+	for _, recipient := range recipients {
+		localNow := now.In(recipient.Location())
+		if !shouldSendWeeklyReport(localNow, recipient.NotificationHour, recipient.LastSentAt) {
+			continue
+		}
+
+		if err := h.sendWeeklyReport(ctx, recipient, startOfWeek(localNow), localNow); err != nil {
+			slog.ErrorContext(ctx, "Ошибка отправки еженедельного отчета", "user_id", recipient.UserID, "error", err)
+			continue
+		}
+
+		if err := h.db.MarkWeeklyReportSent(ctx, recipient.UserID, now); err != nil {
+			slog.ErrorContext(ctx, "Ошибка отметки отправки еженедельного отчета", "user_id", recipient.UserID, "error", err)
+		}
+	}
+}
+
+// sendWeeklyReport сравнивает текущую неделю с предыдущей и отправляет отчет
+func (h *Handler) sendWeeklyReport(ctx context.Context, recipient database.NotificationRecipient, weekStart, now time.Time) error {
+	current, err := h.weekStats(ctx, recipient.UserID, weekStart, now)
+	if err != nil {
+		return err
+	}
+
+	previous, err := h.weekStats(ctx, recipient.UserID, weekStart.AddDate(0, 0, -7), weekStart)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(recipient.TelegramID,
+		formatWeeklyReport(recipient.UILanguage, current, previous, activeStreakAt(now, recipient)))
+	msg.ParseMode = "Markdown"
+	_, err = h.bot.Send(msg)
+
+	return err
+}
+
+// weekStats собирает показатели пользователя за период [from, to)
+func (h *Handler) weekStats(ctx context.Context, userID int64, from, to time.Time) (weekStats, error) {
+	var stats weekStats
+
+	total, correct, err := h.db.CountExercisesBetween(ctx, userID, from, to)
+	if err != nil {
+		return stats, err
+	}
+
+	newWords, err := h.db.CountVocabularyAddedBetween(ctx, userID, from, to)
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Exercises = total
+	stats.Correct = correct
+	stats.NewWords = newWords
+	return stats, nil
+}
+
+// formatWeeklyReport форматирует отчет за неделю на языке lang
+func formatWeeklyReport(lang string, current, previous weekStats, streak int) string {
+	if current.Exercises == 0 && current.NewWords == 0 {
+		return i18n.T(lang, "weekly.quiet")
+	}
+
+	return i18n.T(lang, "weekly.title",
+		current.Exercises, formatDelta(lang, current.Exercises-previous.Exercises, ""),
+		current.SuccessRate(), formatDelta(lang, current.SuccessRate()-previous.SuccessRate(), "%"),
+		current.NewWords, formatDelta(lang, current.NewWords-previous.NewWords, ""),
+		streak,
+	)
+}
+
+// formatDelta форматирует изменение показателя со знаком, например "+3" или "-5%"
+func formatDelta(lang string, delta int, unit string) string {
+	if delta == 0 {
+		return i18n.T(lang, "weekly.no_change")
+	}
+	return fmt.Sprintf("%+d%s", delta, unit)
+}
+
+// activeStreakAt возвращает текущую серию дней пользователя, если она не прервалась к моменту now
+func activeStreakAt(now time.Time, recipient database.NotificationRecipient) int {
+	if recipient.LastActivityDate.Before(startOfDay(now).AddDate(0, 0, -1)) {
+		return 0
+	}
+	return recipient.CurrentStreak
+}
+
+// isNearWeeklyReportDay проверяет, что хотя бы в одном часовом поясе сейчас может быть день отчета:
+// по времени сервера это день отчета или соседний с ним
+func isNearWeeklyReportDay(now time.Time) bool {
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now, now.AddDate(0, 0, 1)} {
+		if day.Weekday() == weeklyReportDay {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldSendWeeklyReport решает, пора ли отправлять еженедельный отчет:
+// сегодня день отчета, наступил час уведомлений и на этой неделе отчет еще не отправлялся.
+// now передается в часовом поясе пользователя
+func shouldSendWeeklyReport(now time.Time, notificationHour int, lastSentAt time.Time) bool {
+	if now.Weekday() != weeklyReportDay || now.Hour() < notificationHour {
+		return false
+	}
+
+	return lastSentAt.Before(startOfWeek(now))
+}
+
+// startOfWeek возвращает начало недели (понедельник) для указанного времени
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -daysSinceMonday)
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"testing"
+	"time"
+)
+
+func TestWeekStatsSuccessRate(t *testing.T) {
+	tests := []struct {
+		stats weekStats
+		want  int
+	}{
+		{weekStats{}, 0},
+		{weekStats{Exercises: 4, Correct: 3}, 75},
+		{weekStats{Exercises: 3, Correct: 2}, 66},
+		{weekStats{Exercises: 5, Correct: 5, NewWords: 10}, 100},
+	}
+
+	for _, tt := range tests {
+		if got := tt.stats.SuccessRate(); got != tt.want {
+			t.Errorf("SuccessRate(%+v) = %d, ожидалось %d", tt.stats, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDelta(t *testing.T) {
+	tests := []struct {
+		delta int
+		unit  string
+		want  string
+	}{
+		{3, "", "+3"},
+		{-5, "%", "-5%"},
+		{12, "%", "+12%"},
+		{0, "", i18n.T(i18n.Russian, "weekly.no_change")},
+		{0, "%", i18n.T(i18n.Russian, "weekly.no_change")},
+	}
+
+	for _, tt := range tests {
+		if got := formatDelta(i18n.Russian, tt.delta, tt.unit); got != tt.want {
+			t.Errorf("formatDelta(%d, %q) = %q, ожидалось %q", tt.delta, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWeeklyReport(t *testing.T) {
+	noChange := i18n.T(i18n.English, "weekly.no_change")
+
+	tests := []struct {
+		name              string
+		current, previous weekStats
+		streak            int
+		want              string
+	}{
+		{"тихая неделя", weekStats{}, weekStats{Exercises: 10, Correct: 8}, 0, i18n.T(i18n.English, "weekly.quiet")},
+		{
+			"рост по всем показателям",
+			weekStats{Exercises: 10, Correct: 9, NewWords: 5},
+			weekStats{Exercises: 4, Correct: 2, NewWords: 1},
+			6,
+			i18n.T(i18n.English, "weekly.title", 10, "+6", 90, "+40%", 5, "+4", 6),
+		},
+		{
+			"спад и без изменений",
+			weekStats{Exercises: 2, Correct: 1, NewWords: 3},
+			weekStats{Exercises: 8, Correct: 4, NewWords: 3},
+			0,
+			i18n.T(i18n.English, "weekly.title", 2, "-6", 50, noChange, 3, noChange, 0),
+		},
+		{
+			"первая неделя",
+			weekStats{NewWords: 2},
+			weekStats{},
+			1,
+			i18n.T(i18n.English, "weekly.title", 0, noChange, 0, noChange, 2, "+2", 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatWeeklyReport(i18n.English, tt.current, tt.previous, tt.streak); got != tt.want {
+				t.Errorf("formatWeeklyReport =\n%s\nожидалось:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldSendWeeklyReport(t *testing.T) {
+	sunday := time.Date(2024, 5, 19, 19, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		hour       int
+		lastSentAt time.Time
+		want       bool
+	}{
+		{"воскресенье, наступил час", sunday, 18, time.Time{}, true},
+		{"час еще не наступил", sunday, 20, time.Time{}, false},
+		{"не воскресенье", sunday.AddDate(0, 0, -1), 18, time.Time{}, false},
+		{"уже отправлен на этой неделе", sunday, 18, sunday.Add(-30 * time.Minute), false},
+		{"отправлен в понедельник этой недели", sunday, 18, monday.Add(time.Minute), false},
+		{"отправлен на прошлой неделе", sunday, 18, sunday.AddDate(0, 0, -7), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldSendWeeklyReport(tt.now, tt.hour, tt.lastSentAt); got != tt.want {
+				t.Errorf("shouldSendWeeklyReport = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	monday := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+
+	for _, day := range []time.Time{
+		monday,
+		monday.Add(15 * time.Hour),
+		time.Date(2024, 5, 15, 12, 30, 0, 0, time.UTC),
+		time.Date(2024, 5, 19, 23, 59, 0, 0, time.UTC),
+	} {
+		if got := startOfWeek(day); !got.Equal(monday) {
+			t.Errorf("startOfWeek(%s) = %s, ожидался понедельник %s", day, got, monday)
+		}
+	}
+}
+
+func TestIsNearWeeklyReportDay(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want bool
+	}{
+		{time.Date(2024, 5, 18, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 5, 19, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 5, 21, 12, 0, 0, 0, time.UTC), false},
+		{time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if got := isNearWeeklyReportDay(tt.date); got != tt.want {
+			t.Errorf("isNearWeeklyReportDay(%s) = %v, ожидалось %v", tt.date.Weekday(), got, tt.want)
+		}
+	}
+}
+
+func TestActiveStreakAt(t *testing.T) {
+	now := time.Date(2024, 5, 19, 19, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		lastActivity time.Time
+		want         int
+	}{
+		{"занимался сегодня", now.Add(-time.Hour), 5},
+		{"занимался вчера", time.Date(2024, 5, 18, 0, 0, 0, 0, time.UTC), 5},
+		{"серия прервана", time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC), 0},
+		{"ни разу не занимался", time.Time{}, 0},
+	}
+
+	for _, tt := range tests {
+		recipient := database.NotificationRecipient{LastActivityDate: tt.lastActivity, CurrentStreak: 5}
+		if got := activeStreakAt(now, recipient); got != tt.want {
+			t.Errorf("%s: activeStreakAt = %d, ожидалось %d", tt.name, got, tt.want)
+		}
+	}
+}
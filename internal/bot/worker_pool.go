@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// workerQueueSize - сколько обновлений может ждать своей очереди у одного обработчика.
+// Когда очередь заполнена, прием новых обновлений приостанавливается
+const workerQueueSize = 64
+
+// WorkerPool обрабатывает обновления фиксированным числом горутин.
+// Обновления одного пользователя всегда попадают к одному обработчику и выполняются по порядку,
+// а медленный запрос одного пользователя не задерживает остальных, у которых другой обработчик
+type WorkerPool struct {
+	next   UpdateHandler
+	queues []chan tgbotapi.Update
+	wg     sync.WaitGroup
+	ctx    context.Context // Контекст обработки, отменяется, если остановка не дождалась обработчиков
+	cancel context.CancelFunc
+}
+
+// NewWorkerPool создает пул из workers обработчиков и запускает их
+func NewWorkerPool(next UpdateHandler, workers int) *WorkerPool {
+	p := &WorkerPool{
+		next:   next,
+		queues: make([]chan tgbotapi.Update, workers),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
+	for i := range p.queues {
+		p.queues[i] = make(chan tgbotapi.Update, workerQueueSize)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+
+	return p
+}
+
+// Submit передает обновление обработчику его пользователя.
+// Блокируется, пока в очереди обработчика нет места
+func (p *WorkerPool) Submit(update tgbotapi.Update) {
+	p.queues[p.queueIndex(update)] <- update
+}
+
+// Shutdown перестает принимать обновления и ждет, пока обработчики завершат уже полученные,
+// но не дольше timeout. Если время ожидания истекло, отменяет контекст обработки,
+// чтобы прервать запросы к внешним сервисам, и возвращает false.
+// После вызова Shutdown вызывать Submit нельзя
+func (p *WorkerPool) Shutdown(timeout time.Duration) bool {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	defer p.cancel()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// work обрабатывает обновления из очереди, пока она не закрыта.
+// Контекст обработки не связан с приемом обновлений, чтобы при остановке
+// уже начатая обработка могла завершиться за отведенное Shutdown время
+func (p *WorkerPool) work(queue <-chan tgbotapi.Update) {
+	defer p.wg.Done()
+
+	for update := range queue {
+		p.next.HandleUpdate(p.ctx, update)
+	}
+}
+
+// queueIndex выбирает обработчик по пользователю обновления, а если его нет - по чату.
+// Обновления без пользователя и чата обрабатывает первый обработчик
+func (p *WorkerPool) queueIndex(update tgbotapi.Update) int {
+	var id int64
+	if user := update.SentFrom(); user != nil {
+		id = user.ID
+	} else if chat := update.FromChat(); chat != nil {
+		id = chat.ID
+	} else {
+		return 0
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(strconv.FormatInt(id, 10)))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handlerFunc позволяет использовать функцию как UpdateHandler
+type handlerFunc func(ctx context.Context, update tgbotapi.Update)
+
+func (f handlerFunc) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	f(ctx, update)
+}
+
+func TestWorkerPoolRunsUsersInParallel(t *testing.T) {
+	started := make(chan int64, 2)
+	release := make(chan struct{})
+	p := NewWorkerPool(handlerFunc(func(ctx context.Context, update tgbotapi.Update) {
+		started <- update.Message.From.ID
+		<-release
+	}), 4)
+
+	// Пользователи 1 и 2 попадают к разным обработчикам
+	if p.queueIndex(textUpdate(1, 1, "hi")) == p.queueIndex(textUpdate(2, 2, "hi")) {
+		t.Fatal("пользователи 1 и 2 попали к одному обработчику")
+	}
+
+	p.Submit(textUpdate(1, 1, "slow request"))
+	p.Submit(textUpdate(2, 2, "slow request"))
+
+	// Медленный запрос первого пользователя не мешает начать запрос второго
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("запросы двух пользователей не выполняются одновременно")
+		}
+	}
+
+	close(release)
+	if !p.Shutdown(2 * time.Second) {
+		t.Error("пул не остановился после завершения обработки")
+	}
+}
+
+func TestWorkerPoolKeepsUserOrder(t *testing.T) {
+	var mu sync.Mutex
+	handled := make(map[int64][]int)
+	running := make(map[int64]bool)
+
+	p := NewWorkerPool(handlerFunc(func(ctx context.Context, update tgbotapi.Update) {
+		userID := update.Message.From.ID
+
+		mu.Lock()
+		if running[userID] {
+			t.Errorf("обновления пользователя %d обрабатываются одновременно", userID)
+		}
+		running[userID] = true
+		mu.Unlock()
+
+		// Первые обновления обрабатываются дольше последующих
+		time.Sleep(time.Duration(10-update.UpdateID%10) * time.Millisecond)
+
+		mu.Lock()
+		running[userID] = false
+		handled[userID] = append(handled[userID], update.UpdateID)
+		mu.Unlock()
+	}), 4)
+
+	for id := range 20 {
+		for _, userID := range []int64{1, 2, 3} {
+			update := textUpdate(id, userID, "message")
+			update.UpdateID = id
+			p.Submit(update)
+		}
+	}
+
+	if !p.Shutdown(5 * time.Second) {
+		t.Fatal("пул не обработал обновления за 5 секунд")
+	}
+
+	want := make([]int, 20)
+	for i := range want {
+		want[i] = i
+	}
+	for _, userID := range []int64{1, 2, 3} {
+		if !slices.Equal(handled[userID], want) {
+			t.Errorf("обновления пользователя %d обработаны в порядке %v", userID, handled[userID])
+		}
+	}
+}
+
+func TestWorkerPoolShutdownDrainsQueues(t *testing.T) {
+	var handler recordingHandler
+	p := NewWorkerPool(handlerFunc(func(ctx context.Context, update tgbotapi.Update) {
+		time.Sleep(time.Millisecond)
+		handler.HandleUpdate(ctx, update)
+	}), 2)
+
+	for id := range 10 {
+		p.Submit(textUpdate(id, int64(id%3), "message"))
+	}
+
+	if !p.Shutdown(2 * time.Second) {
+		t.Fatal("пул не обработал обновления за 2 секунды")
+	}
+	if handler.count() != 10 {
+		t.Errorf("обработано %d обновлений, ожидалось 10", handler.count())
+	}
+}
+
+func TestWorkerPoolShutdownCancelsAfterTimeout(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	p := NewWorkerPool(handlerFunc(func(ctx context.Context, update tgbotapi.Update) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+	}), 1)
+
+	p.Submit(textUpdate(1, 1, "slow request"))
+	<-started
+
+	if p.Shutdown(10 * time.Millisecond) {
+		t.Error("Shutdown сообщил о завершении, хотя обработка не закончилась")
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Error("контекст обработки не отменен после истечения времени остановки")
+	}
+}
+
+func TestWorkerPoolQueueIndex(t *testing.T) {
+	p := &WorkerPool{queues: make([]chan tgbotapi.Update, 16)}
+
+	callback := tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{ID: "1", From: &tgbotapi.User{ID: 42}}}
+	channelPost := tgbotapi.Update{ChannelPost: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 42}}}
+
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   int
+	}{
+		{"сообщение и нажатие кнопки одного пользователя", callback, p.queueIndex(textUpdate(1, 42, "hi"))},
+		{"без пользователя выбирается по чату", channelPost, p.queueIndex(textUpdate(1, 42, "hi"))},
+		{"без пользователя и чата - первый обработчик", tgbotapi.Update{UpdateID: 1}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := p.queueIndex(tt.update); got != tt.want {
+			t.Errorf("%s: обработчик %d, ожидался %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// writingMinWords - минимальное количество слов в тексте, который имеет смысл оценивать
+const writingMinWords = 15
+
+// handleWrite переводит пользователя в режим письменной работы
+func (h *Handler) handleWrite(ctx context.Context, chatID int64, session *database.UserSession) {
+	session.State = StateWriting
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	msg := tgbotapi.NewMessage(chatID, tr(ctx, "write.started", writingMinWords))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+}
+
+// handleWritingSubmission оценивает письменную работу пользователя по критериям,
+// сохраняет оценки для статистики прогресса и возвращает пользователя в главное меню
+func (h *Handler) handleWritingSubmission(ctx context.Context, chatID int64, user *database.User, session *database.UserSession, text string) {
+	// Слишком короткий текст не оцениваем и ждем новый
+	if len(strings.Fields(text)) < writingMinWords {
+		h.bot.Send(tgbotapi.NewMessage(chatID, tr(ctx, "write.too_short", writingMinWords)))
+		return
+	}
+
+	if h.refuseFlagged(ctx, chatID, text) {
+		return
+	}
+
+	var feedback *services.WritingFeedback
+	var err error
+	h.withProgress(ctx, chatID, tr(ctx, "write.grading"), func() {
+		feedback, err = h.exerciseService.GradeWriting(ctx, text, services.EnglishLevel(user.EnglishLevel))
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка оценки письменной работы", "error", err, "user_id", user.ID)
+		h.sendErrorMessage(ctx, chatID, err)
+		return
+	}
+
+	score := database.WritingScore{
+		UserID:       user.ID,
+		Text:         text,
+		Grammar:      feedback.Grammar,
+		Vocabulary:   feedback.Vocabulary,
+		Coherence:    feedback.Coherence,
+		TaskResponse: feedback.TaskResponse,
+	}
+	if err := h.db.AddWritingScore(ctx, score); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сохранения оценки письменной работы", "error", err, "user_id", user.ID)
+	}
+
+	// Сбрасываем состояние
+	session.State = StateIdle
+	h.sessions.UpdateUserSession(ctx, *session)
+
+	msg := tgbotapi.NewMessage(chatID, formatWritingFeedback(languageFrom(ctx), feedback))
+	msg.ParseMode = "Markdown"
+	h.bot.Send(msg)
+
+	// Обновляем статистику пользователя
+	h.db.UpdateUserStreak(ctx, user.ID)
+}
+
+// formatWritingFeedback форматирует оценку письменной работы на языке lang
+func formatWritingFeedback(lang string, feedback *services.WritingFeedback) string {
+	var b strings.Builder
+	b.WriteString(i18n.T(lang, "write.result",
+		feedback.Grammar, services.WritingMaxScore,
+		feedback.Vocabulary, services.WritingMaxScore,
+		feedback.Coherence, services.WritingMaxScore,
+		feedback.TaskResponse, services.WritingMaxScore,
+		feedback.Average(), services.WritingMaxScore))
+
+	if len(feedback.Suggestions) > 0 {
+		b.WriteString(i18n.T(lang, "write.suggestions"))
+		for _, suggestion := range feedback.Suggestions {
+			b.WriteString(fmt.Sprintf("\n• %s", escapeMarkdown(suggestion)))
+		}
+	}
+
+	b.WriteString(i18n.T(lang, "write.next"))
+	return b.String()
+}
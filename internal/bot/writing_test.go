@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"context"
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"english-bot/internal/services"
+	"strings"
+	"testing"
+)
+
+func TestFormatWritingFeedback(t *testing.T) {
+	feedback := &services.WritingFeedback{
+		Grammar:      7,
+		Vocabulary:   6,
+		Coherence:    8,
+		TaskResponse: 9,
+		Suggestions:  []string{"Use *past simple* for finished actions."},
+	}
+
+	text := formatWritingFeedback(i18n.Russian, feedback)
+
+	for _, want := range []string{
+		i18n.T(i18n.Russian, "write.result", 7, 10, 6, 10, 8, 10, 9, 10, 7.5, 10),
+		i18n.T(i18n.Russian, "write.suggestions"),
+		`• Use \*past simple\* for finished actions.`,
+		i18n.T(i18n.Russian, "write.next"),
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("в отзыве нет %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatWritingFeedbackWithoutSuggestions(t *testing.T) {
+	text := formatWritingFeedback(i18n.English, &services.WritingFeedback{Grammar: 10, Vocabulary: 10, Coherence: 10, TaskResponse: 10})
+
+	if strings.Contains(text, i18n.T(i18n.English, "write.suggestions")) {
+		t.Errorf("отзыв без советов содержит их заголовок:\n%s", text)
+	}
+}
+
+func TestHandleWrite(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	h := &Handler{bot: botAPI}
+	h.SetSessionStore(store)
+
+	session := &database.UserSession{ID: 1, UserID: 1, State: StateIdle, ContextData: []byte("{}")}
+	h.handleWrite(context.Background(), 100, session)
+
+	if saved := store.session(1); saved.State != StateWriting {
+		t.Errorf("состояние %s, ожидалось %s", saved.State, StateWriting)
+	}
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "write.started", writingMinWords) {
+		t.Errorf("отправлено %q, ожидалось приглашение написать текст", sent)
+	}
+}
+
+func TestHandleWritingSubmissionTooShort(t *testing.T) {
+	botAPI, stub := newTestBotAPI(t)
+	store := newMemorySessionStore()
+	// Без сервиса упражнений: короткий текст не должен отправляться на оценку
+	h := &Handler{bot: botAPI}
+	h.SetSessionStore(store)
+
+	session := &database.UserSession{ID: 1, UserID: 1, State: StateWriting, ContextData: []byte("{}")}
+	h.handleWritingSubmission(context.Background(), 100, &database.User{ID: 1, EnglishLevel: "A2"}, session, "I like my city.")
+
+	if sent := stub.sent(); len(sent) != 1 || sent[0] != i18n.T(i18n.DefaultLanguage, "write.too_short", writingMinWords) {
+		t.Errorf("отправлено %q, ожидалась просьба написать текст длиннее", sent)
+	}
+	if session.State != StateWriting {
+		t.Errorf("после короткого текста состояние %s, ожидалось ожидание нового текста", session.State)
+	}
+}
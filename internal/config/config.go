@@ -1 +1,408 @@
 package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Значения по умолчанию для необязательных параметров
+const (
+	DefaultRateLimitWindow     = time.Second      // Минимальный интервал между сообщениями одного пользователя
+	DefaultStaleThreshold      = 2 * time.Minute  // Допустимый возраст сообщений, полученных при запуске
+	DefaultDBConnectAttempts   = 5                // Количество попыток подключения к базе данных при запуске
+	DefaultDBConnectInterval   = 2 * time.Second  // Начальная пауза между попытками подключения
+	DefaultLanguageToolTimeout = 10 * time.Second // Таймаут запросов к LanguageTool
+	DefaultLanguageToolCache   = 1000             // Количество результатов LanguageTool в кэше
+	DefaultLanguageToolTTL     = time.Hour        // Время жизни результата LanguageTool в кэше
+	DefaultOpenAIBreakerErrors = 5                // Ошибки OpenAI подряд, после которых запросы временно отклоняются
+	DefaultOpenAIBreakerPause  = 30 * time.Second // Пауза перед пробным запросом к OpenAI после сбоя
+	DefaultOpenAIContextTokens = 3000             // Бюджет токенов на историю диалога с ChatGPT
+	DefaultOpenAITimeout       = 20 * time.Second // Время на один запрос к OpenAI
+	DefaultExercisePoolSize    = 3                // Заранее сгенерированные упражнения для каждой пары тип/уровень
+	DefaultUpdateWorkers       = 16               // Обработчики обновлений, работающие одновременно
+	DefaultSessionCacheTTL     = time.Hour        // Время жизни сессии в Redis с последнего обращения
+)
+
+// UpdateTimeout - общее время на обработку одного обновления. Запросы к OpenAI
+// выполняются внутри этого времени, поэтому OpenAITimeout должен быть меньше
+const UpdateTimeout = 30 * time.Second
+
+// DefaultCommandRateLimits - минимальные интервалы между вызовами дорогих команд,
+// которые обращаются к OpenAI. Остальные команды и сообщения ограничиваются RateLimitWindow
+var DefaultCommandRateLimits = map[string]time.Duration{
+	"exercise":  5 * time.Second,
+	"practice":  10 * time.Second,
+	"quiz":      10 * time.Second,
+	"hint":      3 * time.Second,
+	"skip":      3 * time.Second,
+	"pronounce": 5 * time.Second,
+}
+
+// Режимы получения обновлений от Telegram
+const (
+	BotModePolling = "polling"
+	BotModeWebhook = "webhook"
+)
+
+// Форматы журнала
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Хранилища ограничений частоты запросов
+const (
+	RateLimitStoreMemory   = "memory"   // В памяти процесса: сбрасывается при перезапуске
+	RateLimitStorePostgres = "postgres" // В базе данных: общее для всех экземпляров бота
+)
+
+// Хранилища сессий пользователей
+const (
+	SessionStorePostgres = "postgres" // Только в базе данных
+	SessionStoreRedis    = "redis"    // В Redis с сохранением в базу данных: быстрее чтение при каждом сообщении
+)
+
+// Config содержит конфигурацию бота
+type Config struct {
+	TelegramToken string
+	OpenAIToken   string
+	OpenAIBaseURL string
+	OpenAIModel   string // Модель ChatGPT по умолчанию
+	GrammarModel  string // Модель для проверки грамматики и оценки текстов, пустая - модель по умолчанию
+	ExerciseModel string // Модель для генерации упражнений, пустая - модель по умолчанию
+	Moderation    bool   // Проверять текст пользователя через API модерации OpenAI
+	DBConnString  string
+	Debug         bool
+	BotMode       string // polling или webhook
+	WebhookURL    string // Публичный адрес сервера, на который Telegram отправляет обновления
+	WebhookSecret string // Секрет для пути вебхука и заголовка X-Telegram-Bot-Api-Secret-Token
+
+	LogLevel  slog.Level // Минимальный уровень записей журнала
+	LogFormat string     // json или text
+
+	RateLimitWindow   time.Duration            // Минимальный интервал между сообщениями одного пользователя
+	CommandRateLimits map[string]time.Duration // Собственные интервалы для отдельных команд: имя команды -> интервал
+	RateLimitStore    string                   // Где хранятся ограничения: memory или postgres
+	StaleThreshold    time.Duration            // Сообщения, отправленные раньше запуска больше чем на это время, игнорируются
+
+	DBConnectAttempts int           // Количество попыток подключения к базе данных при запуске
+	DBConnectInterval time.Duration // Начальная пауза между попытками, удваивается после каждой
+
+	AdminIDs []int64 // Telegram ID пользователей, которым доступны служебные команды
+
+	LanguageToolURL     string        // Адрес сервера LanguageTool, например собственного
+	LanguageToolTimeout time.Duration // Таймаут запросов к LanguageTool
+	LanguageToolCache   int           // Размер кэша результатов LanguageTool, 0 выключает кэш
+	LanguageToolTTL     time.Duration // Время жизни записи в кэше LanguageTool
+
+	OpenAIBreakerErrors int           // Ошибки OpenAI подряд, после которых запросы временно отклоняются; 0 выключает защиту
+	OpenAIBreakerPause  time.Duration // Пауза перед пробным запросом к OpenAI после сбоя
+	OpenAIContextTokens int           // Бюджет токенов на историю диалога; старые реплики сверх него отбрасываются
+	OpenAITimeout       time.Duration // Время на один запрос к OpenAI, меньше общего таймаута обработки обновления
+
+	ExercisePoolSize int // Заранее сгенерированные упражнения для каждой пары тип/уровень, 0 выключает пул
+
+	UpdateWorkers int // Обработчики обновлений, работающие одновременно; обновления одного пользователя идут по порядку
+
+	SessionStore    string        // Где хранятся сессии: postgres или redis
+	RedisURL        string        // Адрес Redis, например redis://localhost:6379/0
+	SessionCacheTTL time.Duration // Время жизни сессии в Redis с последнего обращения
+}
+
+// Load загружает конфигурацию из переменных окружения и проверяет ее.
+// Файл .env необязателен: если он есть, его значения дополняют окружение
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("ошибка загрузки .env файла: %w", err)
+	}
+
+	config := &Config{
+		TelegramToken: os.Getenv("TELEGRAM_TOKEN"),
+		OpenAIToken:   os.Getenv("OPENAI_TOKEN"),
+		OpenAIBaseURL: os.Getenv("OPENAI_BASE_URL"),
+		OpenAIModel:   os.Getenv("OPENAI_MODEL"),
+		GrammarModel:  os.Getenv("OPENAI_MODEL_GRAMMAR"),
+		ExerciseModel: os.Getenv("OPENAI_MODEL_EXERCISE"),
+		Moderation:    os.Getenv("OPENAI_MODERATION") == "true",
+		DBConnString:  os.Getenv("DATABASE_URL"),
+		Debug:         os.Getenv("DEBUG") == "true",
+		BotMode:       getEnvDefault("BOT_MODE", BotModePolling),
+		WebhookURL:    os.Getenv("WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+
+		LogLevel:  parseLogLevel(os.Getenv("LOG_LEVEL")),
+		LogFormat: strings.ToLower(getEnvDefault("LOG_FORMAT", LogFormatJSON)),
+
+		RateLimitWindow: DefaultRateLimitWindow,
+		RateLimitStore:  getEnvDefault("RATE_LIMIT_STORE", RateLimitStoreMemory),
+		StaleThreshold:  DefaultStaleThreshold,
+
+		DBConnectAttempts: DefaultDBConnectAttempts,
+		DBConnectInterval: DefaultDBConnectInterval,
+
+		LanguageToolURL:     os.Getenv("LANGUAGETOOL_URL"),
+		LanguageToolTimeout: DefaultLanguageToolTimeout,
+		LanguageToolCache:   DefaultLanguageToolCache,
+		LanguageToolTTL:     DefaultLanguageToolTTL,
+
+		OpenAIBreakerErrors: DefaultOpenAIBreakerErrors,
+		OpenAIBreakerPause:  DefaultOpenAIBreakerPause,
+		OpenAIContextTokens: DefaultOpenAIContextTokens,
+		OpenAITimeout:       DefaultOpenAITimeout,
+
+		ExercisePoolSize: DefaultExercisePoolSize,
+
+		UpdateWorkers: DefaultUpdateWorkers,
+
+		SessionStore:    getEnvDefault("SESSION_STORE", SessionStorePostgres),
+		RedisURL:        os.Getenv("REDIS_URL"),
+		SessionCacheTTL: DefaultSessionCacheTTL,
+	}
+
+	var err error
+	if config.RateLimitWindow, err = getEnvDuration("RATE_LIMIT_WINDOW", config.RateLimitWindow); err != nil {
+		return nil, err
+	}
+	if config.CommandRateLimits, err = getEnvDurationMap("RATE_LIMIT_COMMANDS", DefaultCommandRateLimits); err != nil {
+		return nil, err
+	}
+	if config.StaleThreshold, err = getEnvDuration("STALE_UPDATE_THRESHOLD", config.StaleThreshold); err != nil {
+		return nil, err
+	}
+	if config.DBConnectAttempts, err = getEnvInt("DB_CONNECT_ATTEMPTS", config.DBConnectAttempts); err != nil {
+		return nil, err
+	}
+	if config.DBConnectInterval, err = getEnvDuration("DB_CONNECT_INTERVAL", config.DBConnectInterval); err != nil {
+		return nil, err
+	}
+	if config.AdminIDs, err = getEnvInt64List("ADMIN_IDS"); err != nil {
+		return nil, err
+	}
+	if config.LanguageToolTimeout, err = getEnvDuration("LANGUAGETOOL_TIMEOUT", config.LanguageToolTimeout); err != nil {
+		return nil, err
+	}
+	if config.LanguageToolCache, err = getEnvInt("LANGUAGETOOL_CACHE_SIZE", config.LanguageToolCache); err != nil {
+		return nil, err
+	}
+	if config.LanguageToolTTL, err = getEnvDuration("LANGUAGETOOL_CACHE_TTL", config.LanguageToolTTL); err != nil {
+		return nil, err
+	}
+	if config.OpenAIBreakerErrors, err = getEnvInt("OPENAI_BREAKER_ERRORS", config.OpenAIBreakerErrors); err != nil {
+		return nil, err
+	}
+	if config.OpenAIBreakerPause, err = getEnvDuration("OPENAI_BREAKER_PAUSE", config.OpenAIBreakerPause); err != nil {
+		return nil, err
+	}
+	if config.OpenAIContextTokens, err = getEnvInt("OPENAI_CONTEXT_TOKENS", config.OpenAIContextTokens); err != nil {
+		return nil, err
+	}
+	if config.OpenAITimeout, err = getEnvDuration("OPENAI_TIMEOUT", config.OpenAITimeout); err != nil {
+		return nil, err
+	}
+	if config.ExercisePoolSize, err = getEnvInt("EXERCISE_POOL_SIZE", config.ExercisePoolSize); err != nil {
+		return nil, err
+	}
+	if config.UpdateWorkers, err = getEnvInt("UPDATE_WORKERS", config.UpdateWorkers); err != nil {
+		return nil, err
+	}
+	if config.SessionCacheTTL, err = getEnvDuration("SESSION_CACHE_TTL", config.SessionCacheTTL); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Validate проверяет, что заданы все обязательные параметры
+func (c *Config) Validate() error {
+	var missing []string
+
+	if c.TelegramToken == "" {
+		missing = append(missing, "TELEGRAM_TOKEN")
+	}
+	if c.OpenAIToken == "" {
+		missing = append(missing, "OPENAI_TOKEN")
+	}
+	if c.DBConnString == "" {
+		missing = append(missing, "DATABASE_URL")
+	}
+
+	switch c.BotMode {
+	case BotModePolling:
+	case BotModeWebhook:
+		if c.WebhookURL == "" {
+			missing = append(missing, "WEBHOOK_URL")
+		}
+		if c.WebhookSecret == "" {
+			missing = append(missing, "WEBHOOK_SECRET")
+		} else if !isValidWebhookSecret(c.WebhookSecret) {
+			// Telegram отклонит такой секрет только при регистрации вебхука, поэтому проверяем при запуске
+			return fmt.Errorf("WEBHOOK_SECRET должен состоять из 1-%d символов A-Z, a-z, 0-9, _ и -", maxWebhookSecretLength)
+		}
+	default:
+		return fmt.Errorf("неизвестный режим BOT_MODE %q: допустимы %s и %s", c.BotMode, BotModePolling, BotModeWebhook)
+	}
+
+	switch c.LogFormat {
+	case LogFormatJSON, LogFormatText:
+	default:
+		return fmt.Errorf("неизвестный формат LOG_FORMAT %q: допустимы %s и %s", c.LogFormat, LogFormatJSON, LogFormatText)
+	}
+
+	switch c.RateLimitStore {
+	case RateLimitStoreMemory, RateLimitStorePostgres:
+	default:
+		return fmt.Errorf("неизвестное хранилище RATE_LIMIT_STORE %q: допустимы %s и %s", c.RateLimitStore, RateLimitStoreMemory, RateLimitStorePostgres)
+	}
+
+	switch c.SessionStore {
+	case SessionStorePostgres:
+	case SessionStoreRedis:
+		if c.RedisURL == "" {
+			missing = append(missing, "REDIS_URL")
+		}
+	default:
+		return fmt.Errorf("неизвестное хранилище SESSION_STORE %q: допустимы %s и %s", c.SessionStore, SessionStorePostgres, SessionStoreRedis)
+	}
+
+	if c.OpenAITimeout >= UpdateTimeout {
+		return fmt.Errorf("OPENAI_TIMEOUT должен быть меньше таймаута обработки обновления %v, получено %v", UpdateTimeout, c.OpenAITimeout)
+	}
+
+	if c.UpdateWorkers < 1 {
+		return fmt.Errorf("UPDATE_WORKERS должно быть не меньше 1, получено %d", c.UpdateWorkers)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("не заданы обязательные переменные окружения: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// maxWebhookSecretLength - наибольшая длина секрета вебхука, которую принимает Telegram
+const maxWebhookSecretLength = 256
+
+// isValidWebhookSecret проверяет, что секрет подходит для заголовка X-Telegram-Bot-Api-Secret-Token:
+// от 1 до 256 символов, только латинские буквы, цифры, _ и -
+func isValidWebhookSecret(secret string) bool {
+	if secret == "" || len(secret) > maxWebhookSecretLength {
+		return false
+	}
+	for _, r := range secret {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLogLevel разбирает уровень журнала debug, info, warn или error без учета регистра.
+// Пустое или неизвестное значение означает info, чтобы опечатка не оставила бота без журнала
+func parseLogLevel(value string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// getEnvDefault возвращает значение переменной окружения или значение по умолчанию
+func getEnvDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration разбирает длительность из переменной окружения или возвращает значение по умолчанию
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("некорректное значение %s %q: ожидается положительная длительность, например 1s или 500ms", key, value)
+	}
+
+	return duration, nil
+}
+
+// getEnvDurationMap разбирает список пар "ключ=длительность", разделенных запятыми, из переменной окружения,
+// например "exercise=10s,practice=30s". Заданные пары дополняют и переопределяют значения по умолчанию
+func getEnvDurationMap(key string, defaults map[string]time.Duration) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration, len(defaults))
+	for name, duration := range defaults {
+		result[name] = duration
+	}
+
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || name == "" || err != nil || duration <= 0 {
+			return nil, fmt.Errorf("некорректное значение %s %q: ожидается список вида exercise=10s,practice=30s", key, part)
+		}
+		result[name] = duration
+	}
+
+	return result, nil
+}
+
+// getEnvInt разбирает неотрицательное целое из переменной окружения или возвращает значение по умолчанию
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("некорректное значение %s %q: ожидается неотрицательное целое число", key, value)
+	}
+
+	return number, nil
+}
+
+// getEnvInt64List разбирает список целых чисел, разделенных запятыми, из переменной окружения.
+// Пустые элементы и пробелы вокруг чисел игнорируются
+func getEnvInt64List(key string) ([]int64, error) {
+	var numbers []int64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		number, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное значение %s %q: ожидается список целых чисел через запятую", key, part)
+		}
+		numbers = append(numbers, number)
+	}
+
+	return numbers, nil
+}
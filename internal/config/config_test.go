@@ -0,0 +1,406 @@
+package config
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig возвращает конфигурацию, которая проходит проверку
+func validConfig() *Config {
+	return &Config{
+		TelegramToken:  "telegram-token",
+		OpenAIToken:    "openai-token",
+		DBConnString:   "postgres://localhost/english_bot",
+		BotMode:        BotModePolling,
+		LogFormat:      LogFormatJSON,
+		RateLimitStore: RateLimitStoreMemory,
+		SessionStore:   SessionStorePostgres,
+		UpdateWorkers:  DefaultUpdateWorkers,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{"корректная конфигурация", func(c *Config) {}, ""},
+		{"нет токена Telegram", func(c *Config) { c.TelegramToken = "" }, "TELEGRAM_TOKEN"},
+		{"нет токена OpenAI", func(c *Config) { c.OpenAIToken = "" }, "OPENAI_TOKEN"},
+		{"нет адреса базы данных", func(c *Config) { c.DBConnString = "" }, "DATABASE_URL"},
+		{"вебхук без адреса и секрета", func(c *Config) { c.BotMode = BotModeWebhook }, "WEBHOOK_URL, WEBHOOK_SECRET"},
+		{"вебхук с адресом и секретом", func(c *Config) {
+			c.BotMode = BotModeWebhook
+			c.WebhookURL = "https://bot.example.com"
+			c.WebhookSecret = "secret"
+		}, ""},
+		{"вебхук с секретом из допустимых символов", func(c *Config) {
+			c.BotMode = BotModeWebhook
+			c.WebhookURL = "https://bot.example.com"
+			c.WebhookSecret = "Secret_token-42"
+		}, ""},
+		{"вебхук с секретом наибольшей длины", func(c *Config) {
+			c.BotMode = BotModeWebhook
+			c.WebhookURL = "https://bot.example.com"
+			c.WebhookSecret = strings.Repeat("a", 256)
+		}, ""},
+		{"вебхук со слишком длинным секретом", func(c *Config) {
+			c.BotMode = BotModeWebhook
+			c.WebhookURL = "https://bot.example.com"
+			c.WebhookSecret = strings.Repeat("a", 257)
+		}, "WEBHOOK_SECRET"},
+		{"вебхук с недопустимыми символами в секрете", func(c *Config) {
+			c.BotMode = BotModeWebhook
+			c.WebhookURL = "https://bot.example.com"
+			c.WebhookSecret = "secret/with spaces"
+		}, "WEBHOOK_SECRET"},
+		{"вебхук с секретом не латиницей", func(c *Config) {
+			c.BotMode = BotModeWebhook
+			c.WebhookURL = "https://bot.example.com"
+			c.WebhookSecret = "секрет"
+		}, "WEBHOOK_SECRET"},
+		{"неизвестный режим", func(c *Config) { c.BotMode = "push" }, "BOT_MODE"},
+		{"неизвестный формат журнала", func(c *Config) { c.LogFormat = "xml" }, "LOG_FORMAT"},
+		{"текстовый журнал", func(c *Config) { c.LogFormat = LogFormatText }, ""},
+		{"сессии в Redis без адреса", func(c *Config) { c.SessionStore = SessionStoreRedis }, "REDIS_URL"},
+		{"сессии в Redis", func(c *Config) {
+			c.SessionStore = SessionStoreRedis
+			c.RedisURL = "redis://localhost:6379/0"
+		}, ""},
+		{"неизвестное хранилище сессий", func(c *Config) { c.SessionStore = "memcached" }, "SESSION_STORE"},
+		{"нет обработчиков обновлений", func(c *Config) { c.UpdateWorkers = 0 }, "UPDATE_WORKERS"},
+		{"один обработчик обновлений", func(c *Config) { c.UpdateWorkers = 1 }, ""},
+		{"таймаут OpenAI меньше таймаута обновления", func(c *Config) { c.OpenAITimeout = UpdateTimeout - time.Second }, ""},
+		{"таймаут OpenAI равен таймауту обновления", func(c *Config) { c.OpenAITimeout = UpdateTimeout }, "OPENAI_TIMEOUT"},
+		{"таймаут OpenAI больше таймаута обновления", func(c *Config) { c.OpenAITimeout = time.Minute }, "OPENAI_TIMEOUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := validConfig()
+			tt.modify(config)
+
+			err := config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, ожидалась ошибка с %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateListsAllMissingVariables(t *testing.T) {
+	err := (&Config{BotMode: BotModePolling, LogFormat: LogFormatJSON, RateLimitStore: RateLimitStoreMemory,
+		SessionStore: SessionStorePostgres, UpdateWorkers: 1}).Validate()
+	if err == nil {
+		t.Fatal("Validate пропустил пустую конфигурацию")
+	}
+
+	for _, name := range []string{"TELEGRAM_TOKEN", "OPENAI_TOKEN", "DATABASE_URL"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("в ошибке %q не упомянута %s", err, name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "telegram-token")
+	t.Setenv("OPENAI_TOKEN", "openai-token")
+	t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if config.TelegramToken != "telegram-token" || config.BotMode != BotModePolling {
+		t.Errorf("загружена конфигурация %+v", config)
+	}
+}
+
+func TestLoadFailsWithoutRequiredVariables(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "")
+	t.Setenv("OPENAI_TOKEN", "openai-token")
+	t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "TELEGRAM_TOKEN") {
+		t.Errorf("Load = %v, ожидалась ошибка про TELEGRAM_TOKEN", err)
+	}
+}
+
+func TestLoadLanguageTool(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "telegram-token")
+	t.Setenv("OPENAI_TOKEN", "openai-token")
+	t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+	t.Setenv("LANGUAGETOOL_URL", "http://languagetool:8010")
+	t.Setenv("LANGUAGETOOL_TIMEOUT", "3s")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if config.LanguageToolURL != "http://languagetool:8010" || config.LanguageToolTimeout != 3*time.Second {
+		t.Errorf("LanguageTool: адрес %q, таймаут %v", config.LanguageToolURL, config.LanguageToolTimeout)
+	}
+}
+
+func TestLoadOpenAITimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultOpenAITimeout, false},
+		{"5s", 5 * time.Second, false},
+		{"soon", 0, true},
+		// Запрос не должен переживать обработку обновления, в которой выполняется
+		{"30s", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("TELEGRAM_TOKEN", "telegram-token")
+			t.Setenv("OPENAI_TOKEN", "openai-token")
+			t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+			t.Setenv("OPENAI_TIMEOUT", tt.value)
+
+			config, err := Load()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "OPENAI_TIMEOUT") {
+					t.Errorf("Load = %v, ожидалась ошибка про OPENAI_TIMEOUT", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if config.OpenAITimeout != tt.want {
+				t.Errorf("таймаут OpenAI %v, ожидалось %v", config.OpenAITimeout, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadUpdateWorkers(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", DefaultUpdateWorkers, false},
+		{"4", 4, false},
+		{"0", 0, true},
+		{"many", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("TELEGRAM_TOKEN", "telegram-token")
+			t.Setenv("OPENAI_TOKEN", "openai-token")
+			t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+			t.Setenv("UPDATE_WORKERS", tt.value)
+
+			config, err := Load()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "UPDATE_WORKERS") {
+					t.Errorf("Load = %v, ожидалась ошибка про UPDATE_WORKERS", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if config.UpdateWorkers != tt.want {
+				t.Errorf("обработчиков обновлений %d, ожидалось %d", config.UpdateWorkers, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 10 * time.Second, false},
+		{"500ms", 500 * time.Millisecond, false},
+		{"2m", 2 * time.Minute, false},
+		{"10", 0, true},
+		{"-1s", 0, true},
+		{"0s", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_DURATION", tt.value)
+		got, err := getEnvDuration("TEST_DURATION", 10*time.Second)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("getEnvDuration(%q) = %v, %v; ожидалось %v, ошибка: %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestGetEnvDurationMap(t *testing.T) {
+	defaults := map[string]time.Duration{"exercise": 5 * time.Second, "quiz": 10 * time.Second}
+
+	tests := []struct {
+		value   string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{"", defaults, false},
+		{"exercise=30s", map[string]time.Duration{"exercise": 30 * time.Second, "quiz": 10 * time.Second}, false},
+		{" hint = 2s , ,practice=1m", map[string]time.Duration{"exercise": 5 * time.Second, "quiz": 10 * time.Second, "hint": 2 * time.Second, "practice": time.Minute}, false},
+		{"exercise", nil, true},
+		{"=5s", nil, true},
+		{"exercise=5", nil, true},
+		{"exercise=0s", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_DURATIONS", tt.value)
+		got, err := getEnvDurationMap("TEST_DURATIONS", defaults)
+		if (err != nil) != tt.wantErr || !maps.Equal(got, tt.want) {
+			t.Errorf("getEnvDurationMap(%q) = %v, %v; ожидалось %v, ошибка: %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if defaults["exercise"] != 5*time.Second {
+		t.Errorf("значения по умолчанию изменились: %v", defaults)
+	}
+}
+
+func TestLoadStaleThreshold(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "telegram-token")
+	t.Setenv("OPENAI_TOKEN", "openai-token")
+	t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if config.StaleThreshold != DefaultStaleThreshold {
+		t.Errorf("порог устаревших сообщений по умолчанию %v, ожидался %v", config.StaleThreshold, DefaultStaleThreshold)
+	}
+
+	t.Setenv("STALE_UPDATE_THRESHOLD", "10m")
+	if config, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if config.StaleThreshold != 10*time.Minute {
+		t.Errorf("порог устаревших сообщений %v, ожидалось 10m", config.StaleThreshold)
+	}
+}
+
+func TestGetEnvInt64List(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []int64
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"123", []int64{123}, false},
+		{" 123, 456 ,,789 ", []int64{123, 456, 789}, false},
+		{"123,abc", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_IDS", tt.value)
+		got, err := getEnvInt64List("TEST_IDS")
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("getEnvInt64List(%q) = %v, %v; ожидалось %v, ошибка: %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadModels(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "telegram-token")
+	t.Setenv("OPENAI_TOKEN", "openai-token")
+	t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+	t.Setenv("OPENAI_MODEL", "gpt-4o-mini")
+	t.Setenv("OPENAI_MODEL_GRAMMAR", "gpt-4o")
+	t.Setenv("OPENAI_MODEL_EXERCISE", "")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if config.OpenAIModel != "gpt-4o-mini" || config.GrammarModel != "gpt-4o" || config.ExerciseModel != "" {
+		t.Errorf("модели: по умолчанию %q, грамматика %q, упражнения %q", config.OpenAIModel, config.GrammarModel, config.ExerciseModel)
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		value string
+		want  slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"info", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{" DEBUG ", slog.LevelDebug},
+		{"", slog.LevelInfo},
+		{"verbose", slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		if got := parseLogLevel(tt.value); got != tt.want {
+			t.Errorf("parseLogLevel(%q) = %v, ожидалось %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLoadLogging(t *testing.T) {
+	tests := []struct {
+		name       string
+		level      string
+		format     string
+		wantLevel  slog.Level
+		wantFormat string
+	}{
+		{"по умолчанию", "", "", slog.LevelInfo, LogFormatJSON},
+		{"отладка в тексте", "debug", "TEXT", slog.LevelDebug, LogFormatText},
+		{"только ошибки", "error", "json", slog.LevelError, LogFormatJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TELEGRAM_TOKEN", "telegram-token")
+			t.Setenv("OPENAI_TOKEN", "openai-token")
+			t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+			t.Setenv("LOG_LEVEL", tt.level)
+			t.Setenv("LOG_FORMAT", tt.format)
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if config.LogLevel != tt.wantLevel || config.LogFormat != tt.wantFormat {
+				t.Errorf("журнал: уровень %v, формат %q; ожидалось %v, %q", config.LogLevel, config.LogFormat, tt.wantLevel, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownLogFormat(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "telegram-token")
+	t.Setenv("OPENAI_TOKEN", "openai-token")
+	t.Setenv("DATABASE_URL", "postgres://localhost/english_bot")
+	t.Setenv("LOG_FORMAT", "xml")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "LOG_FORMAT") {
+		t.Errorf("Load = %v, ожидалась ошибка LOG_FORMAT", err)
+	}
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// AchievementDefinition описывает достижение из каталога
+type AchievementDefinition struct {
+	Type        string // Ключ достижения в user_achievements
+	Title       string
+	Description string
+	Level       string // Уровень для достижений за повышение уровня, иначе пусто
+}
+
+// Пороги достижений за серии дней и выполненные упражнения
+var (
+	StreakMilestones   = []int{7, 30, 100}
+	ExerciseMilestones = []int{10, 50, 100, 500}
+)
+
+// QuizMasterAchievementType - ключ достижения за викторину без ошибок
+const QuizMasterAchievementType = "quiz_master"
+
+// AchievementCatalog - полный список достижений в порядке показа.
+// Выдача и отображение достижений используют только его, чтобы они не расходились
+var AchievementCatalog = buildAchievementCatalog()
+
+// buildAchievementCatalog собирает каталог из порогов и уровней
+func buildAchievementCatalog() []AchievementDefinition {
+	var catalog []AchievementDefinition
+
+	for _, days := range StreakMilestones {
+		catalog = append(catalog, AchievementDefinition{
+			Type:        StreakAchievementType(days),
+			Title:       fmt.Sprintf("🔥 %d-day streak", days),
+			Description: fmt.Sprintf("Practice English %d days in a row", days),
+		})
+	}
+
+	for _, count := range ExerciseMilestones {
+		catalog = append(catalog, AchievementDefinition{
+			Type:        ExerciseAchievementType(count),
+			Title:       fmt.Sprintf("📚 %d exercises", count),
+			Description: fmt.Sprintf("Complete %d exercises", count),
+		})
+	}
+
+	catalog = append(catalog, AchievementDefinition{
+		Type:        QuizMasterAchievementType,
+		Title:       "🎯 Quiz master",
+		Description: "Answer every question of a /quiz correctly",
+	})
+
+	// Для начального уровня достижения нет
+	for _, level := range EnglishLevels[1:] {
+		catalog = append(catalog, AchievementDefinition{
+			Type:        LevelUpAchievementType(level),
+			Title:       fmt.Sprintf("🚀 Level %s", level),
+			Description: fmt.Sprintf("Reach English level %s", level),
+			Level:       level,
+		})
+	}
+
+	return catalog
+}
+
+// StreakAchievementType возвращает ключ достижения за серию дней
+func StreakAchievementType(days int) string {
+	return fmt.Sprintf("streak_%d_days", days)
+}
+
+// ExerciseAchievementType возвращает ключ достижения за количество упражнений
+func ExerciseAchievementType(count int) string {
+	return fmt.Sprintf("exercises_%d", count)
+}
+
+// LevelUpAchievementType возвращает ключ достижения за повышение уровня
+func LevelUpAchievementType(level string) string {
+	return fmt.Sprintf("level_up_%s", level)
+}
+
+// FindAchievement ищет достижение в каталоге по ключу
+func FindAchievement(achievementType string) (AchievementDefinition, bool) {
+	for _, definition := range AchievementCatalog {
+		if definition.Type == achievementType {
+			return definition, true
+		}
+	}
+	return AchievementDefinition{}, false
+}
+
+// AwardAchievement выдает пользователю достижение из каталога, если его еще нет
+func (db *PostgresDB) AwardAchievement(ctx context.Context, userID int64, achievementType string) error {
+	definition, ok := FindAchievement(achievementType)
+	if !ok {
+		return fmt.Errorf("неизвестное достижение: %q", achievementType)
+	}
+
+	return db.AddUserAchievement(ctx, userID, definition.Type, definition.Title, definition.Description)
+}
+
+// GetUserAchievements возвращает достижения пользователя в порядке получения
+func (db *PostgresDB) GetUserAchievements(ctx context.Context, userID int64) ([]UserAchievement, error) {
+	query := `
+		SELECT id, user_id, achievement_type, title, COALESCE(description, ''), unlocked_at
+		FROM user_achievements
+		WHERE user_id = $1
+		ORDER BY unlocked_at ASC, id ASC
+	`
+
+	rows, err := db.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса достижений: %w", err)
+	}
+	defer rows.Close()
+
+	var achievements []UserAchievement
+	for rows.Next() {
+		var achievement UserAchievement
+		if err := rows.Scan(
+			&achievement.ID,
+			&achievement.UserID,
+			&achievement.AchievementType,
+			&achievement.Title,
+			&achievement.Description,
+			&achievement.UnlockedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения достижения: %w", err)
+		}
+		achievements = append(achievements, achievement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения достижений: %w", err)
+	}
+
+	return achievements, nil
+}
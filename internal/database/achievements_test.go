@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAchievementCatalog(t *testing.T) {
+	seen := make(map[string]bool, len(AchievementCatalog))
+	for _, definition := range AchievementCatalog {
+		if seen[definition.Type] {
+			t.Errorf("достижение %q встречается в каталоге дважды", definition.Type)
+		}
+		seen[definition.Type] = true
+
+		if definition.Title == "" || definition.Description == "" {
+			t.Errorf("у достижения %q нет названия или описания", definition.Type)
+		}
+	}
+
+	want := []string{QuizMasterAchievementType, LevelUpAchievementType("C2")}
+	for _, days := range StreakMilestones {
+		want = append(want, StreakAchievementType(days))
+	}
+	for _, count := range ExerciseMilestones {
+		want = append(want, ExerciseAchievementType(count))
+	}
+	for _, achievementType := range want {
+		if !seen[achievementType] {
+			t.Errorf("в каталоге нет достижения %q", achievementType)
+		}
+	}
+
+	if seen[LevelUpAchievementType("A1")] {
+		t.Error("за начальный уровень не должно быть достижения")
+	}
+}
+
+func TestFindAchievement(t *testing.T) {
+	definition, ok := FindAchievement("exercises_100")
+	if !ok || definition.Type != "exercises_100" {
+		t.Errorf("FindAchievement(exercises_100) = %+v, %v", definition, ok)
+	}
+
+	if _, ok := FindAchievement("exercises_42"); ok {
+		t.Error("найдено достижение, которого нет в каталоге")
+	}
+}
+
+func TestAwardAchievementUnknownType(t *testing.T) {
+	// Неизвестное достижение отклоняется до обращения к базе
+	db := &PostgresDB{}
+	if err := db.AwardAchievement(context.Background(), 1, "exercises_42"); err == nil {
+		t.Error("AwardAchievement выдал достижение не из каталога")
+	}
+}
+
+func TestGetUserAchievements(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	order := []string{StreakAchievementType(7), QuizMasterAchievementType, LevelUpAchievementType("A2")}
+	for _, achievementType := range order {
+		if err := db.AwardAchievement(ctx, user.ID, achievementType); err != nil {
+			t.Fatalf("AwardAchievement(%s): %v", achievementType, err)
+		}
+	}
+	// Повторная выдача не создает дубликат
+	if err := db.AwardAchievement(ctx, user.ID, StreakAchievementType(7)); err != nil {
+		t.Fatalf("AwardAchievement: %v", err)
+	}
+
+	achievements, err := db.GetUserAchievements(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserAchievements: %v", err)
+	}
+	if len(achievements) != len(order) {
+		t.Fatalf("получено %d достижений, ожидалось %d", len(achievements), len(order))
+	}
+	for i, achievement := range achievements {
+		if achievement.AchievementType != order[i] {
+			t.Errorf("достижение %d = %q, ожидалось %q", i, achievement.AchievementType, order[i])
+		}
+		if i > 0 && achievement.UnlockedAt.Before(achievements[i-1].UnlockedAt) {
+			t.Errorf("достижение %d получено раньше предыдущего", i)
+		}
+	}
+}
+
+func TestAchievementTypes(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{StreakAchievementType(30), "streak_30_days"},
+		{ExerciseAchievementType(100), "exercises_100"},
+		{LevelUpAchievementType("B2"), "level_up_B2"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("ключ достижения %q, ожидался %q", tt.got, tt.want)
+		}
+	}
+}
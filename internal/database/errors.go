@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrUnavailable возвращается вместе с исходной ошибкой, если база данных недоступна:
+// к серверу не удается подключиться или соединение разорвано
+var ErrUnavailable = errors.New("база данных недоступна")
+
+// connPool - пул соединений, который помечает ошибки подключения как ErrUnavailable.
+// Остальные ошибки, например pgx.ErrNoRows, возвращаются без изменений
+type connPool struct {
+	*pgxpool.Pool
+}
+
+func (p connPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := p.Pool.Exec(ctx, sql, args...)
+	return tag, markUnavailable(err)
+}
+
+func (p connPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := p.Pool.Query(ctx, sql, args...)
+	return rows, markUnavailable(err)
+}
+
+func (p connPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return connRow{p.Pool.QueryRow(ctx, sql, args...)}
+}
+
+func (p connPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := p.Pool.Begin(ctx)
+	return tx, markUnavailable(err)
+}
+
+func (p connPool) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	tx, err := p.Pool.BeginTx(ctx, opts)
+	return tx, markUnavailable(err)
+}
+
+// connRow помечает ошибки подключения при чтении строки результата
+type connRow struct {
+	pgx.Row
+}
+
+func (r connRow) Scan(dest ...any) error {
+	return markUnavailable(r.Row.Scan(dest...))
+}
+
+// markUnavailable добавляет к ошибке подключения ErrUnavailable
+func markUnavailable(err error) error {
+	if err == nil || !isConnectionError(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
+
+// isConnectionError проверяет, что ошибка вызвана недоступностью сервера, а не самим запросом
+func isConnectionError(err error) bool {
+	// Истекший или отмененный контекст запроса не говорит о недоступности сервера.
+	// context.DeadlineExceeded к тому же реализует net.Error и иначе попал бы под проверку ниже
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	// Класс 08 - ошибки соединения, 57P - остановка или перезапуск сервера
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P")
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestMarkUnavailable(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+	}{
+		{"разрыв соединения", &pgconn.PgError{Code: "08006"}, true},
+		{"остановка сервера", &pgconn.PgError{Code: "57P01"}, true},
+		{"сетевая ошибка", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"обернутая сетевая ошибка", fmt.Errorf("ошибка запроса: %w", &net.OpError{Op: "read", Err: errors.New("reset")}), true},
+		{"истекший контекст", fmt.Errorf("ошибка запроса: %w", context.DeadlineExceeded), false},
+		{"истекший контекст при подключении", &net.OpError{Op: "dial", Net: "tcp", Err: context.DeadlineExceeded}, false},
+		{"отмененный контекст", fmt.Errorf("ошибка запроса: %w", context.Canceled), false},
+		{"нарушение уникальности", &pgconn.PgError{Code: "23505"}, false},
+		{"нет строк", pgx.ErrNoRows, false},
+		{"обычная ошибка", errors.New("ошибка разбора"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := markUnavailable(tt.err)
+			if got := errors.Is(err, ErrUnavailable); got != tt.unavailable {
+				t.Errorf("errors.Is(%v, ErrUnavailable) = %v, ожидалось %v", err, got, tt.unavailable)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("исходная ошибка %v потеряна в %v", tt.err, err)
+			}
+		})
+	}
+
+	if err := markUnavailable(nil); err != nil {
+		t.Errorf("markUnavailable(nil) = %v", err)
+	}
+}
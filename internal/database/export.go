@@ -0,0 +1,331 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UserDataExport - все данные пользователя для выгрузки по его запросу
+type UserDataExport struct {
+	ExportedAt     time.Time               `json:"exported_at"`
+	User           ExportedUser            `json:"user"`
+	Settings       *ExportedSettings       `json:"settings"`
+	Progress       *ExportedProgress       `json:"progress"`
+	Achievements   []ExportedAchievement   `json:"achievements"`
+	Vocabulary     []ExportedWord          `json:"vocabulary"`
+	Exercises      []ExportedExercise      `json:"exercises"`
+	WritingScores  []ExportedWritingScore  `json:"writing_scores"`
+	SpeakingScores []ExportedSpeakingScore `json:"speaking_scores"`
+	Conversations  []ExportedConversation  `json:"conversations"`
+	Feedback       []ExportedFeedback      `json:"feedback"`
+}
+
+// ExportedUser - учетная запись пользователя в выгрузке
+type ExportedUser struct {
+	TelegramID   int64     `json:"telegram_id"`
+	Username     string    `json:"username"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	LanguageCode string    `json:"language_code"`
+	EnglishLevel string    `json:"english_level"`
+	Timezone     string    `json:"timezone"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ExportedSettings - настройки пользователя в выгрузке
+type ExportedSettings struct {
+	DailyReminders     bool   `json:"daily_reminders"`
+	UILanguage         string `json:"ui_language"`
+	NotificationHour   int    `json:"notification_hour"`
+	DailyWord          bool   `json:"daily_word"`
+	WeeklyReport       bool   `json:"weekly_report"`
+	LeaderboardVisible bool   `json:"leaderboard_visible"`
+	GrammarEngine      string `json:"grammar_engine"`
+}
+
+// ExportedProgress - прогресс пользователя в выгрузке
+type ExportedProgress struct {
+	TotalExercises     int       `json:"total_exercises"`
+	CorrectExercises   int       `json:"correct_exercises"`
+	TotalConversations int       `json:"total_conversations"`
+	TotalMessages      int       `json:"total_messages"`
+	GrammarCorrections int       `json:"grammar_corrections"`
+	CurrentStreak      int       `json:"current_streak"`
+	LongestStreak      int       `json:"longest_streak"`
+	XP                 int       `json:"xp"`
+	LastActivityDate   time.Time `json:"last_activity_date"`
+}
+
+// ExportedAchievement - полученное достижение в выгрузке
+type ExportedAchievement struct {
+	Type       string    `json:"type"`
+	Title      string    `json:"title"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// ExportedWord - слово словаря в выгрузке
+type ExportedWord struct {
+	Word        string    `json:"word"`
+	Translation string    `json:"translation"`
+	Examples    string    `json:"examples"`
+	Mastery     int       `json:"mastery"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ExportedExercise - попытка выполнения упражнения в выгрузке
+type ExportedExercise struct {
+	Type       string    `json:"type"`
+	Content    string    `json:"content"`
+	Answer     string    `json:"answer"`
+	UserAnswer string    `json:"user_answer"`
+	IsCorrect  bool      `json:"is_correct"`
+	Skipped    bool      `json:"skipped"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExportedWritingScore - оценка письменной работы в выгрузке
+type ExportedWritingScore struct {
+	Text         string    `json:"text"`
+	Grammar      int       `json:"grammar"`
+	Vocabulary   int       `json:"vocabulary"`
+	Coherence    int       `json:"coherence"`
+	TaskResponse int       `json:"task_response"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ExportedSpeakingScore - оценка чтения вслух в выгрузке
+type ExportedSpeakingScore struct {
+	Target     string    `json:"target"`
+	Transcript string    `json:"transcript"`
+	Score      int       `json:"score"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExportedConversation - диалог вместе с сообщениями в выгрузке
+type ExportedConversation struct {
+	ID        int64             `json:"id"`
+	Topic     string            `json:"topic"`
+	Level     string            `json:"level"`
+	Summary   string            `json:"summary"`
+	CreatedAt time.Time         `json:"created_at"`
+	Messages  []ExportedMessage `json:"messages"`
+}
+
+// ExportedMessage - сообщение диалога в выгрузке
+type ExportedMessage struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportedFeedback - отзыв пользователя в выгрузке
+type ExportedFeedback struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportUserData собирает все данные пользователя в одном снимке базы данных.
+// Возвращает nil, если пользователь не найден
+func (db *PostgresDB) ExportUserData(ctx context.Context, userID int64) (*UserDataExport, error) {
+	tx, err := db.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции выгрузки данных: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	export := UserDataExport{ExportedAt: time.Now()}
+
+	err = tx.QueryRow(ctx, `
+		SELECT telegram_id, COALESCE(username, ''), COALESCE(first_name, ''), COALESCE(last_name, ''),
+		       COALESCE(language_code, ''), COALESCE(english_level, ''), COALESCE(timezone, ''), created_at
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(
+		&export.User.TelegramID,
+		&export.User.Username,
+		&export.User.FirstName,
+		&export.User.LastName,
+		&export.User.LanguageCode,
+		&export.User.EnglishLevel,
+		&export.User.Timezone,
+		&export.User.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Пользователь не найден
+		}
+		return nil, fmt.Errorf("ошибка выгрузки пользователя: %w", err)
+	}
+
+	var settings ExportedSettings
+	err = tx.QueryRow(ctx, `
+		SELECT daily_reminders, ui_language, notification_hour, daily_word, weekly_report, leaderboard_visible,
+		       grammar_engine
+		FROM user_settings
+		WHERE user_id = $1
+	`, userID).Scan(
+		&settings.DailyReminders,
+		&settings.UILanguage,
+		&settings.NotificationHour,
+		&settings.DailyWord,
+		&settings.WeeklyReport,
+		&settings.LeaderboardVisible,
+		&settings.GrammarEngine,
+	)
+	if err == nil {
+		export.Settings = &settings
+	} else if err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("ошибка выгрузки настроек: %w", err)
+	}
+
+	var progress ExportedProgress
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(total_exercises, 0), COALESCE(correct_exercises, 0), COALESCE(total_conversations, 0),
+		       COALESCE(total_messages, 0), COALESCE(grammar_corrections, 0), COALESCE(current_streak, 0),
+		       COALESCE(longest_streak, 0), COALESCE(xp, 0), last_activity_date
+		FROM user_progress
+		WHERE user_id = $1
+	`, userID).Scan(
+		&progress.TotalExercises,
+		&progress.CorrectExercises,
+		&progress.TotalConversations,
+		&progress.TotalMessages,
+		&progress.GrammarCorrections,
+		&progress.CurrentStreak,
+		&progress.LongestStreak,
+		&progress.XP,
+		&progress.LastActivityDate,
+	)
+	if err == nil {
+		export.Progress = &progress
+	} else if err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("ошибка выгрузки прогресса: %w", err)
+	}
+
+	export.Achievements, err = queryExport(ctx, tx, `
+		SELECT achievement_type, title, unlocked_at
+		FROM user_achievements
+		WHERE user_id = $1
+		ORDER BY unlocked_at
+	`, userID, func(a *ExportedAchievement) []any {
+		return []any{&a.Type, &a.Title, &a.UnlockedAt}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки достижений: %w", err)
+	}
+
+	export.Vocabulary, err = queryExport(ctx, tx, `
+		SELECT word, COALESCE(translation, ''), COALESCE(examples, ''), COALESCE(mastery, 0), created_at
+		FROM user_vocabulary
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID, func(w *ExportedWord) []any {
+		return []any{&w.Word, &w.Translation, &w.Examples, &w.Mastery, &w.CreatedAt}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки словаря: %w", err)
+	}
+
+	export.Exercises, err = queryExport(ctx, tx, `
+		SELECT e.type, e.content, COALESCE(e.answer, ''), COALESCE(ue.user_answer, ''),
+		       ue.is_correct, ue.skipped, ue.created_at
+		FROM user_exercises ue
+		JOIN exercises e ON e.id = ue.exercise_id
+		WHERE ue.user_id = $1
+		ORDER BY ue.created_at, ue.id
+	`, userID, func(e *ExportedExercise) []any {
+		return []any{&e.Type, &e.Content, &e.Answer, &e.UserAnswer, &e.IsCorrect, &e.Skipped, &e.CreatedAt}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки упражнений: %w", err)
+	}
+
+	export.WritingScores, err = queryExport(ctx, tx, `
+		SELECT text, grammar, vocabulary, coherence, task_response, created_at
+		FROM writing_scores
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID, func(s *ExportedWritingScore) []any {
+		return []any{&s.Text, &s.Grammar, &s.Vocabulary, &s.Coherence, &s.TaskResponse, &s.CreatedAt}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки письменных работ: %w", err)
+	}
+
+	export.SpeakingScores, err = queryExport(ctx, tx, `
+		SELECT target, transcript, score, created_at
+		FROM speaking_scores
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID, func(s *ExportedSpeakingScore) []any {
+		return []any{&s.Target, &s.Transcript, &s.Score, &s.CreatedAt}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки оценок произношения: %w", err)
+	}
+
+	export.Conversations, err = queryExport(ctx, tx, `
+		SELECT id, COALESCE(topic, ''), COALESCE(level, ''), COALESCE(summary, ''), created_at
+		FROM conversations
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID, func(c *ExportedConversation) []any {
+		return []any{&c.ID, &c.Topic, &c.Level, &c.Summary, &c.CreatedAt}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки диалогов: %w", err)
+	}
+
+	for i := range export.Conversations {
+		export.Conversations[i].Messages, err = queryExport(ctx, tx, `
+			SELECT role, content, created_at
+			FROM conversation_messages
+			WHERE conversation_id = $1
+			ORDER BY created_at, id
+		`, export.Conversations[i].ID, func(m *ExportedMessage) []any {
+			return []any{&m.Role, &m.Content, &m.CreatedAt}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка выгрузки сообщений диалога: %w", err)
+		}
+	}
+
+	export.Feedback, err = queryExport(ctx, tx, `
+		SELECT text, created_at
+		FROM feedback
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID, func(f *ExportedFeedback) []any {
+		return []any{&f.Text, &f.CreatedAt}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки отзывов: %w", err)
+	}
+
+	return &export, nil
+}
+
+// queryExport выполняет запрос раздела выгрузки и читает строки в срез.
+// fields возвращает поля записи в порядке столбцов запроса.
+// Пустой раздел возвращается как пустой срез, чтобы в JSON он был [], а не null
+func queryExport[T any](ctx context.Context, tx pgx.Tx, query string, id int64, fields func(*T) []any) ([]T, error) {
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		var item T
+		if err := rows.Scan(fields(&item)...); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
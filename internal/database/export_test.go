@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExportUserData(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	if _, err := db.CreateUserSettings(ctx, user.ID); err != nil {
+		t.Fatalf("CreateUserSettings: %v", err)
+	}
+	if err := db.AwardAchievement(ctx, user.ID, QuizMasterAchievementType); err != nil {
+		t.Fatalf("AwardAchievement: %v", err)
+	}
+	if _, err := db.AddVocabulary(ctx, UserVocabulary{UserID: user.ID, Word: "journey", Translation: "путешествие"}); err != nil {
+		t.Fatalf("AddVocabulary: %v", err)
+	}
+	saveTestAnswers(t, db, user.ID, "grammar", 1, 1)
+	if err := db.AddWritingScore(ctx, WritingScore{UserID: user.ID, Text: "My weekend.", Grammar: 7, Vocabulary: 6, Coherence: 8, TaskResponse: 7}); err != nil {
+		t.Fatalf("AddWritingScore: %v", err)
+	}
+	exercise := newTestExercise(t, db, "speaking")
+	if err := db.AddSpeakingScore(ctx, SpeakingScore{UserID: user.ID, ExerciseID: exercise.ID, Target: "Hello there.", Transcript: "Hello there.", Score: 100}); err != nil {
+		t.Fatalf("AddSpeakingScore: %v", err)
+	}
+	conversation, err := db.StartConversation(ctx, user.ID, "travel", "A1")
+	if err != nil {
+		t.Fatalf("StartConversation: %v", err)
+	}
+	if _, err := db.AddConversationMessage(ctx, ConversationMessage{ConversationID: conversation.ID, Role: "user", Content: "I like trains."}); err != nil {
+		t.Fatalf("AddConversationMessage: %v", err)
+	}
+	if err := db.SaveFeedback(ctx, user.ID, "Great bot!"); err != nil {
+		t.Fatalf("SaveFeedback: %v", err)
+	}
+
+	export, err := db.ExportUserData(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ExportUserData: %v", err)
+	}
+	if export == nil {
+		t.Fatal("выгрузка существующего пользователя пуста")
+	}
+
+	if export.User.TelegramID != user.TelegramID || export.User.FirstName != "Test" {
+		t.Errorf("пользователь в выгрузке %+v", export.User)
+	}
+	if export.Settings == nil {
+		t.Error("в выгрузке нет настроек")
+	}
+	if export.Progress == nil || export.Progress.TotalExercises != 2 || export.Progress.CorrectExercises != 1 {
+		t.Errorf("прогресс в выгрузке %+v, ожидалось 2 упражнения, 1 правильное", export.Progress)
+	}
+
+	sections := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"достижения", len(export.Achievements), 1},
+		{"словарь", len(export.Vocabulary), 1},
+		{"упражнения", len(export.Exercises), 2},
+		{"письменные работы", len(export.WritingScores), 1},
+		{"произношение", len(export.SpeakingScores), 1},
+		{"диалоги", len(export.Conversations), 1},
+		{"отзывы", len(export.Feedback), 1},
+	}
+	for _, section := range sections {
+		// Достижения за упражнения могут добавиться к выданному вручную
+		if section.got < section.want || (section.name != "достижения" && section.got != section.want) {
+			t.Errorf("раздел %q: %d записей, ожидалось %d", section.name, section.got, section.want)
+		}
+	}
+
+	if len(export.Conversations) == 1 {
+		messages := export.Conversations[0].Messages
+		if export.Conversations[0].Topic != "travel" || len(messages) != 1 || messages[0].Content != "I like trains." {
+			t.Errorf("диалог в выгрузке %+v", export.Conversations[0])
+		}
+	}
+	if len(export.Vocabulary) == 1 && export.Vocabulary[0].Word != "journey" {
+		t.Errorf("слово в выгрузке %+v", export.Vocabulary[0])
+	}
+}
+
+func TestExportUserDataUnknownUser(t *testing.T) {
+	db := newTestDB(t)
+
+	export, err := db.ExportUserData(context.Background(), -1)
+	if err != nil {
+		t.Fatalf("ExportUserData: %v", err)
+	}
+	if export != nil {
+		t.Errorf("выгрузка несуществующего пользователя %+v, ожидался nil", export)
+	}
+}
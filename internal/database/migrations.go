@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationsFS содержит SQL-файлы миграций вида NNN_описание.sql
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationsLockID - ключ advisory-блокировки, чтобы несколько экземпляров
+// бота не применяли миграции одновременно
+const migrationsLockID = 7_301_286
+
+// migration описывает одну версию схемы
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate применяет к базе данных все еще не примененные миграции.
+// Каждая миграция выполняется в отдельной транзакции и записывается в schema_migrations,
+// поэтому повторный запуск ничего не меняет
+func (db *PostgresDB) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	createQuery := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`
+	if _, err := db.pool.Exec(ctx, createQuery); err != nil {
+		return fmt.Errorf("ошибка создания таблицы миграций: %w", err)
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		ok, err := db.applyMigration(ctx, m)
+		if err != nil {
+			return err
+		}
+		if ok {
+			slog.InfoContext(ctx, "Применена миграция", "version", m.version, "name", m.name)
+			applied++
+		}
+	}
+
+	slog.InfoContext(ctx, "Схема базы данных актуальна", "applied", applied, "total", len(migrations))
+
+	return nil
+}
+
+// applyMigration применяет миграцию, если она еще не была применена.
+// Возвращает true, если миграция была выполнена
+func (db *PostgresDB) applyMigration(ctx context.Context, m migration) (bool, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ошибка начала транзакции миграции %d: %w", m.version, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationsLockID); err != nil {
+		return false, fmt.Errorf("ошибка блокировки миграций: %w", err)
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)", m.version).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки миграции %d: %w", m.version, err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, m.sql); err != nil {
+		return false, fmt.Errorf("ошибка применения миграции %d (%s): %w", m.version, m.name, err)
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.version, m.name)
+	if err != nil {
+		return false, fmt.Errorf("ошибка записи миграции %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("ошибка фиксации миграции %d: %w", m.version, err)
+	}
+
+	return true, nil
+}
+
+// loadMigrations читает встроенные миграции и сортирует их по версии
+func loadMigrations() ([]migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения списка миграций: %w", err)
+	}
+
+	migrations := make([]migration, 0, len(files))
+	seen := make(map[int]string, len(files))
+	for _, file := range files {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+		versionPart, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionPart)
+		if !found || err != nil {
+			return nil, fmt.Errorf("некорректное имя файла миграции %q: ожидается NNN_описание.sql", file)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("повторяющаяся версия миграции %d: %s и %s", version, other, file)
+		}
+		seen[version] = file
+
+		content, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения миграции %q: %w", file, err)
+		}
+
+		migrations = append(migrations, migration{
+			version: version,
+			name:    name,
+			sql:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
@@ -1,4 +1,4 @@
--- Миграция 001 - Создание основных таблиц
+-- Создание основных таблиц
 
 -- Таблица пользователей
 CREATE TABLE IF NOT EXISTS users (
@@ -107,11 +107,11 @@ CREATE TABLE IF NOT EXISTS user_vocabulary (
     );
 
 -- Создание индексов для улучшения производительности
-CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
-CREATE INDEX idx_user_exercises_user_id ON user_exercises(user_id);
-CREATE INDEX idx_user_exercises_exercise_id ON user_exercises(exercise_id);
-CREATE INDEX idx_conversations_user_id ON conversations(user_id);
-CREATE INDEX idx_conversation_messages_conversation_id ON conversation_messages(conversation_id);
-CREATE INDEX idx_user_vocabulary_user_id ON user_vocabulary(user_id);
-CREATE INDEX idx_user_vocabulary_word ON user_vocabulary(word);
-CREATE INDEX idx_user_achievements_user_id ON user_achievements(user_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_exercises_user_id ON user_exercises(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_exercises_exercise_id ON user_exercises(exercise_id);
+CREATE INDEX IF NOT EXISTS idx_conversations_user_id ON conversations(user_id);
+CREATE INDEX IF NOT EXISTS idx_conversation_messages_conversation_id ON conversation_messages(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_user_vocabulary_user_id ON user_vocabulary(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_vocabulary_word ON user_vocabulary(word);
+CREATE INDEX IF NOT EXISTS idx_user_achievements_user_id ON user_achievements(user_id);
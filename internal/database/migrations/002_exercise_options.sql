@@ -0,0 +1,2 @@
+-- Варианты ответов для упражнений с выбором
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS options TEXT[];
@@ -0,0 +1,10 @@
+-- Настройки пользователя
+CREATE TABLE IF NOT EXISTS user_settings (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    daily_reminders BOOLEAN NOT NULL DEFAULT false,
+    ui_language VARCHAR(10) NOT NULL DEFAULT 'en',
+    notification_hour INT NOT NULL DEFAULT 9, -- 0-23
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+    );
@@ -0,0 +1,3 @@
+-- Подписка на слово дня
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS daily_word BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS last_daily_word_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Напоминания о занятиях
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS last_reminder_sent TIMESTAMP;
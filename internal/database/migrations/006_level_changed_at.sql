@@ -0,0 +1,2 @@
+-- Время последнего изменения уровня, чтобы не повышать уровень повторно сразу после смены
+ALTER TABLE users ADD COLUMN IF NOT EXISTS level_changed_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Участие в таблице лидеров: пользователь может скрыть себя из рейтинга
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS leaderboard_visible BOOLEAN NOT NULL DEFAULT true;
@@ -0,0 +1,2 @@
+-- Пропущенные упражнения: учитываются в статистике навыков, но не в счетчиках прогресса
+ALTER TABLE user_exercises ADD COLUMN IF NOT EXISTS skipped BOOLEAN NOT NULL DEFAULT false;
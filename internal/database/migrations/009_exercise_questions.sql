@@ -0,0 +1,2 @@
+-- Структурированные вопросы упражнений на чтение: JSON-массив вопросов с ответами
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS questions JSONB;
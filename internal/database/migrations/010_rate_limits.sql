@@ -0,0 +1,11 @@
+-- Ограничения частоты запросов, общие для всех экземпляров бота.
+-- Пустая команда - общее окно для обычных сообщений и команд без собственного окна
+CREATE TABLE IF NOT EXISTS rate_limits (
+    telegram_id BIGINT NOT NULL,
+    command VARCHAR(64) NOT NULL DEFAULT '',
+    last_request TIMESTAMP NOT NULL,
+    last_notice TIMESTAMP,
+    PRIMARY KEY (telegram_id, command)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_last_request ON rate_limits(last_request);
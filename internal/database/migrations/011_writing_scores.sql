@@ -0,0 +1,13 @@
+-- Оценки письменных работ по критериям, чтобы отслеживать прогресс в письме
+CREATE TABLE IF NOT EXISTS writing_scores (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    grammar INT NOT NULL, -- 0-10
+    vocabulary INT NOT NULL, -- 0-10
+    coherence INT NOT NULL, -- 0-10
+    task_response INT NOT NULL, -- 0-10
+    created_at TIMESTAMP NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_writing_scores_user_created ON writing_scores(user_id, created_at DESC);
@@ -0,0 +1,4 @@
+-- Итоги завершенных диалогов: разбор частых ошибок ученика
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS summary TEXT;
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS mistakes INT NOT NULL DEFAULT 0;
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS ended_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Объяснение правила, проверяемого упражнением. Запрашивается у OpenAI по кнопке и кэшируется
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS explanation TEXT;
@@ -0,0 +1,3 @@
+-- Подписка на еженедельный отчет о прогрессе
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS weekly_report BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS last_weekly_report_at TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Пригласивший пользователь, записанный по ссылке t.me/<bot>?start=ref_<telegram_id>
+ALTER TABLE users ADD COLUMN IF NOT EXISTS referred_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_users_referred_by ON users(referred_by);
@@ -0,0 +1,2 @@
+-- Тема грамматического упражнения (tenses, articles, prepositions), пустая если тема не выбиралась
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS topic VARCHAR(50);
@@ -0,0 +1,2 @@
+-- Признак упражнения, сгенерированного локально без OpenAI
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS offline BOOLEAN DEFAULT false;
@@ -0,0 +1,2 @@
+-- Способ проверки грамматики: ai, languagetool или both
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS grammar_engine VARCHAR(20) NOT NULL DEFAULT 'both';
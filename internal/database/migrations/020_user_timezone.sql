@@ -0,0 +1,2 @@
+-- Часовой пояс пользователя (имя из базы IANA), пустой - время сервера
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
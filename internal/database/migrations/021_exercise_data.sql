@@ -0,0 +1,18 @@
+-- Структурированное содержание упражнения: инструкция, задание, варианты, допустимые ответы и объяснение
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS data JSONB;
+
+-- У сохраненных ранее упражнений инструкция не отделена от задания, поэтому переносим текст целиком,
+-- а ответ разбиваем на допустимые варианты по "/"
+UPDATE exercises
+SET data = jsonb_build_object(
+    'prompt', content,
+    'options', to_jsonb(COALESCE(options, '{}')),
+    'answers', COALESCE(
+        (SELECT jsonb_agg(btrim(variant))
+         FROM unnest(string_to_array(answer, '/')) AS variant
+         WHERE btrim(variant) <> ''),
+        '[]'::jsonb
+    ),
+    'explanation', COALESCE(explanation, '')
+)
+WHERE data IS NULL;
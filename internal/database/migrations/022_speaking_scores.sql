@@ -0,0 +1,12 @@
+-- Оценки чтения предложений вслух, чтобы отслеживать прогресс в произношении
+CREATE TABLE IF NOT EXISTS speaking_scores (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exercise_id BIGINT REFERENCES exercises(id) ON DELETE SET NULL,
+    target TEXT NOT NULL,
+    transcript TEXT NOT NULL,
+    score INT NOT NULL, -- 0-100
+    created_at TIMESTAMP NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_speaking_scores_user_created ON speaking_scores(user_id, created_at DESC);
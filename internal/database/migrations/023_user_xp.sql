@@ -0,0 +1,2 @@
+-- Очки опыта, которые начисляются за викторины /quiz
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS xp INT DEFAULT 0;
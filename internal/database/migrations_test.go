@@ -0,0 +1,30 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("встроенные миграции не найдены")
+	}
+
+	// Версии идут по порядку без пропусков, начиная с 1
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("миграция %q имеет версию %d, ожидалась %d", m.name, m.version, i+1)
+		}
+		if m.name == "" || strings.TrimSpace(m.sql) == "" {
+			t.Errorf("миграция %d без имени или пустая", m.version)
+		}
+	}
+
+	if migrations[0].name != "init" || !strings.Contains(migrations[0].sql, "CREATE TABLE") {
+		t.Errorf("первая миграция %q не создает схему", migrations[0].name)
+	}
+}
@@ -4,6 +4,19 @@ import (
 	"time"
 )
 
+// EnglishLevels - допустимые уровни владения английским в порядке возрастания
+var EnglishLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// IsValidEnglishLevel проверяет, что уровень входит в список допустимых
+func IsValidEnglishLevel(level string) bool {
+	for _, known := range EnglishLevels {
+		if level == known {
+			return true
+		}
+	}
+	return false
+}
+
 // User представляет пользователя бота
 type User struct {
 	ID           int64     `db:"id"`
@@ -13,6 +26,7 @@ type User struct {
 	LastName     string    `db:"last_name"`
 	LanguageCode string    `db:"language_code"`
 	EnglishLevel string    `db:"english_level"` // A1, A2, B1, B2, C1, C2
+	Timezone     string    `db:"timezone"`      // Часовой пояс из базы IANA, пустой - время сервера
 	CreatedAt    time.Time `db:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at"`
 }
@@ -23,7 +37,7 @@ type UserSession struct {
 	UserID         int64     `db:"user_id"`
 	State          string    `db:"state"`           // chat, exercise, grammar_check и т.д.
 	ContextData    []byte    `db:"context_data"`    // JSON с контекстными данными
-	ConversationID string    `db:"conversation_id"` // ID текущего разговора
+	ConversationID string    `db:"conversation_id"` // ID текущего разговора, пустой если разговора нет
 	LastActivity   time.Time `db:"last_activity"`
 	CreatedAt      time.Time `db:"created_at"`
 	UpdatedAt      time.Time `db:"updated_at"`
@@ -31,12 +45,36 @@ type UserSession struct {
 
 // Exercise представляет упражнение
 type Exercise struct {
-	ID        int64     `db:"id"`
-	Type      string    `db:"type"`    // grammar, vocabulary, translation и т.д.
-	Level     string    `db:"level"`   // A1, A2, B1, B2, C1, C2
-	Content   string    `db:"content"` // Содержание упражнения
-	Answer    string    `db:"answer"`  // Правильный ответ или ключ
-	CreatedAt time.Time `db:"created_at"`
+	ID          int64        `db:"id"`
+	Type        string       `db:"type"`        // grammar, vocabulary, translation и т.д.
+	Level       string       `db:"level"`       // A1, A2, B1, B2, C1, C2
+	Content     string       `db:"content"`     // Содержание упражнения
+	Answer      string       `db:"answer"`      // Правильный ответ или ключ
+	Options     []string     `db:"options"`     // Варианты ответов для упражнений с выбором
+	Questions   []byte       `db:"questions"`   // JSON с вопросами к тексту для упражнений на чтение
+	Explanation string       `db:"explanation"` // Объяснение проверяемого правила, пустое пока не запрошено
+	Topic       string       `db:"topic"`       // Тема грамматического упражнения, пустая если не выбиралась
+	Offline     bool         `db:"offline"`     // Упражнение сгенерировано локально, потому что OpenAI был недоступен
+	Data        ExerciseData `db:"data"`        // Структурированное содержание, по которому упражнение проверяется и показывается заново
+	CreatedAt   time.Time    `db:"created_at"`
+}
+
+// ExerciseData - структурированное содержание упражнения, хранится в JSONB.
+// Незаполненные при сохранении поля берутся из Content, Options, Answer и Explanation упражнения
+type ExerciseData struct {
+	Instruction string   `json:"instruction,omitempty"` // Инструкция, пустая если она входит в задание
+	Prompt      string   `json:"prompt"`                // Задание без инструкции
+	Options     []string `json:"options,omitempty"`     // Варианты ответа для упражнений с выбором
+	Answers     []string `json:"answers,omitempty"`     // Все допустимые ответы
+	Explanation string   `json:"explanation,omitempty"` // Объяснение проверяемого правила
+}
+
+// StaleSession - сессия, в которой пользователь давно ничего не отправлял
+type StaleSession struct {
+	UserSession
+	TelegramID   int64
+	LanguageCode string // Язык клиента Telegram
+	UILanguage   string // Язык интерфейса из настроек, пустой если настроек нет
 }
 
 // UserExercise связывает пользователя с упражнением
@@ -46,6 +84,20 @@ type UserExercise struct {
 	ExerciseID int64     `db:"exercise_id"`
 	UserAnswer string    `db:"user_answer"` // Ответ пользователя
 	IsCorrect  bool      `db:"is_correct"`
+	Skipped    bool      `db:"skipped"` // Пользователь пропустил упражнение
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// ExerciseAttempt - попытка пользователя вместе с содержанием упражнения, для истории упражнений
+type ExerciseAttempt struct {
+	ID         int64     `db:"id"`
+	ExerciseID int64     `db:"exercise_id"`
+	Type       string    `db:"type"`
+	Content    string    `db:"content"`
+	Answer     string    `db:"answer"`      // Правильный ответ
+	UserAnswer string    `db:"user_answer"` // Ответ пользователя, пустой для пропуска
+	IsCorrect  bool      `db:"is_correct"`
+	Skipped    bool      `db:"skipped"`
 	CreatedAt  time.Time `db:"created_at"`
 }
 
@@ -68,6 +120,22 @@ type ConversationMessage struct {
 	CreatedAt      time.Time `db:"created_at"`
 }
 
+// SkillStat хранит результаты пользователя по одному типу упражнений
+type SkillStat struct {
+	ExerciseType string `db:"type"`
+	Total        int    `db:"total"`   // Все попытки, включая пропуски
+	Correct      int    `db:"correct"` // Правильные ответы
+	Skipped      int    `db:"skipped"` // Пропущенные упражнения
+}
+
+// SuccessRate возвращает долю правильных ответов в процентах среди всех попыток
+func (s SkillStat) SuccessRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Total) * 100
+}
+
 // UserProgress хранит данные о прогрессе пользователя
 type UserProgress struct {
 	ID                 int64     `db:"id"`
@@ -79,6 +147,7 @@ type UserProgress struct {
 	GrammarCorrections int       `db:"grammar_corrections"`
 	CurrentStreak      int       `db:"current_streak"` // Текущая серия дней занятий
 	LongestStreak      int       `db:"longest_streak"` // Самая длинная серия
+	XP                 int       `db:"xp"`             // Набранный опыт, от которого зависит звание
 	LastActivityDate   time.Time `db:"last_activity_date"`
 	CreatedAt          time.Time `db:"created_at"`
 	UpdatedAt          time.Time `db:"updated_at"`
@@ -107,3 +176,90 @@ type UserVocabulary struct {
 	CreatedAt   time.Time `db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at"`
 }
+
+// WritingScore хранит оценку письменной работы пользователя по критериям (0-10)
+type WritingScore struct {
+	ID           int64     `db:"id"`
+	UserID       int64     `db:"user_id"`
+	Text         string    `db:"text"`
+	Grammar      int       `db:"grammar"`
+	Vocabulary   int       `db:"vocabulary"`
+	Coherence    int       `db:"coherence"`
+	TaskResponse int       `db:"task_response"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// Average возвращает среднюю оценку работы по всем критериям
+func (s WritingScore) Average() float64 {
+	return float64(s.Grammar+s.Vocabulary+s.Coherence+s.TaskResponse) / 4
+}
+
+// SpeakingScore хранит оценку чтения предложения вслух (0-100)
+type SpeakingScore struct {
+	ID         int64     `db:"id"`
+	UserID     int64     `db:"user_id"`
+	ExerciseID int64     `db:"exercise_id"`
+	Target     string    `db:"target"`     // Предложение, которое нужно было прочитать
+	Transcript string    `db:"transcript"` // Распознанная речь пользователя
+	Score      int       `db:"score"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// UserSettings хранит пользовательские настройки
+type UserSettings struct {
+	ID                 int64     `db:"id"`
+	UserID             int64     `db:"user_id"`
+	DailyReminders     bool      `db:"daily_reminders"`     // Ежедневные напоминания о занятиях
+	UILanguage         string    `db:"ui_language"`         // Язык интерфейса: en или ru
+	NotificationHour   int       `db:"notification_hour"`   // Час отправки уведомлений (0-23)
+	DailyWord          bool      `db:"daily_word"`          // Подписка на слово дня
+	LeaderboardVisible bool      `db:"leaderboard_visible"` // Участие в таблице лидеров
+	WeeklyReport       bool      `db:"weekly_report"`       // Подписка на еженедельный отчет
+	GrammarEngine      string    `db:"grammar_engine"`      // Способ проверки грамматики: ai, languagetool или both
+	CreatedAt          time.Time `db:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at"`
+}
+
+// Способы проверки грамматики
+const (
+	GrammarEngineAI           = "ai"           // Только ChatGPT с объяснениями
+	GrammarEngineLanguageTool = "languagetool" // Только LanguageTool, без обращения к ChatGPT
+	GrammarEngineBoth         = "both"         // LanguageTool находит ошибки, ChatGPT объясняет их
+)
+
+// Метрики, по которым строится таблица лидеров
+const (
+	LeaderboardByStreak    = "streak"    // Текущая серия дней
+	LeaderboardByExercises = "exercises" // Количество правильно выполненных упражнений
+	LeaderboardByXP        = "xp"        // Набранный опыт
+)
+
+// LeaderboardEntry представляет строку таблицы лидеров
+type LeaderboardEntry struct {
+	Rank   int
+	UserID int64
+	Name   string
+	Value  int
+}
+
+// BotStats хранит общую статистику использования бота
+type BotStats struct {
+	TotalUsers     int
+	ActiveToday    int // Пользователи, занимавшиеся с начала суток
+	ActiveWeek     int // Пользователи, занимавшиеся за последние 7 дней
+	TotalExercises int // Все ответы на упражнения, включая пропуски
+	TotalMessages  int // Сообщения в диалогах
+}
+
+// NotificationRecipient описывает пользователя, которому нужно отправить плановое уведомление
+type NotificationRecipient struct {
+	UserID           int64     `db:"user_id"`
+	TelegramID       int64     `db:"telegram_id"`
+	EnglishLevel     string    `db:"english_level"`
+	UILanguage       string    `db:"ui_language"` // Язык интерфейса: en или ru
+	NotificationHour int       `db:"notification_hour"`
+	LastSentAt       time.Time `db:"last_sent_at"`       // Время последней отправки, нулевое если не отправлялось
+	LastActivityDate time.Time `db:"last_activity_date"` // Последняя активность, нулевая если ее не было
+	CurrentStreak    int       `db:"current_streak"`
+	Timezone         string    `db:"timezone"` // Часовой пояс из базы IANA, пустой - время сервера
+}
@@ -0,0 +1,34 @@
+package database
+
+import "testing"
+
+func TestIsValidEnglishLevel(t *testing.T) {
+	for _, level := range EnglishLevels {
+		if !IsValidEnglishLevel(level) {
+			t.Errorf("уровень %q считается недопустимым", level)
+		}
+	}
+
+	for _, level := range []string{"", "a1", "B3", "C", "native"} {
+		if IsValidEnglishLevel(level) {
+			t.Errorf("уровень %q считается допустимым", level)
+		}
+	}
+}
+
+func TestSkillStatSuccessRate(t *testing.T) {
+	tests := []struct {
+		stat SkillStat
+		want float64
+	}{
+		{SkillStat{Total: 0, Correct: 0}, 0},
+		{SkillStat{Total: 4, Correct: 1}, 25},
+		{SkillStat{Total: 10, Correct: 7, Skipped: 3}, 70},
+	}
+
+	for _, tt := range tests {
+		if got := tt.stat.SuccessRate(); got != tt.want {
+			t.Errorf("SuccessRate(%+v) = %v, ожидалось %v", tt.stat, got, tt.want)
+		}
+	}
+}
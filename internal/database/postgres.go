@@ -2,8 +2,10 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -12,14 +14,20 @@ import (
 
 // PostgresDB представляет соединение с базой данных PostgreSQL
 type PostgresDB struct {
-	pool *pgxpool.Pool
+	pool connPool
 }
 
-// NewPostgresDB создает новое соединение с базой данных
-func NewPostgresDB(connString string) (*PostgresDB, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+const (
+	// pingTimeout - таймаут одной проверки соединения с базой данных
+	pingTimeout = 10 * time.Second
+	// maxConnectBackoff - максимальная пауза между попытками подключения
+	maxConnectBackoff = 30 * time.Second
+)
 
+// NewPostgresDB создает новое соединение с базой данных.
+// Если база еще не готова (например, контейнер запускается одновременно с ботом),
+// подключение повторяется до attempts раз; пауза начинается с interval и удваивается после каждой попытки
+func NewPostgresDB(connString string, attempts int, interval time.Duration) (*PostgresDB, error) {
 	config, err := pgxpool.ParseConfig(connString)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка разбора строки подключения: %w", err)
@@ -31,19 +39,55 @@ func NewPostgresDB(connString string) (*PostgresDB, error) {
 	config.MaxConnLifetime = 1 * time.Hour
 	config.MaxConnIdleTime = 30 * time.Minute
 
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка подключения к базе данных: %w", err)
 	}
+	db := &PostgresDB{pool: connPool{pool}}
+
+	// Проверяем соединение, повторяя попытки с нарастающей паузой
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := interval
+	for attempt := 1; ; attempt++ {
+		err = db.Ping(context.Background())
+		if err == nil {
+			break
+		}
+		if attempt >= attempts {
+			pool.Close()
+			return nil, fmt.Errorf("ошибка пинга базы данных после %d попыток: %w", attempts, err)
+		}
+
+		slog.Warn("База данных недоступна, повторяем подключение",
+			"attempt", attempt,
+			"attempts", attempts,
+			"retry_in", backoff,
+			"error", err,
+		)
+		time.Sleep(backoff)
 
-	// Проверяем соединение
-	if err := pool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("ошибка пинга базы данных: %w", err)
+		backoff *= 2
+		if backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
 	}
 
 	slog.Info("Успешное подключение к базе данных PostgreSQL")
 
-	return &PostgresDB{pool: pool}, nil
+	return db, nil
+}
+
+// Ping проверяет, что база данных доступна
+func (db *PostgresDB) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if err := db.pool.Ping(ctx); err != nil {
+		return fmt.Errorf("ошибка пинга базы данных: %w", err)
+	}
+	return nil
 }
 
 // Close закрывает соединение с базой данных
@@ -54,7 +98,8 @@ func (db *PostgresDB) Close() {
 // GetUserByTelegramID находит пользователя по его Telegram ID
 func (db *PostgresDB) GetUserByTelegramID(ctx context.Context, telegramID int64) (*User, error) {
 	query := `
-		SELECT id, telegram_id, username, first_name, last_name, language_code, english_level, created_at, updated_at
+		SELECT id, telegram_id, username, first_name, last_name, language_code, english_level,
+		       COALESCE(timezone, ''), created_at, updated_at
 		FROM users
 		WHERE telegram_id = $1
 	`
@@ -68,6 +113,7 @@ func (db *PostgresDB) GetUserByTelegramID(ctx context.Context, telegramID int64)
 		&user.LastName,
 		&user.LanguageCode,
 		&user.EnglishLevel,
+		&user.Timezone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -117,11 +163,29 @@ func (db *PostgresDB) CreateUser(ctx context.Context, user User) (*User, error)
 	return &user, nil
 }
 
+// SetUserReferrer записывает пригласившего пользователя. Пригласивший записывается только один раз
+// и не может совпадать с самим пользователем. Возвращает true, если запись сделана
+func (db *PostgresDB) SetUserReferrer(ctx context.Context, userID, referrerID int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET referred_by = $2, updated_at = $3
+		WHERE id = $1 AND id <> $2 AND referred_by IS NULL
+	`
+
+	tag, err := db.pool.Exec(ctx, query, userID, referrerID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("ошибка записи пригласившего пользователя: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
 // GetOrCreateUserSession получает текущую сессию пользователя или создает новую
 func (db *PostgresDB) GetOrCreateUserSession(ctx context.Context, userID int64) (*UserSession, error) {
-	// Сначала проверяем, есть ли активная сессия
+	// Сначала проверяем, есть ли активная сессия.
+	// У новой сессии conversation_id равен NULL, поэтому читаем его как пустую строку
 	query := `
-		SELECT id, user_id, state, context_data, conversation_id, last_activity, created_at, updated_at
+		SELECT id, user_id, state, context_data, COALESCE(conversation_id, ''), last_activity, created_at, updated_at
 		FROM user_sessions
 		WHERE user_id = $1
 	`
@@ -148,7 +212,7 @@ func (db *PostgresDB) GetOrCreateUserSession(ctx context.Context, userID int64)
 		now := time.Now()
 		_, err = db.pool.Exec(ctx, updateQuery, now, session.ID)
 		if err != nil {
-			slog.Error("Ошибка обновления времени активности сессии", "error", err)
+			slog.ErrorContext(ctx, "Ошибка обновления времени активности сессии", "error", err)
 		}
 		return &session, nil
 	}
@@ -190,11 +254,91 @@ func (db *PostgresDB) GetOrCreateUserSession(ctx context.Context, userID int64)
 	return &session, nil
 }
 
+// GetStaleSessions возвращает сессии в одном из состояний states,
+// последняя активность в которых была раньше before
+func (db *PostgresDB) GetStaleSessions(ctx context.Context, states []string, before time.Time) ([]StaleSession, error) {
+	query := `
+		SELECT s.id, s.user_id, s.state, s.context_data, COALESCE(s.conversation_id, ''), s.last_activity,
+		       s.created_at, s.updated_at, u.telegram_id, COALESCE(u.language_code, ''), COALESCE(st.ui_language, '')
+		FROM user_sessions s
+		JOIN users u ON u.id = s.user_id
+		LEFT JOIN user_settings st ON st.user_id = s.user_id
+		WHERE s.state = ANY($1) AND s.last_activity < $2
+		ORDER BY s.last_activity
+	`
+
+	rows, err := db.pool.Query(ctx, query, states, before)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса неактивных сессий: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []StaleSession
+	for rows.Next() {
+		var session StaleSession
+		if err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.State,
+			&session.ContextData,
+			&session.ConversationID,
+			&session.LastActivity,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+			&session.TelegramID,
+			&session.LanguageCode,
+			&session.UILanguage,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения неактивной сессии: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения неактивных сессий: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ResetStaleSession возвращает сессию в состояние state с пустым контекстом,
+// если она все еще в состоянии from и неактивна с before.
+// Возвращает false, если пользователь успел продолжить работу
+func (db *PostgresDB) ResetStaleSession(ctx context.Context, sessionID int64, from, state string, before time.Time) (bool, error) {
+	query := `
+		UPDATE user_sessions
+		SET state = $1, context_data = '{}', updated_at = $2
+		WHERE id = $3 AND state = $4 AND last_activity < $5
+	`
+
+	tag, err := db.pool.Exec(ctx, query, state, time.Now(), sessionID, from, before)
+	if err != nil {
+		return false, fmt.Errorf("ошибка сброса неактивной сессии: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// TouchUserSession обновляет время последней активности в сессии
+func (db *PostgresDB) TouchUserSession(ctx context.Context, sessionID int64, now time.Time) error {
+	query := `
+		UPDATE user_sessions
+		SET last_activity = $1, updated_at = $1
+		WHERE id = $2
+	`
+
+	if _, err := db.pool.Exec(ctx, query, now, sessionID); err != nil {
+		return fmt.Errorf("ошибка обновления времени активности сессии: %w", err)
+	}
+
+	return nil
+}
+
 // UpdateUserSession обновляет сессию пользователя
 func (db *PostgresDB) UpdateUserSession(ctx context.Context, session UserSession) error {
 	query := `
 		UPDATE user_sessions
-		SET state = $1, context_data = $2, conversation_id = $3, last_activity = $4, updated_at = $4
+		SET state = $1, context_data = $2, conversation_id = NULLIF($3, ''), last_activity = $4, updated_at = $4
 		WHERE id = $5
 	`
 
@@ -217,19 +361,30 @@ func (db *PostgresDB) UpdateUserSession(ctx context.Context, session UserSession
 // SaveExercise сохраняет новое упражнение
 func (db *PostgresDB) SaveExercise(ctx context.Context, exercise Exercise) (*Exercise, error) {
 	query := `
-		INSERT INTO exercises (type, level, content, answer, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO exercises (type, level, content, answer, options, questions, topic, offline, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10)
 		RETURNING id
 	`
 
 	now := time.Now()
 	exercise.CreatedAt = now
+	exercise.Data = exerciseData(exercise)
 
-	err := db.pool.QueryRow(ctx, query,
+	data, err := json.Marshal(exercise.Data)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подготовки содержания упражнения: %w", err)
+	}
+
+	err = db.pool.QueryRow(ctx, query,
 		exercise.Type,
 		exercise.Level,
 		exercise.Content,
 		exercise.Answer,
+		exercise.Options,
+		exercise.Questions,
+		exercise.Topic,
+		exercise.Offline,
+		data,
 		exercise.CreatedAt,
 	).Scan(&exercise.ID)
 
@@ -240,6 +395,35 @@ func (db *PostgresDB) SaveExercise(ctx context.Context, exercise Exercise) (*Exe
 	return &exercise, nil
 }
 
+// exerciseData дополняет структурированное содержание упражнения его текстовыми полями
+func exerciseData(exercise Exercise) ExerciseData {
+	data := exercise.Data
+	if data.Prompt == "" {
+		data.Prompt = exercise.Content
+	}
+	if data.Options == nil {
+		data.Options = exercise.Options
+	}
+	if data.Answers == nil {
+		data.Answers = answerVariants(exercise.Answer)
+	}
+	if data.Explanation == "" {
+		data.Explanation = exercise.Explanation
+	}
+	return data
+}
+
+// answerVariants разбивает ответ на допустимые варианты, записанные через "/"
+func answerVariants(answer string) []string {
+	var variants []string
+	for _, variant := range strings.Split(answer, "/") {
+		if variant = strings.TrimSpace(variant); variant != "" {
+			variants = append(variants, variant)
+		}
+	}
+	return variants
+}
+
 // SaveUserExercise сохраняет ответ пользователя на упражнение
 func (db *PostgresDB) SaveUserExercise(ctx context.Context, userExercise UserExercise) (*UserExercise, error) {
 	query := `
@@ -269,25 +453,210 @@ func (db *PostgresDB) SaveUserExercise(ctx context.Context, userExercise UserExe
 		SET 
 			total_exercises = total_exercises + 1,
 			correct_exercises = correct_exercises + CASE WHEN $1 THEN 1 ELSE 0 END,
-			updated_at = $2
-		WHERE user_id = $3
+			xp = COALESCE(xp, 0) + $2,
+			updated_at = $3
+		WHERE user_id = $4
+		RETURNING total_exercises
 	`
 
-	_, err = db.pool.Exec(ctx, updateQuery,
+	correct := 0
+	if userExercise.IsCorrect {
+		correct = 1
+	}
+
+	var totalExercises int
+	err = db.pool.QueryRow(ctx, updateQuery,
 		userExercise.IsCorrect,
+		ExerciseXP(1, correct),
 		now,
 		userExercise.UserID,
-	)
+	).Scan(&totalExercises)
 
-	if err != nil {
-		slog.Error("Ошибка обновления прогресса пользователя", "error", err)
+	if err != nil && err != pgx.ErrNoRows {
+		slog.ErrorContext(ctx, "Ошибка обновления прогресса пользователя", "error", err)
 	}
 
+	db.awardExerciseMilestones(ctx, userExercise.UserID, totalExercises)
+
 	return &userExercise, nil
 }
 
-// StartConversation начинает новый диалог
+// SaveUserExercises сохраняет ответы пользователя на серию упражнений в одной транзакции
+// и обновляет счетчики прогресса одним запросом, начисляя за серию xp опыта.
+// Возвращает общий опыт пользователя; 0, если записи прогресса еще нет
+func (db *PostgresDB) SaveUserExercises(ctx context.Context, userID int64, userExercises []UserExercise, xp int) (int, error) {
+	if len(userExercises) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции сохранения упражнений: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	insertQuery := `
+		INSERT INTO user_exercises (user_id, exercise_id, user_answer, is_correct, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	now := time.Now()
+	correct := 0
+	batch := &pgx.Batch{}
+	for _, userExercise := range userExercises {
+		batch.Queue(insertQuery, userID, userExercise.ExerciseID, userExercise.UserAnswer, userExercise.IsCorrect, now)
+		if userExercise.IsCorrect {
+			correct++
+		}
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, fmt.Errorf("ошибка сохранения ответов на упражнения: %w", err)
+	}
+
+	updateQuery := `
+		UPDATE user_progress
+		SET 
+			total_exercises = total_exercises + $1,
+			correct_exercises = correct_exercises + $2,
+			xp = COALESCE(xp, 0) + $3,
+			updated_at = $4
+		WHERE user_id = $5
+		RETURNING total_exercises, xp
+	`
+
+	var totalExercises, totalXP int
+	err = tx.QueryRow(ctx, updateQuery, len(userExercises), correct, xp, now, userID).Scan(&totalExercises, &totalXP)
+	if err != nil && err != pgx.ErrNoRows {
+		return 0, fmt.Errorf("ошибка обновления прогресса пользователя: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ошибка фиксации сохранения упражнений: %w", err)
+	}
+
+	db.awardExerciseMilestones(ctx, userID, totalExercises)
+
+	return totalXP, nil
+}
+
+// awardExerciseMilestones выдает все достигнутые достижения за количество упражнений.
+// Сравниваем с порогом через >=, чтобы пользователи, набравшие упражнения
+// до появления достижения, тоже его получили; повторная выдача исключена в AddUserAchievement
+func (db *PostgresDB) awardExerciseMilestones(ctx context.Context, userID int64, totalExercises int) {
+	for _, count := range ExerciseMilestones {
+		if totalExercises < count {
+			break
+		}
+		if err := db.AwardAchievement(ctx, userID, ExerciseAchievementType(count)); err != nil {
+			slog.ErrorContext(ctx, "Ошибка выдачи достижения", "error", err, "user_id", userID)
+		}
+	}
+}
+
+// GetRecentExerciseResults возвращает результаты последних упражнений пользователя заданного типа,
+// начиная с самого нового. Пропущенные упражнения считаются неправильными
+func (db *PostgresDB) GetRecentExerciseResults(ctx context.Context, userID int64, exerciseType string, limit int) ([]bool, error) {
+	query := `
+		SELECT ue.is_correct
+		FROM user_exercises ue
+		JOIN exercises e ON e.id = ue.exercise_id
+		WHERE ue.user_id = $1 AND e.type = $2
+		ORDER BY ue.created_at DESC, ue.id DESC
+		LIMIT $3
+	`
+
+	rows, err := db.pool.Query(ctx, query, userID, exerciseType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса последних результатов упражнений: %w", err)
+	}
+	defer rows.Close()
+
+	var results []bool
+	for rows.Next() {
+		var isCorrect bool
+		if err := rows.Scan(&isCorrect); err != nil {
+			return nil, fmt.Errorf("ошибка чтения результата упражнения: %w", err)
+		}
+		results = append(results, isCorrect)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения результатов упражнений: %w", err)
+	}
+
+	return results, nil
+}
+
+// GetUserExercises возвращает страницу попыток пользователя с содержанием упражнений, начиная с последних
+func (db *PostgresDB) GetUserExercises(ctx context.Context, userID int64, limit, offset int) ([]ExerciseAttempt, error) {
+	query := `
+		SELECT ue.id, ue.exercise_id, e.type, e.content, COALESCE(e.answer, ''), COALESCE(ue.user_answer, ''),
+		       ue.is_correct, ue.skipped, ue.created_at
+		FROM user_exercises ue
+		JOIN exercises e ON e.id = ue.exercise_id
+		WHERE ue.user_id = $1
+		ORDER BY ue.created_at DESC, ue.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := db.pool.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса истории упражнений: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []ExerciseAttempt
+	for rows.Next() {
+		var attempt ExerciseAttempt
+		if err := rows.Scan(
+			&attempt.ID,
+			&attempt.ExerciseID,
+			&attempt.Type,
+			&attempt.Content,
+			&attempt.Answer,
+			&attempt.UserAnswer,
+			&attempt.IsCorrect,
+			&attempt.Skipped,
+			&attempt.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения истории упражнений: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения истории упражнений: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// SaveSkippedExercise отмечает упражнение как пропущенное пользователем.
+// Пропуск учитывается в статистике навыков как неудачная попытка, но не меняет счетчики прогресса
+func (db *PostgresDB) SaveSkippedExercise(ctx context.Context, userID, exerciseID int64) error {
+	query := `
+		INSERT INTO user_exercises (user_id, exercise_id, is_correct, skipped, created_at)
+		VALUES ($1, $2, false, true, $3)
+	`
+
+	_, err := db.pool.Exec(ctx, query, userID, exerciseID, time.Now())
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения пропуска упражнения: %w", err)
+	}
+
+	return nil
+}
+
+// StartConversation начинает новый диалог и увеличивает счетчик диалогов в прогрессе пользователя.
+// Продолжение существующего диалога через GetActiveConversation счетчик не меняет
 func (db *PostgresDB) StartConversation(ctx context.Context, userID int64, topic string, level string) (*Conversation, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции создания диалога: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		INSERT INTO conversations (user_id, topic, level, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $4)
@@ -300,7 +669,7 @@ func (db *PostgresDB) StartConversation(ctx context.Context, userID int64, topic
 	conversation.Topic = topic
 	conversation.Level = level
 
-	err := db.pool.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		conversation.UserID,
 		conversation.Topic,
 		conversation.Level,
@@ -315,6 +684,50 @@ func (db *PostgresDB) StartConversation(ctx context.Context, userID int64, topic
 		return nil, fmt.Errorf("ошибка создания диалога: %w", err)
 	}
 
+	progressQuery := `
+		UPDATE user_progress
+		SET total_conversations = total_conversations + 1, updated_at = $1
+		WHERE user_id = $2
+	`
+
+	if _, err := tx.Exec(ctx, progressQuery, now, userID); err != nil {
+		return nil, fmt.Errorf("ошибка обновления счетчика диалогов: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации создания диалога: %w", err)
+	}
+
+	return &conversation, nil
+}
+
+// GetActiveConversation возвращает текущий диалог пользователя, сохраненный в его сессии.
+// Возвращает nil, если активного диалога нет
+func (db *PostgresDB) GetActiveConversation(ctx context.Context, userID int64) (*Conversation, error) {
+	query := `
+		SELECT c.id, c.user_id, COALESCE(c.topic, ''), COALESCE(c.level, ''), c.created_at, c.updated_at
+		FROM user_sessions s
+		JOIN conversations c ON c.id::text = s.conversation_id AND c.user_id = s.user_id
+		WHERE s.user_id = $1
+	`
+
+	var conversation Conversation
+	err := db.pool.QueryRow(ctx, query, userID).Scan(
+		&conversation.ID,
+		&conversation.UserID,
+		&conversation.Topic,
+		&conversation.Level,
+		&conversation.CreatedAt,
+		&conversation.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка получения активного диалога: %w", err)
+	}
+
 	return &conversation, nil
 }
 
@@ -349,22 +762,28 @@ func (db *PostgresDB) AddConversationMessage(ctx context.Context, message Conver
 
 	_, err = db.pool.Exec(ctx, updateQuery, now, message.ConversationID)
 	if err != nil {
-		slog.Error("Ошибка обновления времени диалога", "error", err)
+		slog.ErrorContext(ctx, "Ошибка обновления времени диалога", "error", err)
 	}
 
-	// Обновляем статистику пользователя
+	// Обновляем статистику пользователя. Опыт начисляется только за сообщения самого пользователя
 	updateProgressQuery := `
 		UPDATE user_progress
 		SET total_messages = total_messages + 1,
-		    updated_at = $1
+		    xp = COALESCE(xp, 0) + $1,
+		    updated_at = $2
 		WHERE user_id = (
-			SELECT user_id FROM conversations WHERE id = $2
+			SELECT user_id FROM conversations WHERE id = $3
 		)
 	`
 
-	_, err = db.pool.Exec(ctx, updateProgressQuery, now, message.ConversationID)
+	xp := 0
+	if message.Role == "user" {
+		xp = XPChatMessage
+	}
+
+	_, err = db.pool.Exec(ctx, updateProgressQuery, xp, now, message.ConversationID)
 	if err != nil {
-		slog.Error("Ошибка обновления статистики сообщений пользователя", "error", err)
+		slog.ErrorContext(ctx, "Ошибка обновления статистики сообщений пользователя", "error", err)
 	}
 
 	return &message, nil
@@ -375,7 +794,7 @@ func (db *PostgresDB) GetUserProgress(ctx context.Context, userID int64) (*UserP
 	query := `
 		SELECT id, user_id, total_exercises, correct_exercises, total_conversations, 
 		       total_messages, grammar_corrections, current_streak, longest_streak, 
-		       last_activity_date, created_at, updated_at
+		       COALESCE(xp, 0), last_activity_date, created_at, updated_at
 		FROM user_progress
 		WHERE user_id = $1
 	`
@@ -391,6 +810,7 @@ func (db *PostgresDB) GetUserProgress(ctx context.Context, userID int64) (*UserP
 		&progress.GrammarCorrections,
 		&progress.CurrentStreak,
 		&progress.LongestStreak,
+		&progress.XP,
 		&progress.LastActivityDate,
 		&progress.CreatedAt,
 		&progress.UpdatedAt,
@@ -407,6 +827,114 @@ func (db *PostgresDB) GetUserProgress(ctx context.Context, userID int64) (*UserP
 	return &progress, nil
 }
 
+// GetSkillStats считает выполненные и правильные упражнения пользователя по типам
+func (db *PostgresDB) GetSkillStats(ctx context.Context, userID int64) ([]SkillStat, error) {
+	query := `
+		SELECT e.type, COUNT(*), COUNT(*) FILTER (WHERE ue.is_correct), COUNT(*) FILTER (WHERE ue.skipped)
+		FROM user_exercises ue
+		JOIN exercises e ON e.id = ue.exercise_id
+		WHERE ue.user_id = $1
+		GROUP BY e.type
+		ORDER BY e.type
+	`
+
+	rows, err := db.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса статистики по навыкам: %w", err)
+	}
+
+	return scanSkillStats(rows)
+}
+
+// GetExerciseTypeStats считает ответы на упражнения всех пользователей по типам упражнений
+func (db *PostgresDB) GetExerciseTypeStats(ctx context.Context) ([]SkillStat, error) {
+	query := `
+		SELECT e.type, COUNT(*), COUNT(*) FILTER (WHERE ue.is_correct), COUNT(*) FILTER (WHERE ue.skipped)
+		FROM user_exercises ue
+		JOIN exercises e ON e.id = ue.exercise_id
+		GROUP BY e.type
+		ORDER BY e.type
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса статистики по типам упражнений: %w", err)
+	}
+
+	return scanSkillStats(rows)
+}
+
+// scanSkillStats читает статистику по типам упражнений из результата запроса
+func scanSkillStats(rows pgx.Rows) ([]SkillStat, error) {
+	defer rows.Close()
+
+	var stats []SkillStat
+	for rows.Next() {
+		var stat SkillStat
+		if err := rows.Scan(&stat.ExerciseType, &stat.Total, &stat.Correct, &stat.Skipped); err != nil {
+			return nil, fmt.Errorf("ошибка чтения статистики по навыкам: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения статистики по навыкам: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetBotStats считает общую статистику бота. Активность считается от начала суток dayStart
+func (db *PostgresDB) GetBotStats(ctx context.Context, dayStart time.Time) (*BotStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM user_progress WHERE last_activity_date >= $1),
+			(SELECT COUNT(*) FROM user_progress WHERE last_activity_date >= $2),
+			(SELECT COUNT(*) FROM user_exercises),
+			(SELECT COUNT(*) FROM conversation_messages)
+	`
+
+	var stats BotStats
+	err := db.pool.QueryRow(ctx, query, dayStart, dayStart.AddDate(0, 0, -6)).Scan(
+		&stats.TotalUsers,
+		&stats.ActiveToday,
+		&stats.ActiveWeek,
+		&stats.TotalExercises,
+		&stats.TotalMessages,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики бота: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// GetAllUserChatIDs возвращает ID чатов всех пользователей для массовой рассылки.
+// Бот работает в личных чатах, поэтому ID чата совпадает с Telegram ID пользователя
+func (db *PostgresDB) GetAllUserChatIDs(ctx context.Context) ([]int64, error) {
+	rows, err := db.pool.Query(ctx, "SELECT telegram_id FROM users ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса чатов пользователей: %w", err)
+	}
+	defer rows.Close()
+
+	var chatIDs []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, fmt.Errorf("ошибка чтения чата пользователя: %w", err)
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения чатов пользователей: %w", err)
+	}
+
+	return chatIDs, nil
+}
+
 // CreateUserProgress создает запись прогресса для нового пользователя
 func (db *PostgresDB) CreateUserProgress(ctx context.Context, userID int64) (*UserProgress, error) {
 	query := `
@@ -441,6 +969,28 @@ func (db *PostgresDB) CreateUserProgress(ctx context.Context, userID int64) (*Us
 	return &progress, nil
 }
 
+// IncrementGrammarCorrections увеличивает счетчик найденных грамматических ошибок пользователя
+func (db *PostgresDB) IncrementGrammarCorrections(ctx context.Context, userID int64, count int) error {
+	// Получаем прогресс, чтобы создать запись при ее отсутствии
+	progress, err := db.GetUserProgress(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка получения прогресса для учета исправлений: %w", err)
+	}
+
+	query := `
+		UPDATE user_progress
+		SET grammar_corrections = grammar_corrections + $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err = db.pool.Exec(ctx, query, count, time.Now(), progress.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления счетчика исправлений: %w", err)
+	}
+
+	return nil
+}
+
 // UpdateUserStreak обновляет серии дней активности пользователя
 func (db *PostgresDB) UpdateUserStreak(ctx context.Context, userID int64) error {
 	// Получаем текущий прогресс
@@ -449,21 +999,29 @@ func (db *PostgresDB) UpdateUserStreak(ctx context.Context, userID int64) error
 		return fmt.Errorf("ошибка получения прогресса для обновления серии: %w", err)
 	}
 
+	// Границы суток считаем в часовом поясе пользователя,
+	// чтобы занятие поздно вечером по его времени не прерывало серию
+	loc, err := db.userLocation(ctx, userID)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
-	yesterday := now.AddDate(0, 0, -1)
+	yesterday := now.In(loc).AddDate(0, 0, -1)
 
-	// Проверяем, был ли пользователь активен вчера
-	if progress.LastActivityDate.Year() == yesterday.Year() &&
-		progress.LastActivityDate.Month() == yesterday.Month() &&
-		progress.LastActivityDate.Day() == yesterday.Day() {
-		// Увеличиваем серию на 1
-		progress.CurrentStreak++
-	} else if progress.LastActivityDate.Year() == now.Year() &&
-		progress.LastActivityDate.Month() == now.Month() &&
-		progress.LastActivityDate.Day() == now.Day() {
+	lastActivity := serverTime(progress.LastActivityDate)
+	switch {
+	case progress.CurrentStreak == 0:
+		// Первая активность: запись прогресса могла быть только что создана
+		// с сегодняшней датой, поэтому дату здесь не проверяем
+		progress.CurrentStreak = 1
+	case sameDay(lastActivity, now, loc):
 		// Уже активен сегодня, ничего не делаем
 		return nil
-	} else {
+	case sameDay(lastActivity, yesterday, loc):
+		// Был активен вчера, увеличиваем серию на 1
+		progress.CurrentStreak++
+	default:
 		// Серия прервалась, начинаем новую
 		progress.CurrentStreak = 1
 	}
@@ -497,12 +1055,37 @@ func (db *PostgresDB) UpdateUserStreak(ctx context.Context, userID int64) error
 	}
 
 	// Проверяем, есть ли новые достижения
-	if progress.CurrentStreak == 7 || progress.CurrentStreak == 30 || progress.CurrentStreak == 100 {
-		achievementType := fmt.Sprintf("streak_%d_days", progress.CurrentStreak)
-		title := fmt.Sprintf("Серия %d дней", progress.CurrentStreak)
-		description := fmt.Sprintf("Вы занимались английским %d дней подряд!", progress.CurrentStreak)
+	for _, days := range StreakMilestones {
+		if progress.CurrentStreak == days {
+			db.AwardAchievement(ctx, userID, StreakAchievementType(days))
+		}
+	}
+
+	return nil
+}
+
+// userLocation возвращает часовой пояс пользователя
+func (db *PostgresDB) userLocation(ctx context.Context, userID int64) (*time.Location, error) {
+	var timezone string
+	err := db.pool.QueryRow(ctx, `SELECT COALESCE(timezone, '') FROM users WHERE id = $1`, userID).Scan(&timezone)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("ошибка получения часового пояса пользователя: %w", err)
+	}
+
+	return Location(timezone), nil
+}
+
+// SetUserTimezone сохраняет часовой пояс пользователя; пустое имя возвращает время сервера
+func (db *PostgresDB) SetUserTimezone(ctx context.Context, userID int64, timezone string) error {
+	query := `
+		UPDATE users
+		SET timezone = NULLIF($1, ''), updated_at = $2
+		WHERE id = $3
+	`
 
-		db.AddUserAchievement(ctx, userID, achievementType, title, description)
+	_, err := db.pool.Exec(ctx, query, timezone, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения часового пояса пользователя: %w", err)
 	}
 
 	return nil
@@ -548,3 +1131,925 @@ func (db *PostgresDB) AddUserAchievement(ctx context.Context, userID int64, achi
 
 	return nil
 }
+
+// EndConversation сохраняет итоги диалога: разбор ошибок ученика и их количество
+func (db *PostgresDB) EndConversation(ctx context.Context, conversationID int64, summary string, mistakes int) error {
+	query := `
+		UPDATE conversations
+		SET summary = $1, mistakes = $2, ended_at = $3, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := db.pool.Exec(ctx, query, summary, mistakes, time.Now(), conversationID)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения итогов диалога: %w", err)
+	}
+
+	return nil
+}
+
+// GetConversationMessages получает последние limit сообщений диалога в хронологическом порядке
+func (db *PostgresDB) GetConversationMessages(ctx context.Context, conversationID int64, limit int) ([]ConversationMessage, error) {
+	query := `
+		SELECT id, conversation_id, role, content, created_at
+		FROM (
+			SELECT id, conversation_id, role, content, created_at
+			FROM conversation_messages
+			WHERE conversation_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		) AS recent
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := db.pool.Query(ctx, query, conversationID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса сообщений диалога: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []ConversationMessage
+	for rows.Next() {
+		var message ConversationMessage
+		if err := rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.Role,
+			&message.Content,
+			&message.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения сообщения диалога: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения сообщений диалога: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetExerciseByID находит упражнение по ID
+func (db *PostgresDB) GetExerciseByID(ctx context.Context, id int64) (*Exercise, error) {
+	query := `
+		SELECT id, type, level, content, COALESCE(answer, ''), COALESCE(options, '{}'), questions,
+		       COALESCE(explanation, ''), COALESCE(topic, ''), COALESCE(offline, false), data, created_at
+		FROM exercises
+		WHERE id = $1
+	`
+
+	var exercise Exercise
+	var data []byte
+	err := db.pool.QueryRow(ctx, query, id).Scan(
+		&exercise.ID,
+		&exercise.Type,
+		&exercise.Level,
+		&exercise.Content,
+		&exercise.Answer,
+		&exercise.Options,
+		&exercise.Questions,
+		&exercise.Explanation,
+		&exercise.Topic,
+		&exercise.Offline,
+		&data,
+		&exercise.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Упражнение не найдено
+		}
+		return nil, fmt.Errorf("ошибка запроса упражнения: %w", err)
+	}
+
+	// Ответы и варианты берем из структурированного содержания, если оно есть
+	if data != nil {
+		if err := json.Unmarshal(data, &exercise.Data); err != nil {
+			return nil, fmt.Errorf("ошибка разбора содержания упражнения: %w", err)
+		}
+		if len(exercise.Data.Answers) > 0 {
+			exercise.Answer = strings.Join(exercise.Data.Answers, "/")
+		}
+		if len(exercise.Data.Options) > 0 {
+			exercise.Options = exercise.Data.Options
+		}
+	}
+	exercise.Data = exerciseData(exercise)
+
+	return &exercise, nil
+}
+
+// SetExerciseExplanation сохраняет объяснение правила, проверяемого упражнением
+func (db *PostgresDB) SetExerciseExplanation(ctx context.Context, id int64, explanation string) error {
+	query := `
+		UPDATE exercises
+		SET explanation = $1, data = jsonb_set(COALESCE(data, '{}'), '{explanation}', to_jsonb($1::text))
+		WHERE id = $2
+	`
+
+	_, err := db.pool.Exec(ctx, query, explanation, id)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения объяснения упражнения: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserSettings получает настройки пользователя, создавая настройки по умолчанию при их отсутствии
+func (db *PostgresDB) GetUserSettings(ctx context.Context, userID int64) (*UserSettings, error) {
+	query := `
+		SELECT id, user_id, daily_reminders, ui_language, notification_hour, daily_word, leaderboard_visible, weekly_report, grammar_engine, created_at, updated_at
+		FROM user_settings
+		WHERE user_id = $1
+	`
+
+	var settings UserSettings
+	err := db.pool.QueryRow(ctx, query, userID).Scan(
+		&settings.ID,
+		&settings.UserID,
+		&settings.DailyReminders,
+		&settings.UILanguage,
+		&settings.NotificationHour,
+		&settings.DailyWord,
+		&settings.LeaderboardVisible,
+		&settings.WeeklyReport,
+		&settings.GrammarEngine,
+		&settings.CreatedAt,
+		&settings.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			// Создаем настройки по умолчанию, если их еще нет
+			return db.CreateUserSettings(ctx, userID)
+		}
+		return nil, fmt.Errorf("ошибка получения настроек пользователя: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// CreateUserSettings создает настройки по умолчанию для пользователя
+func (db *PostgresDB) CreateUserSettings(ctx context.Context, userID int64) (*UserSettings, error) {
+	query := `
+		INSERT INTO user_settings (user_id, daily_reminders, ui_language, notification_hour, created_at, updated_at)
+		SELECT $1, false, CASE WHEN language_code LIKE 'ru%' THEN 'ru' ELSE 'en' END, 9, $2, $2
+		FROM users
+		WHERE id = $1
+		ON CONFLICT (user_id) DO UPDATE SET updated_at = user_settings.updated_at
+		RETURNING id, user_id, daily_reminders, ui_language, notification_hour, daily_word, leaderboard_visible, weekly_report, grammar_engine, created_at, updated_at
+	`
+
+	var settings UserSettings
+	err := db.pool.QueryRow(ctx, query, userID, time.Now()).Scan(
+		&settings.ID,
+		&settings.UserID,
+		&settings.DailyReminders,
+		&settings.UILanguage,
+		&settings.NotificationHour,
+		&settings.DailyWord,
+		&settings.LeaderboardVisible,
+		&settings.WeeklyReport,
+		&settings.GrammarEngine,
+		&settings.CreatedAt,
+		&settings.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания настроек пользователя: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// UpdateUserSettings обновляет настройки пользователя
+func (db *PostgresDB) UpdateUserSettings(ctx context.Context, settings UserSettings) error {
+	query := `
+		UPDATE user_settings
+		SET daily_reminders = $1, ui_language = $2, notification_hour = $3, daily_word = $4,
+		    leaderboard_visible = $5, weekly_report = $6, grammar_engine = $7, updated_at = $8
+		WHERE user_id = $9
+	`
+
+	_, err := db.pool.Exec(ctx, query,
+		settings.DailyReminders,
+		settings.UILanguage,
+		settings.NotificationHour,
+		settings.DailyWord,
+		settings.LeaderboardVisible,
+		settings.WeeklyReport,
+		settings.GrammarEngine,
+		time.Now(),
+		settings.UserID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("ошибка обновления настроек пользователя: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUser удаляет пользователя и все связанные с ним данные в одной транзакции
+func (db *PostgresDB) DeleteUser(ctx context.Context, userID int64) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции удаления пользователя: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Удаляем зависимые данные явно, не полагаясь на ON DELETE CASCADE
+	queries := []string{
+		`DELETE FROM user_sessions WHERE user_id = $1`,
+		`DELETE FROM user_exercises WHERE user_id = $1`,
+		`DELETE FROM conversation_messages WHERE conversation_id IN (SELECT id FROM conversations WHERE user_id = $1)`,
+		`DELETE FROM conversations WHERE user_id = $1`,
+		`DELETE FROM user_progress WHERE user_id = $1`,
+		`DELETE FROM user_achievements WHERE user_id = $1`,
+		`DELETE FROM user_vocabulary WHERE user_id = $1`,
+		`DELETE FROM writing_scores WHERE user_id = $1`,
+		`DELETE FROM speaking_scores WHERE user_id = $1`,
+		`DELETE FROM feedback WHERE user_id = $1`,
+		`DELETE FROM user_settings WHERE user_id = $1`,
+		`DELETE FROM users WHERE id = $1`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			return fmt.Errorf("ошибка удаления данных пользователя: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации удаления пользователя: %w", err)
+	}
+
+	return nil
+}
+
+// ResetUserProgress начинает обучение пользователя заново, сохраняя его учетную запись и настройки:
+// обнуляет прогресс, удаляет словарь и историю упражнений и возвращает уровень A1.
+// При keepAchievements полученные достижения сохраняются
+func (db *PostgresDB) ResetUserProgress(ctx context.Context, userID int64, keepAchievements bool) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции сброса прогресса: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+
+	queries := []string{
+		`DELETE FROM user_sessions WHERE user_id = $1`,
+		`DELETE FROM user_exercises WHERE user_id = $1`,
+		`DELETE FROM user_vocabulary WHERE user_id = $1`,
+		`DELETE FROM writing_scores WHERE user_id = $1`,
+		`DELETE FROM speaking_scores WHERE user_id = $1`,
+	}
+	if !keepAchievements {
+		queries = append(queries, `DELETE FROM user_achievements WHERE user_id = $1`)
+	}
+
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			return fmt.Errorf("ошибка сброса прогресса пользователя: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_progress
+		SET total_exercises = 0, correct_exercises = 0, total_conversations = 0, total_messages = 0,
+		    grammar_corrections = 0, current_streak = 0, longest_streak = 0, xp = 0,
+		    last_activity_date = $1, updated_at = $1
+		WHERE user_id = $2
+	`, now, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка обнуления прогресса пользователя: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET english_level = $1, level_changed_at = NULL, updated_at = $2
+		WHERE id = $3
+	`, EnglishLevels[0], now, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка сброса уровня пользователя: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации сброса прогресса: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateUserEnglishLevel изменяет уровень английского пользователя
+func (db *PostgresDB) UpdateUserEnglishLevel(ctx context.Context, userID int64, level string) error {
+	if !IsValidEnglishLevel(level) {
+		return fmt.Errorf("неизвестный уровень английского: %q", level)
+	}
+
+	query := `
+		UPDATE users
+		SET english_level = $1, level_changed_at = $2, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err := db.pool.Exec(ctx, query, level, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления уровня пользователя: %w", err)
+	}
+
+	return nil
+}
+
+// PromoteUserLevel повышает уровень пользователя с from до to, если уровень
+// не менялся после since. Условие проверяется атомарно, поэтому параллельные
+// вызовы повышают уровень только один раз. Возвращает true, если уровень изменен
+func (db *PostgresDB) PromoteUserLevel(ctx context.Context, userID int64, from, to string, since time.Time) (bool, error) {
+	if !IsValidEnglishLevel(to) {
+		return false, fmt.Errorf("неизвестный уровень английского: %q", to)
+	}
+
+	query := `
+		UPDATE users
+		SET english_level = $1, level_changed_at = $2, updated_at = $2
+		WHERE id = $3 AND english_level = $4
+		  AND (level_changed_at IS NULL OR level_changed_at < $5)
+	`
+
+	result, err := db.pool.Exec(ctx, query, to, time.Now(), userID, from, since)
+	if err != nil {
+		return false, fmt.Errorf("ошибка повышения уровня пользователя: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// AddVocabulary добавляет слово в словарь пользователя.
+// Если слово уже есть, обновляет перевод и примеры
+func (db *PostgresDB) AddVocabulary(ctx context.Context, vocabulary UserVocabulary) (*UserVocabulary, error) {
+	query := `
+		INSERT INTO user_vocabulary (user_id, word, translation, examples, mastery, last_review, next_review, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $5, $5, $5)
+		ON CONFLICT (user_id, word) DO UPDATE
+		SET translation = EXCLUDED.translation,
+		    examples = EXCLUDED.examples,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING id, mastery, last_review, next_review, created_at, updated_at
+	`
+
+	now := time.Now()
+	err := db.pool.QueryRow(ctx, query,
+		vocabulary.UserID,
+		vocabulary.Word,
+		vocabulary.Translation,
+		vocabulary.Examples,
+		now,
+	).Scan(
+		&vocabulary.ID,
+		&vocabulary.Mastery,
+		&vocabulary.LastReview,
+		&vocabulary.NextReview,
+		&vocabulary.CreatedAt,
+		&vocabulary.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка добавления слова в словарь: %w", err)
+	}
+
+	return &vocabulary, nil
+}
+
+// GetVocabularyForReview получает слова пользователя, которые пора повторить
+func (db *PostgresDB) GetVocabularyForReview(ctx context.Context, userID int64, limit int) ([]UserVocabulary, error) {
+	query := `
+		SELECT id, user_id, word, COALESCE(translation, ''), COALESCE(examples, ''), mastery,
+		       COALESCE(last_review, created_at), COALESCE(next_review, created_at), created_at, updated_at
+		FROM user_vocabulary
+		WHERE user_id = $1 AND COALESCE(next_review, created_at) <= $2
+		ORDER BY COALESCE(next_review, created_at) ASC
+		LIMIT $3
+	`
+
+	rows, err := db.pool.Query(ctx, query, userID, time.Now(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса слов для повторения: %w", err)
+	}
+
+	return scanVocabulary(rows)
+}
+
+// ListVocabulary получает страницу словаря пользователя, начиная с последних добавленных слов
+func (db *PostgresDB) ListVocabulary(ctx context.Context, userID int64, limit, offset int) ([]UserVocabulary, error) {
+	query := `
+		SELECT id, user_id, word, COALESCE(translation, ''), COALESCE(examples, ''), mastery,
+		       COALESCE(last_review, created_at), COALESCE(next_review, created_at), created_at, updated_at
+		FROM user_vocabulary
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := db.pool.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса словаря: %w", err)
+	}
+
+	return scanVocabulary(rows)
+}
+
+// UpdateVocabularyMastery обновляет степень усвоения слова и дату следующего повторения
+func (db *PostgresDB) UpdateVocabularyMastery(ctx context.Context, id int64, mastery int, nextReview time.Time) error {
+	query := `
+		UPDATE user_vocabulary
+		SET mastery = $1, last_review = $2, next_review = $3, updated_at = $2
+		WHERE id = $4
+	`
+
+	_, err := db.pool.Exec(ctx, query, mastery, time.Now(), nextReview, id)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления степени усвоения слова: %w", err)
+	}
+
+	return nil
+}
+
+// SaveFeedback сохраняет отзыв пользователя
+func (db *PostgresDB) SaveFeedback(ctx context.Context, userID int64, text string) error {
+	query := `
+		INSERT INTO feedback (user_id, text, created_at)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err := db.pool.Exec(ctx, query, userID, text, time.Now())
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения отзыва: %w", err)
+	}
+
+	return nil
+}
+
+// AddWritingScore сохраняет оценку письменной работы пользователя
+func (db *PostgresDB) AddWritingScore(ctx context.Context, score WritingScore) error {
+	query := `
+		INSERT INTO writing_scores (user_id, text, grammar, vocabulary, coherence, task_response, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := db.pool.Exec(ctx, query,
+		score.UserID,
+		score.Text,
+		score.Grammar,
+		score.Vocabulary,
+		score.Coherence,
+		score.TaskResponse,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения оценки письменной работы: %w", err)
+	}
+
+	return nil
+}
+
+// AddSpeakingScore сохраняет оценку чтения предложения вслух
+func (db *PostgresDB) AddSpeakingScore(ctx context.Context, score SpeakingScore) error {
+	query := `
+		INSERT INTO speaking_scores (user_id, exercise_id, target, transcript, score, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := db.pool.Exec(ctx, query,
+		score.UserID,
+		score.ExerciseID,
+		score.Target,
+		score.Transcript,
+		score.Score,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения оценки произношения: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecentWritingScores получает последние оценки письменных работ пользователя, начиная с новых
+func (db *PostgresDB) GetRecentWritingScores(ctx context.Context, userID int64, limit int) ([]WritingScore, error) {
+	query := `
+		SELECT id, user_id, text, grammar, vocabulary, coherence, task_response, created_at
+		FROM writing_scores
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := db.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса оценок письменных работ: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []WritingScore
+	for rows.Next() {
+		var score WritingScore
+		if err := rows.Scan(
+			&score.ID,
+			&score.UserID,
+			&score.Text,
+			&score.Grammar,
+			&score.Vocabulary,
+			&score.Coherence,
+			&score.TaskResponse,
+			&score.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения оценки письменной работы: %w", err)
+		}
+		scores = append(scores, score)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения оценок письменных работ: %w", err)
+	}
+
+	return scores, nil
+}
+
+// scanVocabulary читает строки словаря из результата запроса
+func scanVocabulary(rows pgx.Rows) ([]UserVocabulary, error) {
+	defer rows.Close()
+
+	var words []UserVocabulary
+	for rows.Next() {
+		var word UserVocabulary
+		if err := rows.Scan(
+			&word.ID,
+			&word.UserID,
+			&word.Word,
+			&word.Translation,
+			&word.Examples,
+			&word.Mastery,
+			&word.LastReview,
+			&word.NextReview,
+			&word.CreatedAt,
+			&word.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения слова из словаря: %w", err)
+		}
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения словаря: %w", err)
+	}
+
+	return words, nil
+}
+
+// GetVocabularyByID находит слово словаря по ID
+func (db *PostgresDB) GetVocabularyByID(ctx context.Context, id int64) (*UserVocabulary, error) {
+	query := `
+		SELECT id, user_id, word, COALESCE(translation, ''), COALESCE(examples, ''), mastery,
+		       COALESCE(last_review, created_at), COALESCE(next_review, created_at), created_at, updated_at
+		FROM user_vocabulary
+		WHERE id = $1
+	`
+
+	rows, err := db.pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса слова: %w", err)
+	}
+
+	words, err := scanVocabulary(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(words) == 0 {
+		return nil, nil // Слово не найдено
+	}
+
+	return &words[0], nil
+}
+
+// GetUsersForDailyWord получает пользователей, подписанных на слово дня и еще не получивших его после since
+func (db *PostgresDB) GetUsersForDailyWord(ctx context.Context, since time.Time) ([]NotificationRecipient, error) {
+	query := `
+		SELECT u.id, u.telegram_id, u.english_level, s.ui_language, s.notification_hour, s.last_daily_word_at,
+		       p.last_activity_date, COALESCE(p.current_streak, 0), COALESCE(u.timezone, '')
+		FROM user_settings s
+		JOIN users u ON u.id = s.user_id
+		LEFT JOIN user_progress p ON p.user_id = s.user_id
+		WHERE s.daily_word = true
+		  AND (s.last_daily_word_at IS NULL OR s.last_daily_word_at < $1)
+	`
+
+	rows, err := db.pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса подписчиков слова дня: %w", err)
+	}
+
+	return scanNotificationRecipients(rows)
+}
+
+// MarkDailyWordSent отмечает, что пользователь получил слово дня
+func (db *PostgresDB) MarkDailyWordSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	query := `
+		UPDATE user_settings
+		SET last_daily_word_at = $1
+		WHERE user_id = $2
+	`
+
+	_, err := db.pool.Exec(ctx, query, sentAt, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка отметки отправки слова дня: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsersNeedingReminder получает пользователей с включенными напоминаниями,
+// которые занимались в последние дни до dayStart и не получали напоминание после него.
+// Границы суток зависят от часового пояса пользователя, поэтому окно взято с запасом в сутки
+// в обе стороны, а точную проверку выполняет вызывающий код
+func (db *PostgresDB) GetUsersNeedingReminder(ctx context.Context, dayStart time.Time) ([]NotificationRecipient, error) {
+	query := `
+		SELECT u.id, u.telegram_id, u.english_level, s.ui_language, s.notification_hour, s.last_reminder_sent,
+		       p.last_activity_date, p.current_streak, COALESCE(u.timezone, '')
+		FROM user_settings s
+		JOIN users u ON u.id = s.user_id
+		JOIN user_progress p ON p.user_id = s.user_id
+		WHERE s.daily_reminders = true
+		  AND p.current_streak > 0
+		  AND p.last_activity_date >= $1
+		  AND p.last_activity_date < $2
+		  AND (s.last_reminder_sent IS NULL OR s.last_reminder_sent < $2)
+	`
+
+	rows, err := db.pool.Query(ctx, query, dayStart.AddDate(0, 0, -2), dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса пользователей для напоминания: %w", err)
+	}
+
+	return scanNotificationRecipients(rows)
+}
+
+// MarkReminderSent отмечает, что пользователю отправлено напоминание
+func (db *PostgresDB) MarkReminderSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	query := `
+		UPDATE user_settings
+		SET last_reminder_sent = $1
+		WHERE user_id = $2
+	`
+
+	_, err := db.pool.Exec(ctx, query, sentAt, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка отметки отправки напоминания: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsersForWeeklyReport получает пользователей, подписанных на еженедельный отчет
+// и еще не получивших его после начала недели weekStart
+func (db *PostgresDB) GetUsersForWeeklyReport(ctx context.Context, weekStart time.Time) ([]NotificationRecipient, error) {
+	query := `
+		SELECT u.id, u.telegram_id, u.english_level, s.ui_language, s.notification_hour, s.last_weekly_report_at,
+		       p.last_activity_date, COALESCE(p.current_streak, 0), COALESCE(u.timezone, '')
+		FROM user_settings s
+		JOIN users u ON u.id = s.user_id
+		LEFT JOIN user_progress p ON p.user_id = s.user_id
+		WHERE s.weekly_report = true
+		  AND (s.last_weekly_report_at IS NULL OR s.last_weekly_report_at < $1)
+	`
+
+	rows, err := db.pool.Query(ctx, query, weekStart)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса подписчиков еженедельного отчета: %w", err)
+	}
+
+	return scanNotificationRecipients(rows)
+}
+
+// MarkWeeklyReportSent отмечает, что пользователь получил еженедельный отчет
+func (db *PostgresDB) MarkWeeklyReportSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	query := `
+		UPDATE user_settings
+		SET last_weekly_report_at = $1
+		WHERE user_id = $2
+	`
+
+	_, err := db.pool.Exec(ctx, query, sentAt, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка отметки отправки еженедельного отчета: %w", err)
+	}
+
+	return nil
+}
+
+// CountExercisesBetween возвращает количество ответов пользователя на упражнения
+// и количество верных из них за период [from, to)
+func (db *PostgresDB) CountExercisesBetween(ctx context.Context, userID int64, from, to time.Time) (total, correct int, err error) {
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE is_correct)
+		FROM user_exercises
+		WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+	`
+
+	if err := db.pool.QueryRow(ctx, query, userID, from, to).Scan(&total, &correct); err != nil {
+		return 0, 0, fmt.Errorf("ошибка подсчета упражнений за период: %w", err)
+	}
+
+	return total, correct, nil
+}
+
+// CountVocabularyAddedBetween возвращает количество слов, добавленных в словарь пользователя за период [from, to)
+func (db *PostgresDB) CountVocabularyAddedBetween(ctx context.Context, userID int64, from, to time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM user_vocabulary
+		WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+	`
+
+	var count int
+	if err := db.pool.QueryRow(ctx, query, userID, from, to).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка подсчета новых слов за период: %w", err)
+	}
+
+	return count, nil
+}
+
+// scanNotificationRecipients читает получателей уведомлений из результата запроса
+func scanNotificationRecipients(rows pgx.Rows) ([]NotificationRecipient, error) {
+	defer rows.Close()
+
+	var recipients []NotificationRecipient
+	for rows.Next() {
+		var recipient NotificationRecipient
+		var lastSentAt, lastActivityDate *time.Time
+		if err := rows.Scan(
+			&recipient.UserID,
+			&recipient.TelegramID,
+			&recipient.EnglishLevel,
+			&recipient.UILanguage,
+			&recipient.NotificationHour,
+			&lastSentAt,
+			&lastActivityDate,
+			&recipient.CurrentStreak,
+			&recipient.Timezone,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка чтения получателя уведомления: %w", err)
+		}
+		// Время переводится в пояс сервера, чтобы сравнивать его с началом суток пользователя
+		if lastSentAt != nil {
+			recipient.LastSentAt = serverTime(*lastSentAt)
+		}
+		if lastActivityDate != nil {
+			recipient.LastActivityDate = serverTime(*lastActivityDate)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения получателей уведомлений: %w", err)
+	}
+
+	return recipients, nil
+}
+
+// activeStreak - текущая серия, которая не прервалась: последняя активность была не раньше вчерашнего дня
+const activeStreak = "CASE WHEN p.last_activity_date >= CURRENT_DATE - 1 THEN p.current_streak ELSE 0 END"
+
+// leaderboardQuery возвращает запрос, ранжирующий видимых в рейтинге пользователей по метрике.
+// При равенстве значений выше стоит пользователь с лучшей второй метрикой, затем зарегистрированный раньше
+func leaderboardQuery(metric string) (string, error) {
+	var value, tieBreaker string
+	switch metric {
+	case LeaderboardByStreak:
+		value, tieBreaker = activeStreak, "p.correct_exercises"
+	case LeaderboardByExercises:
+		value, tieBreaker = "p.correct_exercises", activeStreak
+	case LeaderboardByXP:
+		value, tieBreaker = "COALESCE(p.xp, 0)", "p.correct_exercises"
+	default:
+		return "", fmt.Errorf("неизвестная метрика таблицы лидеров: %q", metric)
+	}
+
+	return fmt.Sprintf(`
+		SELECT ROW_NUMBER() OVER (ORDER BY %[1]s DESC, %[2]s DESC, u.id ASC) AS rank,
+		       u.id, COALESCE(NULLIF(u.first_name, ''), NULLIF(u.username, ''), 'Learner'), %[1]s
+		FROM users u
+		JOIN user_progress p ON p.user_id = u.id
+		LEFT JOIN user_settings s ON s.user_id = u.id
+		WHERE COALESCE(s.leaderboard_visible, true) AND %[1]s > 0
+	`, value, tieBreaker), nil
+}
+
+// GetLeaderboard возвращает первые limit строк таблицы лидеров по метрике
+func (db *PostgresDB) GetLeaderboard(ctx context.Context, metric string, limit int) ([]LeaderboardEntry, error) {
+	ranking, err := leaderboardQuery(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT * FROM (` + ranking + `) AS ranked ORDER BY rank LIMIT $1`
+
+	rows, err := db.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса таблицы лидеров: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []LeaderboardEntry
+	for rows.Next() {
+		var entry LeaderboardEntry
+		if err := rows.Scan(&entry.Rank, &entry.UserID, &entry.Name, &entry.Value); err != nil {
+			return nil, fmt.Errorf("ошибка чтения таблицы лидеров: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения таблицы лидеров: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetLeaderboardEntry возвращает место пользователя в таблице лидеров.
+// Возвращает nil, если пользователь скрыт из рейтинга или еще не набрал очков
+func (db *PostgresDB) GetLeaderboardEntry(ctx context.Context, metric string, userID int64) (*LeaderboardEntry, error) {
+	ranking, err := leaderboardQuery(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT * FROM (` + ranking + `) AS ranked WHERE id = $1`
+
+	var entry LeaderboardEntry
+	err = db.pool.QueryRow(ctx, query, userID).Scan(&entry.Rank, &entry.UserID, &entry.Name, &entry.Value)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка получения места в таблице лидеров: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// TryRateLimitedRequest атомарно отмечает запрос пользователя к команде, если с предыдущего
+// запроса прошло не меньше window. Возвращает false, если запрос нужно отклонить
+func (db *PostgresDB) TryRateLimitedRequest(ctx context.Context, telegramID int64, command string, now time.Time, window time.Duration) (bool, error) {
+	query := `
+		INSERT INTO rate_limits (telegram_id, command, last_request)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (telegram_id, command) DO UPDATE
+		SET last_request = EXCLUDED.last_request
+		WHERE rate_limits.last_request <= $4
+		RETURNING telegram_id
+	`
+
+	var id int64
+	err := db.pool.QueryRow(ctx, query, telegramID, command, now, now.Add(-window)).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("ошибка проверки ограничения запросов: %w", err)
+	}
+
+	return true, nil
+}
+
+// TryRateLimitNotice атомарно отмечает предупреждение об ограничении, если с предыдущего
+// прошло не меньше window. Возвращает true, если пользователя нужно предупредить
+func (db *PostgresDB) TryRateLimitNotice(ctx context.Context, telegramID int64, command string, now time.Time, window time.Duration) (bool, error) {
+	query := `
+		UPDATE rate_limits
+		SET last_notice = $1
+		WHERE telegram_id = $2 AND command = $3
+		  AND (last_notice IS NULL OR last_notice <= $4)
+	`
+
+	result, err := db.pool.Exec(ctx, query, now, telegramID, command, now.Add(-window))
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки предупреждения об ограничении: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// DeleteRateLimitsOlderThan удаляет ограничения, последний запрос по которым был раньше cutoff,
+// и возвращает количество удаленных записей
+func (db *PostgresDB) DeleteRateLimitsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := db.pool.Exec(ctx, `DELETE FROM rate_limits WHERE last_request < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка очистки ограничений запросов: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,1396 @@
+package database
+
+import (
+	"context"
+	"io"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTelegramID выдает уникальные Telegram ID тестовым пользователям,
+// чтобы тесты не конфликтовали с уже существующими записями
+var testTelegramID atomic.Int64
+
+func init() {
+	testTelegramID.Store(-time.Now().UnixNano())
+}
+
+// newTestDB подключается к базе из TEST_DATABASE_URL и применяет миграции.
+// Без переменной окружения тест пропускается
+func newTestDB(t *testing.T) *PostgresDB {
+	t.Helper()
+
+	connString := os.Getenv("TEST_DATABASE_URL")
+	if connString == "" {
+		t.Skip("TEST_DATABASE_URL не задан, тест с базой данных пропущен")
+	}
+
+	db, err := NewPostgresDB(connString, 1, time.Second)
+	if err != nil {
+		t.Fatalf("ошибка подключения к тестовой базе: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	if err := db.Migrate(context.Background()); err != nil {
+		t.Fatalf("ошибка применения миграций: %v", err)
+	}
+
+	return db
+}
+
+// newTestUser создает пользователя с записью прогресса и удаляет его после теста
+func newTestUser(t *testing.T, db *PostgresDB) *User {
+	t.Helper()
+	ctx := context.Background()
+
+	user, err := db.CreateUser(ctx, User{TelegramID: testTelegramID.Add(-1), FirstName: "Test"})
+	if err != nil {
+		t.Fatalf("ошибка создания тестового пользователя: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := db.DeleteUser(context.Background(), user.ID); err != nil {
+			t.Errorf("ошибка удаления тестового пользователя: %v", err)
+		}
+	})
+
+	if _, err := db.CreateUserProgress(ctx, user.ID); err != nil {
+		t.Fatalf("ошибка создания прогресса тестового пользователя: %v", err)
+	}
+
+	return user
+}
+
+func TestGetConversationMessages(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	conversation, err := db.StartConversation(ctx, user.ID, "travel", "B1")
+	if err != nil {
+		t.Fatalf("StartConversation: %v", err)
+	}
+
+	contents := []string{"one", "two", "three", "four", "five"}
+	for i, content := range contents {
+		role := "user"
+		if i%2 == 1 {
+			role = "bot"
+		}
+		if _, err := db.AddConversationMessage(ctx, ConversationMessage{
+			ConversationID: conversation.ID,
+			Role:           role,
+			Content:        content,
+		}); err != nil {
+			t.Fatalf("AddConversationMessage: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{"последние сообщения", 3, []string{"three", "four", "five"}},
+		{"лимит больше истории", 10, contents},
+		{"одно сообщение", 1, []string{"five"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := db.GetConversationMessages(ctx, conversation.ID, tt.limit)
+			if err != nil {
+				t.Fatalf("GetConversationMessages: %v", err)
+			}
+			if len(messages) != len(tt.want) {
+				t.Fatalf("получено %d сообщений, ожидалось %d", len(messages), len(tt.want))
+			}
+			for i, message := range messages {
+				if message.Content != tt.want[i] {
+					t.Errorf("сообщение %d = %q, ожидалось %q", i, message.Content, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetActiveConversation(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	session, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+
+	active, err := db.GetActiveConversation(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetActiveConversation: %v", err)
+	}
+	if active != nil {
+		t.Fatalf("без диалога в сессии возвращен диалог %+v", active)
+	}
+
+	conversation, err := db.StartConversation(ctx, user.ID, "work", "A2")
+	if err != nil {
+		t.Fatalf("StartConversation: %v", err)
+	}
+	session.ConversationID = strconv.FormatInt(conversation.ID, 10)
+	if err := db.UpdateUserSession(ctx, *session); err != nil {
+		t.Fatalf("UpdateUserSession: %v", err)
+	}
+
+	active, err = db.GetActiveConversation(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetActiveConversation: %v", err)
+	}
+	if active == nil || active.ID != conversation.ID || active.Topic != "work" {
+		t.Errorf("активный диалог %+v, ожидался диалог %d", active, conversation.ID)
+	}
+}
+
+func TestStartConversationCountsConversations(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	first, err := db.StartConversation(ctx, user.ID, "travel", "A1")
+	if err != nil {
+		t.Fatalf("StartConversation: %v", err)
+	}
+	if _, err := db.StartConversation(ctx, user.ID, "work", "A1"); err != nil {
+		t.Fatalf("StartConversation: %v", err)
+	}
+
+	// Продолжение уже начатого диалога не считается новым диалогом
+	if _, err := db.AddConversationMessage(ctx, ConversationMessage{ConversationID: first.ID, Role: "user", Content: "I'm back"}); err != nil {
+		t.Fatalf("AddConversationMessage: %v", err)
+	}
+
+	progress, err := db.GetUserProgress(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserProgress: %v", err)
+	}
+	if progress.TotalConversations != 2 {
+		t.Errorf("total_conversations = %d, ожидалось 2", progress.TotalConversations)
+	}
+}
+
+// newTestExercise сохраняет упражнение заданного типа
+func newTestExercise(t *testing.T, db *PostgresDB, exerciseType string) *Exercise {
+	t.Helper()
+
+	exercise, err := db.SaveExercise(context.Background(), Exercise{
+		Type:    exerciseType,
+		Level:   "A1",
+		Content: "She ___ to school every day.",
+		Answer:  "goes",
+		Options: []string{"go", "goes", "going"},
+	})
+	if err != nil {
+		t.Fatalf("ошибка сохранения тестового упражнения: %v", err)
+	}
+
+	return exercise
+}
+
+func TestDeleteUser(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	// Заполняем все таблицы, связанные с пользователем
+	if _, err := db.GetOrCreateUserSession(ctx, user.ID); err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	exercise := newTestExercise(t, db, "grammar")
+	if _, err := db.SaveUserExercise(ctx, UserExercise{UserID: user.ID, ExerciseID: exercise.ID, UserAnswer: "goes", IsCorrect: true}); err != nil {
+		t.Fatalf("SaveUserExercise: %v", err)
+	}
+	conversation, err := db.StartConversation(ctx, user.ID, "travel", "A1")
+	if err != nil {
+		t.Fatalf("StartConversation: %v", err)
+	}
+	if _, err := db.AddConversationMessage(ctx, ConversationMessage{ConversationID: conversation.ID, Role: "user", Content: "Hello"}); err != nil {
+		t.Fatalf("AddConversationMessage: %v", err)
+	}
+	if err := db.AddUserAchievement(ctx, user.ID, "streak_7", "Week", "7 days in a row"); err != nil {
+		t.Fatalf("AddUserAchievement: %v", err)
+	}
+	if _, err := db.AddVocabulary(ctx, UserVocabulary{UserID: user.ID, Word: "apple", Translation: "яблоко"}); err != nil {
+		t.Fatalf("AddVocabulary: %v", err)
+	}
+	if _, err := db.CreateUserSettings(ctx, user.ID); err != nil {
+		t.Fatalf("CreateUserSettings: %v", err)
+	}
+	if err := db.AddWritingScore(ctx, WritingScore{UserID: user.ID, Text: "My day", Grammar: 7, Vocabulary: 6, Coherence: 8, TaskResponse: 7}); err != nil {
+		t.Fatalf("AddWritingScore: %v", err)
+	}
+	if err := db.AddSpeakingScore(ctx, SpeakingScore{UserID: user.ID, ExerciseID: exercise.ID, Target: "Hello there.", Transcript: "Hello there.", Score: 100}); err != nil {
+		t.Fatalf("AddSpeakingScore: %v", err)
+	}
+	if err := db.SaveFeedback(ctx, user.ID, "Great bot"); err != nil {
+		t.Fatalf("SaveFeedback: %v", err)
+	}
+
+	if err := db.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	tests := []struct {
+		table string
+		query string
+		id    int64
+	}{
+		{"users", `SELECT COUNT(*) FROM users WHERE id = $1`, user.ID},
+		{"user_sessions", `SELECT COUNT(*) FROM user_sessions WHERE user_id = $1`, user.ID},
+		{"user_exercises", `SELECT COUNT(*) FROM user_exercises WHERE user_id = $1`, user.ID},
+		{"conversations", `SELECT COUNT(*) FROM conversations WHERE user_id = $1`, user.ID},
+		{"conversation_messages", `SELECT COUNT(*) FROM conversation_messages WHERE conversation_id = $1`, conversation.ID},
+		{"user_progress", `SELECT COUNT(*) FROM user_progress WHERE user_id = $1`, user.ID},
+		{"user_achievements", `SELECT COUNT(*) FROM user_achievements WHERE user_id = $1`, user.ID},
+		{"user_vocabulary", `SELECT COUNT(*) FROM user_vocabulary WHERE user_id = $1`, user.ID},
+		{"user_settings", `SELECT COUNT(*) FROM user_settings WHERE user_id = $1`, user.ID},
+		{"writing_scores", `SELECT COUNT(*) FROM writing_scores WHERE user_id = $1`, user.ID},
+		{"speaking_scores", `SELECT COUNT(*) FROM speaking_scores WHERE user_id = $1`, user.ID},
+		{"feedback", `SELECT COUNT(*) FROM feedback WHERE user_id = $1`, user.ID},
+	}
+
+	for _, tt := range tests {
+		var count int
+		if err := db.pool.QueryRow(ctx, tt.query, tt.id).Scan(&count); err != nil {
+			t.Fatalf("ошибка подсчета строк в %s: %v", tt.table, err)
+		}
+		if count != 0 {
+			t.Errorf("в %s осталось %d строк удаленного пользователя", tt.table, count)
+		}
+	}
+}
+
+// saveTestAnswers сохраняет ответы пользователя на новые упражнения типа exerciseType:
+// correct правильных и wrong неправильных
+func saveTestAnswers(t *testing.T, db *PostgresDB, userID int64, exerciseType string, correct, wrong int) {
+	t.Helper()
+
+	for i := range correct + wrong {
+		exercise := newTestExercise(t, db, exerciseType)
+		if _, err := db.SaveUserExercise(context.Background(), UserExercise{
+			UserID:     userID,
+			ExerciseID: exercise.ID,
+			UserAnswer: "goes",
+			IsCorrect:  i < correct,
+		}); err != nil {
+			t.Fatalf("SaveUserExercise: %v", err)
+		}
+	}
+}
+
+func TestResetUserProgress(t *testing.T) {
+	tests := []struct {
+		name             string
+		keepAchievements bool
+		wantAchievements int
+	}{
+		{"с сохранением достижений", true, 1},
+		{"вместе с достижениями", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			ctx := context.Background()
+			user := newTestUser(t, db)
+
+			saveTestAnswers(t, db, user.ID, "grammar", 3, 1)
+			if _, err := db.AddVocabulary(ctx, UserVocabulary{UserID: user.ID, Word: "apple", Translation: "яблоко"}); err != nil {
+				t.Fatalf("AddVocabulary: %v", err)
+			}
+			if err := db.AddWritingScore(ctx, WritingScore{UserID: user.ID, Text: "My day", Grammar: 7, Vocabulary: 6, Coherence: 8, TaskResponse: 7}); err != nil {
+				t.Fatalf("AddWritingScore: %v", err)
+			}
+			if err := db.AwardAchievement(ctx, user.ID, ExerciseAchievementType(10)); err != nil {
+				t.Fatalf("AwardAchievement: %v", err)
+			}
+			if err := db.UpdateUserEnglishLevel(ctx, user.ID, "B1"); err != nil {
+				t.Fatalf("UpdateUserEnglishLevel: %v", err)
+			}
+
+			if err := db.ResetUserProgress(ctx, user.ID, tt.keepAchievements); err != nil {
+				t.Fatalf("ResetUserProgress: %v", err)
+			}
+
+			progress, err := db.GetUserProgress(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetUserProgress: %v", err)
+			}
+			if progress.TotalExercises != 0 || progress.CorrectExercises != 0 || progress.XP != 0 || progress.CurrentStreak != 0 {
+				t.Errorf("прогресс после сброса %+v, ожидались нули", progress)
+			}
+
+			reset, err := db.GetUserByTelegramID(ctx, user.TelegramID)
+			if err != nil {
+				t.Fatalf("GetUserByTelegramID: %v", err)
+			}
+			if reset == nil || reset.EnglishLevel != "A1" {
+				t.Errorf("пользователь после сброса %+v, ожидался уровень A1", reset)
+			}
+
+			counts := []struct {
+				table string
+				query string
+				want  int
+			}{
+				{"user_exercises", `SELECT COUNT(*) FROM user_exercises WHERE user_id = $1`, 0},
+				{"user_vocabulary", `SELECT COUNT(*) FROM user_vocabulary WHERE user_id = $1`, 0},
+				{"writing_scores", `SELECT COUNT(*) FROM writing_scores WHERE user_id = $1`, 0},
+				{"user_achievements", `SELECT COUNT(*) FROM user_achievements WHERE user_id = $1`, tt.wantAchievements},
+			}
+			for _, c := range counts {
+				var count int
+				if err := db.pool.QueryRow(ctx, c.query, user.ID).Scan(&count); err != nil {
+					t.Fatalf("ошибка подсчета строк в %s: %v", c.table, err)
+				}
+				if count != c.want {
+					t.Errorf("в %s %d строк после сброса, ожидалось %d", c.table, count, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetSkillStats(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	saveTestAnswers(t, db, user.ID, "grammar", 1, 3)
+	saveTestAnswers(t, db, user.ID, "vocabulary", 4, 1)
+	saveTestAnswers(t, db, user.ID, "translation", 2, 2)
+
+	stats, err := db.GetSkillStats(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetSkillStats: %v", err)
+	}
+
+	want := []SkillStat{
+		{ExerciseType: "grammar", Total: 4, Correct: 1},
+		{ExerciseType: "translation", Total: 4, Correct: 2},
+		{ExerciseType: "vocabulary", Total: 5, Correct: 4},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("статистика по %d навыкам, ожидалось %d: %+v", len(stats), len(want), stats)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("навык %d: %+v, ожидалось %+v", i, stats[i], want[i])
+		}
+	}
+}
+
+func TestGetExerciseTypeStats(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	// Статистика общая для всех пользователей: уникальные типы не пересекаются с ответами других тестов
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	listening, dictation := "listening_"+suffix, "dictation_"+suffix
+
+	first, second := newTestUser(t, db), newTestUser(t, db)
+	saveTestAnswers(t, db, first.ID, listening, 2, 1)
+	saveTestAnswers(t, db, second.ID, listening, 1, 0)
+	saveTestAnswers(t, db, second.ID, dictation, 0, 2)
+
+	// Пропуск считается попыткой, но не правильным ответом
+	if _, err := db.SaveUserExercise(ctx, UserExercise{
+		UserID:     first.ID,
+		ExerciseID: newTestExercise(t, db, dictation).ID,
+		Skipped:    true,
+	}); err != nil {
+		t.Fatalf("SaveUserExercise: %v", err)
+	}
+
+	stats, err := db.GetExerciseTypeStats(ctx)
+	if err != nil {
+		t.Fatalf("GetExerciseTypeStats: %v", err)
+	}
+
+	got := make(map[string]SkillStat)
+	for _, stat := range stats {
+		if stat.ExerciseType == listening || stat.ExerciseType == dictation {
+			got[stat.ExerciseType] = stat
+		}
+	}
+	want := map[string]SkillStat{
+		listening: {ExerciseType: listening, Total: 4, Correct: 3},
+		dictation: {ExerciseType: dictation, Total: 3, Skipped: 1},
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("статистика по типам %+v, ожидалось %+v", got, want)
+	}
+
+	if !slices.IsSortedFunc(stats, func(a, b SkillStat) int { return strings.Compare(a.ExerciseType, b.ExerciseType) }) {
+		t.Error("типы упражнений не отсортированы по названию")
+	}
+}
+
+func TestGetRecentExerciseResults(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	// Сначала 3 правильных ответа, затем 2 неправильных; ответы другого типа не учитываются
+	saveTestAnswers(t, db, user.ID, "grammar", 3, 2)
+	saveTestAnswers(t, db, user.ID, "vocabulary", 2, 0)
+
+	tests := []struct {
+		limit int
+		want  []bool
+	}{
+		{10, []bool{false, false, true, true, true}},
+		{3, []bool{false, false, true}},
+	}
+
+	for _, tt := range tests {
+		results, err := db.GetRecentExerciseResults(ctx, user.ID, "grammar", tt.limit)
+		if err != nil {
+			t.Fatalf("GetRecentExerciseResults: %v", err)
+		}
+		if !slices.Equal(results, tt.want) {
+			t.Errorf("последние %d результатов = %v, ожидалось %v", tt.limit, results, tt.want)
+		}
+	}
+}
+
+func TestCountExercisesBetween(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	saveTestAnswers(t, db, user.ID, "grammar", 3, 2)
+	for _, word := range []string{"apple", "river"} {
+		if _, err := db.AddVocabulary(ctx, UserVocabulary{UserID: user.ID, Word: word, Translation: word}); err != nil {
+			t.Fatalf("AddVocabulary: %v", err)
+		}
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name                     string
+		from, to                 time.Time
+		total, correct, newWords int
+	}{
+		{"текущая неделя", now.AddDate(0, 0, -7), now.Add(time.Hour), 5, 3, 2},
+		{"прошлая неделя", now.AddDate(0, 0, -14), now.AddDate(0, 0, -7), 0, 0, 0},
+		{"граница to не входит в период", now.Add(-time.Hour), now.Add(-time.Hour), 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, correct, err := db.CountExercisesBetween(ctx, user.ID, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("CountExercisesBetween: %v", err)
+			}
+			if total != tt.total || correct != tt.correct {
+				t.Errorf("упражнений %d, верных %d, ожидалось %d и %d", total, correct, tt.total, tt.correct)
+			}
+
+			newWords, err := db.CountVocabularyAddedBetween(ctx, user.ID, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("CountVocabularyAddedBetween: %v", err)
+			}
+			if newWords != tt.newWords {
+				t.Errorf("новых слов %d, ожидалось %d", newWords, tt.newWords)
+			}
+		})
+	}
+}
+
+func TestWeeklyReportSentOncePerWeek(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	settings, err := db.CreateUserSettings(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("CreateUserSettings: %v", err)
+	}
+	settings.WeeklyReport = true
+	if err := db.UpdateUserSettings(ctx, *settings); err != nil {
+		t.Fatalf("UpdateUserSettings: %v", err)
+	}
+
+	// isRecipient проверяет, ждет ли пользователь отчет за неделю, начавшуюся weekStart
+	isRecipient := func(weekStart time.Time) bool {
+		t.Helper()
+		recipients, err := db.GetUsersForWeeklyReport(ctx, weekStart)
+		if err != nil {
+			t.Fatalf("GetUsersForWeeklyReport: %v", err)
+		}
+		return slices.ContainsFunc(recipients, func(r NotificationRecipient) bool { return r.UserID == user.ID })
+	}
+
+	weekStart := time.Now().AddDate(0, 0, -3)
+	if !isRecipient(weekStart) {
+		t.Fatal("подписчик не получил бы отчет")
+	}
+
+	if err := db.MarkWeeklyReportSent(ctx, user.ID, time.Now()); err != nil {
+		t.Fatalf("MarkWeeklyReportSent: %v", err)
+	}
+	if isRecipient(weekStart) {
+		t.Error("отчет был бы отправлен повторно на той же неделе")
+	}
+	if !isRecipient(time.Now().AddDate(0, 0, 4)) {
+		t.Error("на следующей неделе отчет не был бы отправлен")
+	}
+
+	settings.WeeklyReport = false
+	if err := db.UpdateUserSettings(ctx, *settings); err != nil {
+		t.Fatalf("UpdateUserSettings: %v", err)
+	}
+	if isRecipient(time.Now().AddDate(0, 0, 4)) {
+		t.Error("отчет был бы отправлен отписавшемуся пользователю")
+	}
+}
+
+func TestSetUserReferrer(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user, referrer, other := newTestUser(t, db), newTestUser(t, db), newTestUser(t, db)
+
+	tests := []struct {
+		name       string
+		referrerID int64
+		want       bool
+	}{
+		{"приглашение самого себя", user.ID, false},
+		{"первое приглашение", referrer.ID, true},
+		{"повторное приглашение", other.ID, false},
+	}
+
+	for _, tt := range tests {
+		recorded, err := db.SetUserReferrer(ctx, user.ID, tt.referrerID)
+		if err != nil {
+			t.Fatalf("%s: SetUserReferrer: %v", tt.name, err)
+		}
+		if recorded != tt.want {
+			t.Errorf("%s: записано %v, ожидалось %v", tt.name, recorded, tt.want)
+		}
+	}
+}
+
+func TestGetUserExercises(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user, other := newTestUser(t, db), newTestUser(t, db)
+
+	// Семь попыток пользователя от старой к новой и одна чужая
+	var exerciseIDs []int64
+	for i := range 7 {
+		exercise := newTestExercise(t, db, "grammar")
+		if _, err := db.SaveUserExercise(ctx, UserExercise{UserID: user.ID, ExerciseID: exercise.ID, UserAnswer: "go", IsCorrect: i%2 == 0}); err != nil {
+			t.Fatalf("SaveUserExercise: %v", err)
+		}
+		exerciseIDs = append(exerciseIDs, exercise.ID)
+	}
+	saveTestAnswers(t, db, other.ID, "grammar", 1, 0)
+	slices.Reverse(exerciseIDs)
+
+	tests := []struct {
+		name          string
+		limit, offset int
+		want          []int64
+	}{
+		{"первая страница", 5, 0, exerciseIDs[:5]},
+		{"вторая страница", 5, 5, exerciseIDs[5:]},
+		{"за концом истории", 5, 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts, err := db.GetUserExercises(ctx, user.ID, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("GetUserExercises: %v", err)
+			}
+
+			var got []int64
+			for _, attempt := range attempts {
+				got = append(got, attempt.ExerciseID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("упражнения %v, ожидались %v", got, tt.want)
+			}
+		})
+	}
+
+	attempts, err := db.GetUserExercises(ctx, user.ID, 1, 0)
+	if err != nil || len(attempts) != 1 {
+		t.Fatalf("GetUserExercises: %v, %v", attempts, err)
+	}
+	want := ExerciseAttempt{
+		ID:         attempts[0].ID,
+		ExerciseID: exerciseIDs[0],
+		Type:       "grammar",
+		Content:    "She ___ to school every day.",
+		Answer:     "goes",
+		UserAnswer: "go",
+		IsCorrect:  true,
+		CreatedAt:  attempts[0].CreatedAt,
+	}
+	if attempts[0] != want {
+		t.Errorf("попытка %+v, ожидалось %+v", attempts[0], want)
+	}
+}
+
+func TestSaveFeedback(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	texts := []string{"The grammar check missed a mistake.", "Please add more reading texts."}
+	for _, text := range texts {
+		if err := db.SaveFeedback(ctx, user.ID, text); err != nil {
+			t.Fatalf("SaveFeedback: %v", err)
+		}
+	}
+
+	export, err := db.ExportUserData(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ExportUserData: %v", err)
+	}
+	var got []string
+	for _, feedback := range export.Feedback {
+		got = append(got, feedback.Text)
+		if feedback.CreatedAt.IsZero() {
+			t.Errorf("у отзыва %q нет времени создания", feedback.Text)
+		}
+	}
+	slices.Sort(got)
+	if want := slices.Sorted(slices.Values(texts)); !slices.Equal(got, want) {
+		t.Errorf("сохранены отзывы %q, ожидались %q", got, want)
+	}
+}
+
+func TestPromoteUserLevel(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+	since := time.Now().Add(-7 * 24 * time.Hour)
+
+	promoted, err := db.PromoteUserLevel(ctx, user.ID, "A1", "A2", since)
+	if err != nil {
+		t.Fatalf("PromoteUserLevel: %v", err)
+	}
+	if !promoted {
+		t.Fatal("уровень не повышен")
+	}
+
+	// Повторное повышение с того же уровня и повышение сразу после смены уровня не срабатывают
+	if promoted, _ := db.PromoteUserLevel(ctx, user.ID, "A1", "A2", since); promoted {
+		t.Error("уровень A1 повышен повторно")
+	}
+	if promoted, _ := db.PromoteUserLevel(ctx, user.ID, "A2", "B1", since); promoted {
+		t.Error("уровень повышен второй раз в пределах паузы")
+	}
+
+	updated, err := db.GetUserByTelegramID(ctx, user.TelegramID)
+	if err != nil {
+		t.Fatalf("GetUserByTelegramID: %v", err)
+	}
+	if updated.EnglishLevel != "A2" {
+		t.Errorf("уровень пользователя %s, ожидался A2", updated.EnglishLevel)
+	}
+
+	if _, err := db.PromoteUserLevel(ctx, user.ID, "A2", "D1", since); err == nil {
+		t.Error("PromoteUserLevel принял неизвестный уровень")
+	}
+}
+
+func TestLeaderboardQueryUnknownMetric(t *testing.T) {
+	if _, err := leaderboardQuery("messages"); err == nil {
+		t.Error("leaderboardQuery принял неизвестную метрику")
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	// first и second набрали поровну, поэтому выше стоит зарегистрированный раньше
+	leader := newTestUser(t, db)
+	first := newTestUser(t, db)
+	second := newTestUser(t, db)
+	hidden := newTestUser(t, db)
+
+	saveTestAnswers(t, db, leader.ID, "grammar", 5, 0)
+	saveTestAnswers(t, db, first.ID, "grammar", 3, 1)
+	saveTestAnswers(t, db, second.ID, "grammar", 3, 0)
+	saveTestAnswers(t, db, hidden.ID, "grammar", 9, 0)
+
+	settings, err := db.CreateUserSettings(ctx, hidden.ID)
+	if err != nil {
+		t.Fatalf("CreateUserSettings: %v", err)
+	}
+	settings.LeaderboardVisible = false
+	if err := db.UpdateUserSettings(ctx, *settings); err != nil {
+		t.Fatalf("UpdateUserSettings: %v", err)
+	}
+
+	entries, err := db.GetLeaderboard(ctx, LeaderboardByExercises, 1000)
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+
+	ranks := make(map[int64]int)
+	for _, entry := range entries {
+		ranks[entry.UserID] = entry.Rank
+	}
+	if _, ok := ranks[hidden.ID]; ok {
+		t.Error("скрытый пользователь попал в таблицу лидеров")
+	}
+	if !(ranks[leader.ID] < ranks[first.ID] && ranks[first.ID] < ranks[second.ID]) {
+		t.Errorf("места %d, %d, %d идут не по убыванию результата", ranks[leader.ID], ranks[first.ID], ranks[second.ID])
+	}
+
+	own, err := db.GetLeaderboardEntry(ctx, LeaderboardByExercises, second.ID)
+	if err != nil {
+		t.Fatalf("GetLeaderboardEntry: %v", err)
+	}
+	if own == nil || own.Rank != ranks[second.ID] || own.Value != 3 {
+		t.Errorf("место пользователя %+v, ожидалось %d со значением 3", own, ranks[second.ID])
+	}
+
+	if own, _ := db.GetLeaderboardEntry(ctx, LeaderboardByExercises, hidden.ID); own != nil {
+		t.Errorf("скрытый пользователь получил место %+v", own)
+	}
+}
+
+func TestSaveUserExerciseAwardsExerciseMilestone(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	// Пользователь уже получил младшие достижения и стоит в шаге от 100 упражнений
+	for _, count := range []int{10, 50} {
+		if err := db.AwardAchievement(ctx, user.ID, ExerciseAchievementType(count)); err != nil {
+			t.Fatalf("AwardAchievement: %v", err)
+		}
+	}
+	if _, err := db.pool.Exec(ctx, `UPDATE user_progress SET total_exercises = 99 WHERE user_id = $1`, user.ID); err != nil {
+		t.Fatalf("ошибка подготовки прогресса: %v", err)
+	}
+
+	saveTestAnswers(t, db, user.ID, "grammar", 1, 0)
+	saveTestAnswers(t, db, user.ID, "grammar", 0, 1)
+
+	achievements, err := db.GetUserAchievements(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserAchievements: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, achievement := range achievements {
+		counts[achievement.AchievementType]++
+	}
+	if len(achievements) != 3 || counts[ExerciseAchievementType(100)] != 1 {
+		t.Errorf("достижения после 100 упражнений %v, ожидалось одно новое exercises_100", counts)
+	}
+}
+
+// flakyDatabase принимает TCP-соединения вместо базы данных: первые rejects соединений
+// сразу закрывает, а следующие передает на target. Без target закрывает все соединения.
+// Возвращает адрес для строки подключения и счетчик принятых соединений
+func flakyDatabase(t *testing.T, rejects int32, target string) (string, *atomic.Int32) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ошибка запуска тестового сервера: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var connections atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if connections.Add(1) <= rejects || target == "" {
+				conn.Close()
+				continue
+			}
+
+			go func() {
+				defer conn.Close()
+				upstream, err := net.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+
+				go io.Copy(upstream, conn)
+				io.Copy(conn, upstream)
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), &connections
+}
+
+func TestNewPostgresDBGivesUpAfterAttempts(t *testing.T) {
+	addr, connections := flakyDatabase(t, 0, "")
+
+	start := time.Now()
+	db, err := NewPostgresDB("postgres://test:test@"+addr+"/test?sslmode=disable&connect_timeout=1", 3, 20*time.Millisecond)
+	if err == nil {
+		db.Close()
+		t.Fatal("NewPostgresDB подключился к серверу, который отклоняет соединения")
+	}
+
+	if !strings.Contains(err.Error(), "3 попыток") {
+		t.Errorf("ошибка %q не сообщает о количестве попыток", err)
+	}
+	if got := connections.Load(); got < 3 {
+		t.Errorf("сервер получил %d соединений, ожидалось не меньше 3", got)
+	}
+	// Паузы между попытками: 20мс и 40мс
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("попытки заняли %v, ожидалось не меньше 60мс", elapsed)
+	}
+}
+
+func TestNewPostgresDBWaitsForDatabase(t *testing.T) {
+	connString := os.Getenv("TEST_DATABASE_URL")
+	if connString == "" {
+		t.Skip("TEST_DATABASE_URL не задан, тест с базой данных пропущен")
+	}
+
+	target, err := url.Parse(connString)
+	if err != nil {
+		t.Fatalf("ошибка разбора TEST_DATABASE_URL: %v", err)
+	}
+
+	// База "запускается" позже бота: первые два соединения отклоняются
+	addr, connections := flakyDatabase(t, 2, target.Host)
+	target.Host = addr
+
+	db, err := NewPostgresDB(target.String(), 5, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewPostgresDB не дождался базы данных: %v", err)
+	}
+	defer db.Close()
+
+	if got := connections.Load(); got < 3 {
+		t.Errorf("сервер получил %d соединений, ожидалось не меньше 3", got)
+	}
+	if err := db.Ping(context.Background()); err != nil {
+		t.Errorf("Ping после подключения: %v", err)
+	}
+}
+
+func TestTryRateLimitedRequest(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	telegramID := testTelegramID.Add(-1)
+	t.Cleanup(func() {
+		db.pool.Exec(context.Background(), `DELETE FROM rate_limits WHERE telegram_id = $1`, telegramID)
+	})
+
+	// Время задается явно, чтобы результат не зависел от скорости базы
+	start := time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		name    string
+		command string
+		at      time.Duration
+		want    bool
+	}{
+		{"первый запрос", "", 0, true},
+		{"повтор сразу", "", 100 * time.Millisecond, false},
+		{"другая команда", "exercise", 200 * time.Millisecond, true},
+		{"повтор команды", "exercise", 500 * time.Millisecond, false},
+		{"после окна", "", time.Second, true},
+	}
+
+	for _, step := range steps {
+		allowed, err := db.TryRateLimitedRequest(ctx, telegramID, step.command, start.Add(step.at), time.Second)
+		if err != nil {
+			t.Fatalf("%s: TryRateLimitedRequest: %v", step.name, err)
+		}
+		if allowed != step.want {
+			t.Errorf("%s: запрос разрешен %v, ожидалось %v", step.name, allowed, step.want)
+		}
+	}
+}
+
+func TestTryRateLimitNotice(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	telegramID := testTelegramID.Add(-1)
+	t.Cleanup(func() {
+		db.pool.Exec(context.Background(), `DELETE FROM rate_limits WHERE telegram_id = $1`, telegramID)
+	})
+
+	start := time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := db.TryRateLimitedRequest(ctx, telegramID, "", start, time.Second); err != nil {
+		t.Fatalf("TryRateLimitedRequest: %v", err)
+	}
+
+	// Предупреждение разрешается не чаще одного раза за окно
+	for i, want := range []bool{true, false, true} {
+		notify, err := db.TryRateLimitNotice(ctx, telegramID, "", start.Add(time.Duration(i)*600*time.Millisecond), time.Second)
+		if err != nil {
+			t.Fatalf("TryRateLimitNotice: %v", err)
+		}
+		if notify != want {
+			t.Errorf("предупреждение %d разрешено %v, ожидалось %v", i+1, notify, want)
+		}
+	}
+
+	if removed, err := db.DeleteRateLimitsOlderThan(ctx, start.Add(time.Minute)); err != nil || removed < 1 {
+		t.Errorf("DeleteRateLimitsOlderThan удалил %d записей: %v", removed, err)
+	}
+	if allowed, _ := db.TryRateLimitedRequest(ctx, telegramID, "", start, time.Second); !allowed {
+		t.Error("после очистки запрос по-прежнему ограничен")
+	}
+}
+
+func TestSaveExerciseTopic(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		topic string
+	}{
+		{"тема выбрана", "prepositions"},
+		{"без темы", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved, err := db.SaveExercise(ctx, Exercise{
+				Type:    "grammar",
+				Level:   "A2",
+				Content: "The book is ___ the table.",
+				Topic:   tt.topic,
+			})
+			if err != nil {
+				t.Fatalf("SaveExercise: %v", err)
+			}
+
+			exercise, err := db.GetExerciseByID(ctx, saved.ID)
+			if err != nil {
+				t.Fatalf("GetExerciseByID: %v", err)
+			}
+			if exercise.Topic != tt.topic {
+				t.Errorf("тема %q, ожидалась %q", exercise.Topic, tt.topic)
+			}
+		})
+	}
+}
+
+func TestUserSessionWithoutConversationID(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	// Новая сессия создается без conversation_id и читается повторно без ошибки сканирования
+	created, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+	session, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("повторный GetOrCreateUserSession: %v", err)
+	}
+	if session.ID != created.ID || session.ConversationID != "" {
+		t.Fatalf("сессия %d с разговором %q, ожидалась сессия %d без разговора", session.ID, session.ConversationID, created.ID)
+	}
+
+	tests := []struct {
+		name           string
+		conversationID string
+	}{
+		{"разговор начат", "42"},
+		{"разговор завершен", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session.ConversationID = tt.conversationID
+			if err := db.UpdateUserSession(ctx, *session); err != nil {
+				t.Fatalf("UpdateUserSession: %v", err)
+			}
+
+			got, err := db.GetOrCreateUserSession(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetOrCreateUserSession: %v", err)
+			}
+			if got.ConversationID != tt.conversationID {
+				t.Errorf("разговор %q, ожидался %q", got.ConversationID, tt.conversationID)
+			}
+		})
+	}
+}
+
+func TestUserSettingsGrammarEngine(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	settings, err := db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserSettings: %v", err)
+	}
+	if settings.GrammarEngine != GrammarEngineBoth {
+		t.Errorf("способ проверки по умолчанию %q, ожидался %q", settings.GrammarEngine, GrammarEngineBoth)
+	}
+
+	settings.GrammarEngine = GrammarEngineLanguageTool
+	if err := db.UpdateUserSettings(ctx, *settings); err != nil {
+		t.Fatalf("UpdateUserSettings: %v", err)
+	}
+
+	settings, err = db.GetUserSettings(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserSettings: %v", err)
+	}
+	if settings.GrammarEngine != GrammarEngineLanguageTool {
+		t.Errorf("сохранен способ проверки %q, ожидался %q", settings.GrammarEngine, GrammarEngineLanguageTool)
+	}
+}
+
+func TestUpdateUserStreak(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, userID int64)
+		calls   int
+		want    int
+	}{
+		{"новый пользователь без прогресса", func(t *testing.T, userID int64) {
+			if _, err := db.pool.Exec(ctx, `DELETE FROM user_progress WHERE user_id = $1`, userID); err != nil {
+				t.Fatalf("ошибка удаления прогресса: %v", err)
+			}
+		}, 1, 1},
+		{"новый пользователь с прогрессом за сегодня", func(t *testing.T, userID int64) {}, 1, 1},
+		{"повтор в тот же день", func(t *testing.T, userID int64) { setTestActivity(t, db, userID, 5, now) }, 3, 5},
+		{"первая активность, повторенная за день", func(t *testing.T, userID int64) {}, 3, 1},
+		{"следующий день", func(t *testing.T, userID int64) { setTestActivity(t, db, userID, 3, now.AddDate(0, 0, -1)) }, 1, 4},
+		{"пропущенный день", func(t *testing.T, userID int64) { setTestActivity(t, db, userID, 3, now.AddDate(0, 0, -2)) }, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser(t, db)
+			if err := db.SetUserTimezone(ctx, user.ID, "UTC"); err != nil {
+				t.Fatalf("SetUserTimezone: %v", err)
+			}
+			tt.prepare(t, user.ID)
+
+			for range tt.calls {
+				if err := db.UpdateUserStreak(ctx, user.ID); err != nil {
+					t.Fatalf("UpdateUserStreak: %v", err)
+				}
+			}
+
+			progress, err := db.GetUserProgress(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetUserProgress: %v", err)
+			}
+			if progress.CurrentStreak != tt.want || progress.LongestStreak < tt.want {
+				t.Errorf("серия %d, самая длинная %d; ожидалась серия %d", progress.CurrentStreak, progress.LongestStreak, tt.want)
+			}
+		})
+	}
+}
+
+// newTestSession создает пользователя с сессией в состоянии state,
+// последняя активность в которой была в lastActivity
+func newTestSession(t *testing.T, db *PostgresDB, state string, lastActivity time.Time) *UserSession {
+	t.Helper()
+	ctx := context.Background()
+
+	user := newTestUser(t, db)
+	session, err := db.GetOrCreateUserSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserSession: %v", err)
+	}
+
+	session.State = state
+	if err := db.UpdateUserSession(ctx, *session); err != nil {
+		t.Fatalf("UpdateUserSession: %v", err)
+	}
+	if err := db.TouchUserSession(ctx, session.ID, lastActivity); err != nil {
+		t.Fatalf("TouchUserSession: %v", err)
+	}
+
+	return session
+}
+
+func TestGetStaleSessions(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		state        string
+		lastActivity time.Time
+		want         bool
+	}{
+		{"давний ответ на упражнение", "exercise_reply", now.Add(-time.Hour), true},
+		{"давний выбор упражнения", "exercise", now.Add(-time.Hour), true},
+		{"недавний ответ на упражнение", "exercise_reply", now.Add(-time.Minute), false},
+		{"давняя сессия без упражнения", "idle", now.Add(-time.Hour), false},
+		{"давняя проверка грамматики", "grammar_check", now.Add(-time.Hour), false},
+	}
+
+	sessions := make([]*UserSession, len(tests))
+	for i, tt := range tests {
+		sessions[i] = newTestSession(t, db, tt.state, tt.lastActivity)
+	}
+
+	stale, err := db.GetStaleSessions(ctx, []string{"exercise", "exercise_reply"}, now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatalf("GetStaleSessions: %v", err)
+	}
+
+	// В базе могут быть сессии других тестов, проверяем только созданные здесь
+	found := make(map[int64]StaleSession)
+	for _, session := range stale {
+		found[session.ID] = session
+	}
+
+	for i, tt := range tests {
+		session, ok := found[sessions[i].ID]
+		if ok != tt.want {
+			t.Errorf("%s: сессия выбрана %v, ожидалось %v", tt.name, ok, tt.want)
+			continue
+		}
+		if ok && (session.UserID != sessions[i].UserID || session.State != tt.state || session.TelegramID == 0) {
+			t.Errorf("%s: выбрана сессия %+v", tt.name, session)
+		}
+	}
+}
+
+func TestResetStaleSession(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+	before := now.Add(-30 * time.Minute)
+
+	tests := []struct {
+		name         string
+		from         string
+		lastActivity time.Time
+		want         bool
+	}{
+		{"давняя сессия сбрасывается", "exercise_reply", now.Add(-time.Hour), true},
+		{"пользователь успел ответить", "exercise_reply", now, false},
+		{"состояние успело смениться", "exercise", now.Add(-time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newTestSession(t, db, "exercise_reply", tt.lastActivity)
+
+			reset, err := db.ResetStaleSession(ctx, session.ID, tt.from, "idle", before)
+			if err != nil {
+				t.Fatalf("ResetStaleSession: %v", err)
+			}
+			if reset != tt.want {
+				t.Errorf("сессия сброшена %v, ожидалось %v", reset, tt.want)
+			}
+
+			saved, err := db.GetOrCreateUserSession(ctx, session.UserID)
+			if err != nil {
+				t.Fatalf("GetOrCreateUserSession: %v", err)
+			}
+			wantState := "exercise_reply"
+			if tt.want {
+				wantState = "idle"
+			}
+			if saved.State != wantState {
+				t.Errorf("состояние сессии %q, ожидалось %q", saved.State, wantState)
+			}
+		})
+	}
+}
+
+func TestAnswerVariants(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   []string
+	}{
+		{"", nil},
+		{"goes", []string{"goes"}},
+		{"happy / glad", []string{"happy", "glad"}},
+		{"like//love/ ", []string{"like", "love"}},
+	}
+
+	for _, tt := range tests {
+		if got := answerVariants(tt.answer); !slices.Equal(got, tt.want) {
+			t.Errorf("answerVariants(%q) = %q, ожидалось %q", tt.answer, got, tt.want)
+		}
+	}
+}
+
+func TestExerciseData(t *testing.T) {
+	exercise := Exercise{
+		Content:     "She ___ to school.",
+		Answer:      "goes/walks",
+		Options:     []string{"go", "goes"},
+		Explanation: "Present Simple",
+	}
+
+	data := exerciseData(exercise)
+	want := ExerciseData{Prompt: "She ___ to school.", Options: []string{"go", "goes"}, Answers: []string{"goes", "walks"}, Explanation: "Present Simple"}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("exerciseData = %+v, ожидалось %+v", data, want)
+	}
+
+	// Заполненные поля структурированного содержания не перезаписываются
+	exercise.Data = ExerciseData{Instruction: "Fill in the blank.", Prompt: "She ___ to school every day.", Answers: []string{"goes"}}
+	data = exerciseData(exercise)
+	if data.Instruction != "Fill in the blank." || data.Prompt != "She ___ to school every day." || !slices.Equal(data.Answers, []string{"goes"}) {
+		t.Errorf("exerciseData перезаписал заданное содержание: %+v", data)
+	}
+}
+
+func TestSaveExerciseData(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	want := ExerciseData{
+		Instruction: "Choose the correct word.",
+		Prompt:      "I am very _____ to see you.",
+		Options:     []string{"happy", "tired", "late", "cold"},
+		Answers:     []string{"happy", "glad"},
+		Explanation: "Adjectives after 'to be'.",
+	}
+
+	tests := []struct {
+		name     string
+		exercise Exercise
+		want     ExerciseData
+	}{
+		{"структурированное содержание", Exercise{Type: "vocabulary", Level: "A2", Content: want.Prompt, Answer: "happy/glad", Options: want.Options, Data: want}, want},
+		{"содержание из текстовых полей", Exercise{Type: "grammar", Level: "A1", Content: "She ___ home.", Answer: "goes", Options: []string{"go", "goes"}},
+			ExerciseData{Prompt: "She ___ home.", Options: []string{"go", "goes"}, Answers: []string{"goes"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved, err := db.SaveExercise(ctx, tt.exercise)
+			if err != nil {
+				t.Fatalf("SaveExercise: %v", err)
+			}
+
+			exercise, err := db.GetExerciseByID(ctx, saved.ID)
+			if err != nil {
+				t.Fatalf("GetExerciseByID: %v", err)
+			}
+			if !reflect.DeepEqual(exercise.Data, tt.want) {
+				t.Errorf("прочитано содержание %+v, ожидалось %+v", exercise.Data, tt.want)
+			}
+			if exercise.Answer != tt.exercise.Answer {
+				t.Errorf("ответ %q, ожидался %q", exercise.Answer, tt.exercise.Answer)
+			}
+		})
+	}
+}
+
+func TestSetExerciseExplanationUpdatesData(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	exercise := newTestExercise(t, db, "grammar")
+
+	if err := db.SetExerciseExplanation(ctx, exercise.ID, "Present Simple"); err != nil {
+		t.Fatalf("SetExerciseExplanation: %v", err)
+	}
+
+	saved, err := db.GetExerciseByID(ctx, exercise.ID)
+	if err != nil {
+		t.Fatalf("GetExerciseByID: %v", err)
+	}
+	if saved.Explanation != "Present Simple" || saved.Data.Explanation != "Present Simple" {
+		t.Errorf("объяснение %q, в содержании %q; ожидалось Present Simple", saved.Explanation, saved.Data.Explanation)
+	}
+	if saved.Data.Prompt != exercise.Content {
+		t.Errorf("после объяснения задание в содержании %q, ожидалось %q", saved.Data.Prompt, exercise.Content)
+	}
+}
+
+func TestExerciseDataMigrationBackfill(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	// Упражнение, сохраненное до появления структурированного содержания
+	var id int64
+	err := db.pool.QueryRow(ctx, `
+		INSERT INTO exercises (type, level, content, answer, options, created_at)
+		VALUES ('vocabulary', 'A2', 'I am _____.', 'happy / glad', '{happy,sad}', NOW())
+		RETURNING id
+	`).Scan(&id)
+	if err != nil {
+		t.Fatalf("ошибка сохранения упражнения без содержания: %v", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	index := slices.IndexFunc(migrations, func(m migration) bool { return m.name == "exercise_data" })
+	if index < 0 {
+		t.Fatal("миграция exercise_data не найдена")
+	}
+	if _, err := db.pool.Exec(ctx, migrations[index].sql); err != nil {
+		t.Fatalf("ошибка применения миграции: %v", err)
+	}
+
+	exercise, err := db.GetExerciseByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetExerciseByID: %v", err)
+	}
+	want := ExerciseData{Prompt: "I am _____.", Options: []string{"happy", "sad"}, Answers: []string{"happy", "glad"}}
+	if !reflect.DeepEqual(exercise.Data, want) {
+		t.Errorf("заполнено содержание %+v, ожидалось %+v", exercise.Data, want)
+	}
+	if exercise.Answer != "happy/glad" {
+		t.Errorf("ответ %q, ожидалось happy/glad", exercise.Answer)
+	}
+}
+
+func TestGetExerciseByID(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	saved := newTestExercise(t, db, "grammar")
+
+	exercise, err := db.GetExerciseByID(ctx, saved.ID)
+	if err != nil {
+		t.Fatalf("GetExerciseByID: %v", err)
+	}
+	if exercise == nil || exercise.ID != saved.ID || exercise.Content != saved.Content ||
+		exercise.Answer != saved.Answer || !slices.Equal(exercise.Options, saved.Options) {
+		t.Errorf("прочитано упражнение %+v, ожидалось %+v", exercise, saved)
+	}
+
+	missing, err := db.GetExerciseByID(ctx, -1)
+	if missing != nil || err != nil {
+		t.Errorf("GetExerciseByID несуществующего упражнения = %+v, %v; ожидалось nil, nil", missing, err)
+	}
+}
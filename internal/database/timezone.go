@@ -0,0 +1,41 @@
+package database
+
+import "time"
+
+// Location возвращает часовой пояс по имени из базы IANA.
+// Для пустого или неизвестного имени возвращается часовой пояс сервера
+func Location(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// Location возвращает часовой пояс пользователя
+func (u *User) Location() *time.Location {
+	return Location(u.Timezone)
+}
+
+// Location возвращает часовой пояс получателя уведомления
+func (r NotificationRecipient) Location() *time.Location {
+	return Location(r.Timezone)
+}
+
+// serverTime возвращает значение столбца TIMESTAMP в часовом поясе сервера.
+// Время записывается в такие столбцы по часам сервера без пояса, а pgx читает его как UTC,
+// поэтому перед переводом в пояс пользователя нужно восстановить исходный пояс
+func serverTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+}
+
+// sameDay проверяет, что два момента времени приходятся на одни сутки в часовом поясе loc
+func sameDay(a, b time.Time, loc *time.Location) bool {
+	ay, am, ad := a.In(loc).Date()
+	by, bm, bd := b.In(loc).Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// mustLoadLocation загружает часовой пояс из базы IANA или завершает тест
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("часовой пояс %s недоступен: %v", name, err)
+	}
+	return loc
+}
+
+// setTestActivity задает серию и время последней активности пользователя.
+// Время записывается по часам сервера, как его записывает UpdateUserStreak
+func setTestActivity(t *testing.T, db *PostgresDB, userID int64, streak int, lastActivity time.Time) {
+	t.Helper()
+
+	_, err := db.pool.Exec(context.Background(),
+		`UPDATE user_progress SET current_streak = $1, longest_streak = $1, last_activity_date = $2 WHERE user_id = $3`,
+		streak, lastActivity.In(time.Local), userID)
+	if err != nil {
+		t.Fatalf("ошибка обновления активности тестового пользователя: %v", err)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", time.Local.String()},
+		{"Europe/Moscow", "Europe/Moscow"},
+		{"UTC", "UTC"},
+		{"Mars/Olympus", time.Local.String()},
+	}
+
+	for _, tt := range tests {
+		if got := Location(tt.name).String(); got != tt.want {
+			t.Errorf("Location(%q) = %s, ожидалось %s", tt.name, got, tt.want)
+		}
+	}
+
+	user := User{Timezone: "Asia/Tokyo"}
+	recipient := NotificationRecipient{Timezone: "Asia/Tokyo"}
+	if user.Location().String() != "Asia/Tokyo" || recipient.Location().String() != "Asia/Tokyo" {
+		t.Errorf("часовые пояса пользователя %s и получателя %s, ожидался Asia/Tokyo", user.Location(), recipient.Location())
+	}
+}
+
+func TestSameDay(t *testing.T) {
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+
+	// 00:10 и 23:30 по Москве 10 марта - это 9 и 10 марта по UTC
+	lateEvening := time.Date(2024, 3, 10, 23, 30, 0, 0, moscow)
+	afterMidnight := time.Date(2024, 3, 10, 0, 10, 0, 0, moscow)
+	nextMorning := time.Date(2024, 3, 11, 0, 10, 0, 0, moscow)
+
+	tests := []struct {
+		name string
+		a, b time.Time
+		loc  *time.Location
+		want bool
+	}{
+		{"одни сутки по Москве", lateEvening, afterMidnight, moscow, true},
+		{"разные сутки по UTC", lateEvening, afterMidnight, time.UTC, false},
+		{"полночь разделяет сутки", lateEvening, nextMorning, moscow, false},
+		{"одни сутки по UTC", lateEvening, nextMorning, time.UTC, true},
+	}
+
+	for _, tt := range tests {
+		if got := sameDay(tt.a, tt.b, tt.loc); got != tt.want {
+			t.Errorf("%s: sameDay = %v, ожидалось %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServerTime(t *testing.T) {
+	// pgx читает TIMESTAMP без пояса как UTC, serverTime возвращает те же часы в поясе сервера
+	read := time.Date(2024, 3, 10, 23, 30, 15, 0, time.UTC)
+
+	got := serverTime(read)
+	if got.Location() != time.Local {
+		t.Errorf("пояс %s, ожидался пояс сервера", got.Location())
+	}
+	if got.Format(time.DateTime) != read.Format(time.DateTime) {
+		t.Errorf("время %s, ожидалось %s по часам сервера", got.Format(time.DateTime), read.Format(time.DateTime))
+	}
+}
+
+func TestSetUserTimezone(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	for _, timezone := range []string{"Asia/Almaty", ""} {
+		if err := db.SetUserTimezone(ctx, user.ID, timezone); err != nil {
+			t.Fatalf("SetUserTimezone(%q): %v", timezone, err)
+		}
+
+		got, err := db.GetUserByTelegramID(ctx, user.TelegramID)
+		if err != nil {
+			t.Fatalf("GetUserByTelegramID: %v", err)
+		}
+		if got.Timezone != timezone {
+			t.Errorf("часовой пояс %q, ожидался %q", got.Timezone, timezone)
+		}
+	}
+}
+
+func TestUpdateUserStreakInUserTimezone(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	// Пояса по разные стороны от UTC: хотя бы в одном из них сутки пользователя
+	// не совпадают с сутками сервера
+	for _, zone := range []string{"Pacific/Kiritimati", "Pacific/Honolulu"} {
+		loc := mustLoadLocation(t, zone)
+		localNow := time.Now().In(loc)
+		year, month, day := localNow.Date()
+		midnight := time.Date(year, month, day, 0, 0, 0, 0, loc)
+
+		tests := []struct {
+			name         string
+			lastActivity time.Time
+			want         int
+		}{
+			{"вчера перед полуночью", midnight.Add(-10 * time.Minute), 4},
+			{"сегодня после полуночи", midnight, 3},
+			{"позавчера перед полуночью", midnight.AddDate(0, 0, -1).Add(-10 * time.Minute), 1},
+		}
+
+		for _, tt := range tests {
+			t.Run(zone+"/"+tt.name, func(t *testing.T) {
+				user := newTestUser(t, db)
+				if err := db.SetUserTimezone(ctx, user.ID, zone); err != nil {
+					t.Fatalf("SetUserTimezone: %v", err)
+				}
+				setTestActivity(t, db, user.ID, 3, tt.lastActivity)
+
+				if err := db.UpdateUserStreak(ctx, user.ID); err != nil {
+					t.Fatalf("UpdateUserStreak: %v", err)
+				}
+
+				progress, err := db.GetUserProgress(ctx, user.ID)
+				if err != nil {
+					t.Fatalf("GetUserProgress: %v", err)
+				}
+				if progress.CurrentStreak != tt.want {
+					t.Errorf("серия %d, ожидалось %d", progress.CurrentStreak, tt.want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package database
+
+// Опыт (XP), который начисляется за действия пользователя и хранится в user_progress.xp.
+// Опыт дают ответы на упражнения, в том числе в сериях /practice, сообщения пользователя
+// в диалоге и викторины /quiz, у которых своя шкала с бонусом за ответы без ошибок.
+// Правильное упражнение ценится выше сообщения в диалоге, а попытка без правильного ответа - ниже всего
+const (
+	XPCorrectExercise  = 10
+	XPChatMessage      = 3
+	XPIncorrectAttempt = 1
+
+	// XPQuizCorrect - опыт за каждый правильный ответ викторины
+	XPQuizCorrect = 10
+	// XPQuizPerfectBonus - дополнительный опыт за викторину без ошибок
+	XPQuizPerfectBonus = 20
+)
+
+// Rank - звание пользователя, которое зависит от набранного опыта и не связано с уровнем CEFR
+type Rank struct {
+	Key   string // Ключ звания, название берется из каталога по ключу "rank.<Key>"
+	MinXP int    // Опыт, с которого присваивается звание
+}
+
+// Ranks - звания в порядке возрастания необходимого опыта
+var Ranks = []Rank{
+	{Key: "novice", MinXP: 0},
+	{Key: "explorer", MinXP: 100},
+	{Key: "apprentice", MinXP: 300},
+	{Key: "achiever", MinXP: 700},
+	{Key: "expert", MinXP: 1500},
+	{Key: "master", MinXP: 3000},
+	{Key: "legend", MinXP: 6000},
+}
+
+// RankForXP возвращает звание для опыта xp и следующее звание.
+// Для самого высокого звания следующее - nil
+func RankForXP(xp int) (Rank, *Rank) {
+	current := 0
+	for i, rank := range Ranks {
+		if xp >= rank.MinXP {
+			current = i
+		}
+	}
+
+	if current+1 < len(Ranks) {
+		return Ranks[current], &Ranks[current+1]
+	}
+	return Ranks[current], nil
+}
+
+// ExerciseXP возвращает опыт за correct правильных ответов из total попыток
+func ExerciseXP(total, correct int) int {
+	return correct*XPCorrectExercise + (total-correct)*XPIncorrectAttempt
+}
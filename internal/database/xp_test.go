@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRankForXP(t *testing.T) {
+	tests := []struct {
+		xp       int
+		wantRank string
+		wantNext string
+	}{
+		{0, "novice", "explorer"},
+		{99, "novice", "explorer"},
+		{100, "explorer", "apprentice"},
+		{299, "explorer", "apprentice"},
+		{300, "apprentice", "achiever"},
+		{1500, "expert", "master"},
+		{5999, "master", "legend"},
+		{6000, "legend", ""},
+		{100000, "legend", ""},
+	}
+
+	for _, tt := range tests {
+		rank, next := RankForXP(tt.xp)
+		nextKey := ""
+		if next != nil {
+			nextKey = next.Key
+		}
+		if rank.Key != tt.wantRank || nextKey != tt.wantNext {
+			t.Errorf("RankForXP(%d) = %q, следующее %q; ожидалось %q, %q", tt.xp, rank.Key, nextKey, tt.wantRank, tt.wantNext)
+		}
+	}
+}
+
+func TestRanksAscending(t *testing.T) {
+	if Ranks[0].MinXP != 0 {
+		t.Errorf("первое звание требует %d XP, ожидалось 0", Ranks[0].MinXP)
+	}
+	for i := 1; i < len(Ranks); i++ {
+		if Ranks[i].MinXP <= Ranks[i-1].MinXP {
+			t.Errorf("звание %q требует %d XP, не больше предыдущего %q", Ranks[i].Key, Ranks[i].MinXP, Ranks[i-1].Key)
+		}
+	}
+}
+
+func TestExerciseXP(t *testing.T) {
+	if !(XPCorrectExercise > XPChatMessage && XPChatMessage > XPIncorrectAttempt) {
+		t.Errorf("правильное упражнение %d, сообщение %d, ошибка %d XP: ожидался убывающий порядок",
+			XPCorrectExercise, XPChatMessage, XPIncorrectAttempt)
+	}
+
+	tests := []struct {
+		total   int
+		correct int
+		want    int
+	}{
+		{1, 1, XPCorrectExercise},
+		{1, 0, XPIncorrectAttempt},
+		{5, 3, 3*XPCorrectExercise + 2*XPIncorrectAttempt},
+		{0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := ExerciseXP(tt.total, tt.correct); got != tt.want {
+			t.Errorf("ExerciseXP(%d, %d) = %d, ожидалось %d", tt.total, tt.correct, got, tt.want)
+		}
+	}
+}
+
+func TestUserXP(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	user := newTestUser(t, db)
+
+	// xpOf возвращает опыт пользователя из его прогресса
+	xpOf := func(userID int64) int {
+		t.Helper()
+		progress, err := db.GetUserProgress(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetUserProgress: %v", err)
+		}
+		return progress.XP
+	}
+
+	saveTestAnswers(t, db, user.ID, "grammar", 1, 1)
+	want := XPCorrectExercise + XPIncorrectAttempt
+	if xp := xpOf(user.ID); xp != want {
+		t.Errorf("после двух ответов %d XP, ожидалось %d", xp, want)
+	}
+
+	// Опыт дают только сообщения самого пользователя
+	conversation, err := db.StartConversation(ctx, user.ID, "travel", "B1")
+	if err != nil {
+		t.Fatalf("StartConversation: %v", err)
+	}
+	for _, role := range []string{"user", "bot"} {
+		if _, err := db.AddConversationMessage(ctx, ConversationMessage{ConversationID: conversation.ID, Role: role, Content: "Hello"}); err != nil {
+			t.Fatalf("AddConversationMessage: %v", err)
+		}
+	}
+	want += XPChatMessage
+	if xp := xpOf(user.ID); xp != want {
+		t.Errorf("после диалога %d XP, ожидалось %d", xp, want)
+	}
+
+	// Серия начисляет переданный опыт и возвращает общий
+	answers := []UserExercise{
+		{UserID: user.ID, ExerciseID: newTestExercise(t, db, "grammar").ID, UserAnswer: "goes", IsCorrect: true},
+		{UserID: user.ID, ExerciseID: newTestExercise(t, db, "grammar").ID, UserAnswer: "go"},
+	}
+	total, err := db.SaveUserExercises(ctx, user.ID, answers, 40)
+	if err != nil {
+		t.Fatalf("SaveUserExercises: %v", err)
+	}
+	want += 40
+	if total != want || xpOf(user.ID) != want {
+		t.Errorf("после серии возвращено %d XP, сохранено %d, ожидалось %d", total, xpOf(user.ID), want)
+	}
+
+	entry, err := db.GetLeaderboardEntry(ctx, LeaderboardByXP, user.ID)
+	if err != nil {
+		t.Fatalf("GetLeaderboardEntry: %v", err)
+	}
+	if entry == nil || entry.Value != want {
+		t.Errorf("в рейтинге по опыту %+v, ожидалось значение %d", entry, want)
+	}
+}
@@ -0,0 +1,266 @@
+package i18n
+
+// english - каталог сообщений на английском языке. Используется для ключей,
+// которых нет в каталоге языка пользователя
+var english = map[string]string{
+	// Общие сообщения
+	"error.generic":                "Sorry, something went wrong. Please try again later.",
+	"error.ai_unavailable":         "🤖 The AI tutor is temporarily unavailable. Please try again in a minute.",
+	"error.ai_rate_limited":        "🤖 The AI tutor is getting too many requests right now. Please wait a minute and try again.",
+	"error.ai_unauthorized":        "🤖 The AI tutor is unavailable because of a configuration problem. We are already looking into it, please try again later.",
+	"error.ai_timeout":             "🤖 The AI tutor is taking too long to answer. Please try again.",
+	"error.db_unavailable":         "🗄 We can't reach our storage right now, so your progress can't be loaded or saved. Please try again in a few minutes.",
+	"callback.inactive":            "This button is no longer active: your data was deleted. Send /start to begin again.",
+	"callback.saved":               "Saved ✅",
+	"notice.rate_limit":            "⏳ You're sending messages too fast, please wait a moment",
+	"notice.admin_only":            "⛔ Sorry, this command is available only to administrators.",
+	"help.title":                   "*Available commands:*",
+	"help.unknown_command":         "Unknown command. Use /help to see available commands.",
+	"settings.on":                  "On",
+	"settings.off":                 "Off",
+	"menu.cancelled":               "Okay, back to the main menu.",
+	"menu.unknown_state":           "I'm not sure what you want to do. Here are some options:\n\n• Use /chat to practice speaking English\n• Use /check to check grammar\n• Use /exercise to get a learning exercise\n• Use /progress to see your stats",
+	"start.welcome":                "👋 *Welcome to English Learning Bot!*\n\nI'm here to help you learn English in an interactive and fun way. You can:\n• Chat with me in English\n• Check your grammar\n• Get personalized exercises\n• Track your progress\n\nUse /help to see all available commands.",
+	"chat.started":                 "🗣️ *Let's practice English!*\n\nI'll be your conversation partner. Feel free to talk about anything you want.\nJust type your message in English, and I'll respond.",
+	"chat.started_topic":           "🗣️ *Let's practice English!*\n\nTopic: *%s*. I'll be your conversation partner — start with anything related to it.\nJust type your message in English, and I'll respond.",
+	"chat.choose_topic":            "💬 What would you like to talk about?",
+	"chat.unknown_topic":           "I don't know this topic. Please choose one:",
+	"chat.topic_chosen":            "💬 Topic: %s",
+	"chat.topic.travel":            "✈️ Travel",
+	"chat.topic.food":              "🍝 Food",
+	"chat.topic.business":          "💼 Business",
+	"chat.topic.smalltalk":         "☕ Small talk",
+	"chat.topic.free":              "🗣️ Free conversation",
+	"topics.title":                 "🗂️ *Topics for level %s*",
+	"topics.chat":                  "💬 *Talk about:*",
+	"topics.exercises":             "📝 *Practice:*",
+	"topics.footer":                "Tap a topic to start a conversation or get an exercise on it.",
+	"endchat.no_chat":              "You're not in a conversation right now. Use /chat to start one.",
+	"endchat.empty":                "👋 Conversation finished. You didn't send any messages, so there's nothing to review.",
+	"endchat.analyzing":            "🔍 Reviewing your messages...",
+	"endchat.title":                "🏁 *Conversation finished!*\n\nI reviewed your %d messages.\n\n",
+	"endchat.work_on":              "*Here's what to work on:*",
+	"endchat.no_mistakes":          "🎉 I didn't find any recurring mistakes. Great job!",
+	"endchat.next":                 "\n\nUse /chat to start a new conversation.",
+	"check.started":                "✅ *Grammar Check Mode*\n\nSend me a sentence or paragraph in English, and I'll check it for grammar mistakes.\nI'll explain any errors and suggest corrections.",
+	"check.progress":               "🔍 Checking grammar...",
+	"check.result":                 "✅ *Grammar Check Result*\n\n%s",
+	"check.explanation":            "%s💡 *Explanation*\n\n%s",
+	"check.no_issues":              "✅ Your text is grammatically correct! Great job!",
+	"check.issues_found":           "🔍 Found %d issue(s):",
+	"check.issue":                  "*Issue*: %s",
+	"check.context":                "*Context*: %s*%s*%s",
+	"check.suggestions":            "*Suggestions*: %s",
+	"moderation.refused":           "🙅 Sorry, I can't help with that. Let's keep our practice friendly — try another message.",
+	"write.started":                "✍️ *Writing practice*\n\nWrite a paragraph of at least %d words in English — for example, about your weekend, your job or your plans — and send it to me.\nI'll grade it for grammar, vocabulary, coherence and task response.",
+	"write.too_short":              "Please write at least %d words so I can grade your text.",
+	"write.grading":                "📝 Grading your text...",
+	"write.result":                 "📝 *Writing feedback*\n\n• Grammar: *%d/%d*\n• Vocabulary: *%d/%d*\n• Coherence: *%d/%d*\n• Task response: *%d/%d*\n\n*Overall:* %.1f/%d",
+	"write.suggestions":            "\n\n💡 *Suggestions:*",
+	"write.next":                   "\n\nSend /write to submit another text.",
+	"message.unsupported":          "🙈 I can only work with text and voice messages here. Please type your message or send a voice note.",
+	"edit.resubmitted":             "✏️ Got your edit — checking the corrected text.",
+	"edit.ignored":                 "✏️ I noticed you edited this message, but I only re-read edits to answers I'm waiting for. Please send the corrected text as a new message.",
+	"voice.empty":                  "🎙️ I couldn't hear anything in your message. Please try again.",
+	"voice.heard":                  "🎙️ I heard: \"%s\"",
+	"voice.download_failed":        "🎙️ I couldn't fetch your audio. Please send the voice message again.",
+	"pronounce.usage":              "🔊 Send the text you want to hear, for example:\n/pronounce How are you today?",
+	"level.current":                "🎯 *Your English level:* %s\n\nChoose a new level if this one feels too easy or too hard:",
+	"level.unknown":                "Unknown level.",
+	"level.changed":                "✅ Your English level is now *%s*.\n\nExercises and conversations will adapt to it.",
+	"level.up":                     "🚀 *Level up!*\n\nGreat progress — you're now at level *%s*. Exercises and conversations will be a bit more challenging from now on.",
+	"reset.prompt":                 "♻️ *Start from scratch?*\n\nYour progress, streaks, vocabulary and exercise history will be erased and your level will go back to A1. Your account and settings stay.\n\nDo you want to keep your achievements?",
+	"reset.keep_button":            "♻️ Reset, keep achievements",
+	"reset.all_button":             "♻️ Reset everything",
+	"reset.cancel_button":          "Cancel",
+	"reset.cancelled":              "👍 Nothing was reset.",
+	"reset.done_toast":             "Reset ✅",
+	"reset.done":                   "✅ Your progress has been reset. Your level is A1 again — send /exercise to begin.",
+	"forget.prompt":                "🗑️ *Delete all your data?*\n\nThis will permanently erase your progress, vocabulary, exercises, conversations and settings. This cannot be undone.",
+	"forget.confirm_button":        "🗑️ Yes, delete everything",
+	"forget.cancel_button":         "Cancel",
+	"forget.cancelled":             "👍 Nothing was deleted.",
+	"forget.deleted_toast":         "Deleted ✅",
+	"forget.deleted":               "✅ All your data has been deleted. Send /start if you ever want to begin again.",
+	"export.caption":               "📦 All your data: profile, settings, progress, vocabulary, exercises, writing, conversations and feedback. Send /forgetme if you want it deleted.",
+	"achievements.title":           "🏅 *Your Achievements*",
+	"achievements.none":            "No achievements yet — keep practicing!",
+	"achievements.locked":          "*Still to unlock:*",
+	"reminder.streak":              "🔥 Don't lose your %d-day streak!\n\nA few minutes is enough: try /exercise, /review or /chat.",
+	"daily.subscribed":             "🌟 You're subscribed to the word of the day! I'll send you a new word every day at %02d:00.\nChange the time in /settings or send /daily again to unsubscribe.",
+	"daily.unsubscribed":           "🔕 You've unsubscribed from the word of the day. Send /daily to subscribe again.",
+	"daily.word":                   "🌟 *Word of the day:* %s\n🇷🇺 %s\n\n📖 %s\n\n💬 %s\n\nThe word has been added to your /vocab.",
+	"weekly.title":                 "📅 *Your week in English*\n\n✏️ Exercises: *%d* (%s)\n🎯 Success rate: *%d%%* (%s)\n📚 New words: *%d* (%s)\n🔥 Current streak: *%d* days\n\nIn brackets: change from the previous week.",
+	"weekly.no_change":             "same as last week",
+	"weekly.quiet":                 "📅 *Your week in English*\n\nNo exercises or new words this week. A few minutes a day is enough — try /exercise or /review!",
+	"admin.stats":                  "📈 *Bot Statistics*\n\n• Users: *%d*\n• Active today: *%d*\n• Active this week: *%d*\n• Exercise answers: *%d*\n• Conversation messages: *%d*",
+	"admin.reloaded":               "🔄 Bot commands have been re-registered with Telegram.",
+	"broadcast.usage":              "Usage: /broadcast <text>",
+	"broadcast.started":            "📣 Broadcasting to %d users...",
+	"broadcast.finished":           "📣 Broadcast finished.\n\n✅ Sent: %d\n🚫 Blocked the bot: %d\n❌ Failed: %d",
+	"settings.title":               "⚙️ *Settings*\n\n• Daily reminders: *%s*\n• Word of the day: *%s*\n• Weekly report: *%s*\n• Show me on the leaderboard: *%s*\n• Grammar check: *%s*\n• Interface language: *%s*\n• Notification time: *%02d:00*\n• Time zone: *%s* (change with /timezone)\n\nTap a button below to change a setting.",
+	"settings.reminders":           "🔔 Reminders: %s",
+	"settings.daily_word":          "🌟 Word of the day: %s",
+	"settings.weekly_report":       "📅 Weekly report: %s",
+	"settings.leaderboard":         "🏆 Leaderboard: %s",
+	"settings.grammar_engine":      "✍️ Grammar check: %s",
+	"settings.engine.both":         "LanguageTool + AI",
+	"settings.engine.languagetool": "LanguageTool only",
+	"settings.engine.ai":           "AI only",
+	"settings.language":            "🌐 Language: %s",
+	"leaderboard.streak":           "🏆 Top learners by current streak",
+	"leaderboard.exercises":        "🏆 Top learners by correct exercises",
+	"leaderboard.xp":               "🏆 Top learners by XP",
+	"leaderboard.unit_days":        "days",
+	"leaderboard.unit_tasks":       "exercises",
+	"leaderboard.empty":            "Nobody is on the leaderboard yet. Be the first!",
+	"leaderboard.you":              "(you)",
+	"leaderboard.unranked":         "You're not on the leaderboard yet. Keep practicing to get ranked!",
+	"leaderboard.footer":           "Also try %s. You can hide yourself from the leaderboard in /settings.",
+
+	// Упражнения
+	"exercise.type.grammar":       "✏️ Grammar",
+	"exercise.type.vocabulary":    "🔤 Vocabulary",
+	"exercise.type.translation":   "🔁 Translation",
+	"exercise.type.reading":       "📖 Reading",
+	"exercise.type.speaking":      "🎙️ Speaking",
+	"exercise.choose_type":        "📚 What kind of exercise would you like?",
+	"exercise.unknown_type":       "Unknown exercise type.",
+	"exercise.type_chosen":        "📚 Exercise type: %s",
+	"exercise.choose_topic":       "✏️ Which grammar topic would you like to practice?",
+	"exercise.topic_chosen":       "✏️ Grammar topic: %s",
+	"exercise.topic.tenses":       "⏳ Tenses",
+	"exercise.topic.articles":     "🅰️ Articles",
+	"exercise.topic.prepositions": "📍 Prepositions",
+	"exercise.topic.any":          "🎲 Any topic",
+	"exercise.generating":         "🔄 Generating exercise for your level, please wait...",
+	"exercise.offline":            "⚠️ The AI is unavailable right now, so here is an exercise from the offline collection.",
+	"exercise.generating_level":   "🔄 Generating a %s exercise based on your recent results, please wait...",
+	"exercise.none_active":        "You don't have an active exercise. Use /exercise to get one.",
+	"exercise.not_found":          "I couldn't find your exercise. Use /exercise to get a new one.",
+	"exercise.inactive":           "This exercise is no longer active.",
+	"exercise.unavailable":        "This exercise is no longer available.",
+	"exercise.answer_failed":      "Sorry, I couldn't get the answer for this one.",
+	"exercise.skipped":            "⏭️ Skipped. The answer was:\n\n%s",
+	"exercise.timed_out":          "⏰ Time's up! The answer was:\n\n%s\n\nSend /exercise for a new one.",
+	"exercise.prompt_type":        "📚 *Exercise*\n\n%s\n\nType your answer when ready.",
+	"exercise.prompt_choose":      "📚 *Exercise*\n\n%s\n\nChoose the correct answer or type it.",
+	"exercise.checking":           "🔍 Checking your answer...",
+	"exercise.correct_answer":     "%s\n\nCorrect answer: %s",
+	"exercise.hints_used":         "%s\n\n💡 Hints used: %d (−%d points)",
+	"exercise.correct":            "🎉 *Correct!* Score: *%d/100*\n\n%s",
+	"exercise.answer.perfect":     "Perfect! Your answer is correct.",
+	"exercise.answer.almost":      "Almost correct! There are some minor errors in your answer.",
+	"exercise.answer.partial":     "Partially correct. Your answer contains the right elements but has some issues.",
+	"exercise.answer.incorrect":   "Your answer is incorrect. Please try again.",
+	"exercise.incorrect":          "❌ *Not quite right.* Score: *%d/100*\n\n%s",
+	"exercise.your_answer":        "%s\n\n👉 Your answer: %s %s",
+	"exercise.explain_button":     "📖 Explain",
+	"exercise.explanation":        "📖 *Rule explanation*\n\n%s",
+	"exercise.next":               "Would you like another exercise? Use /exercise to get one.",
+	"hint.limit":                  "You've used all hints for this exercise. Give it your best try, or use /skip.",
+	"hint.message":                "💡 Hint %d/%d: %s\n\n(Each hint costs %d points.)",
+	"hint.first_letter":           "The answer starts with \"%c\".",
+	"hint.words":                  "The answer has %d words.",
+	"hint.letters":                "The answer is one word of %d letters.",
+	"reading.intro":               "📖 *Reading*\n\n%s\n\nRead the text and answer %d questions, one at a time.",
+	"reading.question":            "❓ *Question %d/%d*\n\n%s",
+	"reading.correct":             "✅ *Question %d/%d: correct!* Score: *%d/100*\n\n%s",
+	"reading.incorrect":           "❌ *Question %d/%d: not quite.* Score: *%d/100*\n\n%s\n\nExpected answer: %s",
+	"reading.summary":             "You answered %d of %d questions correctly.",
+	"reading.answers_failed":      "Sorry, I couldn't get the answers for this one.",
+	"speaking.intro":              "🎙️ *Speaking*\n\n%s\n\nRead the sentence aloud and send it as a voice message. Use /hint to hear it first.",
+	"speaking.voice_required":     "🎙️ Please read the sentence aloud and send a voice message.",
+	"speaking.all_words":          "Every word came through clearly.",
+	"speaking.missed":             "These words didn't come through clearly: %s",
+	"practice.usage":              "Usage: /practice [n], where n is the number of exercises from 1 to %d (default %d).",
+	"practice.choose_type":        "🏋️ A set of %d exercises. What kind would you like?",
+	"practice.started":            "🏋️ Practice set: %d × %s",
+	"practice.next":               "➡️ Exercise %d of %d",
+	"practice.complete":           "🏁 *Practice complete!*",
+	"practice.correct":            "• Correct: *%d/%d*",
+	"practice.skipped":            "• Skipped: *%d*",
+	"practice.average":            "• Average score: *%d/100*",
+	"practice.item_skipped":       "%d. ⏭️ skipped",
+	"practice.again":              "Use /practice to start another set.",
+	"quiz.started":                "🎯 Quiz time! %d quick questions of different kinds. Answer as well as you can.",
+	"quiz.next":                   "🎯 Question %d of %d",
+	"quiz.score":                  "Score so far: %d/%d",
+	"quiz.complete":               "🏁 *Quiz complete!*",
+	"quiz.result":                 "You got *%d/%d* right.",
+	"quiz.grade.perfect":          "🏆 Perfect score!",
+	"quiz.grade.great":            "🌟 Great job!",
+	"quiz.grade.good":             "👍 Good effort!",
+	"quiz.grade.keep_going":       "💪 Keep practicing, you'll get there!",
+	"quiz.xp":                     "✨ +%d XP",
+	"quiz.xp_total":               "(total %d XP)",
+	"quiz.master":                 "🎯 Achievement unlocked: *Quiz master*",
+	"quiz.again":                  "Use /quiz to play again.",
+
+	// Словарь
+	"vocab.help":             "📖 *Your vocabulary*\n\n• /vocab add <word> - <translation> - save a new word\n• /vocab list - show your saved words",
+	"vocab.add_usage":        "Please use the format: /vocab add <word> - <translation>\nFor example: /vocab add apple - яблоко",
+	"vocab.saved":            "✅ Saved: %s - %s",
+	"vocab.empty":            "Your vocabulary is empty. Add a word with /vocab add <word> - <translation>",
+	"vocab.page_empty":       "There are no more words on this page.",
+	"vocab.page_title":       "📖 Your vocabulary (page %d):",
+	"vocab.next_page":        "Next page: /vocab list %d",
+	"history.empty":          "You haven't done any exercises yet. Send /exercise to start!",
+	"history.page_empty":     "There are no more exercises on this page.",
+	"history.title":          "🗂️ Your exercise history (page %d):",
+	"history.your_answer":    "Your answer: %s",
+	"history.correct_answer": "Correct answer: %s",
+	"history.skipped":        "Skipped",
+	"history.prev":           "⬅️ Newer",
+	"history.next":           "Older ➡️",
+	"feedback.usage":         "Tell us what went wrong or what could be better: /feedback <text>\nFor example: /feedback The grammar check marked a correct sentence as wrong.",
+	"feedback.thanks":        "💌 Thank you for your feedback! We read every message.",
+	"feedback.admin":         "💌 Feedback from %s (user %d):\n\n%s",
+	"timezone.current":       "🕒 Your time zone: %s\n\nDays for your streak and notification times are counted in this zone. To change it, send /timezone <zone>, for example /timezone Europe/Moscow or /timezone America/New_York.",
+	"timezone.server":        "not set (server time)",
+	"timezone.invalid":       "❌ Unknown time zone \"%s\". Use a name like Europe/Moscow, Asia/Almaty or UTC.",
+	"timezone.saved":         "✅ Time zone set to %s. Your local time is %s.",
+	"me.profile":             "🪪 *Your profile*\n\n• Name: %s\n• Username: %s\n• English level: *%s*\n• Learning since: %s\n\n*Settings:*\n• Daily reminders: *%s*\n• Interface language: *%s*\n• Grammar check: *%s*\n• Time zone: *%s*\n\n*Streak:* %d days (longest: %d)\n\nChange your settings with /settings.",
+	"me.no_username":         "not set",
+	"vocab.nothing_due":      "🎉 You have no words to review right now. Add new ones with /vocab add <word> - <translation>",
+	"vocab.ask_word":         "🔁 What is the English word for: %s?",
+	"vocab.ask_translation":  "🔁 How do you translate: %s?",
+	"vocab.review_correct":   "✅ Correct! %s - %s %s",
+	"vocab.review_incorrect": "❌ The answer is: %s - %s",
+	"vocab.review_done":      "🎉 Review complete! Come back later for the next words.",
+
+	// Прогресс
+	"progress.summary":                          "📊 *Your Learning Progress*\n\n*Current Level:* %s (%.0f%% completed)\n*Rank:* %s\n\n*Statistics:*\n• Exercises completed: %d\n• Success rate: %.1f%%\n• Conversations: %d\n• Messages exchanged: %d\n• Learning streak: %d days\n• Longest streak: %d days\n\n*Your Strengths:*\n",
+	"progress.weaknesses":                       "\n*Areas to Improve:*\n",
+	"progress.by_type":                          "\n*Success by exercise type:*\n",
+	"progress.writing":                          "\n*Writing:* last text %.1f/10, average of the last %d: %.1f/10\n",
+	"progress.recommendations":                  "\n*Recommendations:*\n",
+	"progress.skill.grammar":                    "Grammar",
+	"progress.skill.vocabulary":                 "Vocabulary",
+	"progress.skill.translation":                "Translation",
+	"progress.skill.listening":                  "Listening",
+	"progress.skill.speaking":                   "Speaking",
+	"progress.skill.reading":                    "Reading",
+	"progress.recommend.grammar.tenses":         "Practice verb tenses",
+	"progress.recommend.grammar.conditionals":   "Work on conditional sentences",
+	"progress.recommend.vocabulary.daily":       "Learn new words daily",
+	"progress.recommend.vocabulary.flashcards":  "Review vocabulary with flashcards",
+	"progress.recommend.listening.podcasts":     "Listen to English podcasts",
+	"progress.recommend.listening.videos":       "Watch English videos with subtitles",
+	"progress.recommend.speaking.conversations": "Practice conversations",
+	"progress.recommend.speaking.record":        "Record and listen to yourself speaking",
+	"progress.recommend.reading.articles":       "Read English articles",
+	"progress.recommend.reading.comprehension":  "Practice reading comprehension",
+	"progress.recommend.translation.both_ways":  "Translate short texts both ways",
+	"progress.recommend.translation.compare":    "Compare your translations with native examples",
+	"progress.ready":                            "\n🎉 *Congratulations!* You're ready to advance to level %s!\n",
+	"progress.rank":                             "%s — %d XP, %d XP to %s",
+	"progress.rank_max":                         "%s — %d XP, the highest rank",
+	"rank.novice":                               "🌱 Novice",
+	"rank.explorer":                             "🧭 Explorer",
+	"rank.apprentice":                           "📘 Apprentice",
+	"rank.achiever":                             "🎖 Achiever",
+	"rank.expert":                               "💎 Expert",
+	"rank.master":                               "👑 Master",
+	"rank.legend":                               "🌟 Legend",
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Поддерживаемые языки интерфейса
+const (
+	English = "en"
+	Russian = "ru"
+)
+
+// DefaultLanguage - язык интерфейса, если язык пользователя не поддерживается
+const DefaultLanguage = English
+
+// catalogs - каталоги сообщений по языкам. Ключ сообщения - его идентификатор, например "exercise.correct"
+var catalogs = map[string]map[string]string{
+	English: english,
+	Russian: russian,
+}
+
+// IsSupported проверяет, есть ли каталог сообщений для языка
+func IsSupported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Resolve выбирает язык интерфейса: язык из настроек пользователя, если он поддерживается,
+// иначе язык клиента Telegram (например "ru" для "ru-RU"), иначе язык по умолчанию
+func Resolve(uiLanguage, languageCode string) string {
+	if IsSupported(uiLanguage) {
+		return uiLanguage
+	}
+
+	base, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	if IsSupported(base) {
+		return base
+	}
+
+	return DefaultLanguage
+}
+
+// Lookup возвращает сообщение по ключу только из каталога указанного языка
+func Lookup(lang, key string) (string, bool) {
+	message, ok := catalogs[lang][key]
+	return message, ok
+}
+
+// T возвращает сообщение по ключу на языке lang. Если в каталоге языка ключа нет,
+// используется английский каталог, а если нет и в нем - сам ключ.
+// Аргументы подставляются в сообщение через fmt.Sprintf
+func T(lang, key string, args ...any) string {
+	message, ok := Lookup(lang, key)
+	if !ok {
+		message, ok = Lookup(DefaultLanguage, key)
+	}
+	if !ok {
+		message = key
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
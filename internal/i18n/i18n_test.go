@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTFallsBackToEnglish(t *testing.T) {
+	english["test.only_english"] = "Only in English: %d"
+	t.Cleanup(func() { delete(english, "test.only_english") })
+
+	if got := T(Russian, "test.only_english", 3); got != "Only in English: 3" {
+		t.Errorf("T без перевода на русский = %q, ожидался английский текст", got)
+	}
+	if _, ok := Lookup(Russian, "test.only_english"); ok {
+		t.Error("Lookup нашел ключ, которого нет в русском каталоге")
+	}
+}
+
+func TestTMissingKey(t *testing.T) {
+	if got := T(Russian, "test.missing"); got != "test.missing" {
+		t.Errorf("T для неизвестного ключа = %q, ожидался сам ключ", got)
+	}
+}
+
+func TestTUnsupportedLanguage(t *testing.T) {
+	key := "exercise.choose_type"
+	if got := T("de", key); got != english[key] {
+		t.Errorf("T на неподдерживаемом языке = %q, ожидался английский текст %q", got, english[key])
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		uiLanguage   string
+		languageCode string
+		want         string
+	}{
+		{"ru", "en", Russian},
+		{"en", "ru", English},
+		{"", "ru", Russian},
+		{"", "ru-RU", Russian},
+		{"", "RU", Russian},
+		{"", "en-GB", English},
+		{"", "de", DefaultLanguage},
+		{"", "", DefaultLanguage},
+		{"fr", "ru", Russian},
+	}
+
+	for _, tt := range tests {
+		if got := Resolve(tt.uiLanguage, tt.languageCode); got != tt.want {
+			t.Errorf("Resolve(%q, %q) = %q, ожидался %q", tt.uiLanguage, tt.languageCode, got, tt.want)
+		}
+	}
+}
+
+// formatVerb находит подстановки fmt в сообщении
+var formatVerb = regexp.MustCompile(`%[-+# 0]*\d*(?:\.\d+)?[a-zA-Z%]`)
+
+func TestCatalogsMatch(t *testing.T) {
+	for lang, catalog := range catalogs {
+		if lang == DefaultLanguage {
+			continue
+		}
+
+		for key, message := range english {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: нет перевода ключа %q", lang, key)
+				continue
+			}
+			// Аргументы передаются в одном порядке для всех языков
+			if want, got := formatVerb.FindAllString(message, -1), formatVerb.FindAllString(translated, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: в %q подстановки %q, в английском %q", lang, key, got, want)
+			}
+		}
+
+		// Английские описания команд заданы в списке команд бота, в каталоге только переводы
+		for key := range catalog {
+			if _, ok := english[key]; !ok && !strings.HasPrefix(key, "command.") {
+				t.Errorf("%s: ключа %q нет в английском каталоге", lang, key)
+			}
+		}
+	}
+}
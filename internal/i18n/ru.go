@@ -0,0 +1,296 @@
+package i18n
+
+// russian - каталог сообщений на русском языке
+var russian = map[string]string{
+	// Общие сообщения
+	"error.generic":                "Извините, что-то пошло не так. Попробуйте позже.",
+	"error.ai_unavailable":         "🤖 ИИ-репетитор временно недоступен. Попробуйте еще раз через минуту.",
+	"error.ai_rate_limited":        "🤖 Сейчас к ИИ-репетитору слишком много запросов. Подождите минуту и попробуйте снова.",
+	"error.ai_unauthorized":        "🤖 ИИ-репетитор недоступен из-за ошибки настройки. Мы уже разбираемся, попробуйте позже.",
+	"error.ai_timeout":             "🤖 ИИ-репетитор слишком долго отвечает. Попробуйте еще раз.",
+	"error.db_unavailable":         "🗄 Хранилище данных сейчас недоступно, поэтому прогресс не может быть загружен или сохранен. Попробуйте через несколько минут.",
+	"callback.inactive":            "Кнопка неактивна: данные удалены. Отправьте /start, чтобы начать заново.",
+	"callback.saved":               "Сохранено ✅",
+	"notice.rate_limit":            "⏳ Вы отправляете сообщения слишком часто, подождите немного",
+	"notice.admin_only":            "⛔ Извините, эта команда доступна только администраторам.",
+	"help.title":                   "*Доступные команды:*",
+	"help.unknown_command":         "Неизвестная команда. Отправьте /help, чтобы увидеть список команд.",
+	"settings.on":                  "Вкл",
+	"settings.off":                 "Выкл",
+	"menu.cancelled":               "Хорошо, возвращаемся в главное меню.",
+	"menu.unknown_state":           "Не совсем понял, что вы хотите сделать. Вот что можно попробовать:\n\n• /chat - поговорить на английском\n• /check - проверить грамматику\n• /exercise - получить упражнение\n• /progress - посмотреть статистику",
+	"start.welcome":                "👋 *Добро пожаловать в English Learning Bot!*\n\nЯ помогу вам учить английский интерактивно и с удовольствием. Вы можете:\n• Общаться со мной на английском\n• Проверять грамматику\n• Получать упражнения под свой уровень\n• Следить за своим прогрессом\n\nОтправьте /help, чтобы увидеть все команды.",
+	"chat.started":                 "🗣️ *Давайте практиковать английский!*\n\nЯ буду вашим собеседником, можно говорить о чем угодно.\nПросто напишите сообщение на английском, и я отвечу.",
+	"chat.started_topic":           "🗣️ *Давайте практиковать английский!*\n\nТема: *%s*. Я буду вашим собеседником — начните с чего угодно на эту тему.\nПросто напишите сообщение на английском, и я отвечу.",
+	"chat.choose_topic":            "💬 О чем хотите поговорить?",
+	"chat.unknown_topic":           "Такой темы я не знаю. Выберите одну из списка:",
+	"chat.topic_chosen":            "💬 Тема: %s",
+	"chat.topic.travel":            "✈️ Путешествия",
+	"chat.topic.food":              "🍝 Еда",
+	"chat.topic.business":          "💼 Бизнес",
+	"chat.topic.smalltalk":         "☕ Светская беседа",
+	"chat.topic.free":              "🗣️ Свободная беседа",
+	"topics.title":                 "🗂️ *Темы для уровня %s*",
+	"topics.chat":                  "💬 *Поговорить о:*",
+	"topics.exercises":             "📝 *Потренировать:*",
+	"topics.footer":                "Нажмите на тему, чтобы начать диалог или получить упражнение по ней.",
+	"endchat.no_chat":              "Сейчас вы не в диалоге. Начните его командой /chat.",
+	"endchat.empty":                "👋 Диалог завершен. Вы не отправили ни одного сообщения, так что разбирать нечего.",
+	"endchat.analyzing":            "🔍 Разбираю ваши сообщения...",
+	"endchat.title":                "🏁 *Диалог завершен!*\n\nСообщений разобрано: %d.\n\n",
+	"endchat.work_on":              "*Над чем стоит поработать:*",
+	"endchat.no_mistakes":          "🎉 Повторяющихся ошибок не нашлось. Отличная работа!",
+	"endchat.next":                 "\n\nЧтобы начать новый диалог, отправьте /chat.",
+	"check.started":                "✅ *Проверка грамматики*\n\nОтправьте предложение или абзац на английском, и я проверю его на ошибки.\nЯ объясню ошибки и предложу исправления.",
+	"check.progress":               "🔍 Проверяю грамматику...",
+	"check.result":                 "✅ *Результат проверки грамматики*\n\n%s",
+	"check.explanation":            "%s💡 *Пояснения*\n\n%s",
+	"check.no_issues":              "✅ В тексте нет грамматических ошибок! Отличная работа!",
+	"check.issues_found":           "🔍 Найдено ошибок: %d",
+	"check.issue":                  "*Ошибка*: %s",
+	"check.context":                "*Где*: %s*%s*%s",
+	"check.suggestions":            "*Варианты исправления*: %s",
+	"moderation.refused":           "🙅 Извините, с этим я помочь не могу. Давайте заниматься дружелюбно — попробуйте другое сообщение.",
+	"write.started":                "✍️ *Письменная практика*\n\nНапишите на английском абзац не короче %d слов — например, о выходных, работе или планах — и отправьте мне.\nЯ оценю грамматику, словарный запас, связность текста и раскрытие темы.",
+	"write.too_short":              "Напишите, пожалуйста, не меньше %d слов, чтобы текст можно было оценить.",
+	"write.grading":                "📝 Оцениваю текст...",
+	"write.result":                 "📝 *Оценка текста*\n\n• Грамматика: *%d/%d*\n• Словарный запас: *%d/%d*\n• Связность: *%d/%d*\n• Раскрытие темы: *%d/%d*\n\n*Итого:* %.1f/%d",
+	"write.suggestions":            "\n\n💡 *Советы:*",
+	"write.next":                   "\n\nОтправьте /write, чтобы сдать еще один текст.",
+	"message.unsupported":          "🙈 Здесь я понимаю только текстовые и голосовые сообщения. Напишите сообщение или отправьте голосовое.",
+	"edit.resubmitted":             "✏️ Вижу исправление — проверяю исправленный текст.",
+	"edit.ignored":                 "✏️ Вижу, что вы исправили сообщение, но заново я читаю только исправленные ответы, которых жду. Отправьте исправленный текст новым сообщением.",
+	"voice.empty":                  "🎙️ Не удалось ничего расслышать в вашем сообщении. Попробуйте еще раз.",
+	"voice.heard":                  "🎙️ Я услышал: \"%s\"",
+	"voice.download_failed":        "🎙️ Не удалось получить ваше аудио. Пожалуйста, отправьте голосовое сообщение еще раз.",
+	"pronounce.usage":              "🔊 Отправьте текст, который хотите услышать, например:\n/pronounce How are you today?",
+	"level.current":                "🎯 *Ваш уровень английского:* %s\n\nВыберите другой уровень, если этот кажется слишком легким или сложным:",
+	"level.unknown":                "Неизвестный уровень.",
+	"level.changed":                "✅ Ваш уровень английского теперь *%s*.\n\nУпражнения и диалоги подстроятся под него.",
+	"level.up":                     "🚀 *Новый уровень!*\n\nОтличный прогресс — теперь ваш уровень *%s*. Упражнения и диалоги станут немного сложнее.",
+	"reset.prompt":                 "♻️ *Начать с нуля?*\n\nПрогресс, серии, словарь и история упражнений будут удалены, а уровень вернется к A1. Учетная запись и настройки сохранятся.\n\nСохранить ваши достижения?",
+	"reset.keep_button":            "♻️ Сбросить, сохранив достижения",
+	"reset.all_button":             "♻️ Сбросить все",
+	"reset.cancel_button":          "Отмена",
+	"reset.cancelled":              "👍 Ничего не сброшено.",
+	"reset.done_toast":             "Сброшено ✅",
+	"reset.done":                   "✅ Ваш прогресс сброшен. Ваш уровень снова A1 — отправьте /exercise, чтобы начать.",
+	"forget.prompt":                "🗑️ *Удалить все ваши данные?*\n\nБудут безвозвратно удалены прогресс, словарь, упражнения, диалоги и настройки. Это действие нельзя отменить.",
+	"forget.confirm_button":        "🗑️ Да, удалить все",
+	"forget.cancel_button":         "Отмена",
+	"forget.cancelled":             "👍 Ничего не удалено.",
+	"forget.deleted_toast":         "Удалено ✅",
+	"forget.deleted":               "✅ Все ваши данные удалены. Отправьте /start, если захотите начать заново.",
+	"export.caption":               "📦 Все ваши данные: профиль, настройки, прогресс, словарь, упражнения, письменные работы, диалоги и отзывы. Отправьте /forgetme, если хотите их удалить.",
+	"achievements.title":           "🏅 *Ваши достижения*",
+	"achievements.none":            "Достижений пока нет — продолжайте заниматься!",
+	"achievements.locked":          "*Еще можно получить:*",
+	"reminder.streak":              "🔥 Не прерывайте серию занятий: %d дн. подряд!\n\nДостаточно нескольких минут: попробуйте /exercise, /review или /chat.",
+	"daily.subscribed":             "🌟 Вы подписались на слово дня! Я буду присылать новое слово каждый день в %02d:00.\nИзменить время можно в /settings, отписаться - снова отправив /daily.",
+	"daily.unsubscribed":           "🔕 Вы отписались от слова дня. Отправьте /daily, чтобы подписаться снова.",
+	"daily.word":                   "🌟 *Слово дня:* %s\n🇷🇺 %s\n\n📖 %s\n\n💬 %s\n\nСлово добавлено в ваш словарь /vocab.",
+	"weekly.title":                 "📅 *Ваша неделя английского*\n\n✏️ Упражнения: *%d* (%s)\n🎯 Верных ответов: *%d%%* (%s)\n📚 Новые слова: *%d* (%s)\n🔥 Текущая серия: *%d* дн.\n\nВ скобках: изменение по сравнению с прошлой неделей.",
+	"weekly.no_change":             "как на прошлой неделе",
+	"weekly.quiet":                 "📅 *Ваша неделя английского*\n\nНа этой неделе не было упражнений и новых слов. Достаточно нескольких минут в день — попробуйте /exercise или /review!",
+	"admin.stats":                  "📈 *Статистика бота*\n\n• Пользователей: *%d*\n• Активны сегодня: *%d*\n• Активны за неделю: *%d*\n• Ответов на упражнения: *%d*\n• Сообщений в диалогах: *%d*",
+	"admin.reloaded":               "🔄 Команды бота заново зарегистрированы в Telegram.",
+	"broadcast.usage":              "Использование: /broadcast <текст>",
+	"broadcast.started":            "📣 Рассылка для %d пользователей...",
+	"broadcast.finished":           "📣 Рассылка завершена.\n\n✅ Отправлено: %d\n🚫 Заблокировали бота: %d\n❌ Ошибок: %d",
+	"settings.title":               "⚙️ *Настройки*\n\n• Ежедневные напоминания: *%s*\n• Слово дня: *%s*\n• Еженедельный отчет: *%s*\n• Показывать меня в рейтинге: *%s*\n• Проверка грамматики: *%s*\n• Язык интерфейса: *%s*\n• Время уведомлений: *%02d:00*\n• Часовой пояс: *%s* (изменить: /timezone)\n\nНажмите кнопку ниже, чтобы изменить настройку.",
+	"settings.reminders":           "🔔 Напоминания: %s",
+	"settings.daily_word":          "🌟 Слово дня: %s",
+	"settings.weekly_report":       "📅 Отчет за неделю: %s",
+	"settings.leaderboard":         "🏆 Рейтинг: %s",
+	"settings.grammar_engine":      "✍️ Проверка грамматики: %s",
+	"settings.engine.both":         "LanguageTool + ИИ",
+	"settings.engine.languagetool": "Только LanguageTool",
+	"settings.engine.ai":           "Только ИИ",
+	"settings.language":            "🌐 Язык: %s",
+	"leaderboard.streak":           "🏆 Лучшие ученики по текущей серии",
+	"leaderboard.exercises":        "🏆 Лучшие ученики по правильным упражнениям",
+	"leaderboard.xp":               "🏆 Лучшие ученики по опыту",
+	"leaderboard.unit_days":        "дн.",
+	"leaderboard.unit_tasks":       "упр.",
+	"leaderboard.empty":            "В рейтинге пока никого нет. Станьте первым!",
+	"leaderboard.you":              "(вы)",
+	"leaderboard.unranked":         "Вас пока нет в рейтинге. Продолжайте заниматься, чтобы попасть в него!",
+	"leaderboard.footer":           "Попробуйте также %s. Скрыть себя из рейтинга можно в /settings.",
+
+	// Упражнения
+	"exercise.type.grammar":       "✏️ Грамматика",
+	"exercise.type.vocabulary":    "🔤 Лексика",
+	"exercise.type.translation":   "🔁 Перевод",
+	"exercise.type.reading":       "📖 Чтение",
+	"exercise.type.speaking":      "🎙️ Произношение",
+	"exercise.choose_type":        "📚 Какое упражнение хотите?",
+	"exercise.unknown_type":       "Неизвестный тип упражнения.",
+	"exercise.type_chosen":        "📚 Тип упражнения: %s",
+	"exercise.choose_topic":       "✏️ Какую тему грамматики хотите потренировать?",
+	"exercise.topic_chosen":       "✏️ Тема грамматики: %s",
+	"exercise.topic.tenses":       "⏳ Времена",
+	"exercise.topic.articles":     "🅰️ Артикли",
+	"exercise.topic.prepositions": "📍 Предлоги",
+	"exercise.topic.any":          "🎲 Любая тема",
+	"exercise.generating":         "🔄 Готовлю упражнение для вашего уровня, подождите...",
+	"exercise.offline":            "⚠️ ИИ сейчас недоступен, поэтому вот упражнение из офлайн-набора.",
+	"exercise.generating_level":   "🔄 Готовлю упражнение уровня %s по вашим последним результатам, подождите...",
+	"exercise.none_active":        "У вас нет активного упражнения. Отправьте /exercise, чтобы получить его.",
+	"exercise.not_found":          "Не удалось найти ваше упражнение. Отправьте /exercise, чтобы получить новое.",
+	"exercise.inactive":           "Это упражнение уже неактивно.",
+	"exercise.unavailable":        "Это упражнение больше недоступно.",
+	"exercise.answer_failed":      "Извините, не удалось получить ответ на это упражнение.",
+	"exercise.skipped":            "⏭️ Пропущено. Правильный ответ:\n\n%s",
+	"exercise.timed_out":          "⏰ Время вышло! Правильный ответ:\n\n%s\n\nОтправьте /exercise, чтобы получить новое упражнение.",
+	"exercise.prompt_type":        "📚 *Упражнение*\n\n%s\n\nНапишите ответ, когда будете готовы.",
+	"exercise.prompt_choose":      "📚 *Упражнение*\n\n%s\n\nВыберите правильный ответ или напишите его.",
+	"exercise.checking":           "🔍 Проверяю ваш ответ...",
+	"exercise.correct_answer":     "%s\n\nПравильный ответ: %s",
+	"exercise.hints_used":         "%s\n\n💡 Использовано подсказок: %d (−%d баллов)",
+	"exercise.correct":            "🎉 *Правильно!* Оценка: *%d/100*\n\n%s",
+	"exercise.answer.perfect":     "Отлично! Ответ правильный.",
+	"exercise.answer.almost":      "Почти правильно! В ответе есть небольшие ошибки.",
+	"exercise.answer.partial":     "Частично правильно. В ответе есть нужные слова, но есть и ошибки.",
+	"exercise.answer.incorrect":   "Ответ неправильный. Попробуйте еще раз.",
+	"exercise.incorrect":          "❌ *Не совсем так.* Оценка: *%d/100*\n\n%s",
+	"exercise.your_answer":        "%s\n\n👉 Ваш ответ: %s %s",
+	"exercise.explain_button":     "📖 Объяснить",
+	"exercise.explanation":        "📖 *Объяснение правила*\n\n%s",
+	"exercise.next":               "Хотите еще упражнение? Отправьте /exercise.",
+	"hint.limit":                  "Вы использовали все подсказки к этому упражнению. Попробуйте ответить сами или отправьте /skip.",
+	"hint.message":                "💡 Подсказка %d/%d: %s\n\n(Каждая подсказка стоит %d баллов.)",
+	"hint.first_letter":           "Ответ начинается с \"%c\".",
+	"hint.words":                  "В ответе %d слов(а).",
+	"hint.letters":                "Ответ - одно слово из %d букв(ы).",
+	"reading.intro":               "📖 *Чтение*\n\n%s\n\nПрочитайте текст и ответьте на %d вопроса(ов) по одному.",
+	"reading.question":            "❓ *Вопрос %d/%d*\n\n%s",
+	"reading.correct":             "✅ *Вопрос %d/%d: верно!* Оценка: *%d/100*\n\n%s",
+	"reading.incorrect":           "❌ *Вопрос %d/%d: не совсем.* Оценка: *%d/100*\n\n%s\n\nОжидаемый ответ: %s",
+	"reading.summary":             "Правильных ответов: %d из %d.",
+	"reading.answers_failed":      "Извините, не удалось получить ответы к этому тексту.",
+	"speaking.intro":              "🎙️ *Произношение*\n\n%s\n\nПрочитайте предложение вслух и отправьте голосовое сообщение. Чтобы сначала послушать его, используйте /hint.",
+	"speaking.voice_required":     "🎙️ Прочитайте предложение вслух и отправьте голосовое сообщение.",
+	"speaking.all_words":          "Все слова прозвучали четко.",
+	"speaking.missed":             "Эти слова прозвучали нечетко: %s",
+	"practice.usage":              "Использование: /practice [n], где n - количество упражнений от 1 до %d (по умолчанию %d).",
+	"practice.choose_type":        "🏋️ Серия из %d упражнений. Какой тип выберете?",
+	"practice.started":            "🏋️ Серия упражнений: %d × %s",
+	"practice.next":               "➡️ Упражнение %d из %d",
+	"practice.complete":           "🏁 *Серия завершена!*",
+	"practice.correct":            "• Правильно: *%d/%d*",
+	"practice.skipped":            "• Пропущено: *%d*",
+	"practice.average":            "• Средняя оценка: *%d/100*",
+	"practice.item_skipped":       "%d. ⏭️ пропущено",
+	"practice.again":              "Отправьте /practice, чтобы начать новую серию.",
+	"quiz.started":                "🎯 Викторина! %d коротких вопросов разных типов. Отвечайте как можете.",
+	"quiz.next":                   "🎯 Вопрос %d из %d",
+	"quiz.score":                  "Текущий счет: %d/%d",
+	"quiz.complete":               "🏁 *Викторина завершена!*",
+	"quiz.result":                 "Правильных ответов: *%d/%d*.",
+	"quiz.grade.perfect":          "🏆 Без единой ошибки!",
+	"quiz.grade.great":            "🌟 Отличный результат!",
+	"quiz.grade.good":             "👍 Хорошая попытка!",
+	"quiz.grade.keep_going":       "💪 Продолжайте заниматься, все получится!",
+	"quiz.xp":                     "✨ +%d XP",
+	"quiz.xp_total":               "(всего %d XP)",
+	"quiz.master":                 "🎯 Новое достижение: *Мастер викторин*",
+	"quiz.again":                  "Чтобы сыграть еще раз, используйте /quiz.",
+
+	// Словарь
+	"vocab.help":             "📖 *Ваш словарь*\n\n• /vocab add <слово> - <перевод> - сохранить новое слово\n• /vocab list - показать сохраненные слова",
+	"vocab.add_usage":        "Используйте формат: /vocab add <слово> - <перевод>\nНапример: /vocab add apple - яблоко",
+	"vocab.saved":            "✅ Сохранено: %s - %s",
+	"vocab.empty":            "Ваш словарь пуст. Добавьте слово: /vocab add <слово> - <перевод>",
+	"vocab.page_empty":       "На этой странице больше нет слов.",
+	"vocab.page_title":       "📖 Ваш словарь (страница %d):",
+	"vocab.next_page":        "Следующая страница: /vocab list %d",
+	"history.empty":          "Вы еще не выполняли упражнений. Отправьте /exercise, чтобы начать!",
+	"history.page_empty":     "На этой странице больше нет упражнений.",
+	"history.title":          "🗂️ История упражнений (страница %d):",
+	"history.your_answer":    "Ваш ответ: %s",
+	"history.correct_answer": "Правильный ответ: %s",
+	"history.skipped":        "Пропущено",
+	"history.prev":           "⬅️ Новее",
+	"history.next":           "Старше ➡️",
+	"feedback.usage":         "Расскажите, что пошло не так или что можно улучшить: /feedback <текст>\nНапример: /feedback Проверка грамматики отметила верное предложение как ошибку.",
+	"feedback.thanks":        "💌 Спасибо за отзыв! Мы читаем каждое сообщение.",
+	"feedback.admin":         "💌 Отзыв от %s (пользователь %d):\n\n%s",
+	"timezone.current":       "🕒 Ваш часовой пояс: %s\n\nПо нему считаются дни серии и время уведомлений. Чтобы изменить его, отправьте /timezone <пояс>, например /timezone Europe/Moscow или /timezone Asia/Novosibirsk.",
+	"timezone.server":        "не задан (время сервера)",
+	"timezone.invalid":       "❌ Неизвестный часовой пояс \"%s\". Укажите название вида Europe/Moscow, Asia/Almaty или UTC.",
+	"timezone.saved":         "✅ Часовой пояс изменен на %s. Ваше местное время: %s.",
+	"me.profile":             "🪪 *Ваш профиль*\n\n• Имя: %s\n• Имя пользователя: %s\n• Уровень английского: *%s*\n• Учитесь с: %s\n\n*Настройки:*\n• Ежедневные напоминания: *%s*\n• Язык интерфейса: *%s*\n• Проверка грамматики: *%s*\n• Часовой пояс: *%s*\n\n*Серия:* %d дн. (рекорд: %d)\n\nИзменить настройки: /settings.",
+	"me.no_username":         "не задано",
+	"vocab.nothing_due":      "🎉 Сейчас нет слов для повторения. Добавьте новые: /vocab add <слово> - <перевод>",
+	"vocab.ask_word":         "🔁 Как будет по-английски: %s?",
+	"vocab.ask_translation":  "🔁 Как переводится: %s?",
+	"vocab.review_correct":   "✅ Верно! %s - %s %s",
+	"vocab.review_incorrect": "❌ Правильный ответ: %s - %s",
+	"vocab.review_done":      "🎉 Повторение завершено! Возвращайтесь позже за следующими словами.",
+
+	// Прогресс
+	"progress.summary":                          "📊 *Ваш прогресс*\n\n*Текущий уровень:* %s (пройдено %.0f%%)\n*Звание:* %s\n\n*Статистика:*\n• Выполнено упражнений: %d\n• Доля правильных ответов: %.1f%%\n• Диалогов: %d\n• Сообщений: %d\n• Текущая серия: %d дн.\n• Самая длинная серия: %d дн.\n\n*Сильные стороны:*\n",
+	"progress.weaknesses":                       "\n*Что подтянуть:*\n",
+	"progress.by_type":                          "\n*Правильные ответы по типам упражнений:*\n",
+	"progress.writing":                          "\n*Письмо:* последний текст %.1f/10, в среднем за последние %d: %.1f/10\n",
+	"progress.recommendations":                  "\n*Рекомендации:*\n",
+	"progress.skill.grammar":                    "Грамматика",
+	"progress.skill.vocabulary":                 "Лексика",
+	"progress.skill.translation":                "Перевод",
+	"progress.skill.listening":                  "Аудирование",
+	"progress.skill.speaking":                   "Произношение",
+	"progress.skill.reading":                    "Чтение",
+	"progress.recommend.grammar.tenses":         "Потренируйте времена глаголов",
+	"progress.recommend.grammar.conditionals":   "Разберите условные предложения",
+	"progress.recommend.vocabulary.daily":       "Учите новые слова каждый день",
+	"progress.recommend.vocabulary.flashcards":  "Повторяйте слова по карточкам",
+	"progress.recommend.listening.podcasts":     "Слушайте подкасты на английском",
+	"progress.recommend.listening.videos":       "Смотрите видео на английском с субтитрами",
+	"progress.recommend.speaking.conversations": "Практикуйтесь в диалогах",
+	"progress.recommend.speaking.record":        "Записывайте и слушайте свою речь",
+	"progress.recommend.reading.articles":       "Читайте статьи на английском",
+	"progress.recommend.reading.comprehension":  "Тренируйте понимание прочитанного",
+	"progress.recommend.translation.both_ways":  "Переводите короткие тексты в обе стороны",
+	"progress.recommend.translation.compare":    "Сравнивайте свои переводы с переводами носителей",
+	"progress.ready":                            "\n🎉 *Поздравляем!* Вы готовы перейти на уровень %s!\n",
+	"progress.rank":                             "%s — %d XP, еще %d XP до звания «%s»",
+	"progress.rank_max":                         "%s — %d XP, высшее звание",
+	"rank.novice":                               "🌱 Новичок",
+	"rank.explorer":                             "🧭 Исследователь",
+	"rank.apprentice":                           "📘 Ученик",
+	"rank.achiever":                             "🎖 Знаток",
+	"rank.expert":                               "💎 Эксперт",
+	"rank.master":                               "👑 Мастер",
+	"rank.legend":                               "🌟 Легенда",
+
+	// Описания команд для меню Telegram и справки /help
+	"command.start":        "Запустить бота",
+	"command.help":         "Показать доступные команды",
+	"command.chat":         "Начать диалог на английском",
+	"command.topics":       "Темы для разговора и упражнений",
+	"command.endchat":      "Завершить диалог и разобрать ошибки",
+	"command.check":        "Проверить грамматику предложения",
+	"command.write":        "Получить оценку своего текста",
+	"command.exercise":     "Получить новое упражнение",
+	"command.practice":     "Выполнить серию упражнений подряд",
+	"command.quiz":         "Пройти короткую викторину из разных вопросов",
+	"command.hint":         "Получить подсказку к текущему упражнению",
+	"command.skip":         "Пропустить текущее упражнение",
+	"command.history":      "Посмотреть прошлые упражнения",
+	"command.pronounce":    "Послушать произношение текста",
+	"command.vocab":        "Управлять словарем",
+	"command.review":       "Повторить слова, срок которых подошел",
+	"command.daily":        "Подписаться на слово дня",
+	"command.progress":     "Показать прогресс обучения",
+	"command.leaderboard":  "Посмотреть лучших учеников",
+	"command.achievements": "Посмотреть достижения",
+	"command.level":        "Посмотреть или изменить уровень английского",
+	"command.me":           "Показать профиль",
+	"command.settings":     "Изменить настройки",
+	"command.timezone":     "Посмотреть или изменить часовой пояс",
+	"command.feedback":     "Сообщить о проблеме или предложить идею",
+	"command.cancel":       "Выйти из текущего режима",
+	"command.reset":        "Начать обучение с нуля",
+	"command.export":       "Скачать все свои данные",
+	"command.forgetme":     "Удалить все свои данные",
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+// newCapturedLogger возвращает журнал с ContextHandler, записи которого попадают в buf в формате JSON
+func newCapturedLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(NewContextHandler(slog.NewJSONHandler(buf, nil)))
+}
+
+// lastRecord разбирает последнюю запись журнала из buf
+func lastRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var record map[string]any
+	if err := json.Unmarshal(lines[len(lines)-1], &record); err != nil {
+		t.Fatalf("не удалось разобрать запись журнала %q: %v", lines[len(lines)-1], err)
+	}
+	return record
+}
+
+func TestNewRequestID(t *testing.T) {
+	format := regexp.MustCompile(`^[0-9a-f]{8}$`)
+	seen := make(map[string]bool)
+
+	for range 100 {
+		id := NewRequestID()
+		if !format.MatchString(id) {
+			t.Fatalf("идентификатор %q не из 8 шестнадцатеричных символов", id)
+		}
+		if seen[id] {
+			t.Fatalf("идентификатор %q повторился", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if id := RequestID(context.Background()); id != "" {
+		t.Errorf("идентификатор без запроса %q, ожидалась пустая строка", id)
+	}
+	if id := RequestID(WithRequestID(context.Background(), "abc123")); id != "abc123" {
+		t.Errorf("идентификатор %q, ожидался abc123", id)
+	}
+}
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newCapturedLogger(&buf)
+	ctx := WithRequestID(context.Background(), "abc123")
+
+	tests := []struct {
+		name   string
+		log    func()
+		wantID any
+	}{
+		{"запись с контекстом", func() { logger.InfoContext(ctx, "Обработка") }, "abc123"},
+		{"запись без контекста", func() { logger.Info("Запуск") }, nil},
+		{"журнал с атрибутами", func() { logger.With("user_id", 100).InfoContext(ctx, "Обработка") }, "abc123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.log()
+			if id := lastRecord(t, &buf)[RequestIDKey]; id != tt.wantID {
+				t.Errorf("идентификатор в записи %v, ожидался %v", id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestContextHandlerWithGroup(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newCapturedLogger(&buf).WithGroup("exercise")
+
+	logger.InfoContext(WithRequestID(context.Background(), "abc123"), "Обработка", "type", "grammar")
+
+	// Атрибуты записи, в том числе идентификатор, попадают в группу журнала
+	group, _ := lastRecord(t, &buf)["exercise"].(map[string]any)
+	if group[RequestIDKey] != "abc123" || group["type"] != "grammar" {
+		t.Errorf("группа записи %v, ожидались идентификатор и тип упражнения", group)
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Метрики бота, публикуемые на /metrics
+var (
+	// UpdatesTotal - количество обработанных обновлений по командам и состояниям пользователя
+	UpdatesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "english_bot",
+		Name:      "updates_total",
+		Help:      "Количество обработанных обновлений Telegram",
+	}, []string{"command", "state"})
+
+	// UpdateDuration - время обработки обновления по командам и состояниям пользователя
+	UpdateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "english_bot",
+		Name:      "update_duration_seconds",
+		Help:      "Время обработки обновления Telegram",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"command", "state"})
+
+	// ExternalErrorsTotal - количество ошибок при обращении к внешним API
+	ExternalErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "english_bot",
+		Name:      "external_errors_total",
+		Help:      "Количество ошибок при обращении к OpenAI и LanguageTool",
+	}, []string{"service", "operation"})
+)
+
+// RecordExternalError учитывает ошибку обращения к внешнему API, если err не nil
+func RecordExternalError(service, operation string, err error) {
+	if err != nil {
+		ExternalErrorsTotal.WithLabelValues(service, operation).Inc()
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen возвращается без обращения к внешнему API, пока автомат защиты разомкнут
+var ErrCircuitOpen = errors.New("внешний API временно недоступен")
+
+// circuitState - состояние автомата защиты
+type circuitState int
+
+const (
+	circuitClosed   circuitState = iota // Запросы проходят, считаются подряд идущие ошибки
+	circuitOpen                         // Запросы сразу отклоняются до конца паузы
+	circuitHalfOpen                     // Пропускается один пробный запрос, чтобы проверить восстановление
+)
+
+// CircuitBreaker размыкается после threshold ошибок подряд и отклоняет запросы в течение cooldown,
+// чтобы во время сбоя внешнего API бот отвечал сразу, а не ждал таймаутов.
+// После паузы пропускает один пробный запрос: успех замыкает автомат, ошибка снова размыкает.
+// Безопасен для использования из нескольких горутин
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int       // Ошибки подряд в замкнутом состоянии
+	openedAt time.Time // Время размыкания
+	probing  bool      // Пробный запрос в полуоткрытом состоянии уже выполняется
+}
+
+// NewCircuitBreaker создает автомат защиты.
+// threshold меньше 1 выключает автомат: все запросы проходят
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow проверяет, можно ли выполнить запрос. Возвращает ErrCircuitOpen, если автомат разомкнут
+// или пробный запрос уже выполняется. Каждый разрешенный запрос нужно завершить вызовом Record
+func (b *CircuitBreaker) Allow() error {
+	if b == nil || b.threshold < 1 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+		return nil
+
+	case circuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	}
+
+	return nil
+}
+
+// Record учитывает результат разрешенного запроса: ошибка приближает размыкание
+// или снова размыкает автомат после пробного запроса, успех замыкает его
+func (b *CircuitBreaker) Record(failed bool) {
+	if b == nil || b.threshold < 1 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.state = circuitClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	switch b.state {
+	case circuitHalfOpen:
+		b.open()
+
+	case circuitClosed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	}
+}
+
+// open размыкает автомат. Вызывается под блокировкой
+func (b *CircuitBreaker) open() {
+	b.state = circuitOpen
+	b.openedAt = b.now()
+	b.failures = 0
+	b.probing = false
+}
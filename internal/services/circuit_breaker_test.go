@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testClock - управляемые часы для автомата защиты
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestBreaker создает автомат защиты с управляемыми часами
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, *testClock) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	breaker := NewCircuitBreaker(threshold, cooldown)
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+// fail выполняет разрешенный запрос, завершившийся ошибкой
+func fail(t *testing.T, b *CircuitBreaker) {
+	t.Helper()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow = %v, ожидалось разрешение", err)
+	}
+	b.Record(true)
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+
+	fail(t, b)
+	fail(t, b)
+	if b.state != circuitClosed {
+		t.Fatalf("после двух ошибок состояние %d, ожидалось замкнутое", b.state)
+	}
+
+	fail(t, b)
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("после трех ошибок Allow = %v, ожидалось ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+
+	fail(t, b)
+	b.Allow()
+	b.Record(false)
+	fail(t, b)
+
+	if err := b.Allow(); err != nil {
+		t.Errorf("ошибки не подряд разомкнули автомат: %v", err)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name      string
+		probeFail bool
+		wantState circuitState
+	}{
+		{"успешная проба замыкает", false, circuitClosed},
+		{"ошибка пробы снова размыкает", true, circuitOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, clock := newTestBreaker(1, time.Minute)
+			fail(t, b)
+
+			clock.Advance(59 * time.Second)
+			if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("до конца паузы Allow = %v, ожидалось ErrCircuitOpen", err)
+			}
+
+			clock.Advance(time.Second)
+			if err := b.Allow(); err != nil {
+				t.Fatalf("после паузы пробный запрос отклонен: %v", err)
+			}
+			if b.state != circuitHalfOpen {
+				t.Fatalf("во время пробы состояние %d, ожидалось полуоткрытое", b.state)
+			}
+			// Пока проба выполняется, остальные запросы отклоняются
+			if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+				t.Errorf("второй запрос во время пробы: %v, ожидалось ErrCircuitOpen", err)
+			}
+
+			b.Record(tt.probeFail)
+			if b.state != tt.wantState {
+				t.Errorf("после пробы состояние %d, ожидалось %d", b.state, tt.wantState)
+			}
+
+			err := b.Allow()
+			if tt.probeFail && !errors.Is(err, ErrCircuitOpen) {
+				t.Errorf("после неудачной пробы Allow = %v, ожидалось ErrCircuitOpen", err)
+			}
+			if !tt.probeFail && err != nil {
+				t.Errorf("после успешной пробы Allow = %v", err)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var nilBreaker *CircuitBreaker
+	disabled, _ := newTestBreaker(0, time.Minute)
+
+	for name, b := range map[string]*CircuitBreaker{"nil": nilBreaker, "порог 0": disabled} {
+		for range 10 {
+			if err := b.Allow(); err != nil {
+				t.Fatalf("%s: выключенный автомат отклонил запрос: %v", name, err)
+			}
+			b.Record(true)
+		}
+	}
+}
+
+func TestCircuitBreakerConcurrentProbe(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+	fail(t, b)
+	clock.Advance(time.Minute)
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.Allow() == nil {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed.Load() != 1 {
+		t.Errorf("пробных запросов пропущено %d, ожидался один", allowed.Load())
+	}
+}
+
+func TestOpenAIServiceFailsFastWhenCircuitOpen(t *testing.T) {
+	var requests atomic.Int32
+	status := http.StatusInternalServerError
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error": {"message": "failure", "type": "server_error"}}`))
+			return
+		}
+		chatReply(w, "Hello!")
+	})
+	breaker, clock := newTestBreaker(2, time.Minute)
+	s.SetCircuitBreaker(breaker)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := s.GenerateResponse(ctx, "Hi", ""); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("ошибка сервера: %v", err)
+		}
+	}
+
+	_, err := s.GenerateResponse(ctx, "Hi", "")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("после двух сбоев ошибка %v, ожидалась ErrCircuitOpen", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("при разомкнутом автомате выполнено %d запросов, ожидалось 2", requests.Load())
+	}
+
+	// OpenAI восстановился: после паузы пробный запрос замыкает автомат
+	status = http.StatusOK
+	clock.Advance(time.Minute)
+	if reply, err := s.GenerateResponse(ctx, "Hi", ""); err != nil || reply != "Hello!" {
+		t.Fatalf("пробный запрос: %q, %v", reply, err)
+	}
+	if _, err := s.GenerateResponse(ctx, "Hi", ""); err != nil {
+		t.Errorf("после восстановления запрос отклонен: %v", err)
+	}
+}
+
+func TestOpenAIServiceClientErrorsDoNotOpenCircuit(t *testing.T) {
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "bad request", "type": "invalid_request_error"}}`))
+	})
+	breaker, _ := newTestBreaker(1, time.Minute)
+	s.SetCircuitBreaker(breaker)
+
+	for range 3 {
+		if _, err := s.GenerateResponse(context.Background(), "Hi", ""); errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("ошибка в запросе разомкнула автомат")
+		}
+	}
+}
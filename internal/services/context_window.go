@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultContextTokens - бюджет токенов на историю диалога по умолчанию, с запасом для ответа модели
+const DefaultContextTokens = 3000
+
+// messageTokenOverhead - служебные токены, которые модель тратит на каждое сообщение (роль и разметку)
+const messageTokenOverhead = 4
+
+// EstimateTokens грубо оценивает количество токенов в тексте без токенизатора модели.
+// В английском тексте токен занимает около 4 символов или 3/4 слова; берется большая из оценок,
+// чтобы не превысить окно контекста на коротких словах и длинных числах
+func EstimateTokens(text string) int {
+	byChars := (utf8.RuneCountInString(text) + 3) / 4
+	byWords := (len(strings.Fields(text))*4 + 2) / 3
+	return max(byChars, byWords)
+}
+
+// estimateMessageTokens оценивает размер сообщения в запросе вместе со служебными токенами
+func estimateMessageTokens(message ChatMessage) int {
+	return EstimateTokens(message.Content) + messageTokenOverhead
+}
+
+// TrimChatHistory отбрасывает самые старые реплики, пока оценка размера истории не уложится в budget.
+// Системные сообщения и последняя реплика сохраняются всегда, даже если сами превышают бюджет
+func TrimChatHistory(messages []ChatMessage, budget int) []ChatMessage {
+	total := 0
+	for _, message := range messages {
+		total += estimateMessageTokens(message)
+	}
+	if total <= budget || len(messages) == 0 {
+		return messages
+	}
+
+	last := len(messages) - 1
+	drop := make([]bool, len(messages))
+	for i := 0; i < last && total > budget; i++ {
+		if messages[i].Role == "system" {
+			continue
+		}
+		drop[i] = true
+		total -= estimateMessageTokens(messages[i])
+	}
+
+	trimmed := make([]ChatMessage, 0, len(messages))
+	for i, message := range messages {
+		if !drop[i] {
+			trimmed = append(trimmed, message)
+		}
+	}
+	return trimmed
+}
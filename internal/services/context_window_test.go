@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// historyTokens оценивает размер истории так же, как TrimChatHistory
+func historyTokens(messages []ChatMessage) int {
+	total := 0
+	for _, message := range messages {
+		total += estimateMessageTokens(message)
+	}
+	return total
+}
+
+// longHistory создает историю из системного промпта и n реплик, пронумерованных по порядку
+func longHistory(n int) []ChatMessage {
+	history := []ChatMessage{{Role: "system", Content: "You are a friendly English tutor."}}
+	for i := range n {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		history = append(history, ChatMessage{Role: role, Content: fmt.Sprintf("Message %d: %s", i, strings.Repeat("word ", 50))})
+	}
+	return history
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"Hi", 2},
+		{"Hello, world", 3},
+		// Короткие слова: оценка по словам больше оценки по символам
+		{"I am a b c d", 8},
+		// Длинное слово: оценка по символам больше оценки по словам
+		{"internationalization", 5},
+		{"привет", 2},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, ожидалось %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTrimChatHistoryFitsBudget(t *testing.T) {
+	history := longHistory(100)
+	budget := 1000
+	if historyTokens(history) <= budget {
+		t.Fatalf("история из %d токенов уже укладывается в бюджет", historyTokens(history))
+	}
+
+	trimmed := TrimChatHistory(history, budget)
+
+	if historyTokens(trimmed) > budget {
+		t.Errorf("после обрезки %d токенов, бюджет %d", historyTokens(trimmed), budget)
+	}
+	if trimmed[0] != history[0] {
+		t.Errorf("первое сообщение %+v, ожидался системный промпт", trimmed[0])
+	}
+	if len(trimmed) < 3 {
+		t.Fatalf("после обрезки осталось %d сообщений", len(trimmed))
+	}
+
+	// Остаются самые новые реплики подряд, без пропусков
+	tail := history[len(history)-len(trimmed)+1:]
+	for i, message := range trimmed[1:] {
+		if message != tail[i] {
+			t.Fatalf("сообщение %d после обрезки %q, ожидалось %q", i+1, message.Content[:12], tail[i].Content[:12])
+		}
+	}
+
+	// Добавление еще одной отброшенной реплики вышло бы за бюджет
+	withOneMore := append([]ChatMessage{trimmed[0], history[len(history)-len(trimmed)]}, trimmed[1:]...)
+	if historyTokens(withOneMore) <= budget {
+		t.Error("отброшено больше реплик, чем нужно")
+	}
+}
+
+func TestTrimChatHistoryKeepsSystemAndLastMessage(t *testing.T) {
+	huge := strings.Repeat("long ", 1000)
+
+	tests := []struct {
+		name    string
+		history []ChatMessage
+		budget  int
+		want    []string // Роли оставшихся сообщений
+	}{
+		{"укладывается в бюджет", longHistory(2), 1000, []string{"system", "user", "assistant"}},
+		{"пустая история", nil, 10, nil},
+		{
+			"последняя реплика больше бюджета",
+			[]ChatMessage{{Role: "system", Content: "Tutor."}, {Role: "user", Content: "Hi"}, {Role: "assistant", Content: "Hello"}, {Role: "user", Content: huge}},
+			100,
+			[]string{"system", "user"},
+		},
+		{
+			"системные сообщения в середине",
+			[]ChatMessage{{Role: "system", Content: "Tutor."}, {Role: "user", Content: huge}, {Role: "system", Content: "Topic: travel."}, {Role: "user", Content: "Hi"}},
+			100,
+			[]string{"system", "system", "user"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, message := range TrimChatHistory(tt.history, tt.budget) {
+				got = append(got, message.Role)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("роли после обрезки %v, ожидались %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulateConversationTrimsHistory(t *testing.T) {
+	var got OpenAIRequest
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		chatReply(w, "Sure!")
+	})
+	s.SetContextTokens(500)
+
+	history := longHistory(60)
+	if _, err := s.SimulateConversation(context.Background(), "Can we talk about food?", history); err != nil {
+		t.Fatalf("SimulateConversation: %v", err)
+	}
+
+	if historyTokens(got.Messages) > 500 {
+		t.Errorf("в запросе %d токенов, бюджет 500", historyTokens(got.Messages))
+	}
+	if len(got.Messages) < 2 || got.Messages[0] != history[0] {
+		t.Fatalf("системный промпт потерян: %+v", got.Messages)
+	}
+	if last := got.Messages[len(got.Messages)-1]; last.Role != "user" || last.Content != "Can we talk about food?" {
+		t.Errorf("последнее сообщение %+v, ожидалась новая реплика пользователя", last)
+	}
+}
+
+func TestSetContextTokens(t *testing.T) {
+	s := NewOpenAIService("test-key", "")
+
+	for _, tt := range []struct{ tokens, want int }{
+		{0, DefaultContextTokens},
+		{-10, DefaultContextTokens},
+		{8000, 8000},
+	} {
+		s.SetContextTokens(tt.tokens)
+		if s.contextTokens != tt.want {
+			t.Errorf("SetContextTokens(%d): бюджет %d, ожидалось %d", tt.tokens, s.contextTokens, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"english-bot/internal/database"
+	"slices"
+)
+
+const (
+	// AdaptiveWindow - сколько последних результатов учитывается при подборе сложности
+	AdaptiveWindow = 10
+	// adaptiveRaiseRate - доля правильных ответов, выше которой сложность повышается
+	adaptiveRaiseRate = 0.9
+	// adaptiveLowerRate - доля правильных ответов, ниже которой сложность понижается
+	adaptiveLowerRate = 0.5
+)
+
+// AdaptiveLevel подбирает уровень сложности упражнения по недавним результатам пользователя.
+// recent - результаты последних упражнений этого типа, начиная с самого нового.
+// Если правильных ответов больше 90%, уровень повышается на одну ступень, если меньше 50% -
+// понижается. Пока результатов меньше AdaptiveWindow, остается уровень пользователя
+func (s *ExerciseService) AdaptiveLevel(level EnglishLevel, recent []bool) EnglishLevel {
+	if len(recent) < AdaptiveWindow {
+		return level
+	}
+
+	index := slices.Index(database.EnglishLevels, string(level))
+	if index == -1 {
+		return level
+	}
+
+	correct := 0
+	for _, isCorrect := range recent[:AdaptiveWindow] {
+		if isCorrect {
+			correct++
+		}
+	}
+	rate := float64(correct) / AdaptiveWindow
+
+	switch {
+	case rate > adaptiveRaiseRate && index < len(database.EnglishLevels)-1:
+		return EnglishLevel(database.EnglishLevels[index+1])
+	case rate < adaptiveLowerRate && index > 0:
+		return EnglishLevel(database.EnglishLevels[index-1])
+	default:
+		return level
+	}
+}
@@ -0,0 +1,46 @@
+package services
+
+import "testing"
+
+// recentResults возвращает результаты упражнений: сначала correct правильных, затем wrong неправильных
+func recentResults(correct, wrong int) []bool {
+	recent := make([]bool, 0, correct+wrong)
+	for range correct {
+		recent = append(recent, true)
+	}
+	for range wrong {
+		recent = append(recent, false)
+	}
+	return recent
+}
+
+func TestAdaptiveLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  EnglishLevel
+		recent []bool
+		want   EnglishLevel
+	}{
+		{"все правильно - уровень выше", EnglishLevelB1, recentResults(10, 0), EnglishLevelB2},
+		{"90% - уровень тот же", EnglishLevelB1, recentResults(9, 1), EnglishLevelB1},
+		{"50% - уровень тот же", EnglishLevelB1, recentResults(5, 5), EnglishLevelB1},
+		{"40% - уровень ниже", EnglishLevelB1, recentResults(4, 6), EnglishLevelA2},
+		{"без правильных ответов - уровень ниже", EnglishLevelA2, recentResults(0, 10), EnglishLevelA1},
+		{"выше C2 не поднимается", EnglishLevelC2, recentResults(10, 0), EnglishLevelC2},
+		{"ниже A1 не опускается", EnglishLevelA1, recentResults(0, 10), EnglishLevelA1},
+		{"мало результатов", EnglishLevelB1, recentResults(9, 0), EnglishLevelB1},
+		{"без истории", EnglishLevelB1, nil, EnglishLevelB1},
+		{"учитываются только последние 10", EnglishLevelB1, recentResults(10, 5), EnglishLevelB2},
+		{"старые ошибки не мешают", EnglishLevelB1, append(recentResults(10, 0), recentResults(0, 10)...), EnglishLevelB2},
+		{"неизвестный уровень", EnglishLevel("D1"), recentResults(10, 0), EnglishLevel("D1")},
+	}
+
+	s := NewExerciseService(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.AdaptiveLevel(tt.level, tt.recent); got != tt.want {
+				t.Errorf("AdaptiveLevel(%s, %v) = %s, ожидался %s", tt.level, tt.recent, got, tt.want)
+			}
+		})
+	}
+}
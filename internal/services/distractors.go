@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// vocabularyDistractors - количество неправильных вариантов в словарном упражнении
+const vocabularyDistractors = 3
+
+// vocabularyBlank - заготовка обозначения пропуска в предложении словарного упражнения
+const vocabularyBlank = "_____"
+
+// VocabularyBlank - предложение с пропущенным словом и само слово
+type VocabularyBlank struct {
+	Sentence     string   `json:"sentence"`
+	Answer       string   `json:"answer"`
+	Alternatives []string `json:"alternatives"` // Другие слова, которые тоже подходят к пропуску
+}
+
+// AcceptedAnswers возвращает все допустимые ответы через "/" в том виде, в котором их разбирает CheckAnswer
+func (b *VocabularyBlank) AcceptedAnswers() string {
+	return strings.Join(append([]string{b.Answer}, b.Alternatives...), "/")
+}
+
+// Запасные списки слов для неправильных вариантов, когда OpenAI их не предложил.
+// Слова сгруппированы по форме, чтобы варианты не выдавали ответ окончанием
+var (
+	fallbackAdverbs     = []string{"quickly", "rarely", "easily", "loudly", "carefully", "suddenly", "hardly", "gently"}
+	fallbackIngForms    = []string{"running", "reading", "cooking", "waiting", "building", "drawing", "singing", "cleaning"}
+	fallbackPastForms   = []string{"played", "opened", "changed", "finished", "visited", "decided", "reached", "arrived"}
+	fallbackOtherWords  = []string{"table", "bright", "carry", "window", "simple", "travel", "heavy", "answer"}
+	fallbackWordsByForm = []struct {
+		suffix string
+		words  []string
+	}{
+		{"ly", fallbackAdverbs},
+		{"ing", fallbackIngForms},
+		{"ed", fallbackPastForms},
+	}
+)
+
+// GenerateVocabularyExercise генерирует через OpenAI предложение с пропущенным словом
+// и добавляет к нему варианты ответа: правильное слово и правдоподобные неправильные
+func (s *ExerciseService) GenerateVocabularyExercise(ctx context.Context, level EnglishLevel) (*Exercise, error) {
+	prompt := s.GetPromptForExerciseType(ExerciseTypeVocabulary, level, GrammarTopicAny)
+
+	response, err := s.openAI.GenerateResponseWithModel(ctx, s.openAI.ModelForExercise(), "Generate an exercise", prompt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации словарного упражнения: %w", err)
+	}
+
+	blank, err := ParseVocabularyBlank(response)
+	if err != nil {
+		return nil, err
+	}
+
+	// Если OpenAI не предложил варианты, BuildOptions возьмет их из запасного списка:
+	// упражнение все равно можно выполнить
+	distractors, _ := s.GenerateDistractors(ctx, blank.Sentence, blank.Answer, level)
+	distractors = withoutWords(distractors, blank.Alternatives)
+
+	return &Exercise{
+		Type:        ExerciseTypeVocabulary,
+		Level:       level,
+		Instruction: "Fill in the blank with the correct word from the options.",
+		Content:     blank.Sentence,
+		Answer:      blank.AcceptedAnswers(),
+		Options:     BuildOptions(blank.Answer, distractors, vocabularyDistractors),
+	}, nil
+}
+
+// ParseVocabularyBlank разбирает ответ модели с предложением и пропущенным словом.
+// Модель может перечислить подходящие слова прямо в answer ("happy/glad", "happy, glad"):
+// первое из них становится ответом, остальные - допустимыми вариантами
+func ParseVocabularyBlank(response string) (*VocabularyBlank, error) {
+	var blank VocabularyBlank
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &blank); err != nil {
+		return nil, fmt.Errorf("ошибка разбора словарного упражнения: %w", err)
+	}
+
+	words := strings.FieldsFunc(blank.Answer, func(r rune) bool {
+		return r == '/' || r == ','
+	})
+	words = uniqueWords(append(words, blank.Alternatives...))
+
+	blank.Sentence = strings.TrimSpace(blank.Sentence)
+	if len(words) == 0 || !strings.Contains(blank.Sentence, vocabularyBlank) {
+		return nil, fmt.Errorf("в словарном упражнении нет пропуска или ответа")
+	}
+	blank.Answer = words[0]
+	blank.Alternatives = words[1:]
+
+	return &blank, nil
+}
+
+// uniqueWords убирает пробелы по краям слов, пустые слова и повторы без учета регистра
+func uniqueWords(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		key := strings.ToLower(word)
+		if word == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, word)
+	}
+	return result
+}
+
+// withoutWords убирает из words слова из exclude без учета регистра,
+// чтобы допустимый ответ не попал в неправильные варианты
+func withoutWords(words, exclude []string) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, word := range exclude {
+		excluded[strings.ToLower(strings.TrimSpace(word))] = true
+	}
+
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		if !excluded[strings.ToLower(strings.TrimSpace(word))] {
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+// GenerateDistractors просит OpenAI подобрать неправильные варианты для пропуска в предложении:
+// слова той же части речи, формы и сложности, которые не подходят по смыслу
+func (s *ExerciseService) GenerateDistractors(ctx context.Context, sentence, answer string, level EnglishLevel) ([]string, error) {
+	systemPrompt := fmt.Sprintf(`You are an English teacher preparing a multiple-choice question for a %s level student.
+Given a sentence with a blank and the correct word, suggest %d wrong options.
+Each option must be a single word of the same part of speech, grammatical form and difficulty as the correct word,
+plausible at first glance but clearly wrong in this sentence.
+Respond ONLY with a JSON object: {"distractors": ["<word>", "<word>", "<word>"]}`, level, vocabularyDistractors)
+
+	prompt := fmt.Sprintf("Sentence: %s\nCorrect word: %s", sentence, answer)
+
+	response, err := s.openAI.GenerateResponseWithModel(ctx, s.openAI.ModelForExercise(), prompt, systemPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации вариантов ответа: %w", err)
+	}
+
+	var result struct {
+		Distractors []string `json:"distractors"`
+	}
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &result); err != nil {
+		return nil, fmt.Errorf("ошибка разбора вариантов ответа: %w", err)
+	}
+
+	return result.Distractors, nil
+}
+
+// BuildOptions составляет перемешанные варианты ответа: правильный и count неправильных.
+// Пустые, повторяющиеся и совпадающие с ответом варианты отбрасываются,
+// а недостающие дополняются словами из запасного списка той же формы
+func BuildOptions(answer string, distractors []string, count int) []string {
+	seen := map[string]bool{strings.ToLower(answer): true}
+	options := make([]string, 0, count+1)
+
+	add := func(word string) {
+		word = strings.TrimSpace(word)
+		key := strings.ToLower(word)
+		if word == "" || seen[key] || len(options) == count {
+			return
+		}
+		seen[key] = true
+		options = append(options, word)
+	}
+
+	for _, word := range distractors {
+		add(word)
+	}
+	for _, word := range fallbackWords(answer) {
+		add(word)
+	}
+
+	options = append(options, answer)
+	rand.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+
+	return options
+}
+
+// fallbackWords возвращает перемешанные запасные слова той же формы, что и ответ
+func fallbackWords(answer string) []string {
+	words := fallbackOtherWords
+	for _, group := range fallbackWordsByForm {
+		if strings.HasSuffix(strings.ToLower(answer), group.suffix) {
+			words = group.words
+			break
+		}
+	}
+
+	shuffled := append([]string(nil), words...)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled
+}
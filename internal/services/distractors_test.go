@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/i18n"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// checkOptions проверяет, что варианты содержат ответ ровно один раз,
+// не повторяются и их на один больше, чем неправильных вариантов
+func checkOptions(t *testing.T, options []string, answer string, distractors int) {
+	t.Helper()
+
+	if len(options) != distractors+1 {
+		t.Errorf("вариантов %d (%v), ожидалось %d", len(options), options, distractors+1)
+	}
+
+	seen := make(map[string]bool)
+	for _, option := range options {
+		key := strings.ToLower(option)
+		if seen[key] {
+			t.Errorf("вариант %q повторяется в %v", option, options)
+		}
+		seen[key] = true
+	}
+
+	if !slices.Contains(options, answer) {
+		t.Errorf("среди вариантов %v нет правильного ответа %q", options, answer)
+	}
+}
+
+func TestBuildOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		answer      string
+		distractors []string
+		want        []string
+	}{
+		{"варианты от OpenAI", "goes", []string{"runs", "sits", "eats"}, []string{"goes", "runs", "sits", "eats"}},
+		{"лишние варианты отбрасываются", "goes", []string{"runs", "sits", "eats", "flies"}, []string{"goes", "runs", "sits", "eats"}},
+		{"повторы, пустые и ответ отбрасываются", "goes", []string{"runs", " ", "Runs", "GOES", "sits", "eats"}, []string{"goes", "runs", "sits", "eats"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := BuildOptions(tt.answer, tt.distractors, vocabularyDistractors)
+			checkOptions(t, options, tt.answer, vocabularyDistractors)
+
+			got := slices.Clone(options)
+			want := slices.Clone(tt.want)
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("BuildOptions = %v, ожидалось %v в любом порядке", options, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildOptionsFallsBackToWordsOfSameForm(t *testing.T) {
+	tests := []struct {
+		answer string
+		words  []string
+	}{
+		{"quickly", fallbackAdverbs},
+		{"swimming", fallbackIngForms},
+		{"walked", fallbackPastForms},
+		{"house", fallbackOtherWords},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			options := BuildOptions(tt.answer, []string{"spare"}, vocabularyDistractors)
+			checkOptions(t, options, tt.answer, vocabularyDistractors)
+
+			for _, option := range options {
+				if option != tt.answer && option != "spare" && !slices.Contains(tt.words, option) {
+					t.Errorf("вариант %q не из запасного списка той же формы", option)
+				}
+			}
+		})
+	}
+}
+
+func TestParseVocabularyBlank(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     string
+		wantSentence string
+		wantAnswer   string
+		wantErr      bool
+	}{
+		{"JSON в markdown", "```json\n{\"sentence\": \" She _____ to school. \", \"answer\": \" goes \"}\n```", "She _____ to school.", "goes", false},
+		{"нет пропуска", `{"sentence": "She goes to school.", "answer": "goes"}`, "", "", true},
+		{"нет ответа", `{"sentence": "She _____ to school.", "answer": ""}`, "", "", true},
+		{"ответ без JSON", "Sorry, I cannot help.", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blank, err := ParseVocabularyBlank(tt.response)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseVocabularyBlank ошибка = %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+			if err == nil && (blank.Sentence != tt.wantSentence || blank.Answer != tt.wantAnswer) {
+				t.Errorf("ParseVocabularyBlank = %+v, ожидалось %q и %q", blank, tt.wantSentence, tt.wantAnswer)
+			}
+		})
+	}
+}
+
+func TestGenerateVocabularyExercise(t *testing.T) {
+	tests := []struct {
+		name             string
+		distractorsReply string
+		distractorsFail  bool
+		want             []string
+	}{
+		{"варианты от OpenAI", `{"distractors": ["rapid", "slow", "loud"]}`, false, []string{"rapid", "slow", "loud"}},
+		{"OpenAI не подобрал варианты", "", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openAI := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+				var request OpenAIRequest
+				json.NewDecoder(r.Body).Decode(&request)
+
+				last := request.Messages[len(request.Messages)-1].Content
+				if !strings.Contains(last, "Correct word:") {
+					chatReply(w, `{"sentence": "The train was very _____.", "answer": "fast"}`)
+					return
+				}
+				if tt.distractorsFail {
+					http.Error(w, `{"error": {"message": "bad request"}}`, http.StatusBadRequest)
+					return
+				}
+				chatReply(w, tt.distractorsReply)
+			})
+			s := NewExerciseService(openAI)
+
+			exercise, err := s.GenerateVocabularyExercise(context.Background(), EnglishLevelB1)
+			if err != nil {
+				t.Fatalf("GenerateVocabularyExercise: %v", err)
+			}
+			if exercise.Content != "The train was very _____." || exercise.Answer != "fast" {
+				t.Errorf("упражнение %q с ответом %q", exercise.Content, exercise.Answer)
+			}
+			checkOptions(t, exercise.Options, "fast", vocabularyDistractors)
+
+			for _, word := range tt.want {
+				if !slices.Contains(exercise.Options, word) {
+					t.Errorf("среди вариантов %v нет %q от OpenAI", exercise.Options, word)
+				}
+			}
+		})
+	}
+}
+
+func TestParseVocabularyBlankAlternatives(t *testing.T) {
+	tests := []struct {
+		name             string
+		response         string
+		wantAnswer       string
+		wantAlternatives []string
+	}{
+		{"без вариантов", `{"sentence": "I am _____.", "answer": "happy"}`, "happy", []string{}},
+		{"варианты через косую черту", `{"sentence": "I am _____.", "answer": "happy/glad"}`, "happy", []string{"glad"}},
+		{"варианты через запятую", `{"sentence": "I am _____.", "answer": "happy, glad"}`, "happy", []string{"glad"}},
+		{"отдельный список", `{"sentence": "I am _____.", "answer": "happy", "alternatives": ["glad", "pleased"]}`, "happy", []string{"glad", "pleased"}},
+		{"повторы и пустые отбрасываются", `{"sentence": "I am _____.", "answer": "happy/ /Glad", "alternatives": ["glad", "HAPPY", ""]}`, "happy", []string{"Glad"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blank, err := ParseVocabularyBlank(tt.response)
+			if err != nil {
+				t.Fatalf("ParseVocabularyBlank: %v", err)
+			}
+			if blank.Answer != tt.wantAnswer || !slices.Equal(blank.Alternatives, tt.wantAlternatives) {
+				t.Errorf("ответ %q и варианты %q, ожидалось %q и %q", blank.Answer, blank.Alternatives, tt.wantAnswer, tt.wantAlternatives)
+			}
+		})
+	}
+}
+
+func TestAcceptedAnswers(t *testing.T) {
+	tests := []struct {
+		blank VocabularyBlank
+		want  string
+	}{
+		{VocabularyBlank{Answer: "happy"}, "happy"},
+		{VocabularyBlank{Answer: "happy", Alternatives: []string{"glad", "pleased"}}, "happy/glad/pleased"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.blank.AcceptedAnswers(); got != tt.want {
+			t.Errorf("AcceptedAnswers(%+v) = %q, ожидалось %q", tt.blank, got, tt.want)
+		}
+	}
+}
+
+func TestWithoutWords(t *testing.T) {
+	got := withoutWords([]string{"sad", "Glad", " pleased ", "angry"}, []string{"glad", "PLEASED"})
+	if want := []string{"sad", "angry"}; !slices.Equal(got, want) {
+		t.Errorf("withoutWords = %q, ожидалось %q", got, want)
+	}
+}
+
+func TestGenerateVocabularyExerciseAcceptsAlternatives(t *testing.T) {
+	openAI := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		var request OpenAIRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		if !strings.Contains(request.Messages[len(request.Messages)-1].Content, "Correct word:") {
+			chatReply(w, `{"sentence": "I am very _____ to see you.", "answer": "happy", "alternatives": ["glad"]}`)
+			return
+		}
+		// Модель может предложить в неправильные варианты слово, которое тоже подходит
+		chatReply(w, `{"distractors": ["glad", "tired", "late", "cold"]}`)
+	})
+	s := NewExerciseService(openAI)
+
+	exercise, err := s.GenerateVocabularyExercise(context.Background(), EnglishLevelA2)
+	if err != nil {
+		t.Fatalf("GenerateVocabularyExercise: %v", err)
+	}
+	if exercise.Answer != "happy/glad" {
+		t.Errorf("ответ %q, ожидалось happy/glad", exercise.Answer)
+	}
+	checkOptions(t, exercise.Options, "happy", vocabularyDistractors)
+	if slices.Contains(exercise.Options, "glad") {
+		t.Errorf("допустимый ответ glad попал в неправильные варианты %v", exercise.Options)
+	}
+
+	tests := []struct {
+		answer  string
+		correct bool
+	}{
+		{"happy", true},
+		{"Glad", true},
+		{"tired", false},
+	}
+
+	for _, tt := range tests {
+		score, feedback := s.CheckAnswer(i18n.Russian, exercise, tt.answer)
+		if (score == 100) != tt.correct {
+			t.Errorf("ответ %q оценен в %d, ожидался верный ответ: %v", tt.answer, score, tt.correct)
+		}
+		if tt.correct && feedback != i18n.T(i18n.Russian, "exercise.answer.perfect") {
+			t.Errorf("ответ %q: отзыв %q, ожидалось %q", tt.answer, feedback, i18n.T(i18n.Russian, "exercise.answer.perfect"))
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"english-bot/internal/i18n"
 	"fmt"
 	"math/rand"
 	"strings"
-	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // ExerciseService предоставляет функциональность для работы с упражнениями
@@ -21,6 +25,7 @@ const (
 	ExerciseTypeTranslation ExerciseType = "translation" // Упражнения на перевод
 	ExerciseTypeListening   ExerciseType = "listening"   // Упражнения на аудирование
 	ExerciseTypeSpeaking    ExerciseType = "speaking"    // Упражнения на разговорную речь
+	ExerciseTypeReading     ExerciseType = "reading"     // Упражнения на понимание прочитанного
 )
 
 // EnglishLevel определяет уровень владения английским
@@ -35,10 +40,34 @@ const (
 	EnglishLevelC2 EnglishLevel = "C2" // Proficiency
 )
 
+// GrammarTopic - тема грамматического упражнения, на которой можно сосредоточиться
+type GrammarTopic string
+
+const (
+	GrammarTopicAny          GrammarTopic = ""             // Любая тема, подходящая уровню
+	GrammarTopicTenses       GrammarTopic = "tenses"       // Времена глаголов
+	GrammarTopicArticles     GrammarTopic = "articles"     // Артикли
+	GrammarTopicPrepositions GrammarTopic = "prepositions" // Предлоги
+)
+
+// grammarTopicFocus - описание темы для промпта генерации упражнения
+var grammarTopicFocus = map[GrammarTopic]string{
+	GrammarTopicTenses:       "verb tenses (choosing and forming the right tense)",
+	GrammarTopicArticles:     "articles (a, an, the and the zero article)",
+	GrammarTopicPrepositions: "prepositions (of time, place and movement, and dependent prepositions)",
+}
+
+// IsKnownGrammarTopic проверяет, что для темы есть описание в промпте
+func IsKnownGrammarTopic(topic GrammarTopic) bool {
+	_, ok := grammarTopicFocus[topic]
+	return ok
+}
+
 // Exercise представляет упражнение
 type Exercise struct {
 	Type        ExerciseType // Тип упражнения
 	Level       EnglishLevel // Уровень сложности
+	Topic       GrammarTopic // Тема грамматического упражнения, пустая для любой темы
 	Instruction string       // Инструкция к упражнению
 	Content     string       // Содержание упражнения
 	Answer      string       // Правильный ответ
@@ -52,28 +81,34 @@ func NewExerciseService(openAI *OpenAIService) *ExerciseService {
 	}
 }
 
-// GetPromptForExerciseType возвращает системный промпт для генерации упражнения
-func (s *ExerciseService) GetPromptForExerciseType(exerciseType ExerciseType, level EnglishLevel) string {
+// GetPromptForExerciseType возвращает системный промпт для генерации упражнения.
+// topic сосредотачивает грамматическое упражнение на одной теме и не влияет на остальные типы
+func (s *ExerciseService) GetPromptForExerciseType(exerciseType ExerciseType, level EnglishLevel, topic GrammarTopic) string {
 	switch exerciseType {
 	case ExerciseTypeGrammar:
-		return fmt.Sprintf(`Create a grammar exercise for %s level student. 
-The exercise should test a specific grammar point appropriate for this level.
-The response should include:
-1. Clear instructions
-2. The exercise content
-3. The correct answer(s)
-4. A brief explanation of the grammar rule tested
-Format the response clearly with sections.`, level)
+		focus := "a specific grammar point appropriate for this level"
+		if description, ok := grammarTopicFocus[topic]; ok {
+			focus = description
+		}
+		return fmt.Sprintf(`Create a grammar exercise for %s level student.
+The exercise should test %s.
+Write only what the student sees: clear instructions and the exercise content.
+Do not include the answers or an explanation of the rule.`, level, focus)
 
 	case ExerciseTypeVocabulary:
-		return fmt.Sprintf(`Create a vocabulary exercise for %s level student.
-The exercise should test knowledge of words appropriate for this level.
-The response should include:
-1. Clear instructions
-2. The exercise content (could be fill-in-the-blank, matching, etc.)
-3. The correct answer(s)
-4. Usage examples for the vocabulary items
-Format the response clearly with sections.`, level)
+		return fmt.Sprintf(`Create a fill-in-the-blank vocabulary exercise for %s level student.
+Write one natural sentence in which a single word appropriate for this level is replaced with "%s".
+The context should make one word clearly the best fit for the blank.
+If other words fit the blank equally well, list them in "alternatives", otherwise leave it empty.
+Respond ONLY with a JSON object:
+{"sentence": "<the sentence with %s>", "answer": "<the missing word>", "alternatives": ["<another word that fits>"]}`, level, vocabularyBlank, vocabularyBlank)
+
+	case ExerciseTypeSpeaking:
+		return fmt.Sprintf(`Create a pronunciation exercise for %s level student.
+Write one natural English sentence of 6-14 words for the student to read aloud.
+Use vocabulary and grammar appropriate for this level and include sounds that learners often find difficult.
+Respond ONLY with a JSON object:
+{"sentence": "<the sentence>"}`, level)
 
 	case ExerciseTypeTranslation:
 		return fmt.Sprintf(`Create a translation exercise for %s level student.
@@ -86,6 +121,16 @@ The response should include:
 4. Notes on any particularly challenging aspects
 Format the response clearly with sections.`, level)
 
+	case ExerciseTypeReading:
+		return fmt.Sprintf(`Create a reading comprehension exercise for %s level student.
+Write a short passage (80-200 words, depending on the level) on an everyday or interesting topic,
+using vocabulary and grammar appropriate for this level.
+Then write %d to %d questions that check understanding of the passage.
+Each question must be answerable from the passage with a short phrase or sentence.
+Respond ONLY with a JSON object:
+{"passage": "<the passage>", "questions": [{"question": "<question>", "answer": "<short correct answer>"}]}`,
+			level, minReadingQuestions, maxReadingQuestions)
+
 	default:
 		return fmt.Sprintf(`Create an English language exercise for %s level student.
 The exercise should be appropriate for this level and engaging.
@@ -97,13 +142,14 @@ Format the response clearly with sections.`, level)
 	}
 }
 
-// GenerateExercise генерирует упражнение через OpenAI
-func (s *ExerciseService) GenerateExercise(exerciseType ExerciseType, level EnglishLevel) (*Exercise, error) {
+// GenerateExercise генерирует упражнение через OpenAI.
+// Для грамматических упражнений topic задает тему, GrammarTopicAny оставляет выбор модели
+func (s *ExerciseService) GenerateExercise(ctx context.Context, exerciseType ExerciseType, level EnglishLevel, topic GrammarTopic) (*Exercise, error) {
 	// Получаем промпт для генерации упражнения
-	prompt := s.GetPromptForExerciseType(exerciseType, level)
+	prompt := s.GetPromptForExerciseType(exerciseType, level, topic)
 
 	// Генерируем упражнение через OpenAI
-	content, err := s.openAI.GenerateResponse("Generate an exercise", prompt)
+	content, err := s.openAI.GenerateResponseWithModel(ctx, s.openAI.ModelForExercise(), "Generate an exercise", prompt)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка генерации упражнения: %w", err)
 	}
@@ -112,6 +158,7 @@ func (s *ExerciseService) GenerateExercise(exerciseType ExerciseType, level Engl
 	exercise := &Exercise{
 		Type:        exerciseType,
 		Level:       level,
+		Topic:       topic,
 		Content:     content,
 		Instruction: extractInstructions(content),
 		// Здесь мы должны извлечь ответ из сгенерированного контента,
@@ -121,11 +168,20 @@ func (s *ExerciseService) GenerateExercise(exerciseType ExerciseType, level Engl
 	return exercise, nil
 }
 
+// SupportsSimpleExercise проверяет, умеет ли GenerateSimpleExercise генерировать упражнения этого типа
+func SupportsSimpleExercise(exerciseType ExerciseType) bool {
+	switch exerciseType {
+	case ExerciseTypeGrammar, ExerciseTypeVocabulary, ExerciseTypeTranslation:
+		return true
+	}
+	return false
+}
+
 // GenerateSimpleExercise генерирует простое упражнение без использования OpenAI
 // Полезно как запасной вариант или для тестирования
 func (s *ExerciseService) GenerateSimpleExercise(exerciseType ExerciseType, level EnglishLevel) (*Exercise, error) {
-	// Инициализируем генератор случайных чисел
-	rand.Seed(time.Now().UnixNano())
+	// Используем глобальный генератор math/rand: он автоматически инициализируется
+	// случайным значением и безопасен для конкурентного использования
 
 	var exercise Exercise
 	exercise.Type = exerciseType
@@ -294,20 +350,31 @@ func (s *ExerciseService) GenerateSimpleExercise(exerciseType ExerciseType, leve
 }
 
 // CheckAnswer проверяет ответ пользователя
-// Возвращает оценку (0-100) и комментарий
-func (s *ExerciseService) CheckAnswer(exercise *Exercise, userAnswer string) (int, string) {
+// Возвращает оценку (0-100) и комментарий на языке lang
+func (s *ExerciseService) CheckAnswer(lang string, exercise *Exercise, userAnswer string) (int, string) {
 	// Очищаем ответы от лишних пробелов, приводим к нижнему регистру
-	normalizedUserAnswer := strings.ToLower(strings.TrimSpace(userAnswer))
-	normalizedCorrectAnswer := strings.ToLower(strings.TrimSpace(exercise.Answer))
+	normalize := func(answer string) string {
+		return strings.ToLower(strings.TrimSpace(answer))
+	}
+
+	// В переводе важен смысл, поэтому пунктуацию и артикли не учитываем
+	if exercise.Type == ExerciseTypeTranslation {
+		normalize = normalizeTranslation
+	}
+
+	normalizedUserAnswer := normalize(userAnswer)
 
 	// Подготавливаем возможные варианты правильных ответов
 	// Некоторые ответы могут иметь несколько вариантов (например, "like/love")
-	correctVariants := strings.Split(normalizedCorrectAnswer, "/")
+	correctVariants := strings.Split(exercise.Answer, "/")
+	for i, variant := range correctVariants {
+		correctVariants[i] = normalize(variant)
+	}
 
 	// Проверяем точное совпадение с одним из вариантов
 	for _, variant := range correctVariants {
 		if normalizedUserAnswer == strings.TrimSpace(variant) {
-			return 100, "Perfect! Your answer is correct."
+			return 100, i18n.T(lang, "exercise.answer.perfect")
 		}
 	}
 
@@ -315,22 +382,136 @@ func (s *ExerciseService) CheckAnswer(exercise *Exercise, userAnswer string) (in
 	for _, variant := range correctVariants {
 		// Если совпадение более 80% (простая эвристика)
 		if levenshteinRatio(normalizedUserAnswer, strings.TrimSpace(variant)) > 0.8 {
-			return 80, "Almost correct! There are some minor errors in your answer."
+			return 80, i18n.T(lang, "exercise.answer.almost")
 		}
 	}
 
 	// Проверяем на частичное совпадение
 	for _, variant := range correctVariants {
 		if strings.Contains(normalizedUserAnswer, strings.TrimSpace(variant)) {
-			return 60, "Partially correct. Your answer contains the right elements but has some issues."
+			return 60, i18n.T(lang, "exercise.answer.partial")
 		}
 	}
 
-	return 0, "Your answer is incorrect. Please try again."
+	return 0, i18n.T(lang, "exercise.answer.incorrect")
+}
+
+// GradeResult представляет оценку ответа, выставленную ChatGPT
+type GradeResult struct {
+	Score       int    `json:"score"`       // Оценка от 0 до 100
+	Explanation string `json:"explanation"` // Пояснение к оценке
+}
+
+// GradeAnswer оценивает ответ пользователя через OpenAI, когда правильный ответ заранее неизвестен
+// Возвращает оценку (0-100) и комментарий
+func (s *ExerciseService) GradeAnswer(ctx context.Context, exerciseContent string, userAnswer string) (int, string, error) {
+	systemPrompt := `You are an English teacher grading a student's answer to an exercise.
+Compare the student's answer with what the exercise expects and respond ONLY with a JSON object:
+{"score": <integer from 0 to 100>, "explanation": "<short explanation addressed to the student, including the correct answer if the student is wrong>"}
+A fully correct answer gets 100, minor spelling mistakes around 80, partially correct answers around 50, wrong answers 0.`
+
+	prompt := fmt.Sprintf("Exercise:\n%s\n\nStudent's answer:\n%s", exerciseContent, userAnswer)
+
+	response, err := s.openAI.GenerateResponse(ctx, prompt, systemPrompt)
+	if err != nil {
+		return 0, "", fmt.Errorf("ошибка оценки ответа: %w", err)
+	}
+
+	var result GradeResult
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &result); err != nil {
+		return 0, "", fmt.Errorf("ошибка разбора оценки ответа: %w", err)
+	}
+
+	// Ограничиваем оценку допустимым диапазоном
+	if result.Score < 0 {
+		result.Score = 0
+	} else if result.Score > 100 {
+		result.Score = 100
+	}
+
+	return result.Score, result.Explanation, nil
+}
+
+// ExplainRule получает от OpenAI краткое объяснение правила, которое проверяет упражнение
+func (s *ExerciseService) ExplainRule(ctx context.Context, exerciseContent string) (string, error) {
+	systemPrompt := `You are an English teacher. Explain the grammar rule or vocabulary point tested by the exercise below
+to the student in 3-5 short sentences, with one or two examples. Do not repeat the exercise itself.`
+
+	response, err := s.openAI.GenerateResponse(ctx, exerciseContent, systemPrompt)
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения объяснения правила: %w", err)
+	}
+
+	return strings.TrimSpace(response), nil
+}
+
+// RevealAnswer получает от OpenAI правильный ответ на упражнение с коротким пояснением,
+// когда ответ заранее неизвестен
+func (s *ExerciseService) RevealAnswer(ctx context.Context, exerciseContent string) (string, error) {
+	systemPrompt := `You are an English teacher. The student skipped the exercise below.
+Give the correct answer and a one-sentence explanation addressed to the student. Be brief.`
+
+	response, err := s.openAI.GenerateResponse(ctx, exerciseContent, systemPrompt)
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения ответа на упражнение: %w", err)
+	}
+
+	return strings.TrimSpace(response), nil
+}
+
+// GenerateHint получает от OpenAI подсказку к упражнению, не раскрывая ответ.
+// hintNumber - номер подсказки начиная с 1: каждая следующая конкретнее предыдущей
+func (s *ExerciseService) GenerateHint(ctx context.Context, exerciseContent string, hintNumber int) (string, error) {
+	systemPrompt := fmt.Sprintf(`You are an English teacher helping a student with the exercise below.
+Give hint number %d. The first hint is a short reminder of the grammar rule or concept involved;
+the second hint is more specific and points at the key word or form.
+Never reveal the full answer. Reply with one or two short sentences addressed to the student.`, hintNumber)
+
+	response, err := s.openAI.GenerateResponse(ctx, exerciseContent, systemPrompt)
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения подсказки: %w", err)
+	}
+
+	return strings.TrimSpace(response), nil
 }
 
 // Вспомогательные функции
 
+// normalizeTranslation приводит перевод к виду для сравнения:
+// нижний регистр, без знаков препинания, артиклей и лишних пробелов
+func normalizeTranslation(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) && r != '\'' {
+			return ' '
+		}
+		return unicode.ToLower(r)
+	}, text)
+
+	words := strings.Fields(text)
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		switch word {
+		case "a", "an", "the":
+			continue
+		}
+		result = append(result, word)
+	}
+
+	return strings.Join(result, " ")
+}
+
+// extractJSONObject извлекает JSON-объект из ответа модели, который может быть обернут в текст или markdown
+func extractJSONObject(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+
+	if start != -1 && end != -1 && start < end {
+		return content[start : end+1]
+	}
+
+	return content
+}
+
 // extractInstructions извлекает инструкции из сгенерированного контента
 func extractInstructions(content string) string {
 	lines := strings.Split(content, "\n")
@@ -379,7 +560,7 @@ func cleanExerciseContent(content string) string {
 // Возвращает значение от 0 до 1, где 1 означает полное совпадение
 func levenshteinRatio(s1, s2 string) float64 {
 	dist := levenshteinDistance(s1, s2)
-	maxLen := float64(max(len(s1), len(s2)))
+	maxLen := float64(max(utf8.RuneCountInString(s1), utf8.RuneCountInString(s2)))
 
 	if maxLen == 0 {
 		return 1.0
@@ -388,19 +569,23 @@ func levenshteinRatio(s1, s2 string) float64 {
 	return 1.0 - float64(dist)/maxLen
 }
 
-// levenshteinDistance вычисляет расстояние Левенштейна между двумя строками
+// levenshteinDistance вычисляет расстояние Левенштейна между двумя строками.
+// Сравнение ведется по символам (рунам), а не по байтам, чтобы корректно работать с кириллицей и диакритикой
 func levenshteinDistance(s1, s2 string) int {
-	if len(s1) == 0 {
-		return len(s2)
+	r1 := []rune(s1)
+	r2 := []rune(s2)
+
+	if len(r1) == 0 {
+		return len(r2)
 	}
-	if len(s2) == 0 {
-		return len(s1)
+	if len(r2) == 0 {
+		return len(r1)
 	}
 
 	// Создаем матрицу расстояний
-	matrix := make([][]int, len(s1)+1)
+	matrix := make([][]int, len(r1)+1)
 	for i := range matrix {
-		matrix[i] = make([]int, len(s2)+1)
+		matrix[i] = make([]int, len(r2)+1)
 		matrix[i][0] = i
 	}
 	for j := range matrix[0] {
@@ -408,10 +593,10 @@ func levenshteinDistance(s1, s2 string) int {
 	}
 
 	// Заполняем матрицу
-	for i := 1; i <= len(s1); i++ {
-		for j := 1; j <= len(s2); j++ {
+	for i := 1; i <= len(r1); i++ {
+		for j := 1; j <= len(r2); j++ {
 			cost := 1
-			if s1[i-1] == s2[j-1] {
+			if r1[i-1] == r2[j-1] {
 				cost = 0
 			}
 			matrix[i][j] = min(
@@ -422,7 +607,7 @@ func levenshteinDistance(s1, s2 string) int {
 		}
 	}
 
-	return matrix[len(s1)][len(s2)]
+	return matrix[len(r1)][len(r2)]
 }
 
 // min возвращает минимальное из трех чисел
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCheckAnswer(t *testing.T) {
+	s := NewExerciseService(nil)
+
+	tests := []struct {
+		name     string
+		exercise Exercise
+		answer   string
+		want     int
+	}{
+		{"точный ответ", Exercise{Type: ExerciseTypeGrammar, Answer: "is speaking"}, "is speaking", 100},
+		{"регистр и пробелы", Exercise{Type: ExerciseTypeGrammar, Answer: "is speaking"}, "  Is Speaking ", 100},
+		{"второй вариант ответа", Exercise{Type: ExerciseTypeTranslation, Answer: "I like pizza./I love pizza."}, "I love pizza", 100},
+		{"опечатка", Exercise{Type: ExerciseTypeVocabulary, Answer: "unprecedented"}, "unprecedentet", 80},
+		{"ответ внутри фразы", Exercise{Type: ExerciseTypeGrammar, Answer: "go"}, "I think it is go here", 60},
+		{"неверный ответ", Exercise{Type: ExerciseTypeGrammar, Answer: "had studied"}, "will study", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, comment := s.CheckAnswer("en", &tt.exercise, tt.answer)
+			if score != tt.want {
+				t.Errorf("CheckAnswer(%q) = %d, ожидалось %d", tt.answer, score, tt.want)
+			}
+			if comment == "" {
+				t.Error("CheckAnswer вернул пустой комментарий")
+			}
+		})
+	}
+}
+
+func TestExtractJSONObject(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`{"score": 90}`, `{"score": 90}`},
+		{"```json\n{\"score\": 90}\n```", `{"score": 90}`},
+		{`Here is the grade: {"score": 50, "explanation": "ok"} Good luck!`, `{"score": 50, "explanation": "ok"}`},
+		{"no json here", "no json here"},
+	}
+
+	for _, tt := range tests {
+		if got := extractJSONObject(tt.content); got != tt.want {
+			t.Errorf("extractJSONObject(%q) = %q, ожидалось %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestGradeAnswer(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		wantScore int
+		wantErr   bool
+	}{
+		{"оценка в markdown", "```json\n{\"score\": 70, \"explanation\": \"Almost.\"}\n```", 70, false},
+		{"оценка больше 100", `{"score": 150, "explanation": "Great."}`, 100, false},
+		{"отрицательная оценка", `{"score": -5, "explanation": "Wrong."}`, 0, false},
+		{"ответ без JSON", "I cannot grade this.", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openAI := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+				chatReply(w, tt.reply)
+			})
+			s := NewExerciseService(openAI)
+
+			score, _, err := s.GradeAnswer(context.Background(), "Describe your weekend.", "I went to the park.")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GradeAnswer ошибка = %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+			if score != tt.wantScore {
+				t.Errorf("GradeAnswer = %d, ожидалось %d", score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestExplainRule(t *testing.T) {
+	var request OpenAIRequest
+	openAI := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		chatReply(w, "\nUse the Present Simple for habits: She goes to school every day.\n")
+	})
+	s := NewExerciseService(openAI)
+
+	explanation, err := s.ExplainRule(context.Background(), "She ___ (go) to school every day.")
+	if err != nil {
+		t.Fatalf("ExplainRule: %v", err)
+	}
+	if explanation != "Use the Present Simple for habits: She goes to school every day." {
+		t.Errorf("объяснение %q", explanation)
+	}
+	if len(request.Messages) != 2 || request.Messages[1].Content != "She ___ (go) to school every day." {
+		t.Errorf("упражнение отправлено как %+v", request.Messages)
+	}
+}
+
+func TestLevenshteinDistance(t *testing.T) {
+	tests := []struct {
+		s1, s2 string
+		want   int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"привет", "привет", 0},
+		{"привет", "превед", 2},
+		{"café", "cafe", 1},
+		{"naïve", "naive", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshteinDistance(tt.s1, tt.s2); got != tt.want {
+			t.Errorf("levenshteinDistance(%q, %q) = %d, ожидалось %d", tt.s1, tt.s2, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshteinRatioCountsRunes(t *testing.T) {
+	// Одна замена в слове из 6 символов: по байтам кириллица дала бы другое соотношение
+	got := levenshteinRatio("привет", "привед")
+	want := 1.0 - 1.0/6.0
+	if got != want {
+		t.Errorf("levenshteinRatio = %v, ожидалось %v", got, want)
+	}
+
+	if got := levenshteinRatio("", ""); got != 1.0 {
+		t.Errorf("levenshteinRatio пустых строк = %v, ожидалось 1", got)
+	}
+}
+
+func TestNormalizeTranslation(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The weather is good today.", "weather is good today"},
+		{"I have a dog and a cat!", "i have dog and cat"},
+		{"  An apple,   please ", "apple please"},
+		{"I don't like it.", "i don't like it"},
+		{"Theatre is there", "theatre is there"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeTranslation(tt.text); got != tt.want {
+			t.Errorf("normalizeTranslation(%q) = %q, ожидалось %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAnswerIgnoresPunctuationAndArticlesInTranslation(t *testing.T) {
+	s := NewExerciseService(nil)
+	exercise := &Exercise{Type: ExerciseTypeTranslation, Answer: "I have a dog and a cat."}
+
+	if score, _ := s.CheckAnswer("en", exercise, "i have the dog and cat"); score != 100 {
+		t.Errorf("перевод с другими артиклями получил %d, ожидалось 100", score)
+	}
+
+	// Для остальных типов артикль остается частью ответа
+	grammar := &Exercise{Type: ExerciseTypeGrammar, Answer: "a"}
+	if score, _ := s.CheckAnswer("en", grammar, "the"); score == 100 {
+		t.Error("в грамматическом упражнении артикль не должен игнорироваться")
+	}
+}
+
+func TestGenerateSimpleExercise(t *testing.T) {
+	s := NewExerciseService(nil)
+	levels := []EnglishLevel{EnglishLevelA1, EnglishLevelA2, EnglishLevelB1, EnglishLevelB2, EnglishLevelC1, EnglishLevelC2}
+
+	for _, exerciseType := range []ExerciseType{ExerciseTypeGrammar, ExerciseTypeVocabulary, ExerciseTypeTranslation} {
+		for _, level := range levels {
+			exercise, err := s.GenerateSimpleExercise(exerciseType, level)
+			if err != nil {
+				t.Fatalf("GenerateSimpleExercise(%s, %s): %v", exerciseType, level, err)
+			}
+			if exercise.Type != exerciseType || exercise.Level != level {
+				t.Errorf("упражнение %s/%s вместо %s/%s", exercise.Type, exercise.Level, exerciseType, level)
+			}
+			if exercise.Instruction == "" || exercise.Content == "" || exercise.Answer == "" {
+				t.Errorf("неполное упражнение %s/%s: %+v", exerciseType, level, exercise)
+			}
+			if len(exercise.Options) > 0 && !slices.Contains(exercise.Options, exercise.Answer) {
+				t.Errorf("среди вариантов %v нет ответа %q", exercise.Options, exercise.Answer)
+			}
+		}
+	}
+}
+
+func TestSupportsSimpleExercise(t *testing.T) {
+	tests := []struct {
+		exerciseType ExerciseType
+		want         bool
+	}{
+		{ExerciseTypeGrammar, true},
+		{ExerciseTypeVocabulary, true},
+		{ExerciseTypeTranslation, true},
+		{ExerciseTypeReading, false},
+		{ExerciseTypeSpeaking, false},
+		{ExerciseType("conversation"), false},
+	}
+
+	for _, tt := range tests {
+		if got := SupportsSimpleExercise(tt.exerciseType); got != tt.want {
+			t.Errorf("SupportsSimpleExercise(%s) = %v, ожидалось %v", tt.exerciseType, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateSimpleExerciseConcurrent(t *testing.T) {
+	s := NewExerciseService(nil)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.GenerateSimpleExercise(ExerciseTypeVocabulary, EnglishLevelA1); err != nil {
+				t.Errorf("GenerateSimpleExercise: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestGetPromptForExerciseTypeGrammarTopic(t *testing.T) {
+	s := NewExerciseService(nil)
+	generic := s.GetPromptForExerciseType(ExerciseTypeGrammar, EnglishLevelB1, GrammarTopicAny)
+
+	tests := []struct {
+		topic GrammarTopic
+		want  string
+	}{
+		{GrammarTopicTenses, "verb tenses"},
+		{GrammarTopicArticles, "articles"},
+		{GrammarTopicPrepositions, "prepositions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.topic), func(t *testing.T) {
+			prompt := s.GetPromptForExerciseType(ExerciseTypeGrammar, EnglishLevelB1, tt.topic)
+			if !strings.Contains(prompt, tt.want) {
+				t.Errorf("в промпте нет темы %q:\n%s", tt.want, prompt)
+			}
+			if strings.Contains(generic, tt.want) {
+				t.Errorf("промпт без темы упоминает %q:\n%s", tt.want, generic)
+			}
+		})
+	}
+}
+
+func TestGetPromptForExerciseTypeIgnoresTopicForOtherTypes(t *testing.T) {
+	s := NewExerciseService(nil)
+
+	for _, exerciseType := range []ExerciseType{ExerciseTypeVocabulary, ExerciseTypeTranslation, ExerciseTypeReading} {
+		want := s.GetPromptForExerciseType(exerciseType, EnglishLevelA2, GrammarTopicAny)
+		if got := s.GetPromptForExerciseType(exerciseType, EnglishLevelA2, GrammarTopicPrepositions); got != want {
+			t.Errorf("тема изменила промпт упражнения %s:\n%s", exerciseType, got)
+		}
+	}
+}
+
+func TestIsKnownGrammarTopic(t *testing.T) {
+	tests := []struct {
+		topic GrammarTopic
+		want  bool
+	}{
+		{GrammarTopicTenses, true},
+		{GrammarTopicArticles, true},
+		{GrammarTopicPrepositions, true},
+		{GrammarTopicAny, false},
+		{GrammarTopic("phrasal verbs"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsKnownGrammarTopic(tt.topic); got != tt.want {
+			t.Errorf("IsKnownGrammarTopic(%q) = %v, ожидалось %v", tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateExerciseWithGrammarTopic(t *testing.T) {
+	var request OpenAIRequest
+	openAI := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		chatReply(w, "Fill in the preposition: The book is ___ the table.")
+	})
+	s := NewExerciseService(openAI)
+
+	exercise, err := s.GenerateExercise(context.Background(), ExerciseTypeGrammar, EnglishLevelA2, GrammarTopicPrepositions)
+	if err != nil {
+		t.Fatalf("GenerateExercise: %v", err)
+	}
+	if exercise.Topic != GrammarTopicPrepositions {
+		t.Errorf("тема упражнения %q, ожидалась %q", exercise.Topic, GrammarTopicPrepositions)
+	}
+	if len(request.Messages) == 0 || !strings.Contains(request.Messages[0].Content, "prepositions") {
+		t.Errorf("системный промпт не упоминает предлоги: %+v", request.Messages)
+	}
+}
@@ -3,16 +3,30 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"english-bot/internal/i18n"
+	"english-bot/internal/metrics"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
+	"unicode/utf16"
 )
 
+// correctionContextLength - количество кодовых единиц UTF-16 контекста по обе стороны от ошибки
+const correctionContextLength = 10
+
+// DefaultLanguageToolBaseURL - адрес публичного LanguageTool API по умолчанию
+const DefaultLanguageToolBaseURL = "https://api.languagetool.org"
+
+// DefaultLanguageToolTimeout - таймаут запросов к LanguageTool по умолчанию
+const DefaultLanguageToolTimeout = 10 * time.Second
+
 // LanguageToolService предоставляет функциональность для работы с LanguageTool API
 type LanguageToolService struct {
 	baseURL string
 	client  *http.Client
+	cache   *checkCache // Кэш результатов проверки, nil если кэширование выключено
 }
 
 // LanguageToolRequest представляет запрос к API LanguageTool
@@ -66,24 +80,68 @@ type LanguageToolResponse struct {
 	} `json:"language"`
 }
 
-// NewLanguageToolService создает новый сервис для работы с LanguageTool
-func NewLanguageToolService() *LanguageToolService {
+// CheckOptions задает дополнительные параметры проверки текста
+type CheckOptions struct {
+	MotherTongue string // Родной язык пользователя, помогает находить ложных друзей переводчика
+	Level        string // Уровень строгости проверки: "default" или "picky"
+}
+
+// NewLanguageToolService создает новый сервис для работы с LanguageTool.
+// baseURL позволяет использовать собственный сервер LanguageTool вместо публичного API
+func NewLanguageToolService(baseURL string, timeout time.Duration) *LanguageToolService {
+	if baseURL == "" {
+		baseURL = DefaultLanguageToolBaseURL
+	}
+	if timeout <= 0 {
+		timeout = DefaultLanguageToolTimeout
+	}
+
 	return &LanguageToolService{
-		baseURL: "https://api.languagetool.org/v2/check",
-		client:  &http.Client{},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// EnableCache включает кэширование результатов проверки в памяти:
+// не более size записей, каждая хранится не дольше ttl.
+// При size <= 0 кэширование выключается
+func (s *LanguageToolService) EnableCache(size int, ttl time.Duration) {
+	if size <= 0 || ttl <= 0 {
+		s.cache = nil
+		return
 	}
+	s.cache = newCheckCache(size, ttl)
 }
 
-// CheckText проверяет текст на грамматические и стилистические ошибки
-func (s *LanguageToolService) CheckText(text string) (*LanguageToolResponse, error) {
+// checkChunk проверяет один фрагмент текста одним запросом к LanguageTool
+func (s *LanguageToolService) checkChunk(text string, opts CheckOptions) (result *LanguageToolResponse, err error) {
+	const language = "en-US"
+
+	// Повторные проверки того же текста берем из кэша
+	var cacheKey string
+	if s.cache != nil {
+		cacheKey = checkCacheKey(text, language, opts)
+		if cached := s.cache.get(cacheKey); cached != nil {
+			return cached, nil
+		}
+	}
+
+	defer func() { metrics.RecordExternalError("languagetool", "check", err) }()
+
 	// Формируем данные для запроса
 	data := url.Values{}
 	data.Set("text", text)
-	data.Set("language", "en-US")
+	data.Set("language", language)
 	data.Set("enabledOnly", "false")
+	if opts.MotherTongue != "" {
+		data.Set("motherTongue", opts.MotherTongue)
+	}
+	if opts.Level != "" {
+		data.Set("level", opts.Level)
+	}
 
 	// Отправляем запрос
-	req, err := http.NewRequest("POST", s.baseURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequest("POST", s.baseURL+"/v2/check", strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
 	}
@@ -110,50 +168,64 @@ func (s *LanguageToolService) CheckText(text string) (*LanguageToolResponse, err
 		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
 	}
 
+	if s.cache != nil {
+		s.cache.put(cacheKey, &response)
+	}
+
 	return &response, nil
 }
 
-// FormatCorrections форматирует найденные ошибки в удобный для пользователя вид
-func (s *LanguageToolService) FormatCorrections(text string, response *LanguageToolResponse) string {
+// FormatCorrections форматирует найденные ошибки в удобный для пользователя вид на языке lang.
+// Результат предназначен для отправки с разметкой Markdown
+func (s *LanguageToolService) FormatCorrections(lang, text string, response *LanguageToolResponse) string {
 	if len(response.Matches) == 0 {
-		return "✅ Ваш текст грамматически корректен! Отличная работа!"
+		return i18n.T(lang, "check.no_issues")
 	}
 
+	// LanguageTool (написан на Java) считает смещения в кодовых единицах UTF-16,
+	// поэтому индексируем текст так же, а не по байтам
+	units := utf16.Encode([]rune(text))
+
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("🔍 Найдено %d ошибок:\n\n", len(response.Matches)))
+	result.WriteString(i18n.T(lang, "check.issues_found", len(response.Matches)) + "\n\n")
 
 	for i, match := range response.Matches {
 		// Добавляем номер ошибки
-		result.WriteString(fmt.Sprintf("%d. *Ошибка*: %s\n", i+1, match.Message))
+		result.WriteString(fmt.Sprintf("%d. %s\n", i+1, i18n.T(lang, "check.issue", EscapeMarkdown(match.Message))))
 
 		// Добавляем контекст с выделенной ошибкой
-		errorText := text[match.Offset : match.Offset+match.Length]
+		start := clamp(match.Offset, 0, len(units))
+		end := clamp(match.Offset+match.Length, start, len(units))
+
+		errorText := utf16String(units[start:end])
 		contextBefore := ""
 		contextAfter := ""
 
-		if match.Offset > 10 {
-			contextBefore = "..." + text[match.Offset-10:match.Offset]
+		if start > correctionContextLength {
+			contextBefore = "..." + utf16String(units[start-correctionContextLength:start])
 		} else {
-			contextBefore = text[:match.Offset]
+			contextBefore = utf16String(units[:start])
 		}
 
-		if match.Offset+match.Length+10 < len(text) {
-			contextAfter = text[match.Offset+match.Length:match.Offset+match.Length+10] + "..."
+		if end+correctionContextLength < len(units) {
+			contextAfter = utf16String(units[end:end+correctionContextLength]) + "..."
 		} else {
-			contextAfter = text[match.Offset+match.Length:]
+			contextAfter = utf16String(units[end:])
 		}
 
-		result.WriteString(fmt.Sprintf("   *Контекст*: %s*%s*%s\n", contextBefore, errorText, contextAfter))
+		// Внутри выделения экранирование не работает, поэтому звездочки из фрагмента убираем
+		result.WriteString("   " + i18n.T(lang, "check.context",
+			EscapeMarkdown(contextBefore), strings.ReplaceAll(errorText, "*", ""), EscapeMarkdown(contextAfter)) + "\n")
 
 		// Добавляем предлагаемые исправления
 		if len(match.Replacements) > 0 {
 			replacements := make([]string, 0, len(match.Replacements))
 			for j, r := range match.Replacements {
 				if j < 3 { // Ограничиваем количество предложений
-					replacements = append(replacements, r.Value)
+					replacements = append(replacements, EscapeMarkdown(r.Value))
 				}
 			}
-			result.WriteString(fmt.Sprintf("   *Варианты исправления*: %s\n", strings.Join(replacements, ", ")))
+			result.WriteString("   " + i18n.T(lang, "check.suggestions", strings.Join(replacements, ", ")) + "\n")
 		}
 
 		// Добавляем пустую строку между ошибками
@@ -163,12 +235,28 @@ func (s *LanguageToolService) FormatCorrections(text string, response *LanguageT
 	return result.String()
 }
 
-// CheckGrammar комбинирует проверку и форматирование результатов
-func (s *LanguageToolService) CheckGrammar(text string) (string, error) {
-	response, err := s.CheckText(text)
+// CheckGrammar комбинирует проверку и форматирование результатов на языке lang
+func (s *LanguageToolService) CheckGrammar(lang, text string) (string, error) {
+	response, err := s.CheckText(text, CheckOptions{})
 	if err != nil {
 		return "", err
 	}
 
-	return s.FormatCorrections(text, response), nil
+	return s.FormatCorrections(lang, text, response), nil
+}
+
+// utf16String преобразует кодовые единицы UTF-16 обратно в строку
+func utf16String(units []uint16) string {
+	return string(utf16.Decode(units))
+}
+
+// clamp ограничивает значение v диапазоном [lo, hi]
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
 }
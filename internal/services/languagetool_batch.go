@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Ограничения пакетной проверки текста, в кодовых единицах UTF-16, как считает LanguageTool
+const (
+	MaxCheckTextLength      = 20000 // Максимальная длина текста для проверки
+	languageToolChunkLength = 1500  // Максимальная длина фрагмента, отправляемого одним запросом
+)
+
+// paragraphSeparator разделяет абзацы проверяемого текста
+const paragraphSeparator = "\n\n"
+
+// sentenceTerminators - знаки, после которых с пробелом начинается новое предложение
+const sentenceTerminators = ".!?"
+
+// ErrTextTooLong возвращается, если текст длиннее MaxCheckTextLength
+var ErrTextTooLong = errors.New("текст слишком длинный для проверки")
+
+// textChunk - фрагмент проверяемого текста
+type textChunk struct {
+	text   string
+	offset int // Смещение фрагмента в исходном тексте в кодовых единицах UTF-16
+}
+
+// CheckText проверяет текст на грамматические и стилистические ошибки.
+// Длинный текст делится на абзацы и предложения, каждый фрагмент проверяется
+// отдельным запросом (с использованием кэша), а найденные ошибки объединяются
+// со смещениями относительно всего текста
+func (s *LanguageToolService) CheckText(text string, opts CheckOptions) (*LanguageToolResponse, error) {
+	if utf16Length(text) > MaxCheckTextLength {
+		return nil, ErrTextTooLong
+	}
+
+	chunks := splitCheckChunks(text, languageToolChunkLength)
+	if len(chunks) <= 1 {
+		return s.checkChunk(text, opts)
+	}
+
+	var merged *LanguageToolResponse
+	for _, chunk := range chunks {
+		response, err := s.checkChunk(chunk.text, opts)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка проверки фрагмента текста: %w", err)
+		}
+
+		if merged == nil {
+			// Копируем сведения о программе и языке, ошибки собираем заново
+			merged = &LanguageToolResponse{Software: response.Software, Language: response.Language}
+		}
+
+		for _, match := range response.Matches {
+			match.Offset += chunk.offset
+			merged.Matches = append(merged.Matches, match)
+		}
+	}
+
+	return merged, nil
+}
+
+// splitCheckChunks делит текст на фрагменты не длиннее maxLength по границам абзацев,
+// а слишком длинные абзацы - по границам предложений. Пустые абзацы пропускаются
+func splitCheckChunks(text string, maxLength int) []textChunk {
+	var chunks []textChunk
+
+	offset := 0 // Смещение начала текущего абзаца в UTF-16
+	for len(text) > 0 {
+		paragraph, rest, found := strings.Cut(text, paragraphSeparator)
+
+		if strings.TrimSpace(paragraph) != "" {
+			chunks = append(chunks, splitParagraph(paragraph, offset, maxLength)...)
+		}
+
+		offset += utf16Length(paragraph)
+		if found {
+			offset += utf16Length(paragraphSeparator)
+		}
+		text = rest
+	}
+
+	return chunks
+}
+
+// splitParagraph делит абзац на фрагменты не длиннее maxLength, объединяя соседние предложения.
+// Предложение длиннее maxLength делится по символам
+func splitParagraph(paragraph string, offset, maxLength int) []textChunk {
+	if utf16Length(paragraph) <= maxLength {
+		return []textChunk{{text: paragraph, offset: offset}}
+	}
+
+	var chunks []textChunk
+	var current strings.Builder
+	currentOffset := offset
+
+	// Фрагменты из одних пробелов не проверяем, но учитываем в смещении
+	add := func(text string) {
+		if strings.TrimSpace(text) != "" {
+			chunks = append(chunks, textChunk{text: text, offset: currentOffset})
+		}
+		currentOffset += utf16Length(text)
+	}
+	flush := func() {
+		add(current.String())
+		current.Reset()
+	}
+
+	for _, sentence := range splitSentences(paragraph) {
+		if utf16Length(current.String())+utf16Length(sentence) > maxLength {
+			flush()
+		}
+
+		for utf16Length(sentence) > maxLength {
+			head, tail := splitAtUTF16(sentence, maxLength)
+			add(head)
+			sentence = tail
+		}
+
+		current.WriteString(sentence)
+	}
+	flush()
+
+	return chunks
+}
+
+// splitSentences делит текст на предложения, оставляя знаки препинания
+// и следующие за ними пробелы в конце предложения, чтобы фрагменты покрывали весь текст
+func splitSentences(text string) []string {
+	var sentences []string
+
+	start := 0
+	for i, r := range text {
+		if !strings.ContainsRune(sentenceTerminators, r) {
+			continue
+		}
+
+		end := i + utf8.RuneLen(r)
+		for end < len(text) && (text[end] == ' ' || text[end] == '\n') {
+			end++
+		}
+		if end > start && (end == len(text) || end > i+utf8.RuneLen(r)) {
+			sentences = append(sentences, text[start:end])
+			start = end
+		}
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+
+	return sentences
+}
+
+// splitAtUTF16 делит строку после не более чем maxLength кодовых единиц UTF-16, не разрывая символы
+func splitAtUTF16(text string, maxLength int) (string, string) {
+	length := 0
+	for i, r := range text {
+		runeLength := utf16.RuneLen(r)
+		if length+runeLength > maxLength {
+			return text[:i], text[i:]
+		}
+		length += runeLength
+	}
+	return text, ""
+}
+
+// utf16Length возвращает длину строки в кодовых единицах UTF-16
+func utf16Length(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16.RuneLen(r)
+	}
+	return length
+}
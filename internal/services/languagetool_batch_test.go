@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// checkChunksCoverText проверяет, что каждый фрагмент совпадает с текстом по своему смещению в UTF-16
+func checkChunksCoverText(t *testing.T, text string, chunks []textChunk, maxLength int) {
+	t.Helper()
+
+	units := utf16.Encode([]rune(text))
+	for _, chunk := range chunks {
+		length := utf16Length(chunk.text)
+		if length > maxLength {
+			t.Errorf("фрагмент %q длиннее %d", chunk.text, maxLength)
+		}
+		if chunk.offset+length > len(units) || string(utf16.Decode(units[chunk.offset:chunk.offset+length])) != chunk.text {
+			t.Errorf("фрагмент %q не совпадает с текстом по смещению %d", chunk.text, chunk.offset)
+		}
+	}
+}
+
+func TestUTF16Length(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"привет", 6},
+		{"😀", 2},
+		{"a😀b", 4},
+	}
+
+	for _, tt := range tests {
+		if got := utf16Length(tt.text); got != tt.want {
+			t.Errorf("utf16Length(%q) = %d, ожидалось %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitAtUTF16(t *testing.T) {
+	tests := []struct {
+		text      string
+		maxLength int
+		wantHead  string
+		wantTail  string
+	}{
+		{"hello", 3, "hel", "lo"},
+		{"abc", 5, "abc", ""},
+		{"a😀b", 2, "a", "😀b"},
+		{"a😀b", 3, "a😀", "b"},
+		{"привет", 4, "прив", "ет"},
+	}
+
+	for _, tt := range tests {
+		head, tail := splitAtUTF16(tt.text, tt.maxLength)
+		if head != tt.wantHead || tail != tt.wantTail {
+			t.Errorf("splitAtUTF16(%q, %d) = %q, %q; ожидалось %q, %q", tt.text, tt.maxLength, head, tail, tt.wantHead, tt.wantTail)
+		}
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"One. Two! Three?", []string{"One. ", "Two! ", "Three?"}},
+		{"Version 1.5 is out. Yes", []string{"Version 1.5 is out. ", "Yes"}},
+		{"Wait... what?", []string{"Wait... ", "what?"}},
+		{"Line one.\nLine two.", []string{"Line one.\n", "Line two."}},
+		{"No terminator", []string{"No terminator"}},
+	}
+
+	for _, tt := range tests {
+		got := splitSentences(tt.text)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitSentences(%q) = %q, ожидалось %q", tt.text, got, tt.want)
+		}
+		if joined := strings.Join(got, ""); joined != tt.text {
+			t.Errorf("предложения %q не складываются в исходный текст", got)
+		}
+	}
+}
+
+func TestSplitCheckChunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		want      []textChunk
+	}{
+		{"один абзац", "She go home.", 100, []textChunk{{"She go home.", 0}}},
+		{"два абзаца", "First one.\n\nSecond one.", 100, []textChunk{{"First one.", 0}, {"Second one.", 12}}},
+		{"пустые абзацы пропускаются", "A.\n\n\n\nB.", 100, []textChunk{{"A.", 0}, {"B.", 6}}},
+		{"смещение в UTF-16", "😀 Привет.\n\nHi.", 100, []textChunk{{"😀 Привет.", 0}, {"Hi.", 12}}},
+		{"длинный абзац по предложениям", "One. Two. Three.", 10, []textChunk{{"One. Two. ", 0}, {"Three.", 10}}},
+		{"длинное предложение по символам", "abcdefghijkl", 5, []textChunk{{"abcde", 0}, {"fghij", 5}, {"kl", 10}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitCheckChunks(tt.text, tt.maxLength)
+			if !slices.Equal(chunks, tt.want) {
+				t.Errorf("splitCheckChunks = %+v, ожидалось %+v", chunks, tt.want)
+			}
+			checkChunksCoverText(t, tt.text, chunks, tt.maxLength)
+		})
+	}
+}
+
+func TestSplitCheckChunksLongText(t *testing.T) {
+	paragraph := strings.Repeat("She go to school every day. ", 100)
+	text := paragraph + "\n\n" + "Привет, мир! " + paragraph
+
+	chunks := splitCheckChunks(text, languageToolChunkLength)
+	if len(chunks) < 4 {
+		t.Errorf("текст разбит на %d фрагментов, ожидалось не меньше 4", len(chunks))
+	}
+	checkChunksCoverText(t, text, chunks, languageToolChunkLength)
+}
+
+func TestCheckTextMergesParagraphs(t *testing.T) {
+	var requests atomic.Int32
+	s := newTestLanguageTool(t, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		r.ParseForm()
+		switch r.Form.Get("text") {
+		case "She go home.":
+			languageToolReply(w, newMatch("Use 'goes'.", 4, 2, "goes"))
+		case "He go to work.":
+			languageToolReply(w, newMatch("Use 'goes'.", 3, 2, "goes"))
+		default:
+			t.Errorf("неожиданный фрагмент %q", r.Form.Get("text"))
+			languageToolReply(w)
+		}
+	})
+
+	text := "She go home.\n\nHe go to work."
+	response, err := s.CheckText(text, CheckOptions{})
+	if err != nil {
+		t.Fatalf("CheckText: %v", err)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("отправлено %d запросов, ожидалось по одному на абзац", got)
+	}
+
+	var offsets []int
+	for _, match := range response.Matches {
+		offsets = append(offsets, match.Offset)
+		if got := text[match.Offset : match.Offset+match.Length]; got != "go" {
+			t.Errorf("ошибка по смещению %d указывает на %q, ожидалось go", match.Offset, got)
+		}
+	}
+	if !slices.Equal(offsets, []int{4, 17}) {
+		t.Errorf("смещения ошибок %v, ожидалось [4 17]", offsets)
+	}
+
+	corrections := s.FormatCorrections("en", text, response)
+	if strings.Count(corrections, "goes") < 2 {
+		t.Errorf("в исправлениях нет обеих ошибок:\n%s", corrections)
+	}
+}
+
+func TestCheckTextReusesCacheForParagraphs(t *testing.T) {
+	var mu sync.Mutex
+	var checked []string
+	s := newTestLanguageTool(t, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		checked = append(checked, r.Form.Get("text"))
+		mu.Unlock()
+		languageToolReply(w)
+	})
+	s.EnableCache(10, time.Hour)
+
+	for _, text := range []string{"First one.\n\nSecond one.", "First one.\n\nThird one."} {
+		if _, err := s.CheckText(text, CheckOptions{}); err != nil {
+			t.Fatalf("CheckText: %v", err)
+		}
+	}
+
+	want := []string{"First one.", "Second one.", "Third one."}
+	if !slices.Equal(checked, want) {
+		t.Errorf("проверены фрагменты %q, ожидалось %q", checked, want)
+	}
+}
+
+func TestCheckTextTooLong(t *testing.T) {
+	var requests atomic.Int32
+	s := newTestLanguageTool(t, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		languageToolReply(w)
+	})
+
+	tests := []struct {
+		name    string
+		text    string
+		wantErr error
+	}{
+		{"на границе", strings.Repeat("a", MaxCheckTextLength), nil},
+		{"длиннее границы", strings.Repeat("a", MaxCheckTextLength+1), ErrTextTooLong},
+		{"длиннее границы в UTF-16", strings.Repeat("😀", MaxCheckTextLength/2+1), ErrTextTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+
+			_, err := s.CheckText(tt.text, CheckOptions{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckText ошибка = %v, ожидалась %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && requests.Load() != 0 {
+				t.Errorf("слишком длинный текст отправлен в LanguageTool %d раз", requests.Load())
+			}
+		})
+	}
+}
+
+func TestCheckTextFailsWhenParagraphFails(t *testing.T) {
+	s := newTestLanguageTool(t, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("text") == "Broken one." {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		languageToolReply(w)
+	})
+
+	if _, err := s.CheckText("Fine one.\n\nBroken one.", CheckOptions{}); err == nil {
+		t.Error("CheckText не вернул ошибку проверки абзаца")
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// checkCache - потокобезопасный LRU-кэш результатов проверки LanguageTool
+// с ограничением по количеству записей и времени жизни
+type checkCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // Последние использованные записи в начале списка
+	entries map[string]*list.Element
+}
+
+// checkCacheEntry - запись кэша
+type checkCacheEntry struct {
+	key       string
+	response  *LanguageToolResponse
+	expiresAt time.Time
+}
+
+// newCheckCache создает кэш на size записей со временем жизни ttl
+func newCheckCache(size int, ttl time.Duration) *checkCache {
+	return &checkCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// checkCacheKey формирует ключ кэша из текста, языка и параметров проверки
+func checkCacheKey(text, language string, opts CheckOptions) string {
+	hash := sha256.New()
+	for _, part := range []string{text, language, opts.MotherTongue, opts.Level} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// get возвращает копию сохраненного результата или nil, если записи нет или она устарела
+func (c *checkCache) get(key string) *LanguageToolResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry := element.Value.(*checkCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil
+	}
+
+	c.order.MoveToFront(element)
+	return copyLanguageToolResponse(entry.response)
+}
+
+// put сохраняет копию результата, вытесняя самую давно использованную запись при переполнении
+func (c *checkCache) put(key string, response *LanguageToolResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &checkCacheEntry{
+		key:       key,
+		response:  copyLanguageToolResponse(response),
+		expiresAt: time.Now().Add(c.ttl),
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*checkCacheEntry).key)
+	}
+}
+
+// copyLanguageToolResponse создает глубокую копию ответа, чтобы вызывающий код
+// не мог изменить данные, хранящиеся в кэше
+func copyLanguageToolResponse(response *LanguageToolResponse) *LanguageToolResponse {
+	result := *response
+	result.Matches = make([]LanguageToolMatch, len(response.Matches))
+	for i, match := range response.Matches {
+		match.Replacements = append(match.Replacements[:0:0], match.Replacements...)
+		result.Matches[i] = match
+	}
+	return &result
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckCacheGetPut(t *testing.T) {
+	cache := newCheckCache(2, time.Hour)
+	response := &LanguageToolResponse{Matches: []LanguageToolMatch{newMatch("Typo.", 0, 3, "the")}}
+
+	if cache.get("a") != nil {
+		t.Fatal("пустой кэш вернул результат")
+	}
+
+	cache.put("a", response)
+	got := cache.get("a")
+	if got == nil || len(got.Matches) != 1 || got.Matches[0].Message != "Typo." {
+		t.Fatalf("из кэша получено %+v", got)
+	}
+}
+
+func TestCheckCacheReturnsCopies(t *testing.T) {
+	cache := newCheckCache(2, time.Hour)
+	response := &LanguageToolResponse{Matches: []LanguageToolMatch{newMatch("Typo.", 0, 3, "the")}}
+	cache.put("a", response)
+
+	// Изменения исходного ответа и полученной копии не должны попадать в кэш
+	response.Matches[0].Message = "changed"
+	got := cache.get("a")
+	got.Matches[0].Offset = 100
+	got.Matches[0].Replacements[0].Value = "changed"
+
+	again := cache.get("a")
+	if again.Matches[0].Message != "Typo." || again.Matches[0].Offset != 0 || again.Matches[0].Replacements[0].Value != "the" {
+		t.Errorf("данные в кэше изменились: %+v", again.Matches[0])
+	}
+}
+
+func TestCheckCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newCheckCache(2, time.Hour)
+	cache.put("a", &LanguageToolResponse{})
+	cache.put("b", &LanguageToolResponse{})
+
+	// Обращение к "a" делает самой старой запись "b"
+	cache.get("a")
+	cache.put("c", &LanguageToolResponse{})
+
+	if cache.get("b") != nil {
+		t.Error("давно использованная запись не вытеснена")
+	}
+	if cache.get("a") == nil || cache.get("c") == nil {
+		t.Error("вытеснена недавно использованная запись")
+	}
+}
+
+func TestCheckCacheExpires(t *testing.T) {
+	cache := newCheckCache(2, 10*time.Millisecond)
+	cache.put("a", &LanguageToolResponse{})
+
+	time.Sleep(20 * time.Millisecond)
+
+	if cache.get("a") != nil {
+		t.Error("устаревшая запись возвращена из кэша")
+	}
+	if len(cache.entries) != 0 || cache.order.Len() != 0 {
+		t.Error("устаревшая запись не удалена из кэша")
+	}
+}
+
+func TestCheckCacheKey(t *testing.T) {
+	base := checkCacheKey("text", "en-US", CheckOptions{})
+
+	if checkCacheKey("text", "en-US", CheckOptions{}) != base {
+		t.Error("ключ одного и того же запроса различается")
+	}
+	for _, key := range []string{
+		checkCacheKey("other", "en-US", CheckOptions{}),
+		checkCacheKey("text", "en-GB", CheckOptions{}),
+		checkCacheKey("text", "en-US", CheckOptions{MotherTongue: "ru"}),
+		checkCacheKey("text", "en-US", CheckOptions{Level: "picky"}),
+	} {
+		if key == base {
+			t.Error("ключ не учитывает параметры проверки")
+		}
+	}
+}
+
+func TestCheckTextUsesCache(t *testing.T) {
+	var requests atomic.Int32
+	s := newTestLanguageTool(t, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		languageToolReply(w, newMatch("Agreement error.", 4, 2, "goes"))
+	})
+	s.EnableCache(10, time.Hour)
+
+	for range 3 {
+		if _, err := s.CheckText("She go home.", CheckOptions{}); err != nil {
+			t.Fatalf("CheckText: %v", err)
+		}
+	}
+	if _, err := s.CheckText("She go home.", CheckOptions{MotherTongue: "ru"}); err != nil {
+		t.Fatalf("CheckText: %v", err)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("отправлено %d запросов к LanguageTool, ожидалось 2", got)
+	}
+}
+
+func TestEnableCacheDisabled(t *testing.T) {
+	s := NewLanguageToolService("", 0)
+	s.EnableCache(0, time.Hour)
+	if s.cache != nil {
+		t.Error("кэш включен при нулевом размере")
+	}
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"encoding/json"
+	"english-bot/internal/i18n"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLanguageTool запускает тестовый сервер вместо LanguageTool и возвращает сервис, настроенный на него
+func newTestLanguageTool(t *testing.T, timeout time.Duration, handler http.HandlerFunc) *LanguageToolService {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewLanguageToolService(server.URL+"/", timeout)
+}
+
+// languageToolReply отвечает на запрос проверки найденными ошибками
+func languageToolReply(w http.ResponseWriter, matches ...LanguageToolMatch) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LanguageToolResponse{Matches: matches})
+}
+
+// newMatch создает найденную LanguageTool ошибку с вариантами исправления
+func newMatch(message string, offset, length int, replacements ...string) LanguageToolMatch {
+	match := LanguageToolMatch{Message: message, Offset: offset, Length: length}
+	for _, value := range replacements {
+		match.Replacements = append(match.Replacements, struct {
+			Value string `json:"value"`
+		}{Value: value})
+	}
+	return match
+}
+
+func TestFormatCorrectionsWithoutMatches(t *testing.T) {
+	s := NewLanguageToolService("", 0)
+
+	got := s.FormatCorrections("en", "I like apples.", &LanguageToolResponse{})
+	if got != i18n.T("en", "check.no_issues") {
+		t.Errorf("FormatCorrections без ошибок = %q", got)
+	}
+}
+
+func TestFormatCorrections(t *testing.T) {
+	s := NewLanguageToolService("", 0)
+	text := "She go to school every day."
+	response := &LanguageToolResponse{Matches: []LanguageToolMatch{
+		newMatch("The verb does not agree with the subject.", 4, 2, "goes", "went", "is going", "has gone"),
+	}}
+
+	got := s.FormatCorrections("en", text, response)
+
+	for _, want := range []string{
+		i18n.T("en", "check.issues_found", 1),
+		"1. " + i18n.T("en", "check.issue", "The verb does not agree with the subject."),
+		i18n.T("en", "check.context", "She ", "go", " to school..."),
+		i18n.T("en", "check.suggestions", "goes, went, is going"),
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("в результате нет %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "has gone") {
+		t.Errorf("результат содержит больше трех исправлений:\n%s", got)
+	}
+}
+
+func TestFormatCorrectionsEscapesMarkdown(t *testing.T) {
+	s := NewLanguageToolService("", 0)
+	text := "my_var is *bold* and _it_"
+	response := &LanguageToolResponse{Matches: []LanguageToolMatch{
+		newMatch("Possible typo: use_underscores", 0, 6, "my_variable"),
+	}}
+
+	got := s.FormatCorrections("en", text, response)
+
+	if !strings.Contains(got, `use\_underscores`) || !strings.Contains(got, `my\_variable`) {
+		t.Errorf("сообщение и исправления не экранированы:\n%s", got)
+	}
+	if !strings.Contains(got, `\*bold`) {
+		t.Errorf("контекст ошибки не экранирован:\n%s", got)
+	}
+}
+
+func TestFormatCorrectionsUTF16Offsets(t *testing.T) {
+	s := NewLanguageToolService("", 0)
+
+	tests := []struct {
+		name    string
+		text    string
+		offset  int
+		length  int
+		wantErr string
+	}{
+		// Эмодзи занимает две кодовые единицы UTF-16 и четыре байта
+		{"после эмодзи", "😀 I has a cat", 5, 3, "has"},
+		// Кириллица занимает одну кодовую единицу UTF-16 и два байта
+		{"после кириллицы", "Привет! I has a cat", 10, 3, "has"},
+		{"диакритика", "The café are open", 9, 3, "are"},
+		{"ошибка в эмодзи-строке", "I love 🍕 pizzas 🍕 tonight", 10, 6, "pizzas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &LanguageToolResponse{Matches: []LanguageToolMatch{newMatch("Check this.", tt.offset, tt.length)}}
+			got := s.FormatCorrections("en", tt.text, response)
+
+			if !strings.Contains(got, "*"+tt.wantErr+"*") {
+				t.Errorf("выделен не тот фрагмент, ожидался %q:\n%s", tt.wantErr, got)
+			}
+		})
+	}
+}
+
+func TestFormatCorrectionsClampsOffsets(t *testing.T) {
+	s := NewLanguageToolService("", 0)
+	text := "Short text"
+
+	tests := []struct {
+		name   string
+		offset int
+		length int
+	}{
+		{"смещение за концом текста", 100, 5},
+		{"длина за концом текста", 6, 50},
+		{"отрицательное смещение", -3, 2},
+		{"отрицательная длина", 3, -2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("FormatCorrections упал: %v", r)
+				}
+			}()
+
+			response := &LanguageToolResponse{Matches: []LanguageToolMatch{newMatch("Check this.", tt.offset, tt.length)}}
+			if got := s.FormatCorrections("en", text, response); got == "" {
+				t.Error("FormatCorrections вернул пустой результат")
+			}
+		})
+	}
+}
+
+func TestFormatCorrectionsLongContext(t *testing.T) {
+	s := NewLanguageToolService("", 0)
+	text := "Ёжик в тумане 🦔 he go home slowly through the misty forest"
+	// "go" начинается после 14 символов кириллицы и пробелов и одного эмодзи из двух кодовых единиц
+	offset := len([]rune("Ёжик в тумане ")) + 2 + len(" he ")
+
+	response := &LanguageToolResponse{Matches: []LanguageToolMatch{newMatch("Check this.", offset, 2)}}
+	got := s.FormatCorrections("en", text, response)
+
+	want := i18n.T("en", "check.context", "...ане 🦔 he ", "go", " home slow...")
+	if !strings.Contains(got, want) {
+		t.Errorf("в результате нет %q:\n%s", want, got)
+	}
+}
+
+func TestNewLanguageToolServiceDefaults(t *testing.T) {
+	s := NewLanguageToolService("", 0)
+	if s.baseURL != DefaultLanguageToolBaseURL || s.client.Timeout != DefaultLanguageToolTimeout {
+		t.Errorf("по умолчанию адрес %q и таймаут %v", s.baseURL, s.client.Timeout)
+	}
+
+	s = NewLanguageToolService("http://languagetool:8010/", 3*time.Second)
+	if s.baseURL != "http://languagetool:8010" || s.client.Timeout != 3*time.Second {
+		t.Errorf("заданы адрес %q и таймаут %v", s.baseURL, s.client.Timeout)
+	}
+}
+
+func TestCheckTextUsesConfiguredServer(t *testing.T) {
+	s := newTestLanguageTool(t, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/check" {
+			t.Errorf("запрос отправлен на %q", r.URL.Path)
+		}
+		r.ParseForm()
+		if r.Form.Get("text") != "She go home." || r.Form.Get("language") != "en-US" {
+			t.Errorf("параметры запроса %v", r.Form)
+		}
+		if r.Form.Get("motherTongue") != "ru" || r.Form.Get("level") != "picky" {
+			t.Errorf("не переданы параметры проверки: %v", r.Form)
+		}
+		languageToolReply(w, newMatch("Agreement error.", 4, 2, "goes"))
+	})
+
+	response, err := s.CheckText("She go home.", CheckOptions{MotherTongue: "ru", Level: "picky"})
+	if err != nil {
+		t.Fatalf("CheckText: %v", err)
+	}
+	if len(response.Matches) != 1 || response.Matches[0].Offset != 4 {
+		t.Errorf("найденные ошибки %+v", response.Matches)
+	}
+}
+
+func TestCheckTextTimeout(t *testing.T) {
+	s := newTestLanguageTool(t, 50*time.Millisecond, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	})
+
+	start := time.Now()
+	if _, err := s.CheckText("She go home.", CheckOptions{}); err == nil {
+		t.Fatal("CheckText не вернул ошибку таймаута")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("CheckText ждал %v при таймауте 50ms", elapsed)
+	}
+}
+
+func TestCheckTextAPIError(t *testing.T) {
+	s := newTestLanguageTool(t, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+	})
+
+	_, err := s.CheckText("She go home.", CheckOptions{})
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("CheckText = %v, ожидалась ошибка со статусом 429", err)
+	}
+}
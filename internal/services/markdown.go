@@ -0,0 +1,12 @@
+package services
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// EscapeMarkdown экранирует служебные символы Markdown (_, *, `, [) в динамическом тексте:
+// вводе пользователя, ответах OpenAI и LanguageTool и данных из БД.
+// Экранирование работает только вне сущностей, поэтому такой текст не стоит оборачивать в *...*
+func EscapeMarkdown(text string) string {
+	return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, text)
+}
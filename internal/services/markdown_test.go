@@ -0,0 +1,24 @@
+package services
+
+import "testing"
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"snake_case_name", `snake\_case\_name`},
+		{"*bold*", `\*bold\*`},
+		{"[link](url)", `\[link](url)`},
+		{"use `code`", "use \\`code\\`"},
+		{"Привет, мир!", "Привет, мир!"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeMarkdown(tt.text); got != tt.want {
+			t.Errorf("EscapeMarkdown(%q) = %q, ожидалось %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -2,15 +2,49 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"english-bot/internal/metrics"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultOpenAIBaseURL - адрес OpenAI API по умолчанию
+const DefaultOpenAIBaseURL = "https://api.openai.com"
+
+// DefaultOpenAIModel - модель ChatGPT по умолчанию
+const DefaultOpenAIModel = "gpt-3.5-turbo"
+
+// DefaultOpenAITimeout - время на один запрос к OpenAI по умолчанию
+const DefaultOpenAITimeout = 20 * time.Second
+
+// MaxSpeechInputLength - максимальная длина текста для синтеза речи
+const MaxSpeechInputLength = 4096
+
+// Ошибки OpenAI API, о которых стоит сообщить пользователю отдельно.
+// Возвращаются обернутыми вместе с описанием ошибки от API
+var (
+	ErrOpenAIRateLimited  = errors.New("превышен лимит запросов к OpenAI")
+	ErrOpenAIUnauthorized = errors.New("OpenAI отклонил ключ API")
+	ErrOpenAITimeout      = errors.New("OpenAI не ответил вовремя")
 )
 
 // OpenAIService предоставляет функциональность для работы с OpenAI API
 type OpenAIService struct {
-	apiKey string
-	client *http.Client
+	apiKey        string
+	baseURL       string
+	client        *http.Client
+	model         string        // Модель ChatGPT по умолчанию
+	grammarModel  string        // Модель для проверки грамматики и оценки текстов, пустая - модель по умолчанию
+	exerciseModel string        // Модель для генерации упражнений, пустая - модель по умолчанию
+	contextTokens int           // Бюджет токенов на историю диалога
+	timeout       time.Duration // Время на один запрос, включая чтение ответа
+	breaker       *CircuitBreaker
 }
 
 // OpenAIRequest представляет запрос к API ChatGPT
@@ -39,16 +73,186 @@ type OpenAIError struct {
 	Type    string `json:"type"`
 }
 
-// NewOpenAIService создает новый сервис для работы с OpenAI
-func NewOpenAIService(apiKey string) *OpenAIService {
+// TranscriptionResponse представляет ответ Whisper API
+type TranscriptionResponse struct {
+	Text  string       `json:"text"`
+	Error *OpenAIError `json:"error,omitempty"`
+}
+
+// SpeechRequest представляет запрос к API синтеза речи
+type SpeechRequest struct {
+	Model          string `json:"model"`
+	Input          string `json:"input"`
+	Voice          string `json:"voice"`
+	ResponseFormat string `json:"response_format"`
+}
+
+// ModerationRequest представляет запрос к API модерации
+type ModerationRequest struct {
+	Input string `json:"input"`
+}
+
+// ModerationResponse представляет ответ API модерации
+type ModerationResponse struct {
+	Results []struct {
+		Flagged bool `json:"flagged"`
+	} `json:"results"`
+	Error *OpenAIError `json:"error,omitempty"`
+}
+
+// NewOpenAIService создает новый сервис для работы с OpenAI.
+// baseURL позволяет работать через прокси или совместимый шлюз (Azure OpenAI, OpenRouter, локальная LLM)
+func NewOpenAIService(apiKey string, baseURL string) *OpenAIService {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+
 	return &OpenAIService{
-		apiKey: apiKey,
-		client: &http.Client{},
+		apiKey:        apiKey,
+		baseURL:       baseURL,
+		client:        &http.Client{},
+		model:         DefaultOpenAIModel,
+		contextTokens: DefaultContextTokens,
+		timeout:       DefaultOpenAITimeout,
+	}
+}
+
+// SetTimeout задает время на один запрос к OpenAI. Оно отсчитывается отдельно от контекста
+// обработки обновления, чтобы при медленном ответе успеть извиниться или выдать запасной вариант.
+// Неположительное значение оставляет DefaultOpenAITimeout
+func (s *OpenAIService) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.timeout = timeout
 	}
 }
 
-// GenerateResponse отправляет запрос к API ChatGPT и получает ответ
-func (s *OpenAIService) GenerateResponse(prompt string, systemPrompt string) (string, error) {
+// SetContextTokens задает бюджет токенов на историю диалога в SimulateConversation.
+// Неположительное значение оставляет DefaultContextTokens
+func (s *OpenAIService) SetContextTokens(tokens int) {
+	if tokens > 0 {
+		s.contextTokens = tokens
+	}
+}
+
+// SetCircuitBreaker включает автомат защиты: во время сбоя OpenAI запросы
+// сразу завершаются ошибкой ErrCircuitOpen, не дожидаясь таймаутов
+func (s *OpenAIService) SetCircuitBreaker(breaker *CircuitBreaker) {
+	s.breaker = breaker
+}
+
+// do выполняет HTTP-запрос к API через автомат защиты. Сбоем считаются
+// сетевые ошибки и ответы 5xx, а ошибки в самом запросе (4xx) - нет.
+// На запрос вместе с чтением ответа отводится timeout от контекста запроса;
+// если время истекло, ошибка оборачивает ErrOpenAITimeout
+func (s *OpenAIService) do(req *http.Request) (*http.Response, error) {
+	if err := s.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	parent := req.Context()
+	ctx, cancel := context.WithTimeout(parent, s.timeout)
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	s.breaker.Record(err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	if err != nil {
+		cancel()
+		return nil, timeoutError(parent, ctx, err)
+	}
+
+	// Таймаут действует, пока вызывающий код читает ответ, и снимается при закрытии тела
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, parent: parent, ctx: ctx, cancel: cancel}
+	return resp, nil
+}
+
+// timeoutBody - тело ответа OpenAI, которое снимает таймаут запроса при закрытии
+// и сообщает об истечении таймаута во время чтения как об ErrOpenAITimeout
+type timeoutBody struct {
+	io.ReadCloser
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = timeoutError(b.parent, b.ctx, err)
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// timeoutError оборачивает err в ErrOpenAITimeout, если истек таймаут запроса ctx,
+// а не контекст parent, от которого он отсчитывался
+func timeoutError(parent, ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+		return fmt.Errorf("%w: %w", ErrOpenAITimeout, err)
+	}
+	return err
+}
+
+// apiError формирует ошибку ответа OpenAI API. Ответы 429 и 401/403
+// оборачивают ErrOpenAIRateLimited и ErrOpenAIUnauthorized соответственно
+func apiError(statusCode int, apiErr *OpenAIError) error {
+	err := fmt.Errorf("ошибка API: статус %d", statusCode)
+	if apiErr != nil {
+		err = fmt.Errorf("ошибка API: %s (%s)", apiErr.Message, apiErr.Type)
+	}
+
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", ErrOpenAIRateLimited, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %w", ErrOpenAIUnauthorized, err)
+	}
+	return err
+}
+
+// SetModels задает модель ChatGPT по умолчанию и модели для отдельных задач.
+// Пустая модель по умолчанию оставляет DefaultOpenAIModel, пустые модели задач - модель по умолчанию
+func (s *OpenAIService) SetModels(defaultModel, grammarModel, exerciseModel string) {
+	if defaultModel != "" {
+		s.model = defaultModel
+	}
+	s.grammarModel = grammarModel
+	s.exerciseModel = exerciseModel
+}
+
+// ModelForGrammar возвращает модель для проверки грамматики и оценки текстов
+func (s *OpenAIService) ModelForGrammar() string {
+	if s.grammarModel != "" {
+		return s.grammarModel
+	}
+	return s.model
+}
+
+// ModelForExercise возвращает модель для генерации упражнений
+func (s *OpenAIService) ModelForExercise() string {
+	if s.exerciseModel != "" {
+		return s.exerciseModel
+	}
+	return s.model
+}
+
+// endpoint формирует полный адрес метода API с учетом завершающего слэша в baseURL.
+// Пути методов начинаются с /v1, поэтому /v1 в конце baseURL, как в адресах прокси
+// и OpenRouter, отбрасывается, чтобы не получить /v1/v1
+func (s *OpenAIService) endpoint(path string) string {
+	base := strings.TrimSuffix(strings.TrimRight(s.baseURL, "/"), "/v1")
+	return base + "/" + strings.TrimLeft(path, "/")
+}
+
+// GenerateResponse отправляет запрос к API ChatGPT и получает ответ модели по умолчанию
+func (s *OpenAIService) GenerateResponse(ctx context.Context, prompt string, systemPrompt string) (string, error) {
+	return s.GenerateResponseWithModel(ctx, s.model, prompt, systemPrompt)
+}
+
+// GenerateResponseWithModel отправляет запрос к API ChatGPT и получает ответ указанной модели
+func (s *OpenAIService) GenerateResponseWithModel(ctx context.Context, model, prompt, systemPrompt string) (string, error) {
 	messages := []ChatMessage{
 		{
 			Role:    "system",
@@ -60,13 +264,20 @@ func (s *OpenAIService) GenerateResponse(prompt string, systemPrompt string) (st
 		},
 	}
 
-	return s.SendChatRequest(messages)
+	return s.sendChatRequest(ctx, model, messages)
 }
 
-// SendChatRequest отправляет запрос к ChatGPT API
-func (s *OpenAIService) SendChatRequest(messages []ChatMessage) (string, error) {
+// SendChatRequest отправляет запрос к ChatGPT API с моделью по умолчанию
+func (s *OpenAIService) SendChatRequest(ctx context.Context, messages []ChatMessage) (string, error) {
+	return s.sendChatRequest(ctx, s.model, messages)
+}
+
+// sendChatRequest отправляет запрос к ChatGPT API с указанной моделью
+func (s *OpenAIService) sendChatRequest(ctx context.Context, model string, messages []ChatMessage) (content string, err error) {
+	defer func() { metrics.RecordExternalError("openai", "chat", err) }()
+
 	reqBody := OpenAIRequest{
-		Model:    "gpt-3.5-turbo", // Можно изменить на другую модель
+		Model:    model,
 		Messages: messages,
 	}
 
@@ -75,7 +286,7 @@ func (s *OpenAIService) SendChatRequest(messages []ChatMessage) (string, error)
 		return "", fmt.Errorf("ошибка маршалинга JSON: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(reqJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint("/v1/chat/completions"), bytes.NewBuffer(reqJSON))
 	if err != nil {
 		return "", fmt.Errorf("ошибка создания запроса: %w", err)
 	}
@@ -83,7 +294,7 @@ func (s *OpenAIService) SendChatRequest(messages []ChatMessage) (string, error)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+s.apiKey)
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return "", fmt.Errorf("ошибка отправки запроса: %w", err)
 	}
@@ -95,7 +306,7 @@ func (s *OpenAIService) SendChatRequest(messages []ChatMessage) (string, error)
 	}
 
 	if response.Error != nil {
-		return "", fmt.Errorf("ошибка API: %s (%s)", response.Error.Message, response.Error.Type)
+		return "", apiError(resp.StatusCode, response.Error)
 	}
 
 	if len(response.Choices) == 0 {
@@ -106,27 +317,58 @@ func (s *OpenAIService) SendChatRequest(messages []ChatMessage) (string, error)
 }
 
 // CheckGrammar проверяет грамматику текста с помощью ChatGPT
-func (s *OpenAIService) CheckGrammar(text string) (string, error) {
+func (s *OpenAIService) CheckGrammar(ctx context.Context, text string) (string, error) {
 	systemPrompt := `You are a helpful English language assistant. Your task is to:
 1. Identify grammar, spelling, and style errors in the provided text
 2. Provide corrections with explanations
 3. Rate the overall proficiency level (A1, A2, B1, B2, C1, C2)
 Format your response in clear sections.`
 
-	return s.GenerateResponse(text, systemPrompt)
+	return s.GenerateResponseWithModel(ctx, s.ModelForGrammar(), text, systemPrompt)
 }
 
 // GenerateExercise создает упражнение заданного уровня сложности
-func (s *OpenAIService) GenerateExercise(exerciseType string, level string) (string, error) {
+func (s *OpenAIService) GenerateExercise(ctx context.Context, exerciseType string, level string) (string, error) {
 	systemPrompt := fmt.Sprintf(`You are an English language tutor. Create a %s exercise for %s level student. 
 The exercise should be challenging but appropriate for the level.
 Format your response clearly with instructions and examples if needed.`, exerciseType, level)
 
-	return s.GenerateResponse("Generate an exercise", systemPrompt)
+	return s.GenerateResponseWithModel(ctx, s.ModelForExercise(), "Generate an exercise", systemPrompt)
+}
+
+// WordOfTheDay представляет слово дня, сгенерированное ChatGPT
+type WordOfTheDay struct {
+	Word        string `json:"word"`
+	Translation string `json:"translation"`
+	Definition  string `json:"definition"`
+	Example     string `json:"example"`
+}
+
+// GenerateWordOfTheDay подбирает полезное слово для заданного уровня с определением и примером
+func (s *OpenAIService) GenerateWordOfTheDay(ctx context.Context, level string) (*WordOfTheDay, error) {
+	systemPrompt := fmt.Sprintf(`You are an English language tutor. Pick one useful English word for a %s level student.
+Respond ONLY with a JSON object:
+{"word": "<the word>", "translation": "<Russian translation>", "definition": "<simple English definition>", "example": "<example sentence>"}`, level)
+
+	response, err := s.GenerateResponse(ctx, "Give me the word of the day", systemPrompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var word WordOfTheDay
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &word); err != nil {
+		return nil, fmt.Errorf("ошибка разбора слова дня: %w", err)
+	}
+
+	if word.Word == "" {
+		return nil, fmt.Errorf("пустое слово дня в ответе API")
+	}
+
+	return &word, nil
 }
 
 // SimulateConversation поддерживает диалог на заданную тему
-func (s *OpenAIService) SimulateConversation(userMessage string, conversationHistory []ChatMessage) (string, error) {
+func (s *OpenAIService) SimulateConversation(ctx context.Context, userMessage string, conversationHistory []ChatMessage) (string, error) {
 	// Добавляем системный промпт для разговора
 	if len(conversationHistory) == 0 {
 		conversationHistory = append(conversationHistory, ChatMessage{
@@ -141,6 +383,169 @@ func (s *OpenAIService) SimulateConversation(userMessage string, conversationHis
 		Content: userMessage,
 	})
 
-	// Отправляем запрос с полной историей диалога
-	return s.SendChatRequest(conversationHistory)
+	// Отбрасываем старые реплики, чтобы запрос поместился в окно контекста модели
+	conversationHistory = TrimChatHistory(conversationHistory, s.contextTokens)
+
+	return s.SendChatRequest(ctx, conversationHistory)
+}
+
+// TranscribeAudio распознает речь в аудиофайле с помощью модели Whisper
+func (s *OpenAIService) TranscribeAudio(ctx context.Context, audio io.Reader, filename string) (text string, err error) {
+	defer func() { metrics.RecordExternalError("openai", "transcription", err) }()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writer.WriteField("model", "whisper-1"); err != nil {
+		return "", fmt.Errorf("ошибка формирования запроса: %w", err)
+	}
+	if err := writer.WriteField("language", "en"); err != nil {
+		return "", fmt.Errorf("ошибка формирования запроса: %w", err)
+	}
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("ошибка формирования запроса: %w", err)
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return "", fmt.Errorf("ошибка чтения аудио: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("ошибка формирования запроса: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint("/v1/audio/transcriptions"), &body)
+	if err != nil {
+		return "", fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", fmt.Errorf("ошибка отправки запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response TranscriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("ошибка декодирования ответа: %w", err)
+	}
+
+	if response.Error != nil {
+		return "", apiError(resp.StatusCode, response.Error)
+	}
+
+	return strings.TrimSpace(response.Text), nil
+}
+
+// SynthesizeSpeech озвучивает текст и возвращает аудио в формате MP3.
+// Текст длиннее MaxSpeechInputLength обрезается по границе слова
+func (s *OpenAIService) SynthesizeSpeech(ctx context.Context, text, voice string) (audio []byte, err error) {
+	defer func() { metrics.RecordExternalError("openai", "speech", err) }()
+
+	if voice == "" {
+		voice = "alloy"
+	}
+
+	reqBody := SpeechRequest{
+		Model:          "tts-1",
+		Input:          truncateText(text, MaxSpeechInputLength),
+		Voice:          voice,
+		ResponseFormat: "mp3",
+	}
+
+	reqJSON, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка маршалинга JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint("/v1/audio/speech"), bytes.NewBuffer(reqJSON))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отправки запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// При ошибке API возвращает JSON вместо аудио
+	if resp.StatusCode != http.StatusOK {
+		var response OpenAIResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err == nil && response.Error != nil {
+			return nil, apiError(resp.StatusCode, response.Error)
+		}
+		return nil, apiError(resp.StatusCode, nil)
+	}
+
+	audio, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения аудио: %w", err)
+	}
+
+	return audio, nil
+}
+
+// Moderate проверяет текст через API модерации OpenAI.
+// Возвращает true, если текст нарушает правила использования
+func (s *OpenAIService) Moderate(ctx context.Context, text string) (flagged bool, err error) {
+	defer func() { metrics.RecordExternalError("openai", "moderation", err) }()
+
+	reqJSON, err := json.Marshal(ModerationRequest{Input: text})
+	if err != nil {
+		return false, fmt.Errorf("ошибка маршалинга JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint("/v1/moderations"), bytes.NewBuffer(reqJSON))
+	if err != nil {
+		return false, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отправки запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response ModerationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return false, fmt.Errorf("ошибка декодирования ответа: %w", err)
+	}
+
+	if response.Error != nil {
+		return false, apiError(resp.StatusCode, response.Error)
+	}
+
+	for _, result := range response.Results {
+		if result.Flagged {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// truncateText обрезает текст до maxLen символов, стараясь не разрывать слова
+func truncateText(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+
+	truncated := string(runes[:maxLen])
+	if idx := strings.LastIndexAny(truncated, " \n"); idx > 0 {
+		truncated = truncated[:idx]
+	}
+
+	return truncated
 }
@@ -0,0 +1,493 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestOpenAI запускает тестовый сервер вместо OpenAI API и возвращает сервис, настроенный на него
+func newTestOpenAI(t *testing.T, handler http.HandlerFunc) *OpenAIService {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewOpenAIService("test-key", server.URL)
+}
+
+// chatReply отвечает на запрос к /v1/chat/completions одним сообщением ассистента
+func chatReply(w http.ResponseWriter, content string) {
+	var response OpenAIResponse
+	response.Choices = append(response.Choices, struct {
+		Message ChatMessage `json:"message"`
+	}{Message: ChatMessage{Role: "assistant", Content: content}})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func TestSimulateConversationSendsHistory(t *testing.T) {
+	var got OpenAIRequest
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("не удалось разобрать запрос: %v", err)
+		}
+		chatReply(w, "Nice to meet you!")
+	})
+
+	history := []ChatMessage{
+		{Role: "system", Content: "You are a tutor."},
+		{Role: "user", Content: "Hi, my name is Anna."},
+		{Role: "assistant", Content: "Hello, Anna!"},
+	}
+
+	reply, err := s.SimulateConversation(context.Background(), "What is my name?", history)
+	if err != nil {
+		t.Fatalf("SimulateConversation: %v", err)
+	}
+	if reply != "Nice to meet you!" {
+		t.Errorf("ответ = %q", reply)
+	}
+
+	want := append(history, ChatMessage{Role: "user", Content: "What is my name?"})
+	if len(got.Messages) != len(want) {
+		t.Fatalf("отправлено %d сообщений, ожидалось %d: %+v", len(got.Messages), len(want), got.Messages)
+	}
+	for i := range want {
+		if got.Messages[i] != want[i] {
+			t.Errorf("сообщение %d = %+v, ожидалось %+v", i, got.Messages[i], want[i])
+		}
+	}
+}
+
+func TestSimulateConversationAddsSystemPromptToEmptyHistory(t *testing.T) {
+	var got OpenAIRequest
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		chatReply(w, "Hello!")
+	})
+
+	if _, err := s.SimulateConversation(context.Background(), "Hello", nil); err != nil {
+		t.Fatalf("SimulateConversation: %v", err)
+	}
+
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Content != "Hello" {
+		t.Errorf("сообщения = %+v, ожидались системный промпт и реплика пользователя", got.Messages)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"", "https://api.openai.com/v1/chat/completions"},
+		{"https://api.openai.com", "https://api.openai.com/v1/chat/completions"},
+		{"https://api.openai.com/", "https://api.openai.com/v1/chat/completions"},
+		{"https://openrouter.ai/api/v1", "https://openrouter.ai/api/v1/chat/completions"},
+		{"https://proxy.example.com/v1/", "https://proxy.example.com/v1/chat/completions"},
+		{"http://localhost:8080/openai", "http://localhost:8080/openai/v1/chat/completions"},
+	}
+
+	for _, tt := range tests {
+		s := NewOpenAIService("test-key", tt.baseURL)
+		if got := s.endpoint("/v1/chat/completions"); got != tt.want {
+			t.Errorf("endpoint при baseURL %q = %q, ожидалось %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
+
+func TestBaseURLWithVersionReachesServer(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		chatReply(w, "ok")
+	}))
+	defer server.Close()
+
+	s := NewOpenAIService("test-key", server.URL+"/v1")
+	if _, err := s.GenerateResponse(context.Background(), "Hi", "You are a tutor."); err != nil {
+		t.Fatalf("GenerateResponse: %v", err)
+	}
+	if path != "/v1/chat/completions" {
+		t.Errorf("запрос отправлен на %q, ожидался /v1/chat/completions", path)
+	}
+}
+
+func TestTranscribeAudio(t *testing.T) {
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			t.Errorf("запрос отправлен на %q", r.URL.Path)
+		}
+		if got := r.FormValue("model"); got != "whisper-1" {
+			t.Errorf("model = %q, ожидалась whisper-1", got)
+		}
+		if got := r.FormValue("language"); got != "en" {
+			t.Errorf("language = %q, ожидался en", got)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("в запросе нет файла: %v", err)
+			return
+		}
+		defer file.Close()
+		audio, _ := io.ReadAll(file)
+		if header.Filename != "voice.ogg" || string(audio) != "OggS-audio" {
+			t.Errorf("файл %q с содержимым %q", header.Filename, audio)
+		}
+
+		json.NewEncoder(w).Encode(TranscriptionResponse{Text: "  I like reading books.\n"})
+	})
+
+	text, err := s.TranscribeAudio(context.Background(), strings.NewReader("OggS-audio"), "voice.ogg")
+	if err != nil {
+		t.Fatalf("TranscribeAudio: %v", err)
+	}
+	if text != "I like reading books." {
+		t.Errorf("распознанный текст = %q", text)
+	}
+}
+
+func TestTranscribeAudioAPIError(t *testing.T) {
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(TranscriptionResponse{
+			Error: &OpenAIError{Message: "Rate limit reached", Type: "requests"},
+		})
+	})
+
+	_, err := s.TranscribeAudio(context.Background(), strings.NewReader("OggS-audio"), "voice.ogg")
+	if !errors.Is(err, ErrOpenAIRateLimited) {
+		t.Errorf("ошибка = %v, ожидалась ErrOpenAIRateLimited", err)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   string
+	}{
+		{"короткий текст", "Hello world", 20, "Hello world"},
+		{"ровно по длине", "Hello", 5, "Hello"},
+		{"по границе слова", "Hello wonderful world", 12, "Hello"},
+		{"без пробелов", "Supercalifragilistic", 5, "Super"},
+		{"кириллица по символам", "Привет мир", 8, "Привет"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateText(tt.text, tt.maxLen); got != tt.want {
+				t.Errorf("truncateText(%q, %d) = %q, ожидалось %q", tt.text, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSynthesizeSpeech(t *testing.T) {
+	var got SpeechRequest
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/speech" {
+			t.Errorf("запрос отправлен на %q", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("ID3-mp3"))
+	})
+
+	audio, err := s.SynthesizeSpeech(context.Background(), "Good morning", "")
+	if err != nil {
+		t.Fatalf("SynthesizeSpeech: %v", err)
+	}
+	if string(audio) != "ID3-mp3" {
+		t.Errorf("аудио = %q", audio)
+	}
+	want := SpeechRequest{Model: "tts-1", Input: "Good morning", Voice: "alloy", ResponseFormat: "mp3"}
+	if got != want {
+		t.Errorf("запрос = %+v, ожидался %+v", got, want)
+	}
+}
+
+func TestSynthesizeSpeechAPIError(t *testing.T) {
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(OpenAIResponse{
+			Error: &OpenAIError{Message: "Incorrect API key", Type: "invalid_request_error"},
+		})
+	})
+
+	_, err := s.SynthesizeSpeech(context.Background(), "Good morning", "nova")
+	if !errors.Is(err, ErrOpenAIUnauthorized) {
+		t.Errorf("ошибка = %v, ожидалась ErrOpenAIUnauthorized", err)
+	}
+}
+
+func TestGenerateWordOfTheDay(t *testing.T) {
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		chatReply(w, "Sure! ```json\n{\"word\": \"resilient\", \"translation\": \"стойкий\", \"definition\": \"able to recover quickly\", \"example\": \"Children are often resilient.\"}\n```")
+	})
+
+	word, err := s.GenerateWordOfTheDay(context.Background(), "B2")
+	if err != nil {
+		t.Fatalf("GenerateWordOfTheDay: %v", err)
+	}
+
+	want := WordOfTheDay{
+		Word:        "resilient",
+		Translation: "стойкий",
+		Definition:  "able to recover quickly",
+		Example:     "Children are often resilient.",
+	}
+	if *word != want {
+		t.Errorf("слово дня = %+v, ожидалось %+v", *word, want)
+	}
+}
+
+func TestGenerateWordOfTheDayEmptyWord(t *testing.T) {
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		chatReply(w, `{"word": "", "translation": ""}`)
+	})
+
+	if _, err := s.GenerateWordOfTheDay(context.Background(), "A1"); err == nil {
+		t.Error("GenerateWordOfTheDay принял ответ без слова")
+	}
+}
+
+func TestModerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     bool
+	}{
+		{"нарушение правил", `{"results": [{"flagged": true}]}`, true},
+		{"обычный текст", `{"results": [{"flagged": false}]}`, false},
+		{"нарушение в одном из результатов", `{"results": [{"flagged": false}, {"flagged": true}]}`, true},
+		{"без результатов", `{"results": []}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ModerationRequest
+			s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/moderations" {
+					t.Errorf("запрос отправлен на %q", r.URL.Path)
+				}
+				json.NewDecoder(r.Body).Decode(&got)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			})
+
+			flagged, err := s.Moderate(context.Background(), "some text")
+			if err != nil {
+				t.Fatalf("Moderate: %v", err)
+			}
+			if flagged != tt.want {
+				t.Errorf("Moderate = %v, ожидалось %v", flagged, tt.want)
+			}
+			if got.Input != "some text" {
+				t.Errorf("на модерацию отправлен текст %q", got.Input)
+			}
+		})
+	}
+}
+
+func TestModerateAPIError(t *testing.T) {
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ModerationResponse{
+			Error: &OpenAIError{Message: "Incorrect API key", Type: "invalid_request_error"},
+		})
+	})
+
+	if _, err := s.Moderate(context.Background(), "some text"); !errors.Is(err, ErrOpenAIUnauthorized) {
+		t.Errorf("ошибка = %v, ожидалась ErrOpenAIUnauthorized", err)
+	}
+}
+
+func TestSetModels(t *testing.T) {
+	tests := []struct {
+		name                                   string
+		defaultModel, grammar, exercise        string
+		wantDefault, wantGrammar, wantExercise string
+	}{
+		{"без настроек", "", "", "", DefaultOpenAIModel, DefaultOpenAIModel, DefaultOpenAIModel},
+		{"только модель по умолчанию", "gpt-4o-mini", "", "", "gpt-4o-mini", "gpt-4o-mini", "gpt-4o-mini"},
+		{"модели задач", "", "gpt-4o", "gpt-4o-mini", DefaultOpenAIModel, "gpt-4o", "gpt-4o-mini"},
+		{"все модели", "gpt-4o-mini", "gpt-4o", "gpt-3.5-turbo", "gpt-4o-mini", "gpt-4o", "gpt-3.5-turbo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewOpenAIService("test-key", "")
+			s.SetModels(tt.defaultModel, tt.grammar, tt.exercise)
+
+			if s.model != tt.wantDefault || s.ModelForGrammar() != tt.wantGrammar || s.ModelForExercise() != tt.wantExercise {
+				t.Errorf("модели %q, %q, %q, ожидались %q, %q, %q",
+					s.model, s.ModelForGrammar(), s.ModelForExercise(), tt.wantDefault, tt.wantGrammar, tt.wantExercise)
+			}
+		})
+	}
+}
+
+func TestRequestsUseFeatureModel(t *testing.T) {
+	var got OpenAIRequest
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		chatReply(w, "I ___ happy.")
+	})
+	s.SetModels("gpt-4o-mini", "gpt-4o", "gpt-3.5-turbo")
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		call      func() error
+		wantModel string
+	}{
+		{"проверка грамматики", func() error { _, err := s.CheckGrammar(ctx, "She go home."); return err }, "gpt-4o"},
+		{"оценка текста", func() error {
+			_, err := NewExerciseService(s).GradeWriting(ctx, "My weekend was nice.", EnglishLevelB1)
+			return err
+		}, "gpt-4o"},
+		{"генерация упражнения", func() error { _, err := s.GenerateExercise(ctx, "grammar", "A2"); return err }, "gpt-3.5-turbo"},
+		{"упражнение сервиса упражнений", func() error {
+			_, err := NewExerciseService(s).GenerateExercise(ctx, ExerciseTypeGrammar, EnglishLevelA2, "")
+			return err
+		}, "gpt-3.5-turbo"},
+		{"обычный запрос", func() error { _, err := s.GenerateResponse(ctx, "Hi", "Be brief."); return err }, "gpt-4o-mini"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = OpenAIRequest{}
+			// Ошибки разбора ответа не важны: проверяется только модель запроса
+			tt.call()
+			if got.Model != tt.wantModel {
+				t.Errorf("запрос отправлен модели %q, ожидалась %q", got.Model, tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestChatRequestAPIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   error
+	}{
+		{"лимит запросов", http.StatusTooManyRequests, ErrOpenAIRateLimited},
+		{"неверный ключ", http.StatusUnauthorized, ErrOpenAIUnauthorized},
+		{"доступ запрещен", http.StatusForbidden, ErrOpenAIUnauthorized},
+		{"ошибка сервера", http.StatusInternalServerError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(OpenAIResponse{
+					Error: &OpenAIError{Message: "request failed", Type: "api_error"},
+				})
+			})
+
+			_, err := s.SendChatRequest(context.Background(), []ChatMessage{{Role: "user", Content: "Hi"}})
+			if err == nil || !strings.Contains(err.Error(), "request failed") {
+				t.Fatalf("ошибка = %v, ожидалось сообщение API", err)
+			}
+
+			for _, sentinel := range []error{ErrOpenAIRateLimited, ErrOpenAIUnauthorized} {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, sentinel, got)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAITimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, r *http.Request, release <-chan struct{})
+	}{
+		{"нет заголовков ответа", func(w http.ResponseWriter, r *http.Request, release <-chan struct{}) {
+			<-release
+		}},
+		{"тело ответа не дочитано", func(w http.ResponseWriter, r *http.Request, release <-chan struct{}) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"choices": [`))
+			w.(http.Flusher).Flush()
+			<-release
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(w, r, release)
+			})
+			// Сервер отпускает зависший запрос до своей остановки
+			t.Cleanup(func() { close(release) })
+			s.SetTimeout(50 * time.Millisecond)
+
+			// Общий таймаут обработки обновления намного длиннее таймаута запроса
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			start := time.Now()
+			_, err := s.SendChatRequest(ctx, []ChatMessage{{Role: "user", Content: "Hi"}})
+			if !errors.Is(err, ErrOpenAITimeout) {
+				t.Errorf("ошибка = %v, ожидалась ErrOpenAITimeout", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("запрос прерван через %v, ожидалось около 50мс", elapsed)
+			}
+			if ctx.Err() != nil {
+				t.Error("истек общий таймаут, а не таймаут запроса к OpenAI")
+			}
+		})
+	}
+}
+
+func TestOpenAITimeoutOuterContext(t *testing.T) {
+	release := make(chan struct{})
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	t.Cleanup(func() { close(release) })
+	s.SetTimeout(5 * time.Second)
+
+	// Если раньше истек контекст обработки обновления, это не таймаут OpenAI
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := s.SendChatRequest(ctx, []ChatMessage{{Role: "user", Content: "Hi"}})
+	if err == nil || errors.Is(err, ErrOpenAITimeout) {
+		t.Errorf("ошибка = %v, ожидалась ошибка общего таймаута без ErrOpenAITimeout", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ошибка = %v, ожидалась context.DeadlineExceeded", err)
+	}
+}
+
+func TestOpenAISetTimeout(t *testing.T) {
+	s := NewOpenAIService("test-key", "")
+
+	s.SetTimeout(0)
+	if s.timeout != DefaultOpenAITimeout {
+		t.Errorf("после SetTimeout(0) таймаут %v, ожидался %v", s.timeout, DefaultOpenAITimeout)
+	}
+
+	s.SetTimeout(5 * time.Second)
+	if s.timeout != 5*time.Second {
+		t.Errorf("таймаут %v, ожидалось 5s", s.timeout)
+	}
+}
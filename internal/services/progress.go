@@ -3,11 +3,19 @@ package services
 import (
 	"context"
 	"english-bot/internal/database"
+	"english-bot/internal/i18n"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 )
 
+// maxRankedSkills - максимальное количество сильных и слабых навыков в статистике
+const maxRankedSkills = 2
+
+// writingTrendSize - количество последних письменных работ, по которым считается средняя оценка
+const writingTrendSize = 5
+
 // ProgressService предоставляет функциональность для работы с прогрессом пользователя
 type ProgressService struct {
 	db *database.PostgresDB
@@ -15,18 +23,23 @@ type ProgressService struct {
 
 // UserStats представляет статистику пользователя
 type UserStats struct {
-	TotalExercises       int       // Общее количество упражнений
-	CorrectExercises     int       // Правильно выполненные упражнения
-	SuccessRate          float64   // Процент успешных упражнений
-	TotalConversations   int       // Общее количество диалогов
-	TotalMessages        int       // Общее количество сообщений
-	CurrentStreak        int       // Текущая серия дней
-	LongestStreak        int       // Самая длинная серия
-	LastActivity         time.Time // Последняя активность
-	DaysActive           int       // Количество дней активности
-	StrongestSkills      []string  // Самые сильные навыки
-	WeakestSkills        []string  // Самые слабые навыки
-	RecommendedExercises []string  // Рекомендованные упражнения
+	TotalExercises       int                // Общее количество упражнений
+	CorrectExercises     int                // Правильно выполненные упражнения
+	SuccessRate          float64            // Процент успешных упражнений
+	TotalConversations   int                // Общее количество диалогов
+	TotalMessages        int                // Общее количество сообщений
+	CurrentStreak        int                // Текущая серия дней
+	LongestStreak        int                // Самая длинная серия
+	XP                   int                // Набранный опыт
+	LastActivity         time.Time          // Последняя активность
+	DaysActive           int                // Количество дней активности
+	StrongestSkills      []string           // Типы упражнений с лучшими результатами
+	WeakestSkills        []string           // Типы упражнений с худшими результатами
+	RecommendedExercises []string           // Ключи рекомендаций в каталоге сообщений
+	SuccessByType        map[string]float64 // Процент правильных ответов по типам упражнений
+	WritingSubmissions   int                // Количество учтенных письменных работ, не больше writingTrendSize
+	WritingLatest        float64            // Средняя оценка последней письменной работы, 0-10
+	WritingAverage       float64            // Средняя оценка последних письменных работ, 0-10
 }
 
 // NewProgressService создает новый сервис для работы с прогрессом
@@ -59,6 +72,7 @@ func (s *ProgressService) GetUserStats(userID int64) (*UserStats, error) {
 		TotalMessages:      progress.TotalMessages,
 		CurrentStreak:      progress.CurrentStreak,
 		LongestStreak:      progress.LongestStreak,
+		XP:                 progress.XP,
 		LastActivity:       progress.LastActivityDate,
 	}
 
@@ -66,10 +80,23 @@ func (s *ProgressService) GetUserStats(userID int64) (*UserStats, error) {
 	// В реальном приложении нужно будет получить эту информацию из БД
 	stats.DaysActive = progress.CurrentStreak
 
-	// Анализируем сильные и слабые стороны пользователя
-	// В реальном приложении нужно будет получить эту информацию из БД на основе упражнений
-	stats.StrongestSkills = []string{"Vocabulary", "Reading"}
-	stats.WeakestSkills = []string{"Grammar", "Listening"}
+	// Анализируем сильные и слабые стороны пользователя по истории упражнений
+	skillStats, err := s.db.GetSkillStats(context.Background(), userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики по навыкам: %w", err)
+	}
+	stats.StrongestSkills, stats.WeakestSkills = rankSkills(skillStats)
+	stats.SuccessByType = make(map[string]float64, len(skillStats))
+	for _, stat := range skillStats {
+		stats.SuccessByType[stat.ExerciseType] = stat.SuccessRate()
+	}
+
+	// Оценки последних письменных работ показывают динамику навыка письма
+	writingScores, err := s.db.GetRecentWritingScores(context.Background(), userID, writingTrendSize)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения оценок письменных работ: %w", err)
+	}
+	stats.WritingSubmissions, stats.WritingLatest, stats.WritingAverage = writingTrend(writingScores)
 
 	// Рекомендуем упражнения на основе слабых сторон
 	stats.RecommendedExercises = s.getRecommendedExercises(stats.WeakestSkills)
@@ -77,25 +104,103 @@ func (s *ProgressService) GetUserStats(userID int64) (*UserStats, error) {
 	return stats, nil
 }
 
-// getRecommendedExercises рекомендует упражнения на основе слабых сторон
+// writingTrend возвращает количество работ, оценку последней и среднюю оценку
+// по оценкам письменных работ, отсортированным от новых к старым
+func writingTrend(scores []database.WritingScore) (count int, latest, average float64) {
+	if len(scores) == 0 {
+		return 0, 0, 0
+	}
+
+	total := 0.0
+	for _, score := range scores {
+		total += score.Average()
+	}
+
+	return len(scores), scores[0].Average(), total / float64(len(scores))
+}
+
+// rankSkills определяет сильные и слабые навыки (типы упражнений) по доле правильных ответов.
+// Пока истории упражнений нет, возвращает навыки по умолчанию
+func rankSkills(skillStats []database.SkillStat) (strongest, weakest []string) {
+	if len(skillStats) == 0 {
+		return []string{string(ExerciseTypeVocabulary), string(ExerciseTypeReading)},
+			[]string{string(ExerciseTypeGrammar), string(ExerciseTypeListening)}
+	}
+
+	ranked := make([]database.SkillStat, len(skillStats))
+	copy(ranked, skillStats)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		// Сравниваем доли правильных ответов без деления: ci/ti > cj/tj
+		left := ranked[i].Correct * ranked[j].Total
+		right := ranked[j].Correct * ranked[i].Total
+		if left != right {
+			return left > right
+		}
+		return ranked[i].Total > ranked[j].Total
+	})
+
+	// Делим навыки пополам: лучшие - сильные стороны, остальные - слабые
+	strongCount := (len(ranked) + 1) / 2
+	if strongCount > maxRankedSkills {
+		strongCount = maxRankedSkills
+	}
+	for _, stat := range ranked[:strongCount] {
+		strongest = append(strongest, stat.ExerciseType)
+	}
+
+	weakCount := len(ranked) - strongCount
+	if weakCount > maxRankedSkills {
+		weakCount = maxRankedSkills
+	}
+	for i := len(ranked) - 1; i >= len(ranked)-weakCount; i-- {
+		weakest = append(weakest, ranked[i].ExerciseType)
+	}
+
+	return strongest, weakest
+}
+
+// skillName возвращает название навыка для типа упражнения на языке lang.
+// Для типа без названия в каталоге возвращается сам тип
+func skillName(lang, exerciseType string) string {
+	if _, ok := i18n.Lookup(i18n.DefaultLanguage, "progress.skill."+exerciseType); !ok {
+		return exerciseType
+	}
+	return i18n.T(lang, "progress.skill."+exerciseType)
+}
+
+// FormatSuccessByType форматирует процент правильных ответов по типам упражнений
+// на языке lang, по строке на тип в алфавитном порядке
+func FormatSuccessByType(lang string, successByType map[string]float64) string {
+	types := make([]string, 0, len(successByType))
+	for exerciseType := range successByType {
+		types = append(types, exerciseType)
+	}
+	sort.Strings(types)
+
+	message := i18n.T(lang, "progress.by_type")
+	for _, exerciseType := range types {
+		message += fmt.Sprintf("• %s: %.0f%%\n", skillName(lang, exerciseType), successByType[exerciseType])
+	}
+	return message
+}
+
+// recommendationKeys - ключи рекомендаций в каталоге сообщений для каждого навыка
+var recommendationKeys = map[ExerciseType][]string{
+	ExerciseTypeGrammar:     {"progress.recommend.grammar.tenses", "progress.recommend.grammar.conditionals"},
+	ExerciseTypeVocabulary:  {"progress.recommend.vocabulary.daily", "progress.recommend.vocabulary.flashcards"},
+	ExerciseTypeListening:   {"progress.recommend.listening.podcasts", "progress.recommend.listening.videos"},
+	ExerciseTypeSpeaking:    {"progress.recommend.speaking.conversations", "progress.recommend.speaking.record"},
+	ExerciseTypeReading:     {"progress.recommend.reading.articles", "progress.recommend.reading.comprehension"},
+	ExerciseTypeTranslation: {"progress.recommend.translation.both_ways", "progress.recommend.translation.compare"},
+}
+
+// getRecommendedExercises рекомендует упражнения на основе слабых сторон.
+// Возвращает ключи рекомендаций в каталоге сообщений
 func (s *ProgressService) getRecommendedExercises(weakestSkills []string) []string {
-	recommendations := make([]string, 0, len(weakestSkills))
+	recommendations := make([]string, 0, 2*len(weakestSkills))
 
 	for _, skill := range weakestSkills {
-		switch skill {
-		case "Grammar":
-			recommendations = append(recommendations, "Practice verb tenses", "Work on conditional sentences")
-		case "Vocabulary":
-			recommendations = append(recommendations, "Learn new words daily", "Review vocabulary with flashcards")
-		case "Listening":
-			recommendations = append(recommendations, "Listen to English podcasts", "Watch English videos with subtitles")
-		case "Speaking":
-			recommendations = append(recommendations, "Practice conversations", "Record and listen to yourself speaking")
-		case "Reading":
-			recommendations = append(recommendations, "Read English articles", "Practice reading comprehension")
-		case "Writing":
-			recommendations = append(recommendations, "Write short essays", "Practice writing emails")
-		}
+		recommendations = append(recommendations, recommendationKeys[ExerciseType(skill)]...)
 	}
 
 	return recommendations
@@ -104,15 +209,17 @@ func (s *ProgressService) getRecommendedExercises(weakestSkills []string) []stri
 // CalculateLevelProgress рассчитывает прогресс пользователя на текущем уровне
 // Возвращает процент выполнения (0-100)
 func (s *ProgressService) CalculateLevelProgress(userID int64, level string) (float64, error) {
-	// В реальном приложении нужно будет получить данные из БД
-	// Здесь используем упрощенный алгоритм
-
 	// Получаем статистику пользователя
 	stats, err := s.GetUserStats(userID)
 	if err != nil {
 		return 0, err
 	}
 
+	return levelProgress(stats, level), nil
+}
+
+// levelProgress рассчитывает прогресс на уровне по уже полученной статистике
+func levelProgress(stats *UserStats, level string) float64 {
 	// Упрощенный алгоритм расчета прогресса
 	// Зависит от количества упражнений и процента правильных ответов
 	exercises := float64(stats.TotalExercises)
@@ -143,7 +250,7 @@ func (s *ProgressService) CalculateLevelProgress(userID int64, level string) (fl
 	totalProgress := exerciseProgress + rateProgress
 
 	// Округляем до двух знаков после запятой
-	return math.Round(totalProgress*100) / 100, nil
+	return math.Round(totalProgress*100) / 100
 }
 
 // IsReadyForNextLevel проверяет, готов ли пользователь перейти на следующий уровень
@@ -154,13 +261,18 @@ func (s *ProgressService) IsReadyForNextLevel(userID int64, currentLevel string)
 		return false, "", err
 	}
 
+	isReady, nextLevel := readyForNextLevel(progress, currentLevel)
+	return isReady, nextLevel, nil
+}
+
+// readyForNextLevel проверяет готовность к следующему уровню по рассчитанному прогрессу
+func readyForNextLevel(progress float64, currentLevel string) (bool, string) {
 	// Если прогресс более 85%, пользователь готов перейти на следующий уровень
 	if progress >= 85 {
-		nextLevel := getNextLevel(currentLevel)
-		return true, nextLevel, nil
+		return true, getNextLevel(currentLevel)
 	}
 
-	return false, "", nil
+	return false, ""
 }
 
 // getNextLevel возвращает следующий уровень
@@ -181,23 +293,26 @@ func getNextLevel(currentLevel string) string {
 	}
 }
 
-// FormatProgressMessage форматирует сообщение о прогрессе пользователя
-func (s *ProgressService) FormatProgressMessage(stats *UserStats, level string) string {
+// FormatRank форматирует звание для опыта xp на языке lang
+// вместе с опытом, которого не хватает до следующего звания
+func FormatRank(lang string, xp int) string {
+	rank, next := database.RankForXP(xp)
+	if next == nil {
+		return i18n.T(lang, "progress.rank_max", i18n.T(lang, "rank."+rank.Key), xp)
+	}
+	return i18n.T(lang, "progress.rank", i18n.T(lang, "rank."+rank.Key), xp, next.MinXP-xp, i18n.T(lang, "rank."+next.Key))
+}
+
+// FormatProgressMessage форматирует сообщение о прогрессе пользователя на языке интерфейса lang.
+// Прогресс на уровне рассчитывается по переданной статистике этого пользователя
+func (s *ProgressService) FormatProgressMessage(lang string, stats *UserStats, level string) string {
 	// Рассчитываем прогресс на текущем уровне
-	levelProgress, _ := s.CalculateLevelProgress(0, level) // userID 0 для примера
+	progress := levelProgress(stats, level)
 
 	// Форматируем сообщение
-	message := fmt.Sprintf("📊 *Your Learning Progress*\n\n"+
-		"*Current Level:* %s (%.0f%% completed)\n\n"+
-		"*Statistics:*\n"+
-		"• Exercises completed: %d\n"+
-		"• Success rate: %.1f%%\n"+
-		"• Conversations: %d\n"+
-		"• Messages exchanged: %d\n"+
-		"• Learning streak: %d days\n"+
-		"• Longest streak: %d days\n\n"+
-		"*Your Strengths:*\n",
-		level, levelProgress,
+	message := i18n.T(lang, "progress.summary",
+		level, progress,
+		FormatRank(lang, stats.XP),
 		stats.TotalExercises,
 		stats.SuccessRate,
 		stats.TotalConversations,
@@ -207,27 +322,36 @@ func (s *ProgressService) FormatProgressMessage(stats *UserStats, level string)
 
 	// Добавляем сильные стороны
 	for _, skill := range stats.StrongestSkills {
-		message += fmt.Sprintf("✅ %s\n", skill)
+		message += fmt.Sprintf("✅ %s\n", skillName(lang, skill))
 	}
 
-	message += "\n*Areas to Improve:*\n"
+	message += i18n.T(lang, "progress.weaknesses")
 
 	// Добавляем слабые стороны
 	for _, skill := range stats.WeakestSkills {
-		message += fmt.Sprintf("⚠️ %s\n", skill)
+		message += fmt.Sprintf("⚠️ %s\n", skillName(lang, skill))
+	}
+
+	// Добавляем процент правильных ответов по типам упражнений
+	if len(stats.SuccessByType) > 0 {
+		message += FormatSuccessByType(lang, stats.SuccessByType)
+	}
+
+	// Добавляем динамику оценок письменных работ
+	if stats.WritingSubmissions > 0 {
+		message += i18n.T(lang, "progress.writing", stats.WritingLatest, stats.WritingSubmissions, stats.WritingAverage)
 	}
 
-	message += "\n*Recommendations:*\n"
+	message += i18n.T(lang, "progress.recommendations")
 
 	// Добавляем рекомендации
 	for _, rec := range stats.RecommendedExercises {
-		message += fmt.Sprintf("• %s\n", rec)
+		message += fmt.Sprintf("• %s\n", i18n.T(lang, rec))
 	}
 
 	// Проверяем, готов ли пользователь перейти на следующий уровень
-	isReady, nextLevel, _ := s.IsReadyForNextLevel(0, level) // userID 0 для примера
-	if isReady {
-		message += fmt.Sprintf("\n🎉 *Congratulations!* You're ready to advance to level %s!\n", nextLevel)
+	if isReady, nextLevel := readyForNextLevel(progress, level); isReady {
+		message += i18n.T(lang, "progress.ready", nextLevel)
 	}
 
 	return message
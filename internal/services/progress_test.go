@@ -0,0 +1,227 @@
+package services
+
+import (
+	"english-bot/internal/database"
+	"english-bot/internal/i18n"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRankSkills(t *testing.T) {
+	tests := []struct {
+		name          string
+		stats         []database.SkillStat
+		wantStrongest []string
+		wantWeakest   []string
+	}{
+		{
+			name:          "без истории",
+			stats:         nil,
+			wantStrongest: []string{"vocabulary", "reading"},
+			wantWeakest:   []string{"grammar", "listening"},
+		},
+		{
+			name:          "один навык",
+			stats:         []database.SkillStat{{ExerciseType: "grammar", Total: 4, Correct: 1}},
+			wantStrongest: []string{"grammar"},
+			wantWeakest:   nil,
+		},
+		{
+			name: "три навыка",
+			stats: []database.SkillStat{
+				{ExerciseType: "translation", Total: 10, Correct: 2},
+				{ExerciseType: "grammar", Total: 10, Correct: 9},
+				{ExerciseType: "vocabulary", Total: 10, Correct: 5},
+			},
+			wantStrongest: []string{"grammar", "vocabulary"},
+			wantWeakest:   []string{"translation"},
+		},
+		{
+			name: "не больше двух с каждой стороны",
+			stats: []database.SkillStat{
+				{ExerciseType: "grammar", Total: 10, Correct: 10},
+				{ExerciseType: "vocabulary", Total: 10, Correct: 8},
+				{ExerciseType: "reading", Total: 10, Correct: 6},
+				{ExerciseType: "translation", Total: 10, Correct: 4},
+				{ExerciseType: "listening", Total: 10, Correct: 2},
+			},
+			wantStrongest: []string{"grammar", "vocabulary"},
+			wantWeakest:   []string{"listening", "translation"},
+		},
+		{
+			name: "равная доля - выше навык с большим числом попыток",
+			stats: []database.SkillStat{
+				{ExerciseType: "grammar", Total: 2, Correct: 1},
+				{ExerciseType: "vocabulary", Total: 10, Correct: 5},
+			},
+			wantStrongest: []string{"vocabulary"},
+			wantWeakest:   []string{"grammar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strongest, weakest := rankSkills(tt.stats)
+			if !slices.Equal(strongest, tt.wantStrongest) {
+				t.Errorf("сильные навыки %v, ожидались %v", strongest, tt.wantStrongest)
+			}
+			if !slices.Equal(weakest, tt.wantWeakest) {
+				t.Errorf("слабые навыки %v, ожидались %v", weakest, tt.wantWeakest)
+			}
+		})
+	}
+}
+
+func TestRankSkillsKeepsInputOrder(t *testing.T) {
+	stats := []database.SkillStat{
+		{ExerciseType: "translation", Total: 10, Correct: 2},
+		{ExerciseType: "grammar", Total: 10, Correct: 9},
+	}
+
+	rankSkills(stats)
+
+	if stats[0].ExerciseType != "translation" {
+		t.Error("rankSkills изменил порядок переданной статистики")
+	}
+}
+
+func TestSkillName(t *testing.T) {
+	if got := skillName(i18n.Russian, "grammar"); got != i18n.T(i18n.Russian, "progress.skill.grammar") {
+		t.Errorf("skillName(grammar) = %q", got)
+	}
+	if got := skillName(i18n.Russian, "dictation"); got != "dictation" {
+		t.Errorf("тип без названия в каталоге превратился в %q", got)
+	}
+}
+
+func TestLevelProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats UserStats
+		level string
+		want  float64
+	}{
+		{"без упражнений", UserStats{}, "A1", 0},
+		{"половина упражнений A1", UserStats{TotalExercises: 25, SuccessRate: 100}, "A1", 65},
+		{"все упражнения A1", UserStats{TotalExercises: 50, SuccessRate: 50}, "A1", 85},
+		{"упражнений больше минимума", UserStats{TotalExercises: 200, SuccessRate: 80}, "A2", 94},
+		{"округление", UserStats{TotalExercises: 100, SuccessRate: 90}, "B1", 73.67},
+		{"уровень C2", UserStats{TotalExercises: 150}, "C2", 35},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := levelProgress(&tt.stats, tt.level); got != tt.want {
+				t.Errorf("levelProgress = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadyForNextLevel(t *testing.T) {
+	tests := []struct {
+		progress  float64
+		level     string
+		wantReady bool
+		wantNext  string
+	}{
+		{85, "A1", true, "A2"},
+		{99, "B2", true, "C1"},
+		{84.99, "A1", false, ""},
+		{100, "C2", true, "C2"},
+	}
+
+	for _, tt := range tests {
+		ready, next := readyForNextLevel(tt.progress, tt.level)
+		if ready != tt.wantReady || next != tt.wantNext {
+			t.Errorf("readyForNextLevel(%v, %s) = %v, %q; ожидалось %v, %q", tt.progress, tt.level, ready, next, tt.wantReady, tt.wantNext)
+		}
+	}
+}
+
+func TestFormatSuccessByType(t *testing.T) {
+	got := FormatSuccessByType(i18n.Russian, map[string]float64{
+		"vocabulary": 66.666,
+		"dictation":  50,
+		"grammar":    100,
+	})
+
+	want := i18n.T(i18n.Russian, "progress.by_type") +
+		"• dictation: 50%\n" +
+		"• " + i18n.T(i18n.Russian, "progress.skill.grammar") + ": 100%\n" +
+		"• " + i18n.T(i18n.Russian, "progress.skill.vocabulary") + ": 67%\n"
+	if got != want {
+		t.Errorf("FormatSuccessByType =\n%s\nожидалось:\n%s", got, want)
+	}
+}
+
+func TestFormatRank(t *testing.T) {
+	lang := i18n.English
+
+	tests := []struct {
+		xp   int
+		want string
+	}{
+		{0, i18n.T(lang, "progress.rank", i18n.T(lang, "rank.novice"), 0, 100, i18n.T(lang, "rank.explorer"))},
+		{250, i18n.T(lang, "progress.rank", i18n.T(lang, "rank.explorer"), 250, 50, i18n.T(lang, "rank.apprentice"))},
+		{7000, i18n.T(lang, "progress.rank_max", i18n.T(lang, "rank.legend"), 7000)},
+	}
+
+	for _, tt := range tests {
+		if got := FormatRank(lang, tt.xp); got != tt.want {
+			t.Errorf("FormatRank(%d) = %q, ожидалось %q", tt.xp, got, tt.want)
+		}
+	}
+
+	message := NewProgressService(nil).FormatProgressMessage(lang, &UserStats{XP: 250}, "A1")
+	if !strings.Contains(message, tests[1].want) {
+		t.Errorf("в сообщении о прогрессе нет звания %q:\n%s", tests[1].want, message)
+	}
+}
+
+func TestFormatProgressMessageSuccessByType(t *testing.T) {
+	s := NewProgressService(nil)
+	heading := i18n.T(i18n.English, "progress.by_type")
+
+	message := s.FormatProgressMessage(i18n.English, &UserStats{}, "A1")
+	if strings.Contains(message, heading) {
+		t.Errorf("без ответов показана разбивка по типам:\n%s", message)
+	}
+
+	successByType := map[string]float64{"grammar": 25, "reading": 80}
+	message = s.FormatProgressMessage(i18n.English, &UserStats{TotalExercises: 9, SuccessByType: successByType}, "A1")
+	if want := FormatSuccessByType(i18n.English, successByType); !strings.Contains(message, want) {
+		t.Errorf("в сообщении нет разбивки по типам %q:\n%s", want, message)
+	}
+}
+
+func TestFormatProgressMessageUsesUserStats(t *testing.T) {
+	s := NewProgressService(nil)
+
+	tests := []struct {
+		name      string
+		stats     UserStats
+		wantReady bool
+	}{
+		{"новый пользователь", UserStats{}, false},
+		{"готов к следующему уровню", UserStats{TotalExercises: 50, CorrectExercises: 45, SuccessRate: 90}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := s.FormatProgressMessage("en", &tt.stats, "A1")
+
+			progress := fmt.Sprintf("A1 (%.0f%% completed)", levelProgress(&tt.stats, "A1"))
+			if !strings.Contains(message, progress) {
+				t.Errorf("в сообщении нет прогресса %q:\n%s", progress, message)
+			}
+
+			banner := i18n.T("en", "progress.ready", "A2")
+			if ready := strings.Contains(message, banner); ready != tt.wantReady {
+				t.Errorf("поздравление с новым уровнем показано: %v, ожидалось %v", ready, tt.wantReady)
+			}
+		})
+	}
+}
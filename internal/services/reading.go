@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// minReadingQuestions - минимальное количество вопросов к тексту
+	minReadingQuestions = 3
+	// maxReadingQuestions - максимальное количество вопросов к тексту, лишние отбрасываются
+	maxReadingQuestions = 4
+)
+
+// ReadingQuestion - вопрос к тексту упражнения на чтение
+type ReadingQuestion struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"` // Краткий правильный ответ
+}
+
+// ReadingExercise - упражнение на понимание прочитанного: текст и вопросы к нему
+type ReadingExercise struct {
+	Passage   string            `json:"passage"`
+	Questions []ReadingQuestion `json:"questions"`
+}
+
+// GenerateReadingExercise генерирует через OpenAI текст заданного уровня с вопросами к нему
+func (s *ExerciseService) GenerateReadingExercise(ctx context.Context, level EnglishLevel) (*ReadingExercise, error) {
+	prompt := s.GetPromptForExerciseType(ExerciseTypeReading, level, GrammarTopicAny)
+
+	response, err := s.openAI.GenerateResponseWithModel(ctx, s.openAI.ModelForExercise(), "Generate an exercise", prompt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации упражнения на чтение: %w", err)
+	}
+
+	return ParseReadingExercise(response)
+}
+
+// ParseReadingExercise разбирает ответ модели с текстом и вопросами.
+// Вопросы без текста или ответа отбрасываются, лишние вопросы обрезаются до maxReadingQuestions
+func ParseReadingExercise(response string) (*ReadingExercise, error) {
+	var exercise ReadingExercise
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &exercise); err != nil {
+		return nil, fmt.Errorf("ошибка разбора упражнения на чтение: %w", err)
+	}
+
+	exercise.Passage = strings.TrimSpace(exercise.Passage)
+	if exercise.Passage == "" {
+		return nil, fmt.Errorf("пустой текст упражнения на чтение в ответе API")
+	}
+
+	questions := make([]ReadingQuestion, 0, maxReadingQuestions)
+	for _, question := range exercise.Questions {
+		question.Question = strings.TrimSpace(question.Question)
+		question.Answer = strings.TrimSpace(question.Answer)
+		if question.Question == "" || question.Answer == "" {
+			continue
+		}
+		questions = append(questions, question)
+		if len(questions) == maxReadingQuestions {
+			break
+		}
+	}
+
+	if len(questions) < minReadingQuestions {
+		return nil, fmt.Errorf("недостаточно вопросов в упражнении на чтение: %d из %d", len(questions), minReadingQuestions)
+	}
+	exercise.Questions = questions
+
+	return &exercise, nil
+}
+
+// GradeReadingAnswer оценивает ответ на вопрос к тексту.
+// Сначала ответ сравнивается с ожидаемым, а если совпадения нет, его оценивает ChatGPT,
+// ведь на вопрос по тексту можно правильно ответить разными словами
+func (s *ExerciseService) GradeReadingAnswer(ctx context.Context, lang, passage string, question ReadingQuestion, userAnswer string) (int, string, error) {
+	score, comment := s.CheckAnswer(lang, &Exercise{Type: ExerciseTypeReading, Answer: question.Answer}, userAnswer)
+	if score >= 80 {
+		return score, comment, nil
+	}
+
+	return s.GradeAnswer(ctx, ReadingQuestionContent(passage, question), userAnswer)
+}
+
+// ReadingQuestionContent формирует описание вопроса вместе с текстом и ожидаемым ответом
+// для запросов к ChatGPT: оценки, подсказки или показа ответа
+func ReadingQuestionContent(passage string, question ReadingQuestion) string {
+	return fmt.Sprintf("Read the text and answer the question.\n\nText:\n%s\n\nQuestion: %s\nExpected answer: %s",
+		passage, question.Question, question.Answer)
+}
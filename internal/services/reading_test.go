@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetPromptForReadingExercise(t *testing.T) {
+	s := NewExerciseService(nil)
+	prompt := s.GetPromptForExerciseType(ExerciseTypeReading, EnglishLevelB1, GrammarTopicAny)
+
+	for _, want := range []string{"reading comprehension", "B1 level", "3 to 4 questions", `"passage"`, `"questions"`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("в промпте нет %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestParseReadingExercise(t *testing.T) {
+	response := "Here you go:\n```json\n" + `{
+		"passage": "  Tom lives in London. He works as a teacher.  ",
+		"questions": [
+			{"question": "Where does Tom live?", "answer": "In London"},
+			{"question": "", "answer": "skipped"},
+			{"question": "What is his job?", "answer": " A teacher "},
+			{"question": "Is Tom a student?", "answer": "No"},
+			{"question": "Who is Tom?", "answer": "A teacher in London"},
+			{"question": "One too many?", "answer": "Yes"}
+		]
+	}` + "\n```"
+
+	exercise, err := ParseReadingExercise(response)
+	if err != nil {
+		t.Fatalf("ParseReadingExercise: %v", err)
+	}
+
+	if exercise.Passage != "Tom lives in London. He works as a teacher." {
+		t.Errorf("текст %q", exercise.Passage)
+	}
+	if len(exercise.Questions) != maxReadingQuestions {
+		t.Fatalf("получено %d вопросов, ожидалось %d", len(exercise.Questions), maxReadingQuestions)
+	}
+	if exercise.Questions[1] != (ReadingQuestion{Question: "What is his job?", Answer: "A teacher"}) {
+		t.Errorf("вопрос без текста не отброшен или ответ не очищен: %+v", exercise.Questions[1])
+	}
+}
+
+func TestParseReadingExerciseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{"не JSON", "Sorry, I can't do that."},
+		{"пустой текст", `{"passage": " ", "questions": [{"question": "Q1", "answer": "A1"}, {"question": "Q2", "answer": "A2"}, {"question": "Q3", "answer": "A3"}]}`},
+		{"мало вопросов", `{"passage": "Text", "questions": [{"question": "Q1", "answer": "A1"}, {"question": "Q2", "answer": ""}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if exercise, err := ParseReadingExercise(tt.response); err == nil {
+				t.Errorf("ParseReadingExercise принял ответ: %+v", exercise)
+			}
+		})
+	}
+}
+
+func TestGradeReadingAnswerMatchesExpectedAnswer(t *testing.T) {
+	// Совпадающий ответ оценивается без запроса к OpenAI
+	s := NewExerciseService(newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("ответ, совпадающий с ожидаемым, отправлен на оценку в OpenAI")
+		chatReply(w, `{"score": 0, "explanation": ""}`)
+	}))
+
+	question := ReadingQuestion{Question: "Where does Tom live?", Answer: "In London"}
+	score, _, err := s.GradeReadingAnswer(context.Background(), "en", "Tom lives in London.", question, "in london")
+	if err != nil {
+		t.Fatalf("GradeReadingAnswer: %v", err)
+	}
+	if score != 100 {
+		t.Errorf("оценка %d, ожидалось 100", score)
+	}
+}
+
+func TestGradeReadingAnswerAsksModel(t *testing.T) {
+	s := NewExerciseService(newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		chatReply(w, `{"score": 90, "explanation": "Correct, London is his city."}`)
+	}))
+
+	question := ReadingQuestion{Question: "Where does Tom live?", Answer: "In London"}
+	score, _, err := s.GradeReadingAnswer(context.Background(), "en", "Tom lives in London.", question, "He lives in the capital of England")
+	if err != nil {
+		t.Fatalf("GradeReadingAnswer: %v", err)
+	}
+	if score != 90 {
+		t.Errorf("оценка %d, ожидалась оценка модели 90", score)
+	}
+}
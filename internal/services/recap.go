@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// recapSeparator разделяет сообщения ученика при проверке одним запросом
+const recapSeparator = "\n\n"
+
+// RecurringMistake - тип ошибки, найденный в сообщениях ученика, с количеством повторений
+type RecurringMistake struct {
+	RuleID     string // Правило LanguageTool
+	Message    string // Описание ошибки
+	Example    string // Ошибочный фрагмент из сообщений ученика
+	Correction string // Первое предложенное исправление, если есть
+	Count      int    // Сколько раз ошибка встретилась
+}
+
+// SummarizeMistakes проверяет сообщения ученика одним запросом к LanguageTool
+// и возвращает самые частые ошибки, не больше limit, и общее количество найденных ошибок
+func (s *LanguageToolService) SummarizeMistakes(messages []string, opts CheckOptions, limit int) ([]RecurringMistake, int, error) {
+	text := strings.Join(messages, recapSeparator)
+
+	response, err := s.CheckText(text, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка проверки сообщений диалога: %w", err)
+	}
+
+	return groupMistakes(text, response.Matches, limit), len(response.Matches), nil
+}
+
+// groupMistakes объединяет ошибки по правилам LanguageTool и сортирует их по частоте.
+// Пример и исправление берутся из первого вхождения ошибки
+func groupMistakes(text string, matches []LanguageToolMatch, limit int) []RecurringMistake {
+	units := utf16.Encode([]rune(text))
+
+	byRule := make(map[string]*RecurringMistake)
+	var order []string
+	for _, match := range matches {
+		ruleID := match.Rule.ID
+		if ruleID == "" {
+			ruleID = match.Message
+		}
+
+		if mistake, ok := byRule[ruleID]; ok {
+			mistake.Count++
+			continue
+		}
+
+		start := clamp(match.Offset, 0, len(units))
+		end := clamp(match.Offset+match.Length, start, len(units))
+
+		mistake := &RecurringMistake{
+			RuleID:  ruleID,
+			Message: match.Message,
+			Example: utf16String(units[start:end]),
+			Count:   1,
+		}
+		if len(match.Replacements) > 0 {
+			mistake.Correction = match.Replacements[0].Value
+		}
+
+		byRule[ruleID] = mistake
+		order = append(order, ruleID)
+	}
+
+	mistakes := make([]RecurringMistake, 0, len(order))
+	for _, ruleID := range order {
+		mistakes = append(mistakes, *byRule[ruleID])
+	}
+
+	// Стабильная сортировка сохраняет порядок появления ошибок с одинаковой частотой
+	sort.SliceStable(mistakes, func(i, j int) bool {
+		return mistakes[i].Count > mistakes[j].Count
+	})
+
+	if len(mistakes) > limit {
+		mistakes = mistakes[:limit]
+	}
+	return mistakes
+}
+
+// SummarizeMistakes просит ChatGPT кратко описать самые частые ошибки в сообщениях ученика.
+// Используется, когда LanguageTool недоступен
+func (s *OpenAIService) SummarizeMistakes(ctx context.Context, messages []string, limit int) (string, error) {
+	systemPrompt := fmt.Sprintf(`You are an English teacher reviewing the messages a student wrote during a conversation.
+Find at most %d recurring mistakes (grammar, word choice, spelling) and list them as short bullet points
+addressed to the student, each with an example from their messages and the correction.
+If there are no noticeable mistakes, reply with one encouraging sentence. Be brief.`, limit)
+
+	response, err := s.GenerateResponse(ctx, strings.Join(messages, recapSeparator), systemPrompt)
+	if err != nil {
+		return "", fmt.Errorf("ошибка анализа ошибок диалога: %w", err)
+	}
+
+	return strings.TrimSpace(response), nil
+}
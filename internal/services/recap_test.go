@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ruleMatch создает найденную LanguageTool ошибку с идентификатором правила
+func ruleMatch(ruleID, message string, offset, length int, replacements ...string) LanguageToolMatch {
+	match := newMatch(message, offset, length, replacements...)
+	match.Rule.ID = ruleID
+	return match
+}
+
+func TestGroupMistakes(t *testing.T) {
+	text := "She go home." + recapSeparator + "He go to work." + recapSeparator + "I has a cat."
+
+	tests := []struct {
+		name    string
+		matches []LanguageToolMatch
+		limit   int
+		want    []RecurringMistake
+	}{
+		{"без ошибок", nil, 3, []RecurringMistake{}},
+		{
+			"частые ошибки первыми",
+			[]LanguageToolMatch{
+				ruleMatch("HAVE_HAS", "Use 'have'.", 32, 3, "have"),
+				ruleMatch("AGREEMENT", "Use 'goes'.", 4, 2, "goes"),
+				ruleMatch("AGREEMENT", "Use 'goes'.", 17, 2, "goes"),
+			},
+			3,
+			[]RecurringMistake{
+				{RuleID: "AGREEMENT", Message: "Use 'goes'.", Example: "go", Correction: "goes", Count: 2},
+				{RuleID: "HAVE_HAS", Message: "Use 'have'.", Example: "has", Correction: "have", Count: 1},
+			},
+		},
+		{
+			"ограничение количества",
+			[]LanguageToolMatch{
+				ruleMatch("AGREEMENT", "Use 'goes'.", 4, 2, "goes"),
+				ruleMatch("HAVE_HAS", "Use 'have'.", 32, 3, "have"),
+			},
+			1,
+			[]RecurringMistake{
+				{RuleID: "AGREEMENT", Message: "Use 'goes'.", Example: "go", Correction: "goes", Count: 1},
+			},
+		},
+		{
+			"без правила и без исправлений",
+			[]LanguageToolMatch{
+				ruleMatch("", "Check this word.", 0, 3),
+				ruleMatch("", "Check this word.", 14, 2),
+			},
+			3,
+			[]RecurringMistake{
+				{RuleID: "Check this word.", Message: "Check this word.", Example: "She", Count: 2},
+			},
+		},
+		{
+			"смещение за концом текста",
+			[]LanguageToolMatch{ruleMatch("TAIL", "Ends badly.", len(text)-1, 10)},
+			3,
+			[]RecurringMistake{{RuleID: "TAIL", Message: "Ends badly.", Example: ".", Count: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupMistakes(text, tt.matches, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("groupMistakes = %+v, ожидалось %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ошибка %d: %+v, ожидалось %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGroupMistakesUTF16Offsets(t *testing.T) {
+	// LanguageTool считает смещения в UTF-16: эмодзи занимает две единицы
+	text := "😀 I has a cat."
+
+	got := groupMistakes(text, []LanguageToolMatch{ruleMatch("HAVE_HAS", "Use 'have'.", 5, 3, "have")}, 3)
+	if len(got) != 1 || got[0].Example != "has" {
+		t.Errorf("groupMistakes = %+v, ожидался пример %q", got, "has")
+	}
+}
+
+func TestLanguageToolSummarizeMistakes(t *testing.T) {
+	// Каждое сообщение проверяется отдельным абзацем, смещения ошибок - относительно абзаца
+	replies := map[string][]LanguageToolMatch{
+		"She go home.":   {ruleMatch("AGREEMENT", "Use 'goes'.", 4, 2, "goes")},
+		"He go to work.": {ruleMatch("AGREEMENT", "Use 'goes'.", 3, 2, "goes")},
+		"I has a cat.":   {ruleMatch("HAVE_HAS", "Use 'have'.", 2, 3, "have")},
+	}
+	var checked []string
+	s := newTestLanguageTool(t, 0, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		text := r.Form.Get("text")
+		checked = append(checked, text)
+		languageToolReply(w, replies[text]...)
+	})
+
+	messages := []string{"She go home.", "He go to work.", "I has a cat."}
+	mistakes, total, err := s.SummarizeMistakes(messages, CheckOptions{}, 2)
+	if err != nil {
+		t.Fatalf("SummarizeMistakes: %v", err)
+	}
+
+	if !slices.Equal(checked, messages) {
+		t.Errorf("на проверку отправлены %q, ожидались сообщения %q", checked, messages)
+	}
+	if total != 3 {
+		t.Errorf("всего ошибок %d, ожидалось 3", total)
+	}
+	want := []RecurringMistake{
+		{RuleID: "AGREEMENT", Message: "Use 'goes'.", Example: "go", Correction: "goes", Count: 2},
+		{RuleID: "HAVE_HAS", Message: "Use 'have'.", Example: "has", Correction: "have", Count: 1},
+	}
+	if !slices.Equal(mistakes, want) {
+		t.Errorf("частые ошибки %+v, ожидалось %+v", mistakes, want)
+	}
+}
+
+func TestLanguageToolSummarizeMistakesError(t *testing.T) {
+	s := newTestLanguageTool(t, 0, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	if _, _, err := s.SummarizeMistakes([]string{"She go home."}, CheckOptions{}, 3); err == nil {
+		t.Error("ошибка LanguageTool не возвращена")
+	}
+}
+
+func TestOpenAISummarizeMistakes(t *testing.T) {
+	var request OpenAIRequest
+	s := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		chatReply(w, "  - Use 'goes' after 'she'.\n")
+	})
+
+	summary, err := s.SummarizeMistakes(context.Background(), []string{"She go home.", "I has a cat."}, 2)
+	if err != nil {
+		t.Fatalf("SummarizeMistakes: %v", err)
+	}
+	if summary != "- Use 'goes' after 'she'." {
+		t.Errorf("разбор ошибок %q", summary)
+	}
+
+	if len(request.Messages) != 2 || !strings.Contains(request.Messages[0].Content, "at most 2 recurring mistakes") {
+		t.Fatalf("сообщения запроса %+v", request.Messages)
+	}
+	if request.Messages[1].Content != "She go home."+recapSeparator+"I has a cat." {
+		t.Errorf("сообщения ученика отправлены как %q", request.Messages[1].Content)
+	}
+}
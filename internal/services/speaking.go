@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SpeakingResult - оценка чтения предложения вслух
+type SpeakingResult struct {
+	Score  int      // Сходство распознанной речи с предложением, 0-100
+	Missed []string // Слова предложения, которые не удалось распознать или которые прозвучали иначе
+}
+
+// GenerateSpeakingExercise генерирует через OpenAI предложение, которое пользователь читает вслух
+func (s *ExerciseService) GenerateSpeakingExercise(ctx context.Context, level EnglishLevel) (*Exercise, error) {
+	prompt := s.GetPromptForExerciseType(ExerciseTypeSpeaking, level, GrammarTopicAny)
+
+	response, err := s.openAI.GenerateResponseWithModel(ctx, s.openAI.ModelForExercise(), "Generate an exercise", prompt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации упражнения на произношение: %w", err)
+	}
+
+	sentence, err := ParseSpeakingSentence(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Exercise{
+		Type:        ExerciseTypeSpeaking,
+		Level:       level,
+		Instruction: "Read the sentence aloud and send a voice message.",
+		Content:     sentence,
+		Answer:      sentence,
+	}, nil
+}
+
+// ParseSpeakingSentence разбирает ответ модели с предложением для чтения вслух
+func ParseSpeakingSentence(response string) (string, error) {
+	var result struct {
+		Sentence string `json:"sentence"`
+	}
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &result); err != nil {
+		return "", fmt.Errorf("ошибка разбора упражнения на произношение: %w", err)
+	}
+
+	sentence := strings.TrimSpace(result.Sentence)
+	if sentence == "" {
+		return "", fmt.Errorf("пустое предложение в упражнении на произношение")
+	}
+
+	return sentence, nil
+}
+
+// ScoreSpeaking сравнивает распознанную речь с предложением, которое нужно было прочитать.
+// Регистр и пунктуация не учитываются. Оценка - сходство строк по Левенштейну,
+// а непроизнесенные слова находятся по наибольшей общей подпоследовательности слов
+func ScoreSpeaking(target, transcript string) SpeakingResult {
+	targetWords := speechWords(target)
+	spokenWords := speechWords(transcript)
+
+	ratio := levenshteinRatio(strings.Join(targetWords, " "), strings.Join(spokenWords, " "))
+
+	return SpeakingResult{
+		Score:  int(ratio*100 + 0.5),
+		Missed: missedWords(targetWords, spokenWords),
+	}
+}
+
+// speechWords разбивает текст на слова в нижнем регистре без пунктуации.
+// Апострофы внутри слов сохраняются, чтобы "don't" не превращалось в два слова
+func speechWords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+
+	result := words[:0]
+	for _, word := range words {
+		word = strings.Trim(strings.ReplaceAll(word, "’", "'"), "'")
+		if word != "" {
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+// missedWords возвращает слова target, не вошедшие в наибольшую общую подпоследовательность с spoken
+func missedWords(target, spoken []string) []string {
+	// lcs[i][j] - длина общей подпоследовательности target[i:] и spoken[j:]
+	lcs := make([][]int, len(target)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(spoken)+1)
+	}
+	for i := len(target) - 1; i >= 0; i-- {
+		for j := len(spoken) - 1; j >= 0; j-- {
+			if target[i] == spoken[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var missed []string
+	i, j := 0, 0
+	for i < len(target) {
+		switch {
+		case j < len(spoken) && target[i] == spoken[j]:
+			i++
+			j++
+		case j < len(spoken) && lcs[i][j+1] > lcs[i+1][j]:
+			j++
+		default:
+			missed = append(missed, target[i])
+			i++
+		}
+	}
+	return missed
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestSpeechWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"I don't like rainy days.", []string{"i", "don't", "like", "rainy", "days"}},
+		{"I don’t know!", []string{"i", "don't", "know"}},
+		{"'Hello,' she said -- twice.", []string{"hello", "she", "said", "twice"}},
+		{"Room 42, please", []string{"room", "42", "please"}},
+		{"...", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := speechWords(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("speechWords(%q) = %q, ожидалось %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestScoreSpeaking(t *testing.T) {
+	// Без пунктуации и регистра предложение - 27 символов, оценка - доля совпавших символов
+	const target = "I usually go to work by bus."
+
+	tests := []struct {
+		name       string
+		transcript string
+		wantScore  int
+		wantMissed []string
+	}{
+		{"точное совпадение", "I usually go to work by bus.", 100, nil},
+		{"регистр и пунктуация не учитываются", "i usually go to work by bus", 100, nil},
+		{"пропущено слово", "I usually go work by bus.", 89, []string{"to"}},
+		{"слово прозвучало иначе", "I usually go to walk by bus.", 93, []string{"work"}},
+		{"лишнее слово", "Well, I usually go to work by bus.", 84, nil},
+		{"ничего не распознано", "", 0, []string{"i", "usually", "go", "to", "work", "by", "bus"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ScoreSpeaking(target, tt.transcript)
+			if result.Score != tt.wantScore {
+				t.Errorf("оценка %d, ожидалось %d", result.Score, tt.wantScore)
+			}
+			if !slices.Equal(result.Missed, tt.wantMissed) {
+				t.Errorf("нечеткие слова %q, ожидалось %q", result.Missed, tt.wantMissed)
+			}
+		})
+	}
+}
+
+func TestScoreSpeakingDecreasesWithMistakes(t *testing.T) {
+	const target = "The weather is lovely today."
+
+	previous := 101
+	for _, transcript := range []string{
+		"The weather is lovely today.",
+		"The weather is lovely to day.",
+		"The whether is lovely to day.",
+		"The whether is lonely to day.",
+		"Whether lonely.",
+	} {
+		score := ScoreSpeaking(target, transcript).Score
+		if score >= previous {
+			t.Errorf("оценка %q = %d, ожидалось меньше %d", transcript, score, previous)
+		}
+		previous = score
+	}
+}
+
+func TestParseSpeakingSentence(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+		wantErr  bool
+	}{
+		{"JSON в markdown", "```json\n{\"sentence\": \" I like green tea. \"}\n```", "I like green tea.", false},
+		{"пустое предложение", `{"sentence": "  "}`, "", true},
+		{"ответ без JSON", "Sorry, I cannot help.", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sentence, err := ParseSpeakingSentence(tt.response)
+			if (err != nil) != tt.wantErr || sentence != tt.want {
+				t.Errorf("ParseSpeakingSentence = %q, %v; ожидалось %q, ошибка: %v", sentence, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateSpeakingExercise(t *testing.T) {
+	openAI := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		chatReply(w, `{"sentence": "She reads a book every evening."}`)
+	})
+	s := NewExerciseService(openAI)
+
+	exercise, err := s.GenerateSpeakingExercise(context.Background(), EnglishLevelA2)
+	if err != nil {
+		t.Fatalf("GenerateSpeakingExercise: %v", err)
+	}
+	if exercise.Type != ExerciseTypeSpeaking || exercise.Content != "She reads a book every evening." || exercise.Answer != exercise.Content {
+		t.Errorf("упражнение %+v, ожидалось предложение для чтения вслух", exercise)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxMastery - максимальная степень усвоения слова
+const MaxMastery = 5
+
+// reviewIntervals - интервалы повторения для каждой степени усвоения (0-5).
+// Слово с нулевой степенью повторяется почти сразу, дальше интервалы растут как в SM-2
+var reviewIntervals = []time.Duration{
+	10 * time.Minute,
+	1 * 24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	16 * 24 * time.Hour,
+	35 * 24 * time.Hour,
+}
+
+// ScheduleNextReview рассчитывает дату следующего повторения слова по степени его усвоения
+func ScheduleNextReview(mastery int, lastReview time.Time) time.Time {
+	if mastery < 0 {
+		mastery = 0
+	}
+	if mastery > MaxMastery {
+		mastery = MaxMastery
+	}
+
+	return lastReview.Add(reviewIntervals[mastery])
+}
+
+// NextMastery возвращает новую степень усвоения после повторения.
+// Верный ответ повышает степень на 1, ошибка сбрасывает ее, как в SM-2
+func NextMastery(mastery int, correct bool) int {
+	if !correct {
+		return 0
+	}
+
+	if mastery >= MaxMastery {
+		return MaxMastery
+	}
+
+	return mastery + 1
+}
+
+// CheckVocabularyAnswer проверяет ответ при повторении слова.
+// Ожидаемое значение может содержать несколько вариантов через запятую или "/",
+// небольшие опечатки допускаются
+func CheckVocabularyAnswer(expected, answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" {
+		return false
+	}
+
+	variants := strings.FieldsFunc(strings.ToLower(expected), func(r rune) bool {
+		return r == ',' || r == '/' || r == ';'
+	})
+
+	for _, variant := range variants {
+		variant = strings.TrimSpace(variant)
+		if variant == "" {
+			continue
+		}
+		if answer == variant || levenshteinRatio(answer, variant) >= 0.8 {
+			return true
+		}
+	}
+
+	return false
+}
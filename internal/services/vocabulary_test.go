@@ -0,0 +1,89 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextMastery(t *testing.T) {
+	tests := []struct {
+		mastery int
+		correct bool
+		want    int
+	}{
+		{0, true, 1},
+		{3, true, 4},
+		{MaxMastery - 1, true, MaxMastery},
+		{MaxMastery, true, MaxMastery},
+		{MaxMastery + 2, true, MaxMastery},
+		{4, false, 0},
+		{0, false, 0},
+	}
+
+	for _, tt := range tests {
+		if got := NextMastery(tt.mastery, tt.correct); got != tt.want {
+			t.Errorf("NextMastery(%d, %v) = %d, ожидалось %d", tt.mastery, tt.correct, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextReview(t *testing.T) {
+	lastReview := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		mastery int
+		want    time.Time
+	}{
+		{0, lastReview.Add(10 * time.Minute)},
+		{1, lastReview.AddDate(0, 0, 1)},
+		{2, lastReview.AddDate(0, 0, 3)},
+		{3, lastReview.AddDate(0, 0, 7)},
+		{4, lastReview.AddDate(0, 0, 16)},
+		{5, lastReview.AddDate(0, 0, 35)},
+		{-3, lastReview.Add(10 * time.Minute)},
+		{9, lastReview.AddDate(0, 0, 35)},
+	}
+
+	for _, tt := range tests {
+		if got := ScheduleNextReview(tt.mastery, lastReview); !got.Equal(tt.want) {
+			t.Errorf("ScheduleNextReview(%d) = %v, ожидалось %v", tt.mastery, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextReviewGrowsWithMastery(t *testing.T) {
+	lastReview := time.Now()
+	previous := lastReview
+	for mastery := 0; mastery <= MaxMastery; mastery++ {
+		next := ScheduleNextReview(mastery, lastReview)
+		if !next.After(previous) {
+			t.Errorf("интервал для степени %d не больше предыдущего", mastery)
+		}
+		previous = next
+	}
+}
+
+func TestCheckVocabularyAnswer(t *testing.T) {
+	tests := []struct {
+		expected string
+		answer   string
+		want     bool
+	}{
+		{"яблоко", "яблоко", true},
+		{"яблоко", "  Яблоко ", true},
+		{"яблоко", "яблако", true},
+		{"apple", "aple", true},
+		{"бежать, бегать", "бегать", true},
+		{"бежать/бегать", "бежать", true},
+		{"идти; ходить", "ходить", true},
+		{"яблоко", "груша", false},
+		{"яблоко", "", false},
+		{"cat", "dog", false},
+	}
+
+	for _, tt := range tests {
+		if got := CheckVocabularyAnswer(tt.expected, tt.answer); got != tt.want {
+			t.Errorf("CheckVocabularyAnswer(%q, %q) = %v, ожидалось %v", tt.expected, tt.answer, got, tt.want)
+		}
+	}
+}
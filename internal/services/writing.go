@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// WritingMaxScore - максимальная оценка по каждому критерию письменной работы
+	WritingMaxScore = 10
+	// maxWritingSuggestions - максимальное количество советов в отзыве на письменную работу
+	maxWritingSuggestions = 3
+)
+
+// WritingFeedback - оценка письменной работы по критериям и советы по улучшению
+type WritingFeedback struct {
+	Grammar      int      `json:"grammar"`       // Грамматика, 0-10
+	Vocabulary   int      `json:"vocabulary"`    // Словарный запас, 0-10
+	Coherence    int      `json:"coherence"`     // Связность и логика текста, 0-10
+	TaskResponse int      `json:"task_response"` // Раскрытие темы, 0-10
+	Suggestions  []string `json:"suggestions"`
+}
+
+// Average возвращает среднюю оценку по всем критериям
+func (f *WritingFeedback) Average() float64 {
+	return float64(f.Grammar+f.Vocabulary+f.Coherence+f.TaskResponse) / 4
+}
+
+// WritingPrompt формирует системный промпт для оценки письменной работы ученика заданного уровня
+func WritingPrompt(level EnglishLevel) string {
+	return fmt.Sprintf(`You are an English teacher grading a short text written by a student at %s level.
+Grade the text from 0 to %d on each criterion, taking the student's level into account:
+- grammar: accuracy of grammar and spelling
+- vocabulary: range and appropriateness of words
+- coherence: logical flow and linking between sentences
+- task_response: how fully and clearly the student expresses their ideas
+Then give up to %d short, specific suggestions addressed to the student.
+Respond ONLY with a JSON object:
+{"grammar": <int>, "vocabulary": <int>, "coherence": <int>, "task_response": <int>, "suggestions": ["<suggestion>", ...]}`,
+		level, WritingMaxScore, maxWritingSuggestions)
+}
+
+// GradeWriting оценивает письменную работу ученика через OpenAI
+func (s *ExerciseService) GradeWriting(ctx context.Context, text string, level EnglishLevel) (*WritingFeedback, error) {
+	response, err := s.openAI.GenerateResponseWithModel(ctx, s.openAI.ModelForGrammar(), text, WritingPrompt(level))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка оценки письменной работы: %w", err)
+	}
+
+	return ParseWritingFeedback(response)
+}
+
+// ParseWritingFeedback разбирает ответ модели с оценками письменной работы.
+// Оценки ограничиваются диапазоном 0-10, пустые советы отбрасываются,
+// лишние обрезаются до maxWritingSuggestions
+func ParseWritingFeedback(response string) (*WritingFeedback, error) {
+	var feedback WritingFeedback
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &feedback); err != nil {
+		return nil, fmt.Errorf("ошибка разбора оценки письменной работы: %w", err)
+	}
+
+	for _, score := range []*int{&feedback.Grammar, &feedback.Vocabulary, &feedback.Coherence, &feedback.TaskResponse} {
+		if *score < 0 {
+			*score = 0
+		} else if *score > WritingMaxScore {
+			*score = WritingMaxScore
+		}
+	}
+
+	suggestions := make([]string, 0, maxWritingSuggestions)
+	for _, suggestion := range feedback.Suggestions {
+		suggestion = strings.TrimSpace(suggestion)
+		if suggestion == "" {
+			continue
+		}
+		suggestions = append(suggestions, suggestion)
+		if len(suggestions) == maxWritingSuggestions {
+			break
+		}
+	}
+	feedback.Suggestions = suggestions
+
+	return &feedback, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWritingPrompt(t *testing.T) {
+	prompt := WritingPrompt(EnglishLevelB1)
+
+	for _, want := range []string{"B1 level", "from 0 to 10", "grammar", "vocabulary", "coherence", "task_response", "up to 3"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("в промпте нет %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestParseWritingFeedback(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     WritingFeedback
+	}{
+		{
+			"ответ модели с пояснением",
+			"Here is the evaluation:\n```json\n" + `{"grammar": 7, "vocabulary": 6, "coherence": 8, "task_response": 9,
+				"suggestions": ["Use past simple for finished actions.", "Try linking words like 'however'."]}` + "\n```",
+			WritingFeedback{Grammar: 7, Vocabulary: 6, Coherence: 8, TaskResponse: 9,
+				Suggestions: []string{"Use past simple for finished actions.", "Try linking words like 'however'."}},
+		},
+		{
+			"оценки вне диапазона",
+			`{"grammar": 12, "vocabulary": -1, "coherence": 10, "task_response": 0, "suggestions": []}`,
+			WritingFeedback{Grammar: 10, Vocabulary: 0, Coherence: 10, TaskResponse: 0, Suggestions: []string{}},
+		},
+		{
+			"лишние и пустые советы",
+			`{"grammar": 5, "vocabulary": 5, "coherence": 5, "task_response": 5, "suggestions": [" one ", "", "two", "  ", "three", "four"]}`,
+			WritingFeedback{Grammar: 5, Vocabulary: 5, Coherence: 5, TaskResponse: 5, Suggestions: []string{"one", "two", "three"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWritingFeedback(tt.response)
+			if err != nil {
+				t.Fatalf("ParseWritingFeedback: %v", err)
+			}
+			if got.Grammar != tt.want.Grammar || got.Vocabulary != tt.want.Vocabulary ||
+				got.Coherence != tt.want.Coherence || got.TaskResponse != tt.want.TaskResponse ||
+				!slices.Equal(got.Suggestions, tt.want.Suggestions) {
+				t.Errorf("оценка %+v, ожидалась %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWritingFeedbackInvalid(t *testing.T) {
+	if _, err := ParseWritingFeedback("I can't grade this text."); err == nil {
+		t.Error("ParseWritingFeedback принял ответ без JSON")
+	}
+}
+
+func TestWritingFeedbackAverage(t *testing.T) {
+	feedback := WritingFeedback{Grammar: 7, Vocabulary: 6, Coherence: 8, TaskResponse: 8}
+	if got := feedback.Average(); got != 7.25 {
+		t.Errorf("Average = %v, ожидалось 7.25", got)
+	}
+}
+
+func TestGradeWriting(t *testing.T) {
+	var got OpenAIRequest
+	s := NewExerciseService(newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		chatReply(w, `{"grammar": 6, "vocabulary": 7, "coherence": 5, "task_response": 8, "suggestions": ["Check articles."]}`)
+	}))
+
+	text := "Yesterday I go to the park with my friends and we play football."
+	feedback, err := s.GradeWriting(context.Background(), text, EnglishLevelA2)
+	if err != nil {
+		t.Fatalf("GradeWriting: %v", err)
+	}
+	if feedback.Grammar != 6 || len(feedback.Suggestions) != 1 {
+		t.Errorf("оценка %+v", feedback)
+	}
+
+	if len(got.Messages) != 2 || got.Messages[0].Content != WritingPrompt(EnglishLevelA2) || got.Messages[1].Content != text {
+		t.Errorf("сообщения запроса %+v, ожидались промпт оценки и текст ученика", got.Messages)
+	}
+}